@@ -25,6 +25,20 @@ var ErrUnauthorized = BHSError{Message: "not authorized", StatusCode: 401, Code:
 // ErrAdminTokenNotFound is when admin token was not found in Block Header Service
 var ErrAdminTokenNotFound = BHSError{Message: "admin token not found", StatusCode: 401, Code: "ErrAdminTokenNotFound"}
 
+// ErrTokenExpired is when access token has expired
+var ErrTokenExpired = BHSError{Message: "access token expired", StatusCode: 401, Code: "ErrTokenExpired"}
+
+// ErrMissingScope is when token was not granted the scope required by the endpoint
+var ErrMissingScope = BHSError{Message: "token is missing required scope", StatusCode: 403, Code: "ErrMissingScope"}
+
+// ////////////////////////////////// RATE LIMIT ERRORS
+
+// ErrRateLimited is when client made too many requests in short time
+var ErrRateLimited = BHSError{Message: "too many requests", StatusCode: 429, Code: "ErrRateLimited"}
+
+// ErrQuotaExceeded is when token made all requests allowed by its daily quota
+var ErrQuotaExceeded = BHSError{Message: "daily quota exceeded", StatusCode: 429, Code: "ErrQuotaExceeded"}
+
 // ////////////////////////////////// MERKLE ROOTS ERRORS
 
 // ErrMerklerootNotFound is when provided merkleroot from user was not found in Block Header Service's database
@@ -53,6 +67,24 @@ var ErrCreateToken = BHSError{Message: "failed to create new token", StatusCode:
 // ErrDeleteToken is when delete token fails
 var ErrDeleteToken = BHSError{Message: "failed to delete token", StatusCode: 400, Code: "ErrDeleteToken"}
 
+// ErrGetTokens is when getting tokens fails
+var ErrGetTokens = BHSError{Message: "failed to get tokens", StatusCode: 500, Code: "ErrGetTokens"}
+
+// ErrUpdateToken is when update of token fails
+var ErrUpdateToken = BHSError{Message: "failed to update token", StatusCode: 500, Code: "ErrUpdateToken"}
+
+// ErrRotateToken is when rotation of token fails
+var ErrRotateToken = BHSError{Message: "failed to rotate token", StatusCode: 400, Code: "ErrRotateToken"}
+
+// ErrRotateExpiredToken is when user requested rotation of expired token
+var ErrRotateExpiredToken = BHSError{Message: "expired token cannot be rotated", StatusCode: 400, Code: "ErrRotateExpiredToken"}
+
+// ErrInvalidTokenExpiry is when user requested token expiring in the past
+var ErrInvalidTokenExpiry = BHSError{Message: "token expiration time must be in the future", StatusCode: 400, Code: "ErrInvalidTokenExpiry"}
+
+// ErrInvalidScope is when user requested unknown token scope
+var ErrInvalidScope = BHSError{Message: "invalid token scope", StatusCode: 400, Code: "ErrInvalidScope"}
+
 // ////////////////////////////////// HEADERS ERRORS
 
 // ErrAncestorHashHigher is when ancestor hash height is higher than requested header
@@ -76,6 +108,18 @@ var ErrHeadersForGivenRangeNotFound = BHSError{Message: "could not find headers
 // ErrHeaderStopHeightNotFound is when stop height for given heade was not found
 var ErrHeaderStopHeightNotFound = BHSError{Message: "could not find stop height for given header", StatusCode: 404, Code: "ErrHeaderStopHeightNotFound"}
 
+// ErrInvalidHeaderHash is when provided header hash is not a valid hash
+var ErrInvalidHeaderHash = BHSError{Message: "invalid header hash", StatusCode: 400, Code: "ErrInvalidHeaderHash"}
+
+// ErrWatchHeadersBadBody is when request for watching headers confirmations has wrong body
+var ErrWatchHeadersBadBody = BHSError{Message: "at least one header hash is required", StatusCode: 400, Code: "ErrWatchHeadersBadBody"}
+
+// ErrWatchHeaders is when it fails to store headers watched for confirmations
+var ErrWatchHeaders = BHSError{Message: "failed to store watched headers", StatusCode: 500, Code: "ErrWatchHeaders"}
+
+// ErrHeaderWatchNotFound is when header isn't watched for confirmations by the token
+var ErrHeaderWatchNotFound = BHSError{Message: "header watch not found", StatusCode: 404, Code: "ErrHeaderWatchNotFound"}
+
 // ////////////////////////////////// TIPS ERRORS
 
 // ErrGetTips is when it fails to get tips
@@ -103,3 +147,60 @@ var ErrGetAllWebhooks = BHSError{Message: "failed to get all webhooks", StatusCo
 
 // ErrDeleteWebhook is when it failed to delete a webhook
 var ErrDeleteWebhook = BHSError{Message: "failed to delete webhook", StatusCode: 400, Code: "ErrDeleteWebhook"}
+
+// ErrWebhookURLNotAllowed is when webhook url is not allowed by the webhook policy
+var ErrWebhookURLNotAllowed = BHSError{Message: "webhook url is not allowed", StatusCode: 400, Code: "ErrWebhookURLNotAllowed"}
+
+// ErrWebhookVerification is when webhook did not echo the verification challenge
+var ErrWebhookVerification = BHSError{Message: "webhook did not echo the verification challenge", StatusCode: 400, Code: "ErrWebhookVerification"}
+
+// ErrInvalidWebhookFormat is when requested format of webhook events is not supported
+var ErrInvalidWebhookFormat = BHSError{Message: "format must be one of json, cloudevents or cloudevents-binary", StatusCode: 400, Code: "ErrInvalidWebhookFormat"}
+
+// ////////////////////////////////// MERKLE ROOT WATCH ERRORS
+
+// ErrWatchMerklerootsBadBody is when request for watching merkleroots has wrong body
+var ErrWatchMerklerootsBadBody = BHSError{Message: "at least one merkleroot or BUMP is required", StatusCode: 400, Code: "ErrWatchMerklerootsBadBody"}
+
+// ErrInvalidBUMP is when provided BUMP could not be parsed
+var ErrInvalidBUMP = BHSError{Message: "invalid BUMP", StatusCode: 400, Code: "ErrInvalidBUMP"}
+
+// ErrInvalidMerkleRoot is when merkleroot to watch is not a hex encoded hash
+var ErrInvalidMerkleRoot = BHSError{Message: "merkleroot must be a hex encoded hash", StatusCode: 400, Code: "ErrInvalidMerkleRoot"}
+
+// ErrMerklerootsChannelForbidden is when client subscribes to the channel of merkle roots watched by another token
+var ErrMerklerootsChannelForbidden = BHSError{Message: "merkleroots channel of another token", StatusCode: 403, Code: "ErrMerklerootsChannelForbidden"}
+
+// ErrCreateMerklerootWatch is when it fails to create a merkleroot watch
+var ErrCreateMerklerootWatch = BHSError{Message: "failed to create a merkleroot watch", StatusCode: 400, Code: "ErrCreateMerklerootWatch"}
+
+// ErrMerklerootWatchNotFound is when merkleroot watch was not found
+var ErrMerklerootWatchNotFound = BHSError{Message: "merkleroot watch not found", StatusCode: 404, Code: "ErrMerklerootWatchNotFound"}
+
+// ErrGetAllMerklerootWatches is when it failed to get all merkleroot watches
+var ErrGetAllMerklerootWatches = BHSError{Message: "failed to get all merkleroot watches", StatusCode: 400, Code: "ErrGetAllMerklerootWatches"}
+
+// ErrDeleteMerklerootWatch is when it failed to delete a merkleroot watch
+var ErrDeleteMerklerootWatch = BHSError{Message: "failed to delete merkleroot watch", StatusCode: 400, Code: "ErrDeleteMerklerootWatch"}
+
+// ErrUpdateMerklerootWatch is when it failed to update a merkleroot watch
+var ErrUpdateMerklerootWatch = BHSError{Message: "failed to update merkleroot watch", StatusCode: 400, Code: "ErrUpdateMerklerootWatch"}
+
+// ////////////////////////////////// EVENTS STREAM ERRORS
+
+// ErrInvalidEventsChannel is when requested events channel or its filters are invalid
+var ErrInvalidEventsChannel = BHSError{Message: "invalid events channel", StatusCode: 400, Code: "ErrInvalidEventsChannel"}
+
+// ErrInvalidLastEventID is when provided Last-Event-ID is not an id of event sent by Block Header Service
+var ErrInvalidLastEventID = BHSError{Message: "invalid Last-Event-ID", StatusCode: 400, Code: "ErrInvalidLastEventID"}
+
+// ////////////////////////////////// AUDIT ERRORS
+
+// ErrCreateAuditRecord is when it fails to save a record of the audit log
+var ErrCreateAuditRecord = BHSError{Message: "failed to create audit record", StatusCode: 500, Code: "ErrCreateAuditRecord"}
+
+// ErrGetAuditRecords is when it fails to get records of the audit log
+var ErrGetAuditRecords = BHSError{Message: "failed to get audit records", StatusCode: 500, Code: "ErrGetAuditRecords"}
+
+// ErrInvalidAuditFilter is when the filter of audit records is invalid
+var ErrInvalidAuditFilter = BHSError{Message: "invalid audit filter", StatusCode: 400, Code: "ErrInvalidAuditFilter"}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/database"
+	sqlrepository "github.com/bitcoin-sv/block-headers-service/database/repository"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/rs/zerolog"
+)
+
+const commandsUsage = `Commands:
+  migrate up         apply all pending migrations of the database schema
+  migrate down       roll back the last applied migration
+  migrate to <n>     apply or roll back migrations until the schema has version n (0 rolls back all)
+  migrate status     show the version of the schema and pending migrations
+  signing-key <file> generate ed25519 key to sign exported headers, its public key is printed
+  check [--repair]   check integrity of stored headers, with --repair recompute their states and work`
+
+// Command is run with the loaded config instead of starting the service.
+type Command func(cfg *config.AppConfig, log *zerolog.Logger) error
+
+func parseCommand(args []string, cli *cliFlags) (Command, error) {
+	if len(args) == 0 {
+		if cli.repair {
+			return nil, fmt.Errorf("--repair is a flag of the check command\n%s", commandsUsage)
+		}
+		return nil, nil
+	}
+
+	switch args[0] {
+	case "migrate":
+		return migrateCommand(args[1:])
+	case "signing-key":
+		return signingKeyCommand(args[1:])
+	case "check":
+		return checkCommand(args[1:], cli.repair)
+	default:
+		return nil, fmt.Errorf("unknown command %s\n%s", args[0], commandsUsage)
+	}
+}
+
+func exportCommand(opts database.ExportOptions) (Command, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return func(cfg *config.AppConfig, log *zerolog.Logger) error {
+		return database.ExportHeaders(cfg, opts, log)
+	}, nil
+}
+
+func migrateCommand(args []string) (Command, error) {
+	action, err := parseMigrateArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(cfg *config.AppConfig, log *zerolog.Logger) (err error) {
+		m, err := database.NewMigrator(cfg.Db, log)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := m.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}()
+
+		if err = action(m); err != nil {
+			return err
+		}
+
+		status, err := m.Status()
+		if err != nil {
+			return err
+		}
+		printMigrationStatus(status)
+		return nil
+	}, nil
+}
+
+func signingKeyCommand(args []string) (Command, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("invalid signing-key command %s\n%s", strings.Join(args, " "), commandsUsage)
+	}
+
+	return func(*config.AppConfig, *zerolog.Logger) error {
+		publicKey, err := database.GenerateSigningKey(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("public key: %s\n", publicKey)
+		return nil
+	}, nil
+}
+
+func checkCommand(args []string, repair bool) (Command, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("invalid check command %s\n%s", strings.Join(args, " "), commandsUsage)
+	}
+
+	return func(cfg *config.AppConfig, log *zerolog.Logger) (err error) {
+		// headers can't be repaired while the service synchronizes them, and bolt databases can't be opened at all
+		store, err := database.ConnectStore(cfg.Db, repair, log)
+		if errors.Is(err, database.ErrDatabaseLocked) {
+			command := "check"
+			if repair {
+				command = "check --repair"
+			}
+			return fmt.Errorf("stop the service before running %s: %w", command, err)
+		}
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := store.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}()
+
+		repo := &repository.Repositories{
+			Headers: sqlrepository.NewHeadersRepository(store),
+		}
+		integrity := service.NewIntegrityService(repo, log)
+
+		check := integrity.Check
+		if repair {
+			check = integrity.Repair
+		}
+		report, err := check()
+		if err != nil {
+			return err
+		}
+		printIntegrityReport(report)
+
+		if !repair && !report.Consistent() {
+			return errors.New("headers in the database are inconsistent, run check --repair to repair them")
+		}
+		return nil
+	}, nil
+}
+
+func printIntegrityReport(report *domains.IntegrityReport) {
+	fmt.Printf("tip height: %d\n", report.TipHeight)
+	fmt.Printf("checked headers: %d\n", report.HeadersChecked)
+	fmt.Printf("issues: %d\n", report.IssuesCount)
+	for _, issue := range report.Issues {
+		fmt.Printf("  %s at height %d %s: %s\n", issue.Kind, issue.Height, issue.Hash, issue.Details)
+	}
+	if len(report.Issues) < report.IssuesCount {
+		fmt.Printf("  ... %d more\n", report.IssuesCount-len(report.Issues))
+	}
+	fmt.Printf("repaired headers: %d\n", report.Repaired)
+}
+
+func parseMigrateArgs(args []string) (func(m *database.Migrator) error, error) {
+	if len(args) == 0 {
+		return nil, errors.New(commandsUsage)
+	}
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		return (*database.Migrator).Up, nil
+	case args[0] == "down" && len(args) == 1:
+		return (*database.Migrator).Down, nil
+	case args[0] == "status" && len(args) == 1:
+		return func(*database.Migrator) error { return nil }, nil
+	case args[0] == "to" && len(args) == 2:
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %s: %w", args[1], err)
+		}
+		return func(m *database.Migrator) error { return m.To(uint(version)) }, nil
+	default:
+		return nil, fmt.Errorf("invalid migrate command %s\n%s", strings.Join(args, " "), commandsUsage)
+	}
+}
+
+func printMigrationStatus(status *database.MigrationStatus) {
+	pending := "none"
+	if len(status.Pending) > 0 {
+		versions := make([]string, 0, len(status.Pending))
+		for _, v := range status.Pending {
+			versions = append(versions, strconv.FormatUint(uint64(v), 10))
+		}
+		pending = strings.Join(versions, ", ")
+	}
+
+	fmt.Printf("version: %d\n", status.Version)
+	fmt.Printf("dirty: %t\n", status.Dirty)
+	fmt.Printf("latest: %d\n", status.Latest)
+	fmt.Printf("pending: %s\n", pending)
+}
@@ -16,12 +16,21 @@ type cliFlags struct {
 	showHelp      bool `mapstructure:"showHelp"`
 	exportHeaders bool `mapstructure:"exportHeaders"`
 	dumpConfig    bool `mapstructure:"dumpConfig"`
+
+	exportFormat     string `mapstructure:"exportFormat"`
+	exportOutput     string `mapstructure:"exportOutput"`
+	exportFromHeight int32  `mapstructure:"exportFromHeight"`
+	exportToHeight   int32  `mapstructure:"exportToHeight"`
+	exportSigningKey string `mapstructure:"exportSigningKey"`
+
+	repair bool `mapstructure:"repair"`
 }
 
-// LoadFlags loads flags from command line and binds them to the config
-func LoadFlags(cfg *config.AppConfig) error {
+// LoadFlags loads flags from command line.
+// It returns the command to run instead of starting the service, or nil when no command was passed.
+func LoadFlags() (Command, error) {
 	if !anyFlagsPassed() {
-		return nil
+		return nil, nil
 	}
 
 	cli := &cliFlags{}
@@ -40,9 +49,19 @@ func LoadFlags(cfg *config.AppConfig) error {
 		os.Exit(1)
 	}
 
-	parseCliFlags(cli, cfg, appFlags)
+	parseCliFlags(cli, appFlags)
+
+	if cli.exportHeaders {
+		return exportCommand(database.ExportOptions{
+			Format:         database.ExportFormat(cli.exportFormat),
+			OutputPath:     cli.exportOutput,
+			FromHeight:     cli.exportFromHeight,
+			ToHeight:       cli.exportToHeight,
+			SigningKeyFile: cli.exportSigningKey,
+		})
+	}
 
-	return nil
+	return parseCommand(appFlags.Args(), cli)
 }
 
 func anyFlagsPassed() bool {
@@ -52,17 +71,24 @@ func anyFlagsPassed() bool {
 func initFlags(fs *pflag.FlagSet, cliFlags *cliFlags) {
 	fs.StringP(config.ConfigFilePathKey, "C", "", "custom config file path")
 
-	fs.BoolVarP(&cliFlags.exportHeaders, "export_headers", "e", false, "export headers from database to CSV file")
+	fs.BoolVarP(&cliFlags.exportHeaders, "export_headers", "e", false, "export headers from database to a file")
+	fs.StringVar(&cliFlags.exportFormat, "export_format", string(database.ExportCSVGzip), "format of exported headers [csv.gz|csv|raw|jsonl]")
+	fs.StringVar(&cliFlags.exportOutput, "export_output", "", "path of the file with exported headers (default prepared_db_file_path for csv.gz, headers file next to it otherwise)")
+	fs.Int32Var(&cliFlags.exportFromHeight, "export_from_height", 0, "first height of exported headers")
+	fs.Int32Var(&cliFlags.exportToHeight, "export_to_height", 0, "last height of exported headers (default the tip of the longest chain)")
+	fs.StringVar(&cliFlags.exportSigningKey, "export_signing_key", "", "path of the file with hex encoded ed25519 key to write signature of exported file next to it")
+	fs.BoolVar(&cliFlags.repair, "repair", false, "repair states and work of headers found by the check command")
 	fs.BoolVarP(&cliFlags.showHelp, "help", "h", false, "show help")
 	fs.BoolVarP(&cliFlags.showVersion, "version", "v", false, "show version")
 	fs.BoolVarP(&cliFlags.dumpConfig, "dump_config", "d", false, "dump config to file, specified by config_file flag")
 }
 
-func parseCliFlags(cli *cliFlags, cfg *config.AppConfig, appFlags *pflag.FlagSet) {
+func parseCliFlags(cli *cliFlags, appFlags *pflag.FlagSet) {
 	log := logging.GetDefaultLogger().With().Str("service", "flags").Logger()
 
 	if cli.showHelp {
 		appFlags.PrintDefaults()
+		fmt.Println(commandsUsage)
 		os.Exit(0)
 	}
 
@@ -71,14 +97,6 @@ func parseCliFlags(cli *cliFlags, cfg *config.AppConfig, appFlags *pflag.FlagSet
 		os.Exit(0)
 	}
 
-	if cli.exportHeaders {
-		if err := database.ExportHeaders(cfg, &log); err != nil {
-			log.Error().Msgf("error while exporting headers: %v", err.Error())
-			os.Exit(1)
-		}
-		os.Exit(0)
-	}
-
 	if cli.dumpConfig {
 		configPath := viper.GetString(config.ConfigFilePathKey)
 		if configPath == "" {
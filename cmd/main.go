@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"os"
@@ -12,18 +13,17 @@ import (
 	"runtime"
 	"runtime/debug"
 	"syscall"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/cli"
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/database"
 	sqlrepository "github.com/bitcoin-sv/block-headers-service/database/repository"
-	"github.com/bitcoin-sv/block-headers-service/database/sql"
 	p2pexp "github.com/bitcoin-sv/block-headers-service/internal/transports/p2p"
 	"github.com/bitcoin-sv/block-headers-service/internal/wire"
 	"github.com/bitcoin-sv/block-headers-service/logging"
 	"github.com/bitcoin-sv/block-headers-service/metrics"
 	"github.com/bitcoin-sv/block-headers-service/notification"
-	"github.com/bitcoin-sv/block-headers-service/repository"
 	"github.com/bitcoin-sv/block-headers-service/service"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints"
 	httpserver "github.com/bitcoin-sv/block-headers-service/transports/http/server"
@@ -36,6 +36,9 @@ import (
 // (e.g. go build -ldflags "-X main.version=1.2.3").
 var version = "development"
 
+// notifierShutdownTimeout is the maximum time of delivering notifications queued before shutdown.
+const notifierShutdownTimeout = 10 * time.Second
+
 type bsvP2PServer interface {
 	Start() error
 	Shutdown() error
@@ -54,7 +57,8 @@ func main() {
 
 	defaultCfg := config.GetDefaultAppConfig()
 
-	if err := cli.LoadFlags(defaultCfg); err != nil {
+	command, err := cli.LoadFlags()
+	if err != nil {
 		defaultLog.Error().Msgf("cannot load flags because of error: %v", err)
 		os.Exit(1)
 	}
@@ -70,12 +74,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	if command != nil {
+		if err := command(cfg, log); err != nil {
+			log.Error().Msgf("command failed: %v", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if cfg.Metrics.Enabled {
 		log.Info().Msg("metrics enabled")
 		metrics.EnableMetrics()
 	}
 
-	db, err := database.Init(cfg, log)
+	store, err := database.Open(cfg, log)
 	if err != nil {
 		log.Error().Msgf("cannot setup database because of error: %v", err)
 		os.Exit(1)
@@ -98,12 +110,7 @@ func main() {
 
 	peers := make(map[*peerpkg.Peer]*peerpkg.SyncState)
 
-	headersStore := sql.NewHeadersDb(db, log)
-	repo := &repository.Repositories{
-		Headers:  sqlrepository.NewHeadersRepository(headersStore),
-		Tokens:   sqlrepository.NewTokensRepository(headersStore),
-		Webhooks: sqlrepository.NewWebhooksRepository(headersStore),
-	}
+	repo := sqlrepository.NewRepositories(store)
 
 	hs := service.NewServices(service.Dept{
 		Repositories: repo,
@@ -119,15 +126,27 @@ func main() {
 
 	server.ApplyConfiguration(endpoints.SetupRoutes(hs, cfg.HTTP))
 
-	ws, err := websocket.NewServer(log, hs, cfg.HTTP.UseAuth)
+	ws, err := websocket.NewServer(log, hs, cfg.HTTP.UseAuth, cfg.Websocket)
 	if err != nil {
 		log.Error().Msgf("failed to init a new websocket server: %v\n", err)
 		os.Exit(1)
 	}
 	server.ApplyConfiguration(ws.SetupEntrypoint)
 
-	hs.Notifier.AddChannel(hs.Webhooks)
-	hs.Notifier.AddChannel(notification.NewWebsocketChannel(log, ws.Publisher(), cfg.Websocket))
+	hs.Notifier.AddChannel("webhooks", hs.Webhooks)
+	hs.Notifier.AddChannel("merkleroot_watches", hs.MerkleRootWatches)
+	hs.Notifier.AddChannel("events", hs.EventStream)
+	hs.Notifier.AddChannel("websocket", notification.NewWebsocketChannel(log, ws.Publisher(), ws.Subscriptions(), hs.EventEncoder, cfg.Websocket))
+
+	var broker *notification.BrokerChannel
+	if cfg.Broker.Enabled {
+		broker, err = notification.NewBrokerChannel(cfg.Broker, hs.EventEncoder, log)
+		if err != nil {
+			log.Error().Msgf("failed to init broker channel: %v", err)
+			os.Exit(1)
+		}
+		hs.Notifier.AddChannel("broker", broker)
+	}
 
 	go func() {
 		if err := server.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -160,6 +179,18 @@ func main() {
 		}
 	}()
 
+	hs.Prune.Start()
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			if err := server.ReloadCertificates(); err != nil {
+				log.Error().Msgf("cannot reload TLS certificates: %v", err)
+			}
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 
@@ -169,6 +200,20 @@ func main() {
 		log.Error().Msgf("failed to stop p2p server: %v", err)
 	}
 
+	hs.Prune.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifierShutdownTimeout)
+	defer cancel()
+	if err := hs.Notifier.Shutdown(ctx); err != nil {
+		log.Error().Msgf("failed to deliver pending notifications: %v", err)
+	}
+
+	if broker != nil {
+		if err := broker.Close(); err != nil {
+			log.Error().Msgf("failed to close broker channel: %v", err)
+		}
+	}
+
 	if err := ws.Shutdown(); err != nil {
 		log.Error().Msgf("failed to stop websocket server: %v", err)
 	}
@@ -176,4 +221,8 @@ func main() {
 	if err := server.Shutdown(); err != nil {
 		log.Error().Msgf("failed to stop http server: %v", err)
 	}
+
+	if err := store.Close(); err != nil {
+		log.Error().Msgf("failed to close database: %v", err)
+	}
 }
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
+	"net/netip"
 	"os"
 	"time"
 
@@ -40,6 +43,44 @@ const (
 	DBSQLite DbEngine = "sqlite"
 	// DBPostgreSQL is the value representing postgres database engine.
 	DBPostgreSQL DbEngine = "postgres"
+	// DBBolt is the value representing the embedded bolt key-value database engine.
+	DBBolt DbEngine = "bolt"
+)
+
+// OverflowPolicy defines what happens with events when queue of a notification channel is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock blocks the notifying component until the channel catches up, at most for the block timeout.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest drops the oldest queued event to make room for the new one.
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+	// OverflowCoalesce keeps only the newest queued event of each kind, ex. only the latest tip.
+	OverflowCoalesce OverflowPolicy = "coalesce"
+)
+
+// SyncPolicy defines how a notification channel receives header events during initial synchronization of headers.
+type SyncPolicy string
+
+const (
+	// SyncDeliver passes every header event to the channel.
+	SyncDeliver SyncPolicy = "deliver"
+	// SyncSuppress drops header events until the headers are synchronized.
+	SyncSuppress SyncPolicy = "suppress"
+	// SyncProgress replaces header events with periodic synchronization progress events.
+	SyncProgress SyncPolicy = "progress"
+)
+
+// BrokerType message broker type.
+type BrokerType string
+
+const (
+	// BrokerNATS is the value representing NATS JetStream broker.
+	BrokerNATS BrokerType = "nats"
+	// BrokerRedis is the value representing Redis Streams broker.
+	BrokerRedis BrokerType = "redis"
+	// BrokerKafka is the value representing Kafka broker.
+	BrokerKafka BrokerType = "kafka"
 )
 
 // Version returns the version of the application.
@@ -49,29 +90,38 @@ func Version() string {
 
 // AppConfig returns strongly typed config values.
 type AppConfig struct {
-	Db         *DbConfig         `mapstructure:"db"`
-	P2P        *P2PConfig        `mapstructure:"p2p"`
-	MerkleRoot *MerkleRootConfig `mapstructure:"merkleroot"`
-	Webhook    *WebhookConfig    `mapstructure:"webhook"`
-	Websocket  *WebsocketConfig  `mapstructure:"websocket"`
-	HTTP       *HTTPConfig       `mapstructure:"http"`
-	Logging    *LoggingConfig    `mapstructure:"logging"`
-	Metrics    *MetricsConfig    `mapstructure:"metrics"`
+	Db           *DbConfig           `mapstructure:"db"`
+	P2P          *P2PConfig          `mapstructure:"p2p"`
+	MerkleRoot   *MerkleRootConfig   `mapstructure:"merkleroot"`
+	Webhook      *WebhookConfig      `mapstructure:"webhook"`
+	Websocket    *WebsocketConfig    `mapstructure:"websocket"`
+	Notification *NotificationConfig `mapstructure:"notification"`
+	Broker       *BrokerConfig       `mapstructure:"broker"`
+	Prune        *PruneConfig        `mapstructure:"prune"`
+	HTTP         *HTTPConfig         `mapstructure:"http"`
+	Logging      *LoggingConfig      `mapstructure:"logging"`
+	Metrics      *MetricsConfig      `mapstructure:"metrics"`
 }
 
 // DbConfig represents a database connection.
 type DbConfig struct {
-	// Engine is the engine of database [sqlite|postgres].
+	// Engine is the engine of database [sqlite|postgres|bolt].
 	Engine DbEngine `mapstructure:"engine"`
-	// SchemaPath is the path to the database schema.
+	// SchemaPath is the path to migrations of the database schema, with migrations of the engine in its subdirectory.
+	// Migrations built into the binary are used when it is empty.
 	SchemaPath string `mapstructure:"schema_path"`
 	// PreparedDb is a flag for enabling prepared database.
 	PreparedDb bool `mapstructure:"prepared_db"`
 	// PreparedDbFilePath is the path to the prepared database file.
 	PreparedDbFilePath string `mapstructure:"prepared_db_file_path"`
+	// PreparedDbPublicKey is the hex encoded ed25519 public key, when it is set the prepared database file is imported only with its valid signature.
+	PreparedDbPublicKey string `mapstructure:"prepared_db_public_key"`
+	// PreparedDbSignatureFilePath is the path to the signature of the prepared database file, by default it is the file path with .sig extension.
+	PreparedDbSignatureFilePath string `mapstructure:"prepared_db_signature_file_path"`
 
 	Postgres PostgreSQLConfig `mapstructure:"postgres"`
 	SQLite   SQLiteConfig     `mapstructure:"sqlite"`
+	Bolt     BoltConfig       `mapstructure:"bolt"`
 }
 
 // SQLiteConfig represents a sqlite config.
@@ -80,6 +130,12 @@ type SQLiteConfig struct {
 	FilePath string `mapstructure:"file_path"`
 }
 
+// BoltConfig represents a bolt config.
+type BoltConfig struct {
+	// FilePath is the path to the database file.
+	FilePath string `mapstructure:"file_path"`
+}
+
 // PostgreSQLConfig represents a postgres config.
 type PostgreSQLConfig struct {
 	Host     string `mapstructure:"host"`
@@ -98,8 +154,36 @@ type MerkleRootConfig struct {
 
 // WebhookConfig represents a webhook config.
 type WebhookConfig struct {
-	// MaxTries is the maximum number of tries to send a webhook.
+	// MaxTries is the number of consecutive failed notifications after which the circuit of the webhook opens.
 	MaxTries int `mapstructure:"max_tries"`
+	// Workers is the maximum number of webhooks notified concurrently.
+	Workers int `mapstructure:"workers"`
+	// ConnectTimeout is the maximum duration of connecting to a webhook.
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+	// ResponseTimeout is the maximum duration of waiting for a webhook response after sending a notification.
+	ResponseTimeout time.Duration `mapstructure:"response_timeout"`
+	// CircuitOpenDuration is how long notifications of a failing webhook are skipped before a trial notification.
+	CircuitOpenDuration time.Duration `mapstructure:"circuit_open_duration"`
+	// CircuitMaxOpenDuration limits the open duration, which doubles after every failed trial notification.
+	CircuitMaxOpenDuration time.Duration `mapstructure:"circuit_max_open_duration"`
+	// Policy restricts urls to which webhooks can send events.
+	Policy *WebhookPolicyConfig `mapstructure:"policy"`
+	// Verification is a flag for requiring webhook to echo a challenge sent on registration before it becomes active.
+	Verification bool `mapstructure:"verification"`
+}
+
+// WebhookPolicyConfig represents a policy of webhook targets, enforced on registration and when connecting to them.
+type WebhookPolicyConfig struct {
+	// AllowedSchemes are the only allowed schemes of webhook urls.
+	AllowedSchemes []string `mapstructure:"allowed_schemes"`
+	// AllowedHosts are the only allowed hostnames of webhook urls, ex. "hooks.example.com" or "*.example.com". Empty allows any host.
+	AllowedHosts []string `mapstructure:"allowed_hosts"`
+	// DeniedHosts are hostnames to which webhooks cannot be registered.
+	DeniedHosts []string `mapstructure:"denied_hosts"`
+	// DeniedCIDRs are networks to which webhooks cannot connect.
+	DeniedCIDRs []string `mapstructure:"denied_cidrs"`
+	// AllowedCIDRs are networks excluded from DeniedCIDRs.
+	AllowedCIDRs []string `mapstructure:"allowed_cidrs"`
 }
 
 // WebsocketConfig represents a websocket config.
@@ -108,6 +192,129 @@ type WebsocketConfig struct {
 	HistoryMax int `mapstructure:"history_max"`
 	// HistoryTTL is the maximum duration for keeping history in memory.
 	HistoryTTL int `mapstructure:"history_ttl"`
+	// RecoveryMax is the maximum number of missed events sent from the database to a resubscribing client at once.
+	RecoveryMax int `mapstructure:"recovery_max"`
+	// HTTPStreamEnabled is a flag for enabling centrifuge HTTP-streaming transport for clients which cannot use websocket.
+	HTTPStreamEnabled bool `mapstructure:"http_stream_enabled"`
+}
+
+// NotificationConfig represents a config of notifications about chain events.
+type NotificationConfig struct {
+	// ConfirmationDepth is the number of confirmations after which a header is reported as confirmed, 0 disables it.
+	ConfirmationDepth int `mapstructure:"confirmation_depth"`
+	// ConfirmAllHeaders is a flag for reporting confirmations of every header instead of only the watched ones.
+	ConfirmAllHeaders bool `mapstructure:"confirm_all_headers"`
+	// QueueSize is the number of events queued for a single notification channel.
+	QueueSize int `mapstructure:"queue_size"`
+	// OverflowPolicy is the behaviour of a notification channel with full queue [block|drop-oldest|coalesce].
+	OverflowPolicy OverflowPolicy `mapstructure:"overflow_policy"`
+	// BlockTimeout is the maximum time of waiting for a channel with block overflow policy, after which the oldest event is dropped.
+	BlockTimeout time.Duration `mapstructure:"block_timeout"`
+	// SyncPolicy is the behaviour of a notification channel during initial synchronization of headers [deliver|suppress|progress].
+	SyncPolicy SyncPolicy `mapstructure:"sync_policy"`
+	// SyncProgressInterval is the number of headers between synchronization progress events.
+	SyncProgressInterval int32 `mapstructure:"sync_progress_interval"`
+	// Channels overrides settings of single channels [webhooks|websocket|events|merkleroot_watches|broker].
+	Channels map[string]*ChannelConfig `mapstructure:"channels"`
+}
+
+// ChannelConfig represents settings of a single notification channel.
+type ChannelConfig struct {
+	// QueueSize is the number of events queued for the channel, 0 uses notification.queue_size.
+	QueueSize int `mapstructure:"queue_size"`
+	// OverflowPolicy is the behaviour of the channel with full queue, empty uses notification.overflow_policy.
+	OverflowPolicy OverflowPolicy `mapstructure:"overflow_policy"`
+	// BlockTimeout is the maximum time of waiting for the channel with block policy, 0 uses notification.block_timeout.
+	BlockTimeout time.Duration `mapstructure:"block_timeout"`
+	// SyncPolicy is the behaviour of the channel during synchronization, empty uses notification.sync_policy.
+	SyncPolicy SyncPolicy `mapstructure:"sync_policy"`
+}
+
+// ForChannel returns settings of the channel with given name.
+func (c *NotificationConfig) ForChannel(name string) ChannelConfig {
+	settings := ChannelConfig{QueueSize: c.QueueSize, OverflowPolicy: c.OverflowPolicy, BlockTimeout: c.BlockTimeout, SyncPolicy: c.SyncPolicy}
+	if override, ok := c.Channels[name]; ok && override != nil {
+		if override.QueueSize > 0 {
+			settings.QueueSize = override.QueueSize
+		}
+		if override.OverflowPolicy != "" {
+			settings.OverflowPolicy = override.OverflowPolicy
+		}
+		if override.BlockTimeout > 0 {
+			settings.BlockTimeout = override.BlockTimeout
+		}
+		if override.SyncPolicy != "" {
+			settings.SyncPolicy = override.SyncPolicy
+		}
+	}
+	return settings
+}
+
+// BrokerConfig represents a config of message broker channel publishing notifications.
+type BrokerConfig struct {
+	// Enabled is a flag for enabling publishing of notifications to the broker.
+	Enabled bool `mapstructure:"enabled"`
+	// Type is the type of broker [nats|redis|kafka].
+	Type BrokerType `mapstructure:"type"`
+	// URL is the address of the broker, ex. nats://localhost:4222, redis://localhost:6379/0, kafka://localhost:9092.
+	URL string `mapstructure:"url"`
+	// SubjectTemplate is the template of NATS subject, Redis stream or Kafka topic to which event is published,
+	// supporting placeholders {type}, {operation} and {state}.
+	SubjectTemplate string `mapstructure:"subject_template"`
+	// PublishTimeout is the maximum duration of waiting for the broker to acknowledge a single event.
+	PublishTimeout time.Duration `mapstructure:"publish_timeout"`
+	// RetryInterval is the duration between retries of unacknowledged events.
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+	// MaxRetries is the maximum number of retries of an unacknowledged event, after which the event is dropped.
+	MaxRetries int `mapstructure:"max_retries"`
+	// Format is the format of published events [json|cloudevents].
+	Format string `mapstructure:"format"`
+	// Username authenticates to the broker, with NATS user and password, Redis ACL or Kafka SASL.
+	// It overrides the user of the url.
+	Username string `mapstructure:"username"`
+	// Password of the user authenticating to the broker, it overrides the password of the url.
+	Password string `mapstructure:"password"`
+	// NATSCredentialsFile is the path to the NATS credentials file (user JWT and nkey seed).
+	NATSCredentialsFile string `mapstructure:"nats_credentials_file"`
+	// KafkaSASLMechanism is the SASL mechanism authenticating Kafka user [plain|scram-sha-256|scram-sha-512].
+	KafkaSASLMechanism string `mapstructure:"kafka_sasl_mechanism"`
+	// TLS of the connection to the broker.
+	TLS BrokerTLSConfig `mapstructure:"tls"`
+}
+
+// Supported SASL mechanisms of Kafka.
+const (
+	KafkaSASLPlain       = "plain"
+	KafkaSASLScramSHA256 = "scram-sha-256"
+	KafkaSASLScramSHA512 = "scram-sha-512"
+)
+
+// BrokerTLSConfig represents TLS of the connection to the message broker.
+type BrokerTLSConfig struct {
+	// Enabled is a flag for connecting to the broker over TLS, NATS tls:// and Redis rediss:// urls enable it as well.
+	Enabled bool `mapstructure:"enabled"`
+	// CAFile is the path to PEM encoded certificates of authorities verifying the broker, system ones are used when empty.
+	CAFile string `mapstructure:"ca_file"`
+	// CertFile is the path to PEM encoded client certificate, for brokers authenticating clients with certificates.
+	CertFile string `mapstructure:"cert_file"`
+	// KeyFile is the path to PEM encoded private key of the client certificate.
+	KeyFile string `mapstructure:"key_file"`
+	// InsecureSkipVerify is a flag for not verifying the certificate of the broker, only for testing.
+	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
+}
+
+// PruneConfig represents the retention policy of headers outside the longest chain.
+type PruneConfig struct {
+	// Enabled is a flag for pruning headers periodically, the admin endpoint prunes them regardless of it.
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the duration between periodic prunes.
+	Interval time.Duration `mapstructure:"interval"`
+	// StaleDepth is the number of blocks below the tip, stale branches forked deeper are pruned, 0 keeps all stale branches.
+	StaleDepth int32 `mapstructure:"stale_depth"`
+	// OrphanMaxAge is the time since orphan headers were stored after which they are pruned, 0 keeps all orphans.
+	OrphanMaxAge time.Duration `mapstructure:"orphan_max_age"`
+	// RejectedMaxAge is the time since rejected headers were stored after which they are pruned, 0 keeps all rejected headers.
+	RejectedMaxAge time.Duration `mapstructure:"rejected_max_age"`
 }
 
 // HTTPConfig represents a HTTPConfig config.
@@ -124,6 +331,112 @@ type HTTPConfig struct {
 	AuthToken string `mapstructure:"auth_token"`
 	// ProfilingEndpointsEnabled is a flag for enabling additional endpoits for profiling with use of pprof.
 	ProfilingEndpointsEnabled bool `mapstructure:"debug_profiling"`
+	// RateLimit limits requests of every token, or every IP address when authorization is disabled.
+	RateLimit *RateLimitConfig `mapstructure:"rate_limit"`
+	// JWT enables authorization with JWTs signed by an identity provider, in addition to tokens created by the service.
+	JWT *JWTConfig `mapstructure:"jwt"`
+	// TLS enables serving the API and the websocket over TLS.
+	TLS *TLSConfig `mapstructure:"tls"`
+}
+
+// Supported minimum TLS versions.
+const (
+	TLSVersion12 = "1.2"
+	TLSVersion13 = "1.3"
+)
+
+// TLSConfig represents TLS of the HTTP server and authentication with client certificates.
+type TLSConfig struct {
+	// Enabled is a flag for serving over TLS instead of plain HTTP.
+	Enabled bool `mapstructure:"enabled"`
+	// CertFile is a path to the PEM encoded certificate chain of the server.
+	CertFile string `mapstructure:"cert_file"`
+	// KeyFile is a path to the PEM encoded private key of the server.
+	KeyFile string `mapstructure:"key_file"`
+	// MinVersion is the minimum accepted TLS version [1.2|1.3].
+	MinVersion string `mapstructure:"min_version"`
+	// ClientCAFile is a path to the PEM encoded bundle of CAs verifying client certificates, client certificates are ignored when empty.
+	ClientCAFile string `mapstructure:"client_ca_file"`
+	// RequireClientCert is a flag for rejecting connections without a valid client certificate (mutual TLS).
+	RequireClientCert bool `mapstructure:"require_client_cert"`
+	// ClientCerts maps subjects of client certificates to scopes or tokens, which authenticates requests without a token.
+	ClientCerts []ClientCertMapping `mapstructure:"client_certs"`
+}
+
+// ClientCertMapping maps the subject of a client certificate to scopes or to a token.
+type ClientCertMapping struct {
+	// Subject is the distinguished name of the certificate subject, ex. CN=billing,O=Example.
+	Subject string `mapstructure:"subject"`
+	// Scopes granted to clients with the certificate.
+	Scopes []string `mapstructure:"scopes"`
+	// TokenID is the id of a token whose scopes and quota apply to clients with the certificate.
+	TokenID string `mapstructure:"token_id"`
+}
+
+// JWTConfig represents verification of JWTs issued by an identity provider.
+type JWTConfig struct {
+	// Enabled is a flag for accepting JWTs.
+	Enabled bool `mapstructure:"enabled"`
+	// JWKSFile is a path to a file with the JSON Web Key Set of keys signing JWTs.
+	JWKSFile string `mapstructure:"jwks_file"`
+	// JWKSURL is a URL of the JSON Web Key Set of keys signing JWTs, used when JWKSFile is empty.
+	JWKSURL string `mapstructure:"jwks_url"`
+	// JWKSRefreshInterval is the time after which the key set is loaded again, to pick up rotated keys.
+	JWKSRefreshInterval time.Duration `mapstructure:"jwks_refresh_interval"`
+	// Issuer is the required iss claim.
+	Issuer string `mapstructure:"issuer"`
+	// Audience is the value required in the aud claim.
+	Audience string `mapstructure:"audience"`
+	// ScopesClaim is the claim with scopes, either a space separated string or a list.
+	ScopesClaim string `mapstructure:"scopes_claim"`
+	// ScopeMapping maps scopes from the claim to scopes of the service, scopes of the service are accepted as they are.
+	ScopeMapping map[string]string `mapstructure:"scope_mapping"`
+	// Leeway is the allowed clock skew when checking exp and nbf claims.
+	Leeway time.Duration `mapstructure:"leeway"`
+}
+
+// Rate limited groups of routes.
+const (
+	RateLimitGroupHeaders     = "headers"
+	RateLimitGroupMerkleRoots = "merkleroots"
+	RateLimitGroupWebhooks    = "webhooks"
+	RateLimitGroupNetwork     = "network"
+	RateLimitGroupAccess      = "access"
+	RateLimitGroupEvents      = "events"
+	RateLimitGroupWebsocket   = "websocket"
+	// RateLimitGroupIP limits all requests of an IP address before they are authorized.
+	RateLimitGroupIP = "ip"
+)
+
+// RateLimitConfig represents rate limits and quotas of the HTTP and websocket APIs.
+type RateLimitConfig struct {
+	// Enabled is a flag for enabling rate limits and quotas.
+	Enabled bool `mapstructure:"enabled"`
+	// Default is the limit of groups without own limit.
+	Default RateLimit `mapstructure:"default"`
+	// Groups are limits of groups of routes [headers|merkleroots|webhooks|network|access|events|websocket]
+	// and the limit of every IP address applied before authorization [ip].
+	Groups map[string]*RateLimit `mapstructure:"groups"`
+	// VerifyBatchSize is the number of merkle roots verified for the cost of a single request, every started batch costs one more request.
+	VerifyBatchSize int `mapstructure:"verify_batch_size"`
+	// DailyQuota is the number of requests a token can make per day (UTC) unless the token has own quota, 0 means no quota.
+	DailyQuota int64 `mapstructure:"daily_quota"`
+}
+
+// RateLimit is a token bucket limit.
+type RateLimit struct {
+	// Rate is the number of requests per second.
+	Rate float64 `mapstructure:"rate"`
+	// Burst is the number of requests which can be made at once.
+	Burst int `mapstructure:"burst"`
+}
+
+// Limit returns the limit of the group.
+func (c *RateLimitConfig) Limit(group string) RateLimit {
+	if l, ok := c.Groups[group]; ok && l != nil {
+		return *l
+	}
+	return c.Default
 }
 
 // P2PConfig represents a p2p config.
@@ -172,6 +485,230 @@ func (c *AppConfig) Validate() error {
 		return err
 	}
 
+	if err := c.HTTP.RateLimit.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.HTTP.JWT.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.HTTP.TLS.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Webhook.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Notification.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Broker.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Prune.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Validate validates the rate limit configuration.
+func (c *RateLimitConfig) Validate() error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	limits := map[string]RateLimit{"default": c.Default}
+	for group, l := range c.Groups {
+		if l != nil {
+			limits[group] = *l
+		}
+	}
+	for group, l := range limits {
+		if l.Rate <= 0 || l.Burst <= 0 {
+			return fmt.Errorf("rate limit: rate and burst of %s must be positive", group)
+		}
+	}
+	if c.VerifyBatchSize <= 0 {
+		return errors.New("rate limit: verify batch size must be positive")
+	}
+	if c.DailyQuota < 0 {
+		return errors.New("rate limit: daily quota can't be negative")
+	}
+	return nil
+}
+
+// Validate validates the JWT configuration.
+func (c *JWTConfig) Validate() error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if (c.JWKSFile == "") == (c.JWKSURL == "") {
+		return errors.New("jwt: exactly one of jwks_file and jwks_url must be set")
+	}
+	if c.Issuer == "" || c.Audience == "" {
+		return errors.New("jwt: issuer and audience must be set")
+	}
+	if c.ScopesClaim == "" {
+		return errors.New("jwt: scopes claim must be set")
+	}
+	if c.JWKSRefreshInterval <= 0 {
+		return errors.New("jwt: jwks refresh interval must be positive")
+	}
+	if c.Leeway < 0 {
+		return errors.New("jwt: leeway can't be negative")
+	}
+	return nil
+}
+
+// Validate validates the TLS configuration.
+func (c *TLSConfig) Validate() error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if c.CertFile == "" || c.KeyFile == "" {
+		return errors.New("tls: cert file and key file must be set")
+	}
+	if c.MinVersion != TLSVersion12 && c.MinVersion != TLSVersion13 {
+		return fmt.Errorf("tls: unsupported min version %s", c.MinVersion)
+	}
+	if c.ClientCAFile == "" && (c.RequireClientCert || len(c.ClientCerts) > 0) {
+		return errors.New("tls: client CA file must be set to verify client certificates")
+	}
+	for _, m := range c.ClientCerts {
+		if m.Subject == "" {
+			return errors.New("tls: subject of client certificate must be set")
+		}
+		if (len(m.Scopes) == 0) == (m.TokenID == "") {
+			return fmt.Errorf("tls: client certificate %s must be mapped to either scopes or token id", m.Subject)
+		}
+	}
+	return nil
+}
+
+// Validate validates the webhook configuration.
+func (c *WebhookConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.Workers <= 0 {
+		return errors.New("webhook: number of workers must be positive")
+	}
+	if c.ConnectTimeout <= 0 || c.ResponseTimeout <= 0 {
+		return errors.New("webhook: timeouts must be positive")
+	}
+	if c.CircuitOpenDuration <= 0 || c.CircuitMaxOpenDuration < c.CircuitOpenDuration {
+		return errors.New("webhook: circuit open duration must be positive and not greater than the max open duration")
+	}
+
+	if c.Policy == nil {
+		return nil
+	}
+
+	if len(c.Policy.AllowedSchemes) == 0 {
+		return errors.New("webhook: at least one scheme must be allowed")
+	}
+	for _, cidr := range append(append([]string{}, c.Policy.DeniedCIDRs...), c.Policy.AllowedCIDRs...) {
+		if _, err := netip.ParsePrefix(cidr); err != nil {
+			return fmt.Errorf("webhook: invalid policy cidr %s: %w", cidr, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the notification configuration.
+func (c *NotificationConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.SyncProgressInterval <= 0 {
+		return errors.New("notification: sync progress interval must be positive")
+	}
+
+	settings := []ChannelConfig{{QueueSize: c.QueueSize, OverflowPolicy: c.OverflowPolicy, BlockTimeout: c.BlockTimeout, SyncPolicy: c.SyncPolicy}}
+	for name := range c.Channels {
+		settings = append(settings, c.ForChannel(name))
+	}
+	for _, s := range settings {
+		switch s.OverflowPolicy {
+		case OverflowBlock, OverflowDropOldest, OverflowCoalesce:
+		default:
+			return fmt.Errorf("notification: unsupported overflow policy %s", s.OverflowPolicy)
+		}
+		switch s.SyncPolicy {
+		case SyncDeliver, SyncSuppress, SyncProgress:
+		default:
+			return fmt.Errorf("notification: unsupported sync policy %s", s.SyncPolicy)
+		}
+		if s.QueueSize <= 0 {
+			return errors.New("notification: queue size must be positive")
+		}
+		if s.OverflowPolicy == OverflowBlock && s.BlockTimeout <= 0 {
+			return errors.New("notification: block timeout must be positive")
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the broker configuration.
+func (c *BrokerConfig) Validate() error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	switch c.Type {
+	case BrokerNATS, BrokerRedis, BrokerKafka:
+	default:
+		return fmt.Errorf("broker: unsupported type %s", c.Type)
+	}
+
+	if c.URL == "" {
+		return errors.New("broker: url cannot be empty when broker is enabled")
+	}
+	if c.SubjectTemplate == "" {
+		return errors.New("broker: subject template cannot be empty when broker is enabled")
+	}
+	if c.MaxRetries < 0 {
+		return errors.New("broker: max retries cannot be negative")
+	}
+	switch c.Format {
+	case "", "json", "cloudevents":
+	default:
+		return fmt.Errorf("broker: unsupported format %s", c.Format)
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return errors.New("broker: tls cert file and key file must be set together")
+	}
+	switch c.KafkaSASLMechanism {
+	case "", KafkaSASLPlain, KafkaSASLScramSHA256, KafkaSASLScramSHA512:
+	default:
+		return fmt.Errorf("broker: unsupported kafka sasl mechanism %s", c.KafkaSASLMechanism)
+	}
+	return nil
+}
+
+// Validate validates the prune configuration.
+func (c *PruneConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.StaleDepth < 0 || c.OrphanMaxAge < 0 || c.RejectedMaxAge < 0 {
+		return errors.New("prune: stale depth and max ages can't be negative")
+	}
+	if c.Enabled && c.Interval <= 0 {
+		return errors.New("prune: interval must be positive when periodic prune is enabled")
+	}
 	return nil
 }
 
@@ -188,6 +725,11 @@ func (c *DbConfig) Validate() error {
 		if !fileExists(c.PreparedDbFilePath) {
 			return fmt.Errorf("headers import: prepared database file does not exist at path %s", c.PreparedDbFilePath)
 		}
+		if c.PreparedDbPublicKey != "" {
+			if key, err := hex.DecodeString(c.PreparedDbPublicKey); err != nil || len(key) != ed25519.PublicKeySize {
+				return errors.New("headers import: public key of the prepared database file should be hex encoded ed25519 public key")
+			}
+		}
 	}
 
 	switch c.Engine {
@@ -201,6 +743,11 @@ func (c *DbConfig) Validate() error {
 			return fmt.Errorf("db: postgres configuration should be filled properly to use postgres engine %s", DBPostgreSQL)
 		}
 
+	case DBBolt:
+		if len(c.Bolt.FilePath) == 0 {
+			return fmt.Errorf("db: bolt configuration cannot be empty where db type is set to %s", DBBolt)
+		}
+
 	default:
 		return errors.New("db: unsupported type")
 	}
@@ -10,26 +10,34 @@ const DefaultAppToken = "mQZQ6WmxURxWz5ch" // #nosec G101
 // GetDefaultAppConfig returns the default configuration for the application
 func GetDefaultAppConfig() *AppConfig {
 	return &AppConfig{
-		Db:         getDbDefaults(),
-		HTTP:       getHTTPConfigDefaults(),
-		MerkleRoot: getMerkleRootDefaults(),
-		Websocket:  getWebsocketDefaults(),
-		Webhook:    getWebhookDefaults(),
-		P2P:        getP2PDefaults(),
-		Logging:    getLoggingDefaults(),
-		Metrics:    getMetricsDefaults(),
+		Db:           getDbDefaults(),
+		HTTP:         getHTTPConfigDefaults(),
+		MerkleRoot:   getMerkleRootDefaults(),
+		Websocket:    getWebsocketDefaults(),
+		Webhook:      getWebhookDefaults(),
+		Notification: getNotificationDefaults(),
+		Broker:       getBrokerDefaults(),
+		Prune:        getPruneDefaults(),
+		P2P:          getP2PDefaults(),
+		Logging:      getLoggingDefaults(),
+		Metrics:      getMetricsDefaults(),
 	}
 }
 
 func getDbDefaults() *DbConfig {
 	return &DbConfig{
-		Engine:             DBSQLite,
-		SchemaPath:         "./database/migrations",
-		PreparedDb:         false,
-		PreparedDbFilePath: "./data/blockheaders.csv.gz",
+		Engine:                      DBSQLite,
+		SchemaPath:                  "",
+		PreparedDb:                  false,
+		PreparedDbFilePath:          "./data/blockheaders.csv.gz",
+		PreparedDbPublicKey:         "",
+		PreparedDbSignatureFilePath: "",
 		SQLite: SQLiteConfig{
 			FilePath: "./data/blockheaders.db",
 		},
+		Bolt: BoltConfig{
+			FilePath: "./data/blockheaders.bolt",
+		},
 		Postgres: getPostgresDefaults(),
 	}
 }
@@ -42,6 +50,45 @@ func getHTTPConfigDefaults() *HTTPConfig {
 		UseAuth:                   true,
 		AuthToken:                 DefaultAppToken,
 		ProfilingEndpointsEnabled: true,
+		RateLimit:                 getRateLimitDefaults(),
+		JWT:                       getJWTDefaults(),
+		TLS:                       getTLSDefaults(),
+	}
+}
+
+func getTLSDefaults() *TLSConfig {
+	return &TLSConfig{
+		Enabled:           false,
+		MinVersion:        TLSVersion12,
+		RequireClientCert: false,
+		ClientCerts:       []ClientCertMapping{},
+	}
+}
+
+func getJWTDefaults() *JWTConfig {
+	return &JWTConfig{
+		Enabled:             false,
+		JWKSRefreshInterval: time.Hour,
+		ScopesClaim:         "scope",
+		ScopeMapping:        map[string]string{},
+		Leeway:              30 * time.Second,
+	}
+}
+
+func getRateLimitDefaults() *RateLimitConfig {
+	return &RateLimitConfig{
+		Enabled: false,
+		Default: RateLimit{Rate: 50, Burst: 100},
+		Groups: map[string]*RateLimit{
+			RateLimitGroupMerkleRoots: {Rate: 20, Burst: 40},
+			RateLimitGroupWebhooks:    {Rate: 1, Burst: 10},
+			RateLimitGroupAccess:      {Rate: 1, Burst: 10},
+			RateLimitGroupEvents:      {Rate: 1, Burst: 10},
+			RateLimitGroupWebsocket:   {Rate: 5, Burst: 20},
+			RateLimitGroupIP:          {Rate: 100, Burst: 200},
+		},
+		VerifyBatchSize: 100,
+		DailyQuota:      0,
 	}
 }
 
@@ -53,14 +100,70 @@ func getMerkleRootDefaults() *MerkleRootConfig {
 
 func getWebsocketDefaults() *WebsocketConfig {
 	return &WebsocketConfig{
-		HistoryMax: 300,
-		HistoryTTL: 10,
+		HistoryMax:        300,
+		HistoryTTL:        10,
+		RecoveryMax:       1000,
+		HTTPStreamEnabled: false,
 	}
 }
 
 func getWebhookDefaults() *WebhookConfig {
 	return &WebhookConfig{
-		MaxTries: 10,
+		MaxTries:               10,
+		Workers:                10,
+		ConnectTimeout:         5 * time.Second,
+		ResponseTimeout:        10 * time.Second,
+		CircuitOpenDuration:    30 * time.Second,
+		CircuitMaxOpenDuration: 30 * time.Minute,
+		Policy: &WebhookPolicyConfig{
+			AllowedSchemes: []string{"http", "https"},
+			AllowedHosts:   []string{},
+			DeniedHosts:    []string{},
+			// loopback, private, link-local (including cloud metadata endpoints) and other non-public networks
+			DeniedCIDRs: []string{
+				"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12",
+				"192.168.0.0/16", "224.0.0.0/4", "::/128", "::1/128", "fc00::/7", "fe80::/10", "ff00::/8",
+			},
+			AllowedCIDRs: []string{},
+		},
+		Verification: false,
+	}
+}
+
+func getNotificationDefaults() *NotificationConfig {
+	return &NotificationConfig{
+		ConfirmationDepth:    6,
+		ConfirmAllHeaders:    false,
+		QueueSize:            1000,
+		OverflowPolicy:       OverflowDropOldest,
+		BlockTimeout:         time.Second,
+		SyncPolicy:           SyncProgress,
+		SyncProgressInterval: 10000,
+		Channels:             map[string]*ChannelConfig{},
+	}
+}
+
+func getBrokerDefaults() *BrokerConfig {
+	return &BrokerConfig{
+		Enabled:            false,
+		Type:               BrokerNATS,
+		URL:                "nats://localhost:4222",
+		SubjectTemplate:    "bhs.{type}.{operation}",
+		PublishTimeout:     5 * time.Second,
+		RetryInterval:      time.Second,
+		MaxRetries:         10,
+		Format:             "json",
+		KafkaSASLMechanism: KafkaSASLPlain,
+	}
+}
+
+func getPruneDefaults() *PruneConfig {
+	return &PruneConfig{
+		Enabled:        false,
+		Interval:       time.Hour,
+		StaleDepth:     1000,
+		OrphanMaxAge:   24 * time.Hour,
+		RejectedMaxAge: 24 * time.Hour,
 	}
 }
 
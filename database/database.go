@@ -1,22 +1,21 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"os"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
 	"github.com/jmoiron/sqlx"
-	// use blank import to register PostgreSQL driver.
-	_ "github.com/lib/pq"
-	// use blank import to register sqlite driver.
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/rs/zerolog"
 )
 
 type dbAdapter interface {
 	connect(cfg *config.DbConfig) error
-	doMigrations(cfg *config.DbConfig) error
-	importHeaders(inputFile *os.File, log *zerolog.Logger) (int, error)
+	migrationDriver() (migratedb.Driver, error)
+	driverName() string
+	importHeaders(snapshot *snapshotReader, withoutIndexes bool, log *zerolog.Logger) (int, error)
 	getDBx() *sqlx.DB
 }
 
@@ -25,103 +24,157 @@ type dbIndex struct {
 	sql  string
 }
 
-// Init initializes the database connection and does the necessary migrations.
-func Init(cfg *config.AppConfig, log *zerolog.Logger) (*sqlx.DB, error) {
+// Init initializes the database connection and does the necessary migrations. The database is locked
+// until the returned unlock function is called, see lockDatabase.
+func Init(cfg *config.AppConfig, log *zerolog.Logger) (db *sqlx.DB, unlock func() error, err error) {
 	dbLog := log.With().Str("subservice", "database").Logger()
 
 	adapter, err := newDbAdapter(cfg.Db)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err = adapter.connect(cfg.Db); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if err := adapter.doMigrations(cfg.Db); err != nil {
-		return nil, err
+	if unlock, err = lockDatabase(cfg.Db, adapter.getDBx()); err != nil {
+		_ = adapter.getDBx().Close()
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = unlock()
+			_ = adapter.getDBx().Close()
+		}
+	}()
+
+	if err := migrateUp(adapter, cfg.Db, &dbLog); err != nil {
+		return nil, nil, err
+	}
+
+	if err := hashPlaintextTokens(adapter, &dbLog); err != nil {
+		return nil, nil, err
 	}
 
 	if cfg.Db.PreparedDb {
-		if err := importHeaders(adapter, cfg, &dbLog); err != nil {
-			return nil, err
+		if err := importHeaders(sql.NewHeadersDb(adapter.getDBx(), &dbLog), &sqlImport{adapter: adapter}, cfg, &dbLog); err != nil {
+			return nil, nil, err
 		}
 	} else {
 		if err := insertGenesisBlock(adapter, cfg, &dbLog); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
+	return adapter.getDBx(), unlock, nil
+}
+
+// Connect opens the database connection without migrations nor importing headers, for commands working with
+// an existing database.
+func Connect(cfg *config.DbConfig) (*sqlx.DB, error) {
+	adapter, err := newDbAdapter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = adapter.connect(cfg); err != nil {
+		return nil, err
+	}
 	return adapter.getDBx(), nil
 }
 
+// hashPlaintextTokens replaces values of tokens created before only their hashes were stored.
+func hashPlaintextTokens(adapter dbAdapter, log *zerolog.Logger) error {
+	hashed, err := sql.NewHeadersDb(adapter.getDBx(), log).HashPlaintextTokens(context.Background())
+	if err != nil {
+		return err
+	}
+	if hashed > 0 {
+		log.Info().Msgf("Replaced %d stored tokens with their hashes", hashed)
+	}
+	return nil
+}
+
 func newDbAdapter(cfg *config.DbConfig) (dbAdapter, error) {
 	switch cfg.Engine {
 	case config.DBSQLite:
 		return &sqLiteAdapter{}, nil
 	case config.DBPostgreSQL:
 		return &postgreSQLAdapter{}, nil
+	case config.DBBolt:
+		return nil, fmt.Errorf("database engine %s has no SQL schema, only sqlite and postgres are supported", cfg.Engine)
 	default:
 		return nil, fmt.Errorf("unsupported database engine %s", cfg.Engine)
 	}
 }
 
-// dropIndexes removes indexes found by indexQuery. Returns the index restore function if successful.
+// dropIndexes removes indexes found by indexQuery. Definitions of the indexes are stored in the same transaction,
+// so indexes dropped by an interrupted import can be restored by restoreDroppedIndexes on the next start.
+// Returns the index restore function if successful.
 func dropIndexes(db *sqlx.DB, indexQuery *string) (func() error, error) {
+	var dbIndexes []dbIndex
 	qr, err := db.Query(*indexQuery)
 	if err != nil {
 		return nil, err
 	}
-	if qr.Err() != nil {
-		return nil, qr.Err()
-	}
 	defer func() {
 		_ = qr.Close()
 	}()
-
-	var dbIndexes []dbIndex
 	for qr.Next() {
-		var indexName, indexSQL string
-		err := qr.Scan(&indexName, &indexSQL)
-		if err != nil {
+		var index dbIndex
+		if err := qr.Scan(&index.name, &index.sql); err != nil {
 			return nil, err
 		}
-
-		index := dbIndex{
-			name: indexName,
-			sql:  indexSQL,
-		}
-
 		dbIndexes = append(dbIndexes, index)
 	}
+	if err := qr.Err(); err != nil {
+		return nil, err
+	}
 
-	dropedIndexes := make([]dbIndex, 0)
-	for _, index := range dbIndexes {
-		fmt.Printf("Drop Value: %v\n", index)
-
-		_, err = db.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s;", index.name))
-		if err != nil {
-			if restoreErr := restoreIndexes(db, dropedIndexes); restoreErr != nil {
-				err = fmt.Errorf("%w. Restoring already droped indexes failed: %w", err, restoreErr)
-			}
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() //nolint
 
+	for _, index := range dbIndexes {
+		if _, err := tx.Exec(tx.Rebind("INSERT INTO dropped_indexes(name, definition) VALUES (?, ?)"), index.name, index.sql); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s;", index.name)); err != nil {
 			return nil, err
 		}
-
-		dropedIndexes = append(dropedIndexes, index)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
-	return func() error { return restoreIndexes(db, dbIndexes) }, nil
+	return func() error { return restoreDroppedIndexes(db) }, nil
 }
 
-func restoreIndexes(db *sqlx.DB, dbIndexes []dbIndex) error {
-	for _, index := range dbIndexes {
-		fmt.Printf("Create Value: %v\n", index)
+// restoreDroppedIndexes creates indexes stored by dropIndexes again.
+func restoreDroppedIndexes(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint
 
-		_, err := db.Exec(index.sql)
-		if err != nil {
-			return err
+	var dbIndexes []struct {
+		Name       string `db:"name"`
+		Definition string `db:"definition"`
+	}
+	if err := tx.Select(&dbIndexes, "SELECT name, definition FROM dropped_indexes"); err != nil {
+		return err
+	}
+	for _, index := range dbIndexes {
+		if _, err := tx.Exec(index.Definition); err != nil {
+			return fmt.Errorf("cannot restore index %s: %w", index.Name, err)
 		}
 	}
-	return nil
+	if _, err := tx.Exec("DELETE FROM dropped_indexes"); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
@@ -1,162 +1,403 @@
 package database
 
 import (
-	"database/sql"
+	"bufio"
+	"context"
 	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
+	sqlrepository "github.com/bitcoin-sv/block-headers-service/database/repository"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
 	"github.com/jmoiron/sqlx"
+	gz "github.com/klauspost/compress/gzip"
 	"github.com/rs/zerolog"
 )
 
-const (
-	selectHeadersSQL = `
+// selectHeadersSQL selects headers of the longest chain in the height range, timestamps are converted to unix time
+// when written, so the query works with every engine.
+const selectHeadersSQL = `
 	SELECT
+		height,
+		hash,
 		version,
 		merkleroot,
 		nonce,
 		bits,
-		strftime('%s', timestamp) as timestamp
+		timestamp,
+		header_state,
+		chainwork,
+		cumulated_work,
+		previous_block
 	FROM headers
-	WHERE header_state = 'LONGEST_CHAIN'
+	WHERE header_state = ? AND height >= ? AND height <= ?
 	ORDER BY height asc
 	`
+
+// ExportFormat is the format of the file with exported headers.
+type ExportFormat string
+
+// Formats of exported headers.
+const (
+	// ExportCSV is the CSV file with version, merkle root, nonce, bits and timestamp of headers.
+	ExportCSV ExportFormat = "csv"
+	// ExportCSVGzip is the gzipped CSV file, the format of the prepared database file.
+	ExportCSVGzip ExportFormat = "csv.gz"
+	// ExportRaw is the concatenation of serialized 80 bytes headers.
+	ExportRaw ExportFormat = "raw"
+	// ExportJSONLines is the file with a JSON object of a header with its hash, height and chainwork in every line.
+	ExportJSONLines ExportFormat = "jsonl"
 )
 
-// ExportHeaders exports headers from the database to a file
-func ExportHeaders(cfg *config.AppConfig, log *zerolog.Logger) error {
-	log.Info().Msgf("Exporting headers from database to file %s", cfg.Db.PreparedDbFilePath)
+var exportFileExtensions = map[ExportFormat]string{
+	ExportCSV:       ".csv",
+	ExportCSVGzip:   ".csv.gz",
+	ExportRaw:       ".bin",
+	ExportJSONLines: ".jsonl",
+}
 
-	tmpHeadersFileName := "headers.csv"
-	tmpHeadersFilePath := filepath.Clean(filepath.Join(os.TempDir(), tmpHeadersFileName))
+// ExportOptions selects headers to export and where to write them.
+type ExportOptions struct {
+	Format ExportFormat
+	// OutputPath is the path of the exported file. By default it is the prepared database file for ExportCSVGzip,
+	// and headers file with extension of the format in the same directory for other formats.
+	OutputPath string
+	// FromHeight is the first exported height.
+	FromHeight int32
+	// ToHeight is the last exported height, 0 exports headers up to the tip of the longest chain.
+	ToHeight int32
+	// SigningKeyFile is the path to the file with hex encoded ed25519 key, when it is set the detached signature
+	// of the exported file is written next to it.
+	SigningKeyFile string
+}
 
-	adapter, err := newDbAdapter(cfg.Db)
-	if err != nil {
-		return err
+// Validate checks the options.
+func (o *ExportOptions) Validate() error {
+	if _, ok := exportFileExtensions[o.Format]; !ok {
+		return fmt.Errorf("unsupported export format %s", o.Format)
 	}
-
-	if err = adapter.connect(cfg.Db); err != nil {
-		return err
+	if o.FromHeight < 0 || o.ToHeight < 0 {
+		return errors.New("heights of exported headers cannot be negative")
 	}
+	if o.ToHeight != 0 && o.ToHeight < o.FromHeight {
+		return fmt.Errorf("last exported height %d is lower than the first one %d", o.ToHeight, o.FromHeight)
+	}
+	return nil
+}
 
-	db := adapter.getDBx()
+func (o *ExportOptions) outputPath(cfg *config.DbConfig) string {
+	if o.OutputPath != "" {
+		return o.OutputPath
+	}
+	if o.Format == ExportCSVGzip {
+		return cfg.PreparedDbFilePath
+	}
+	return filepath.Join(filepath.Dir(cfg.PreparedDbFilePath), "headers"+exportFileExtensions[o.Format])
+}
 
-	tmpCsvFile, err := os.Create(tmpHeadersFilePath)
-	if err != nil {
+// ExportHeaders exports headers of the longest chain from the database to a file.
+// Files in ExportCSVGzip format can be used as the prepared database file, the ones which don't start at genesis
+// have also height, previous block and cumulated work of headers, so they can be appended to the headers in a database.
+func ExportHeaders(cfg *config.AppConfig, opts ExportOptions, log *zerolog.Logger) error {
+	if err := opts.Validate(); err != nil {
 		return err
 	}
+	outputPath := opts.outputPath(cfg.Db)
+	log.Info().Msgf("Exporting headers from database to %s file %s", opts.Format, outputPath)
 
-	writer := csv.NewWriter(tmpCsvFile)
-
-	// TODO: Consider querying the database for smaller data chunks to avoid potential performance issues
-	rows, err := queryDatabaseTable(db, log)
+	export, db, err := openExport(cfg.Db, log)
 	if err != nil {
-		log.Error().Msgf("Error querying database table: %s", err.Error())
 		return err
 	}
-	defer func(log *zerolog.Logger) {
-		if err := rows.Close(); err != nil {
-			log.Error().Msgf("Error closing rows: %s", err.Error())
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error().Msgf("Error closing database: %s", err.Error())
 		}
-	}(log)
+	}()
 
-	if err := writeColumnNamesToCsvFile(rows, writer); err != nil {
+	count, err := exportToFile(export, opts, outputPath)
+	if err != nil {
 		return err
 	}
 
-	if err := writeRowsToCsvFile(rows, writer); err != nil {
-		return err
+	log.Info().Msgf("Exported %d headers to %s", count, outputPath)
+
+	if opts.SigningKeyFile != "" {
+		signaturePath, err := signFile(outputPath, opts.SigningKeyFile)
+		if err != nil {
+			return fmt.Errorf("cannot sign exported file: %w", err)
+		}
+		log.Info().Msgf("Signature of exported file written to %s", signaturePath)
 	}
+	return nil
+}
 
-	writer.Flush()
+// exportFunc writes headers selected by the options with the writer and returns their count.
+type exportFunc func(opts ExportOptions, writer headerWriter) (int, error)
 
-	log.Info().Msg("Data exported successfully")
-	log.Info().Msg("Compressing exported file")
+// openExport opens the database of the configured engine and returns the function exporting its headers.
+// Bolt databases are locked by the running service, so they are exported while the service is stopped.
+func openExport(cfg *config.DbConfig, log *zerolog.Logger) (exportFunc, io.Closer, error) {
+	if cfg.Engine == config.DBBolt {
+		store, err := openBolt(cfg, log)
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(opts ExportOptions, writer headerWriter) (int, error) {
+			return exportStoreHeaders(store, opts, writer)
+		}, store, nil
+	}
 
-	compressedFile, err := os.Create(cfg.Db.PreparedDbFilePath)
+	db, err := Connect(cfg)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
+	return func(opts ExportOptions, writer headerWriter) (int, error) {
+		return exportHeaders(db, opts, writer)
+	}, db, nil
+}
 
-	if err := db.Close(); err != nil {
-		return err
+// exportToFile writes headers to a temporary file, which replaces the output file only when all headers are written.
+func exportToFile(export exportFunc, opts ExportOptions, outputPath string) (count int, err error) {
+	tmpFile, err := os.CreateTemp(filepath.Dir(outputPath), ".headers-export-*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmpFile.Close()
+			_ = os.Remove(tmpFile.Name())
+		}
+	}()
+
+	buffered := bufio.NewWriter(tmpFile)
+	var out io.Writer = buffered
+	var gzipWriter *gz.Writer
+	if opts.Format == ExportCSVGzip {
+		if gzipWriter, err = gz.NewWriterLevel(buffered, gz.BestSpeed); err != nil {
+			return 0, fmt.Errorf("creating gzip writer: %w", err)
+		}
+		out = gzipWriter
 	}
 
-	if err := gzipFastCompress(tmpCsvFile, compressedFile); err != nil {
-		return err
+	if count, err = export(opts, newHeaderWriter(opts, out)); err != nil {
+		return count, err
 	}
 
-	if err := tmpCsvFile.Close(); err != nil {
-		return err
+	if gzipWriter != nil {
+		if err = gzipWriter.Close(); err != nil {
+			return count, err
+		}
 	}
-	if err := compressedFile.Close(); err != nil {
-		return err
+	if err = buffered.Flush(); err != nil {
+		return count, err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return count, err
 	}
+	return count, os.Rename(tmpFile.Name(), outputPath)
+}
 
-	if err := os.Remove(tmpHeadersFilePath); err != nil {
-		return err
+func exportHeaders(db *sqlx.DB, opts ExportOptions, writer headerWriter) (int, error) {
+	toHeight := opts.ToHeight
+	if toHeight == 0 {
+		toHeight = math.MaxInt32
 	}
 
-	log.Info().Msgf("File compressed successfully to %s", cfg.Db.PreparedDbFilePath)
+	rows, err := db.Queryx(db.Rebind(selectHeadersSQL), string(domains.LongestChain), opts.FromHeight, toHeight)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query headers: %w", err)
+	}
+	defer rows.Close() //nolint: all
 
-	return nil
-}
+	if err := writer.begin(); err != nil {
+		return 0, err
+	}
 
-func queryDatabaseTable(db *sqlx.DB, log *zerolog.Logger) (*sqlx.Rows, error) {
-	rows, err := db.Queryx(selectHeadersSQL)
-	if err != nil {
-		log.Error().Msgf("Failed to query rows: %v", err)
-		return nil, err
+	count := 0
+	for rows.Next() {
+		var header dto.DbBlockHeader
+		if err := rows.StructScan(&header); err != nil {
+			return count, err
+		}
+		if err := writer.write(&header); err != nil {
+			return count, fmt.Errorf("cannot write header on height %d: %w", header.Height, err)
+		}
+		count++
 	}
-	return rows, nil
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	return count, writer.end()
 }
 
-func writeColumnNamesToCsvFile(rows *sqlx.Rows, writer *csv.Writer) error {
-	columns, err := rows.Columns()
+// exportStoreHeaders exports headers of the longest chain from the store in batches of heights,
+// for engines which can't be queried with selectHeadersSQL.
+func exportStoreHeaders(store sqlrepository.HeadersStore, opts ExportOptions, writer headerWriter) (int, error) {
+	tip, err := store.GetTip(context.Background())
 	if err != nil {
-		return err
+		return 0, err
+	}
+	toHeight := tip.Height
+	if opts.ToHeight != 0 {
+		toHeight = min(opts.ToHeight, toHeight)
 	}
 
-	if err := writer.Write(columns); err != nil {
-		return err
+	if err := writer.begin(); err != nil {
+		return 0, err
 	}
 
-	return nil
+	count := 0
+	for from := opts.FromHeight; from <= toHeight; from += boltBatchSize {
+		headers, err := store.GetHeadersByHeightRange(int(from), int(min(from+boltBatchSize-1, toHeight)))
+		if err != nil {
+			return count, fmt.Errorf("failed to read headers: %w", err)
+		}
+		for _, header := range headers {
+			if err := writer.write(header); err != nil {
+				return count, fmt.Errorf("cannot write header on height %d: %w", header.Height, err)
+			}
+			count++
+		}
+	}
+
+	return count, writer.end()
 }
 
-func writeRowsToCsvFile(rows *sqlx.Rows, writer *csv.Writer) error {
-	for rows.Next() {
-		var recordStrings []string
+// headerWriter writes exported headers in one of the formats.
+type headerWriter interface {
+	begin() error
+	write(header *dto.DbBlockHeader) error
+	end() error
+}
 
-		colTypes, err := rows.ColumnTypes()
-		if err != nil {
-			return err
-		}
+func newHeaderWriter(opts ExportOptions, w io.Writer) headerWriter {
+	switch opts.Format {
+	case ExportRaw:
+		return &rawHeaderWriter{w: w}
+	case ExportJSONLines:
+		return &jsonLinesHeaderWriter{encoder: json.NewEncoder(w)}
+	default:
+		return &csvHeaderWriter{w: csv.NewWriter(w), withStart: opts.FromHeight > 0}
+	}
+}
 
-		pointers := make([]interface{}, len(colTypes))
+// csvHeaderWriter writes headers in the format of the prepared database file.
+type csvHeaderWriter struct {
+	w *csv.Writer
+	// withStart adds columns describing where the snapshot starts, for snapshots which don't start at genesis.
+	withStart bool
+}
 
-		for i := range colTypes {
-			pointers[i] = new(sql.RawBytes)
-		}
+func (c *csvHeaderWriter) begin() error {
+	columns := append([]string{}, snapshotColumns...)
+	if c.withStart {
+		columns = append(columns, snapshotHeightColumn, snapshotPreviousBlockColumn, snapshotCumulatedWorkColumn)
+	}
+	return c.w.Write(columns)
+}
 
-		if err := rows.Scan(pointers...); err != nil {
-			return err
-		}
+func (c *csvHeaderWriter) write(header *dto.DbBlockHeader) error {
+	record := []string{
+		strconv.FormatInt(int64(header.Version), 10),
+		header.MerkleRoot.String(),
+		strconv.FormatUint(uint64(header.Nonce), 10),
+		strconv.FormatUint(uint64(header.Bits), 10),
+		strconv.FormatInt(header.Timestamp.Unix(), 10),
+	}
+	if c.withStart {
+		record = append(record, strconv.FormatInt(int64(header.Height), 10), header.PreviousBlock.String(), header.CumulatedWork.String())
+	}
+	return c.w.Write(record)
+}
 
-		for _, ptr := range pointers {
-			recordStrings = append(recordStrings, string(*ptr.(*sql.RawBytes)))
-		}
+func (c *csvHeaderWriter) end() error {
+	c.w.Flush()
+	return c.w.Error()
+}
 
-		if err := writer.Write(recordStrings); err != nil {
-			return err
-		}
+// rawHeaderWriter writes headers serialized as in the bitcoin protocol, 80 bytes each.
+type rawHeaderWriter struct {
+	w io.Writer
+}
+
+func (r *rawHeaderWriter) begin() error {
+	return nil
+}
+
+func (r *rawHeaderWriter) write(header *dto.DbBlockHeader) error {
+	prevBlock, err := chainhash.NewHashFromStr(header.PreviousBlock.String())
+	if err != nil {
+		return err
+	}
+	merkleRoot, err := chainhash.NewHashFromStr(header.MerkleRoot.String())
+	if err != nil {
+		return err
 	}
 
-	if rows.Err() != nil {
-		return rows.Err()
+	h := wire.BlockHeader{
+		Version:    header.Version,
+		PrevBlock:  *prevBlock,
+		MerkleRoot: *merkleRoot,
+		Timestamp:  header.Timestamp,
+		Bits:       header.Bits,
+		Nonce:      header.Nonce,
 	}
+	return h.Serialize(r.w)
+}
+
+func (r *rawHeaderWriter) end() error {
+	return nil
+}
+
+// jsonLinesHeaderWriter writes every header as a JSON object in a separate line.
+type jsonLinesHeaderWriter struct {
+	encoder *json.Encoder
+}
+
+type exportedHeader struct {
+	Hash          string `json:"hash"`
+	Height        int32  `json:"height"`
+	Version       int32  `json:"version"`
+	MerkleRoot    string `json:"merkleRoot"`
+	PreviousBlock string `json:"prevBlockHash"`
+	Timestamp     int64  `json:"timestamp"`
+	Bits          uint32 `json:"bits"`
+	Nonce         uint32 `json:"nonce"`
+	Chainwork     string `json:"chainwork"`
+	CumulatedWork string `json:"cumulatedWork"`
+}
+
+func (j *jsonLinesHeaderWriter) begin() error {
+	return nil
+}
+
+func (j *jsonLinesHeaderWriter) write(header *dto.DbBlockHeader) error {
+	return j.encoder.Encode(exportedHeader{
+		Hash:          header.Hash.String(),
+		Height:        header.Height,
+		Version:       header.Version,
+		MerkleRoot:    header.MerkleRoot.String(),
+		PreviousBlock: header.PreviousBlock.String(),
+		Timestamp:     header.Timestamp.Unix(),
+		Bits:          header.Bits,
+		Nonce:         header.Nonce,
+		Chainwork:     header.Chainwork.String(),
+		CumulatedWork: header.CumulatedWork.String(),
+	})
+}
 
+func (j *jsonLinesHeaderWriter) end() error {
 	return nil
 }
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/rs/zerolog"
+)
+
+// first headers of the mainnet in the format of the prepared database file
+var exportTestRecords = [][]string{
+	{"1", "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b", "2083236893", "486604799", "1231006505"},
+	{"1", "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098", "2573394689", "486604799", "1231469665"},
+	{"1", "9b0fc92260312ce44e74ef369f5c66bbb85848f2eddd5a7a1cde251e54ccfdd5", "1639830024", "486604799", "1231469744"},
+}
+
+var exportTestHashes = []string{
+	"000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f",
+	"00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048",
+	"000000006a625f06636b8bb6ac7b960a8d03705d1ace08b1a19da3fdcc99ddbd",
+}
+
+func TestExportPreparedDbFile(t *testing.T) {
+	// setup
+	cfg, log := createExportTestDatabase(t)
+
+	// when
+	err := ExportHeaders(cfg, ExportOptions{Format: ExportCSVGzip}, log)
+
+	// then
+	assert.NoError(t, err)
+	f, err := os.Open(cfg.Db.PreparedDbFilePath)
+	assert.NoError(t, err)
+	defer f.Close() //nolint: all
+	r, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	lines := readLines(t, bufio.NewScanner(r))
+	assert.Equal(t, len(lines), len(exportTestRecords)+1)
+	assert.Equal(t, lines[0], "version,merkleroot,nonce,bits,timestamp")
+	for i, record := range exportTestRecords {
+		assert.Equal(t, lines[i+1], strings.Join(record, ","))
+	}
+}
+
+func TestExportRawHeaders(t *testing.T) {
+	// setup
+	cfg, log := createExportTestDatabase(t)
+	output := filepath.Join(t.TempDir(), "headers.bin")
+
+	// when
+	err := ExportHeaders(cfg, ExportOptions{Format: ExportRaw, OutputPath: output}, log)
+
+	// then
+	assert.NoError(t, err)
+	data, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), 80*len(exportTestHashes))
+	for i, hash := range exportTestHashes {
+		assert.Equal(t, chainhash.DoubleHashH(data[i*80:(i+1)*80]).String(), hash)
+	}
+}
+
+func TestExportJSONLinesInHeightRange(t *testing.T) {
+	// setup
+	cfg, log := createExportTestDatabase(t)
+	output := filepath.Join(t.TempDir(), "headers.jsonl")
+
+	// when
+	err := ExportHeaders(cfg, ExportOptions{Format: ExportJSONLines, OutputPath: output, FromHeight: 1, ToHeight: 1}, log)
+
+	// then
+	assert.NoError(t, err)
+	f, err := os.Open(output)
+	assert.NoError(t, err)
+	defer f.Close() //nolint: all
+	lines := readLines(t, bufio.NewScanner(f))
+	assert.Equal(t, len(lines), 1)
+
+	var header exportedHeader
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &header))
+	assert.Equal(t, header.Hash, exportTestHashes[1])
+	assert.Equal(t, header.Height, 1)
+	assert.Equal(t, header.PreviousBlock, exportTestHashes[0])
+	assert.Equal(t, header.Chainwork, "4295032833")
+	assert.Equal(t, header.CumulatedWork, "8590065666")
+}
+
+func TestExportBoltHeaders(t *testing.T) {
+	// setup
+	logger := zerolog.Nop()
+	cfg := config.GetDefaultAppConfig()
+	cfg.Db.Engine = config.DBBolt
+	cfg.Db.Bolt.FilePath = filepath.Join(t.TempDir(), "blockheaders.bolt")
+	cfg.Db.PreparedDb = true
+	cfg.Db.PreparedDbFilePath = exportTestSnapshot(t, 0)
+	store, err := Open(cfg, &logger)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Close())
+	output := filepath.Join(t.TempDir(), "headers.bin")
+
+	// when
+	err = ExportHeaders(cfg, ExportOptions{Format: ExportRaw, OutputPath: output, FromHeight: 1}, &logger)
+
+	// then
+	assert.NoError(t, err)
+	data, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), 80*(len(exportTestHashes)-1))
+	for i, hash := range exportTestHashes[1:] {
+		assert.Equal(t, chainhash.DoubleHashH(data[i*80:(i+1)*80]).String(), hash)
+	}
+}
+
+func TestExportWithInvalidOptions(t *testing.T) {
+	tests := map[string]ExportOptions{
+		"unknown format":  {Format: "xml"},
+		"negative height": {Format: ExportCSV, FromHeight: -1},
+		"reversed range":  {Format: ExportCSV, FromHeight: 10, ToHeight: 5},
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when
+			err := opts.Validate()
+
+			// then
+			if err == nil {
+				t.Fatalf("Expected options to be invalid")
+			}
+		})
+	}
+}
+
+func createExportTestDatabase(t *testing.T) (*config.AppConfig, *zerolog.Logger) {
+	logger := zerolog.Nop()
+	dir := t.TempDir()
+	cfg := config.GetDefaultAppConfig()
+	cfg.Db.SQLite.FilePath = filepath.Join(dir, "blockheaders.db")
+	cfg.Db.PreparedDbFilePath = filepath.Join(dir, "blockheaders.csv.gz")
+
+	adapter, err := newDbAdapter(cfg.Db)
+	assert.NoError(t, err)
+	assert.NoError(t, adapter.connect(cfg.Db))
+	defer adapter.getDBx().Close() //nolint: all
+	assert.NoError(t, migrateUp(adapter, cfg.Db, &logger))
+
+	headers := make([]dto.DbBlockHeader, 0, len(exportTestRecords))
+	previousHash, cumulatedWork := chainhash.Hash{}.String(), "0"
+	for i, record := range exportTestRecords {
+		header, err := prepareRecord(record, previousHash, cumulatedWork, i)
+		assert.NoError(t, err)
+		headers = append(headers, *header)
+		previousHash, cumulatedWork = header.Hash.String(), header.CumulatedWork.String()
+	}
+	assert.NoError(t, sql.NewHeadersDb(adapter.getDBx(), &logger).CreateMultiple(context.Background(), headers))
+
+	return cfg, &logger
+}
+
+func readLines(t *testing.T, scanner *bufio.Scanner) []string {
+	lines := make([]string, 0)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	assert.NoError(t, scanner.Err())
+	return lines
+}
@@ -86,7 +86,7 @@ func gzipDecompress(compressedFile, outputFile *os.File) error {
 	return nil
 }
 
-func gzipDecompressWithBuffer(compressedFile, outputFile *os.File) error {
+func gzipDecompressWithBuffer(compressedFile io.Reader, outputFile *os.File) error {
 	gzipReader, err := gz.NewReader(compressedFile)
 	if err != nil {
 		return err
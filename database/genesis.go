@@ -31,17 +31,17 @@ func insertGenesisBlock(db dbAdapter, cfg *config.AppConfig, log *zerolog.Logger
 func createGenesisHeaderBlock(genesisBlockHeader wire.BlockHeader) dto.DbBlockHeader {
 	longestChain := domains.LongestChain
 	genesisBlock := dto.DbBlockHeader{
-		Hash:          genesisBlockHeader.BlockHash().String(),
+		Hash:          dto.DbHash(genesisBlockHeader.BlockHash().String()),
 		Height:        0,
 		Version:       1,
-		PreviousBlock: chainhash.Hash{}.String(),              // 0000000000000000000000000000000000000000000000000000000000000000
-		MerkleRoot:    genesisBlockHeader.MerkleRoot.String(), // 4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b
+		PreviousBlock: dto.DbHash(chainhash.Hash{}.String()),              // 0000000000000000000000000000000000000000000000000000000000000000
+		MerkleRoot:    dto.DbHash(genesisBlockHeader.MerkleRoot.String()), // 4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b
 		Timestamp:     time.Unix(genesisBlockHeader.Timestamp.Unix(), 0),
 		Bits:          genesisBlockHeader.Bits,
 		Nonce:         genesisBlockHeader.Nonce,
 		State:         longestChain.String(),
-		Chainwork:     domains.CalculateWork(genesisBlockHeader.Bits).BigInt().String(),
-		CumulatedWork: domains.CalculateWork(genesisBlockHeader.Bits).BigInt().String(),
+		Chainwork:     dto.DbWork(domains.CalculateWork(genesisBlockHeader.Bits).BigInt().String()),
+		CumulatedWork: dto.DbWork(domains.CalculateWork(genesisBlockHeader.Bits).BigInt().String()),
 	}
 
 	return genesisBlock
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
 	"path/filepath"
@@ -11,8 +12,9 @@ import (
 	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
-	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	sqlrepository "github.com/bitcoin-sv/block-headers-service/database/repository"
 	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 	"github.com/bitcoin-sv/block-headers-service/repository/dto"
 	"github.com/bitcoin-sv/block-headers-service/service"
@@ -24,52 +26,294 @@ const (
 	numberOfColumnsInCSVDatabaseFile = 5
 )
 
-func importHeaders(db dbAdapter, cfg *config.AppConfig, log *zerolog.Logger) error {
+// importHook has the steps of importHeaders specific to the database.
+type importHook interface {
+	// restoreIndexes creates indexes dropped by an interrupted import again.
+	restoreIndexes() error
+	// insertHeaders inserts the remaining headers of the snapshot, without indexes into an empty database.
+	insertHeaders(snapshot *snapshotReader, empty bool, log *zerolog.Logger) (int, error)
+	// validate checks the database after the import, returning the lowest height of the longest chain to be checked
+	// against checkpoints.
+	validate(startHeight int32) (int32, error)
+	// importedSnapshotHeight returns height of the last header of the file, when the same file was imported before.
+	importedSnapshotHeight(file snapshotFile) (int32, bool, error)
+	// saveImportedSnapshot stores height of the last header of the imported file.
+	saveImportedSnapshot(file snapshotFile, lastHeight int32) error
+}
+
+// importHeaders imports headers from the prepared database file. Into an empty database the whole snapshot is imported,
+// otherwise headers of the snapshot after the tip are appended, which also resumes an interrupted import.
+// All headers are validated before the first one is inserted, so an invalid snapshot is refused as a whole.
+func importHeaders(store sqlrepository.HeadersStore, hook importHook, cfg *config.AppConfig, log *zerolog.Logger) error {
 	log.Info().Msg("Import headers from file to the database")
 
-	hRepository := sql.NewHeadersDb(db.getDBx(), log)
-	hCount, _ := hRepository.Count(context.Background())
+	// indexes dropped by an interrupted import have to be created again, even when there is nothing to import
+	if err := hook.restoreIndexes(); err != nil {
+		return err
+	}
 
+	ctx := context.Background()
+	hCount, err := store.Count(ctx)
+	if err != nil {
+		return err
+	}
+
+	var tip *dto.DbBlockHeader
 	if hCount > 0 {
-		log.Info().Msgf("skipping preloading database from file, database already contains %d block headers", hCount)
-		return nil
+		if tip, err = store.GetTip(ctx); err != nil {
+			return err
+		}
+	}
+
+	file, err := statSnapshotFile(cfg.Db.PreparedDbFilePath)
+	if err != nil {
+		return err
+	}
+	if tip != nil {
+		lastHeight, imported, err := hook.importedSnapshotHeight(file)
+		if err != nil {
+			return err
+		}
+		if imported && tip.Height >= lastHeight {
+			log.Info().Msgf("skipping preloading database from file, file was imported before and the tip is at height %d", tip.Height)
+			return nil
+		}
+	}
+
+	signature, err := readSnapshotSignature(cfg.Db)
+	if err != nil {
+		return err
 	}
 
-	tmpHeadersFile, tmpHeadersFilePath, err := getHeadersFile(cfg.Db.PreparedDbFilePath, log)
+	tmpHeadersFile, tmpHeadersFilePath, err := getHeadersFile(cfg.Db.PreparedDbFilePath, signature, log)
 	if err != nil {
 		return err
 	}
 	defer dropHeadersFile(tmpHeadersFile, tmpHeadersFilePath, log)
 
-	log.Info().Msg("Inserting headers from file to the database")
+	snapshot, err := openSnapshot(tmpHeadersFile, cfg, tip)
+	if errors.Is(err, io.EOF) {
+		log.Info().Msgf("skipping preloading database from file, database already contains all %d headers of the file", hCount)
+		// the file has no headers above the tip, which is enough to skip it on the next start
+		return hook.saveImportedSnapshot(file, tip.Height)
+	}
+	if errors.Is(err, errSnapshotDoesNotConnect) {
+		log.Warn().Msgf("skipping preloading database from file: %v", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if tip != nil {
+		log.Info().Msgf("Database contains headers up to height %d, appending headers of the file after it", tip.Height)
+	}
 
-	importCount, err := db.importHeaders(tmpHeadersFile, log)
+	startHeight := snapshot.startHeight()
+	log.Info().Msgf("Validating headers of the file from height %d", startHeight)
+	if err := validateSnapshot(snapshot); err != nil {
+		return fmt.Errorf("prepared database file is invalid: %w", err)
+	}
+
+	if snapshot, err = openSnapshot(tmpHeadersFile, cfg, tip); err != nil {
+		return err
+	}
+
+	log.Info().Msgf("Inserting headers from file to the database from height %d", startHeight)
+
+	importCount, err := hook.insertHeaders(snapshot, hCount == 0, log)
 	if err != nil {
 		return err
 	}
 
 	log.Info().Msgf("Inserted total of %d rows", importCount)
 
-	if err := validateDbConsistency(importCount, hRepository, db.getDBx()); err != nil {
+	if err := validateImportedCount(hCount, importCount, startHeight, store); err != nil {
+		return err
+	}
+	lowestHeight, err := hook.validate(startHeight)
+	if err != nil {
 		return err
 	}
+	if err := validateCheckpoints(snapshotCheckpoints(cfg), store, lowestHeight); err != nil {
+		return err
+	}
+
+	return hook.saveImportedSnapshot(file, startHeight+int32(importCount)-1)
+}
+
+// sqlImport imports headers into the SQL database, dropping indexes of headers for the import into an empty database.
+type sqlImport struct {
+	adapter dbAdapter
+}
+
+func (i *sqlImport) restoreIndexes() error {
+	return restoreDroppedIndexes(i.adapter.getDBx())
+}
+
+func (i *sqlImport) insertHeaders(snapshot *snapshotReader, empty bool, log *zerolog.Logger) (int, error) {
+	// indexes are rebuilt after loading a whole snapshot, which is faster than updating them
+	return i.adapter.importHeaders(snapshot, empty, log)
+}
+
+func (i *sqlImport) validate(int32) (int32, error) {
+	db := i.adapter.getDBx()
+	if err := validateHeightUniqueness(db); err != nil {
+		return 0, fmt.Errorf("database is not consistent with csv file, %w", err)
+	}
+
+	var lowestHeight int32
+	if err := db.Get(&lowestHeight, db.Rebind("SELECT MIN(height) FROM headers WHERE header_state = ?"), string(domains.LongestChain)); err != nil {
+		return 0, err
+	}
+	return lowestHeight, nil
+}
+
+func (i *sqlImport) importedSnapshotHeight(file snapshotFile) (int32, bool, error) {
+	var heights []int32
+	db := i.adapter.getDBx()
+	query := db.Rebind("SELECT last_height FROM imported_snapshots WHERE path = ? AND size = ? AND modified_at = ?")
+	if err := db.Select(&heights, query, file.Path, file.Size, file.ModifiedAt); err != nil {
+		return 0, false, err
+	}
+	if len(heights) == 0 {
+		return 0, false, nil
+	}
+	return heights[0], true, nil
+}
+
+func (i *sqlImport) saveImportedSnapshot(file snapshotFile, lastHeight int32) error {
+	db := i.adapter.getDBx()
+	query := db.Rebind(`INSERT INTO imported_snapshots(path, size, modified_at, last_height) VALUES (?, ?, ?, ?)
+		ON CONFLICT (path) DO UPDATE SET size = excluded.size, modified_at = excluded.modified_at, last_height = excluded.last_height`)
+	_, err := db.Exec(query, file.Path, file.Size, file.ModifiedAt, lastHeight)
+	return err
+}
+
+// storeImport imports headers into a store without indexes to rebuild, inserting them in batches.
+// Imported files aren't stored, so they are read again on every start.
+type storeImport struct {
+	store     sqlrepository.HeadersStore
+	batchSize int
+}
+
+func (i *storeImport) restoreIndexes() error {
+	return nil
+}
+
+func (i *storeImport) insertHeaders(snapshot *snapshotReader, _ bool, _ *zerolog.Logger) (int, error) {
+	importCount := 0
+	for {
+		batch := make([]dto.DbBlockHeader, 0, i.batchSize)
+		for len(batch) < i.batchSize {
+			header, err := snapshot.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return importCount, err
+			}
+			batch = append(batch, *header)
+		}
+		if len(batch) == 0 {
+			return importCount, nil
+		}
+		if err := i.store.CreateMultiple(context.Background(), batch); err != nil {
+			return importCount, err
+		}
+		importCount += len(batch)
+	}
+}
+
+// validate returns the start height, heights of the longest chain are unique keys of stores,
+// so only checkpoints of imported headers are checked.
+func (i *storeImport) validate(startHeight int32) (int32, error) {
+	return startHeight, nil
+}
+
+func (i *storeImport) importedSnapshotHeight(snapshotFile) (int32, bool, error) {
+	return 0, false, nil
+}
 
+func (i *storeImport) saveImportedSnapshot(snapshotFile, int32) error {
 	return nil
 }
 
-func getHeadersFile(preparedDbFilePath string, log *zerolog.Logger) (*os.File, string, error) {
-	currentDir, err := os.Getwd()
+// snapshotFile identifies the prepared database file, so a file imported before doesn't have to be
+// decompressed and hashed again on every start.
+type snapshotFile struct {
+	Path       string
+	Size       int64
+	ModifiedAt int64
+}
+
+func statSnapshotFile(preparedDbFilePath string) (snapshotFile, error) {
+	path, err := filepath.Abs(preparedDbFilePath)
 	if err != nil {
-		return nil, "", err
+		return snapshotFile{}, err
 	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return snapshotFile{}, fmt.Errorf("file %s does not exist or is not readable", preparedDbFilePath)
+	}
+	return snapshotFile{Path: path, Size: info.Size(), ModifiedAt: info.ModTime().UnixNano()}, nil
+}
 
+// openSnapshot reads the decompressed snapshot from the beginning, positioned at the first header which isn't
+// in the database yet.
+func openSnapshot(file *os.File, cfg *config.AppConfig, tip *dto.DbBlockHeader) (*snapshotReader, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	params := cfg.P2P.GetNetParams()
+	snapshot, err := newSnapshotReader(file, newHeaderValidator(params.PowLimit, snapshotCheckpoints(cfg)))
+	if err != nil {
+		return nil, err
+	}
+
+	if tip == nil {
+		err = snapshot.startOnEmptyDatabase()
+	} else {
+		err = snapshot.continueFrom(tip)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// validateSnapshot reads all remaining headers of the snapshot, returning the error of the first invalid one.
+func validateSnapshot(snapshot *snapshotReader) error {
+	for {
+		if _, err := snapshot.next(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func snapshotCheckpoints(cfg *config.AppConfig) []chaincfg.Checkpoint {
+	if cfg.P2P.DisableCheckpoints {
+		return nil
+	}
+	return cfg.P2P.GetNetParams().Checkpoints
+}
+
+// getHeadersFile decompresses the prepared database file to a temporary file. The signature, if any,
+// is verified on the data read during decompression, before the temporary file is returned.
+func getHeadersFile(preparedDbFilePath string, signature *snapshotSignature, log *zerolog.Logger) (*os.File, string, error) {
 	if !fileExistsAndIsReadable(preparedDbFilePath) {
 		return nil, "", fmt.Errorf("file %s does not exist or is not readable", preparedDbFilePath)
 	}
 
 	tmpHeadersFileName := fmt.Sprintf("%d-blockheaders.csv", time.Now().Unix())
 
-	compressedHeadersFilePath := filepath.Clean(filepath.Join(currentDir, preparedDbFilePath))
+	compressedHeadersFilePath, err := filepath.Abs(preparedDbFilePath)
+	if err != nil {
+		return nil, "", err
+	}
 	tmpHeadersFilePath := filepath.Clean(filepath.Join(os.TempDir(), tmpHeadersFileName))
 
 	log.Info().Msgf("Decompressing file %s to %s", compressedHeadersFilePath, tmpHeadersFilePath)
@@ -87,9 +331,17 @@ func getHeadersFile(preparedDbFilePath string, log *zerolog.Logger) (*os.File, s
 		return nil, "", err
 	}
 
-	if err := gzipDecompressWithBuffer(compressedHeadersFile, tmpHeadersFile); err != nil {
+	compressedHeaders := signature.reader(compressedHeadersFile)
+	decompressErr := gzipDecompressWithBuffer(compressedHeaders, tmpHeadersFile)
+	// an invalid signature explains a file which cannot be decompressed as well
+	if err := signature.verify(compressedHeaders); err != nil {
+		dropHeadersFile(tmpHeadersFile, tmpHeadersFilePath, log)
 		return nil, "", err
 	}
+	if decompressErr != nil {
+		dropHeadersFile(tmpHeadersFile, tmpHeadersFilePath, log)
+		return nil, "", decompressErr
+	}
 
 	log.Info().Msgf("Decompressed and wrote contents to %s", tmpHeadersFilePath)
 
@@ -166,16 +418,16 @@ func calculateFields(dbBlock *domains.BlockHeaderSource, cumulatedChainWork stri
 
 	dbBlockHeader := dto.DbBlockHeader{
 		Height:        int32(rowIndex),
-		Hash:          blockhash.String(),
+		Hash:          dto.DbHash(blockhash.String()),
 		Version:       dbBlock.Version,
-		MerkleRoot:    dbBlock.MerkleRoot.String(),
+		MerkleRoot:    dto.DbHash(dbBlock.MerkleRoot.String()),
 		Timestamp:     dbBlock.Timestamp,
 		Bits:          dbBlock.Bits,
 		Nonce:         dbBlock.Nonce,
 		State:         "LONGEST_CHAIN",
-		Chainwork:     chainWork.String(),
-		CumulatedWork: cumulatedChainWorkBigInt.String(),
-		PreviousBlock: dbBlock.PrevBlock.String(),
+		Chainwork:     dto.DbWork(chainWork.String()),
+		CumulatedWork: dto.DbWork(cumulatedChainWorkBigInt.String()),
+		PreviousBlock: dto.DbHash(dbBlock.PrevBlock.String()),
 	}
 	return &dbBlockHeader
 }
@@ -191,33 +443,49 @@ func parseBigInt(s string) *big.Int {
 	return bi
 }
 
-func validateDbConsistency(importCount int, repo *sql.HeadersDb, db *sqlx.DB) error {
+// validateImportedCount checks that the database has all imported headers, the last of them being the highest one.
+func validateImportedCount(countBefore, importCount int, startHeight int32, repo sqlrepository.HeadersStore) error {
 	ctx := context.Background()
 
-	if dbHeadersCount, _ := repo.Count(ctx); dbHeadersCount != importCount {
-		return fmt.Errorf("database is not consistent with csv file, imported %d headers, number of headers in database %d", importCount, dbHeadersCount)
+	if dbHeadersCount, _ := repo.Count(ctx); dbHeadersCount != countBefore+importCount {
+		return fmt.Errorf("database is not consistent with csv file, imported %d headers to %d headers, number of headers in database %d", importCount, countBefore, dbHeadersCount)
 	}
 
-	if maxHeight, _ := repo.Height(ctx); maxHeight != importCount-1 {
-		return fmt.Errorf("database is not consistent with csv file, current maximum header height (%d) is different from imported headers number -1 (%d)", maxHeight, importCount)
+	lastHeight := int(startHeight) + importCount - 1
+	if maxHeight, _ := repo.Height(ctx); maxHeight != lastHeight {
+		return fmt.Errorf("database is not consistent with csv file, current maximum header height (%d) is different from height of the last imported header (%d)", maxHeight, lastHeight)
 	}
+	return nil
+}
 
-	if err := validateHeightUniqueness(db); err != nil {
-		return fmt.Errorf("database is not consistent with csv file, %w", err)
+// validateCheckpoints checks that the longest chain has headers of all checkpoints between the lowest height and its
+// highest header, it doesn't have lower headers when the snapshot started at a checkpoint.
+func validateCheckpoints(checkpoints []chaincfg.Checkpoint, repo sqlrepository.HeadersStore, lowestHeight int32) error {
+	highestHeight, err := repo.Height(context.Background())
+	if err != nil {
+		return err
 	}
 
-	if err := validateNewestCheckpointBlock(db); err != nil {
-		return fmt.Errorf("database is not consistent with csv file, %w", err)
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Height < lowestHeight || int(checkpoint.Height) > highestHeight {
+			continue
+		}
+		header, err := repo.GetHeaderByHeight(context.Background(), checkpoint.Height, string(domains.LongestChain))
+		if err != nil {
+			return fmt.Errorf("database has no header of checkpoint at height %d: %w", checkpoint.Height, err)
+		}
+		if header.Hash.String() != checkpoint.Hash.String() {
+			return fmt.Errorf("database has header %s at height %d, which doesn't match checkpoint %s", header.Hash, checkpoint.Height, checkpoint.Hash)
+		}
 	}
-
 	return nil
 }
 
 func validateHeightUniqueness(db *sqlx.DB) error {
 	tmpIndex := "tmp_height_unique"
-	_, err := db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX %s ON headers (height)", tmpIndex))
+	_, err := db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX %s ON headers (height) WHERE header_state = '%s'", tmpIndex, domains.LongestChain))
 	if err != nil {
-		return errors.New("height values are not unique(they should be just after import)")
+		return errors.New("height values of the longest chain are not unique(they should be just after import)")
 	}
 
 	if _, err = db.Exec(fmt.Sprintf("DROP INDEX %s;", tmpIndex)); err != nil {
@@ -226,17 +494,3 @@ func validateHeightUniqueness(db *sqlx.DB) error {
 
 	return nil
 }
-
-func validateNewestCheckpointBlock(db *sqlx.DB) error {
-	newestCheckpointBlock := config.Checkpoints[len(config.Checkpoints)-1]
-	newestCheckpointBlockQuery := fmt.Sprintf("SELECT hash FROM %s WHERE height = %d", sql.HeadersTableName, newestCheckpointBlock.Height)
-	var hashResult string
-	err := db.Get(&hashResult, newestCheckpointBlockQuery)
-	if err != nil {
-		return fmt.Errorf("newest checkpoint block with height \"%d\" is not present in the database", newestCheckpointBlock.Height)
-	}
-	if newestCheckpointBlock.Hash.String() != hashResult {
-		return fmt.Errorf("newest checkpoint block has different hash \"%s\" than hash \"%s\" of block in database with the same height (%d)", newestCheckpointBlock.Hash.String(), hashResult, newestCheckpointBlock.Height)
-	}
-	return nil
-}
@@ -132,8 +132,8 @@ func TestPrepareRecordHappyPath(t *testing.T) {
 					t.Errorf("Error while preparing record: %v", err)
 				}
 				result = append(result, *block)
-				tc.data.previousBlockHash = block.Hash
-				tc.data.cumulatedChainWork = block.CumulatedWork
+				tc.data.previousBlockHash = block.Hash.String()
+				tc.data.cumulatedChainWork = block.CumulatedWork.String()
 				tc.data.rowIndex++
 			}
 			assert.Equal[dto.DbBlockHeader](t, result[tc.data.numberOfBlocks-1], *tc.expectedBlock)
@@ -0,0 +1,56 @@
+package kv
+
+import (
+	"context"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"go.etcd.io/bbolt"
+)
+
+// auditBucket has records of the audit log under their ids, which are sorted by time.
+var auditBucket = []byte("audit_log")
+
+// CreateAuditRecord method will append the record to the audit log. Records are never updated nor deleted.
+func (h *HeadersKv) CreateAuditRecord(_ context.Context, record *dto.DbAuditRecord) error {
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		return insertRecord(tx, auditBucket, []byte(record.ID), record)
+	})
+	if err != nil {
+		return bhserrors.ErrCreateAuditRecord.Wrap(err)
+	}
+	return nil
+}
+
+// GetAuditRecords method will return records of the audit log matching the filter, newest first.
+func (h *HeadersKv) GetAuditRecords(_ context.Context, filter domains.AuditFilter) ([]*dto.DbAuditRecord, error) {
+	records := make([]*dto.DbAuditRecord, 0)
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(auditBucket).Cursor()
+		k, _ := c.Last()
+		if filter.Before != "" {
+			// Seek positions the cursor on the first id not lower than Before
+			if k, _ = c.Seek([]byte(filter.Before)); k == nil {
+				k, _ = c.Last()
+			} else {
+				k, _ = c.Prev()
+			}
+		}
+		for ; k != nil && len(records) < filter.Limit; k, _ = c.Prev() {
+			var record dto.DbAuditRecord
+			if _, err := getRecord(tx, auditBucket, k, &record); err != nil {
+				return err
+			}
+			if filter.Action != "" && record.Action != string(filter.Action) || filter.Actor != "" && record.Actor != filter.Actor {
+				continue
+			}
+			records = append(records, &record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, bhserrors.ErrGetAuditRecords.Wrap(err)
+	}
+	return records, nil
+}
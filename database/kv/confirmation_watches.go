@@ -0,0 +1,105 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"slices"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"go.etcd.io/bbolt"
+)
+
+// confirmationWatchesBucket has keys of hash and owner of headers watched for reaching the confirmation depth.
+var confirmationWatchesBucket = []byte("confirmation_watches")
+
+// confirmationWatchHashLength is the length of hashes of watched headers, which the owner follows in the key.
+const confirmationWatchHashLength = 64
+
+// CreateConfirmationWatches method will add hashes of headers watched by the owner for reaching the confirmation depth into db.
+func (h *HeadersKv) CreateConfirmationWatches(_ context.Context, owner string, hashes []string) error {
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		for _, hash := range hashes {
+			if err := tx.Bucket(confirmationWatchesBucket).Put(confirmationWatchKey(owner, hash), nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return bhserrors.ErrWatchHeaders.Wrap(err)
+	}
+	return nil
+}
+
+// DeleteConfirmationWatches method will delete hashes of headers watched by the owner from db.
+func (h *HeadersKv) DeleteConfirmationWatches(_ context.Context, owner string, hashes []string) error {
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		for _, hash := range hashes {
+			if err := tx.Bucket(confirmationWatchesBucket).Delete(confirmationWatchKey(owner, hash)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return bhserrors.ErrWatchHeaders.Wrap(err)
+	}
+	return nil
+}
+
+// GetConfirmationWatches method will return all watched headers with their owners from db.
+func (h *HeadersKv) GetConfirmationWatches(_ context.Context) ([]*dto.DbConfirmationWatch, error) {
+	watches := make([]*dto.DbConfirmationWatch, 0)
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(confirmationWatchesBucket).ForEach(func(k, _ []byte) error {
+			watches = append(watches, decodeConfirmationWatchKey(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, bhserrors.ErrWatchHeaders.Wrap(err)
+	}
+	return watches, nil
+}
+
+// TransferConfirmationWatches method will change the owner of watched headers in db.
+func (h *HeadersKv) TransferConfirmationWatches(_ context.Context, from, to string) error {
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		watches := tx.Bucket(confirmationWatchesBucket)
+		owned := make([]*dto.DbConfirmationWatch, 0)
+		err := watches.ForEach(func(k, _ []byte) error {
+			if w := decodeConfirmationWatchKey(k); w.Owner == from {
+				owned = append(owned, w)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, w := range owned {
+			if err := watches.Delete(confirmationWatchKey(from, w.Hash)); err != nil {
+				return err
+			}
+			if err := watches.Put(confirmationWatchKey(to, w.Hash), nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return bhserrors.ErrWatchHeaders.Wrap(err)
+	}
+	return nil
+}
+
+// confirmationWatchKey keys watches of the same header by their owners, the key of a watch without an owner
+// is the same as it was before watches had owners.
+func confirmationWatchKey(owner, hash string) []byte {
+	return slices.Concat([]byte(hash), []byte(owner))
+}
+
+func decodeConfirmationWatchKey(k []byte) *dto.DbConfirmationWatch {
+	n := min(len(k), confirmationWatchHashLength)
+	return &dto.DbConfirmationWatch{Hash: string(bytes.Clone(k[:n])), Owner: string(k[n:])}
+}
@@ -0,0 +1,93 @@
+package kv
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+)
+
+// headerValueSize is the size of the encoded header without its state, which is stored at the end of the value.
+const headerValueSize = 4 + 4 + 32 + 32 + 8 + 4 + 4 + dto.WorkSize + dto.WorkSize + 8
+
+// encodeHeader encodes the header stored in the headers bucket under its hash.
+func encodeHeader(h *dto.DbBlockHeader) ([]byte, error) {
+	prevBlock, err := h.PreviousBlock.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	merkleRoot, err := h.MerkleRoot.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	chainwork, err := h.Chainwork.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	cumulatedWork, err := h.CumulatedWork.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	v := make([]byte, 0, headerValueSize+len(h.State))
+	v = binary.BigEndian.AppendUint32(v, uint32(h.Height))
+	v = binary.BigEndian.AppendUint32(v, uint32(h.Version))
+	v = append(v, prevBlock...)
+	v = append(v, merkleRoot...)
+	v = binary.BigEndian.AppendUint64(v, uint64(h.Timestamp.Unix()))
+	v = binary.BigEndian.AppendUint32(v, h.Bits)
+	v = binary.BigEndian.AppendUint32(v, h.Nonce)
+	v = append(v, chainwork...)
+	v = append(v, cumulatedWork...)
+	v = binary.BigEndian.AppendUint64(v, uint64(h.CreatedAt.UnixNano()))
+	v = append(v, h.State...)
+	return v, nil
+}
+
+// decodeHeader decodes the header with the hash from the value encoded by encodeHeader.
+func decodeHeader(hash, v []byte) (*dto.DbBlockHeader, error) {
+	if len(v) < headerValueSize {
+		return nil, errors.New("invalid length of encoded header")
+	}
+	h := &dto.DbBlockHeader{}
+	if err := h.Hash.Scan(hash); err != nil {
+		return nil, err
+	}
+	h.Height = int32(binary.BigEndian.Uint32(v[0:4]))
+	h.Version = int32(binary.BigEndian.Uint32(v[4:8]))
+	if err := h.PreviousBlock.Scan(v[8:40]); err != nil {
+		return nil, err
+	}
+	if err := h.MerkleRoot.Scan(v[40:72]); err != nil {
+		return nil, err
+	}
+	h.Timestamp = time.Unix(int64(binary.BigEndian.Uint64(v[72:80])), 0).UTC()
+	h.Bits = binary.BigEndian.Uint32(v[80:84])
+	h.Nonce = binary.BigEndian.Uint32(v[84:88])
+	if err := h.Chainwork.SetBytes(v[88 : 88+dto.WorkSize]); err != nil {
+		return nil, err
+	}
+	if err := h.CumulatedWork.SetBytes(v[88+dto.WorkSize : headerValueSize-8]); err != nil {
+		return nil, err
+	}
+	h.CreatedAt = time.Unix(0, int64(binary.BigEndian.Uint64(v[headerValueSize-8:headerValueSize]))).UTC()
+	h.State = string(v[headerValueSize:])
+	return h, nil
+}
+
+// heightKey is the key of the height in the heights and branches buckets, sorted by height.
+func heightKey(height int32) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(height))
+}
+
+// statePrefix is the prefix of keys of headers in the state in the states bucket.
+func statePrefix(state string) []byte {
+	return append([]byte(state), 0)
+}
+
+// stateKey is the key of the header in the states bucket, sorted by state and time of storing.
+func stateKey(state string, createdAt time.Time, hash []byte) []byte {
+	return slices.Concat(binary.BigEndian.AppendUint64(statePrefix(state), uint64(createdAt.UnixNano())), hash)
+}
@@ -0,0 +1,751 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/rs/zerolog"
+	"go.etcd.io/bbolt"
+)
+
+const longestChainState = "LONGEST_CHAIN"
+
+var (
+	// headersBucket maps hash to the encoded header.
+	headersBucket = []byte("headers")
+	// heightsBucket maps height to the hash of the header of the longest chain.
+	heightsBucket = []byte("heights")
+	// branchesBucket has keys of height and hash of headers which aren't in heightsBucket, mostly stale, orphan and rejected ones.
+	branchesBucket = []byte("branches")
+	// merkleRootsBucket has keys of merkle root and hash of every header.
+	merkleRootsBucket = []byte("merkleroots")
+	// statesBucket has keys of state, time of storing and hash of every header, to find old headers of a state.
+	statesBucket = []byte("states")
+	// metaBucket has counters of stored records.
+	metaBucket = []byte("meta")
+
+	headersCountKey = []byte("headers_count")
+)
+
+// HeadersKv represents the embedded key-value database of headers, tokens, webhooks, merkle root watches and the audit log.
+type HeadersKv struct {
+	db  *bbolt.DB
+	log *zerolog.Logger
+}
+
+// NewHeadersKv will create missing buckets and return a new store.
+func NewHeadersKv(db *bbolt.DB, log *zerolog.Logger) (*HeadersKv, error) {
+	headerLogger := log.With().Str("subservice", "headers-kv").Logger()
+	err := db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{headersBucket, heightsBucket, branchesBucket, merkleRootsBucket, statesBucket, metaBucket, tokensBucket, webhooksBucket, merkleRootWatchesBucket, confirmationWatchesBucket, auditBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &HeadersKv{db: db, log: &headerLogger}, nil
+}
+
+// Close closes the database.
+func (h *HeadersKv) Close() error {
+	return h.db.Close()
+}
+
+// Create method will add new record into db.
+func (h *HeadersKv) Create(ctx context.Context, req dto.DbBlockHeader) error {
+	return h.CreateMultiple(ctx, []dto.DbBlockHeader{req})
+}
+
+// CreateMultiple method will add multiple new records into db, headers which already exist are omitted.
+func (h *HeadersKv) CreateMultiple(_ context.Context, headers []dto.DbBlockHeader) error {
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		for i := range headers {
+			hash, err := headers[i].Hash.Bytes()
+			if err != nil {
+				return err
+			}
+			if tx.Bucket(headersBucket).Get(hash) != nil {
+				continue
+			}
+			if err := putHeader(tx, &headers[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateState will update state of headers of hashes to given state.
+func (h *HeadersKv) UpdateState(_ context.Context, hashes []string, state string) error {
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		for _, hash := range hashes {
+			header, err := getHeader(tx, dto.DbHash(hash))
+			if err != nil {
+				return err
+			}
+			if header == nil {
+				continue
+			}
+			header.State = state
+			if err := putHeader(tx, header); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateChain will update height, state and work of headers, which place them in the chain.
+func (h *HeadersKv) UpdateChain(_ context.Context, headers []dto.DbBlockHeader) error {
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		for _, record := range headers {
+			header, err := getHeader(tx, record.Hash)
+			if err != nil {
+				return err
+			}
+			if header == nil {
+				continue
+			}
+			header.Height = record.Height
+			header.State = record.State
+			header.Chainwork = record.Chainwork
+			header.CumulatedWork = record.CumulatedWork
+			if err := putHeader(tx, header); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete will remove headers with given hashes and return the number of removed ones.
+func (h *HeadersKv) Delete(_ context.Context, hashes []string) (int, error) {
+	deleted := 0
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		for _, hash := range hashes {
+			header, err := getHeader(tx, dto.DbHash(hash))
+			if err != nil {
+				return err
+			}
+			if header == nil {
+				continue
+			}
+			if err := deleteHeader(tx, header); err != nil {
+				return err
+			}
+			deleted++
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// DeleteOlderThan will remove headers in given state stored before given time and return the number of removed ones.
+func (h *HeadersKv) DeleteOlderThan(_ context.Context, state string, before time.Time) (int, error) {
+	deleted := 0
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		prefix := statePrefix(state)
+		end := stateKey(state, before, nil)
+		keys := make([][]byte, 0)
+		c := tx.Bucket(statesBucket).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.Compare(k, end) < 0; k, _ = c.Next() {
+			keys = append(keys, bytes.Clone(k))
+		}
+		for _, k := range keys {
+			hash := k[len(end):]
+			header, err := decodeHeader(hash, tx.Bucket(headersBucket).Get(hash))
+			if err != nil {
+				return err
+			}
+			if err := deleteHeader(tx, header); err != nil {
+				return err
+			}
+		}
+		deleted = len(keys)
+		return nil
+	})
+	return deleted, err
+}
+
+// Height will return the current highest block height we have stored in the db.
+func (h *HeadersKv) Height(_ context.Context) (int, error) {
+	var height int32
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		if k, _ := tx.Bucket(heightsBucket).Cursor().Last(); k != nil {
+			height = int32(binary.BigEndian.Uint32(k))
+		}
+		if k, _ := tx.Bucket(branchesBucket).Cursor().Last(); k != nil {
+			height = max(height, int32(binary.BigEndian.Uint32(k)))
+		}
+		return nil
+	})
+	return int(height), err
+}
+
+// Count will return the current number of headers in db.
+func (h *HeadersKv) Count(_ context.Context) (int, error) {
+	var count uint64
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		count = getCounter(tx, headersCountKey)
+		return nil
+	})
+	return int(count), err
+}
+
+// GetHeaderByHash will return header from db with given hash.
+func (h *HeadersKv) GetHeaderByHash(_ context.Context, hash string) (*dto.DbBlockHeader, error) {
+	var header *dto.DbBlockHeader
+	err := h.db.View(func(tx *bbolt.Tx) (err error) {
+		header, err = getHeader(tx, dto.DbHash(hash))
+		return err
+	})
+	if err != nil {
+		return nil, bhserrors.ErrHeaderNotFound.Wrap(err)
+	}
+	if header == nil {
+		return nil, bhserrors.ErrHeaderNotFound
+	}
+	return header, nil
+}
+
+// GetHeaderByHeight will return header from db with given height and in given state.
+func (h *HeadersKv) GetHeaderByHeight(_ context.Context, height int32, state string) (*dto.DbBlockHeader, error) {
+	var header *dto.DbBlockHeader
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		headers, err := headersInHeightRange(tx, height, height)
+		if err != nil {
+			return err
+		}
+		i := slices.IndexFunc(headers, func(h *dto.DbBlockHeader) bool { return h.State == state })
+		if i >= 0 {
+			header = headers[i]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.New("could not find height")
+	}
+	return header, nil
+}
+
+// GetHeaderByHeightRange will return headers from db for given height range (including sended height).
+func (h *HeadersKv) GetHeaderByHeightRange(from int, to int) ([]*dto.DbBlockHeader, error) {
+	var headers []*dto.DbBlockHeader
+	err := h.db.View(func(tx *bbolt.Tx) (err error) {
+		headers, err = headersInHeightRange(tx, int32(from), int32(to))
+		return err
+	})
+	if err != nil {
+		return nil, bhserrors.ErrHeadersForGivenRangeNotFound.Wrap(err)
+	}
+	return headers, nil
+}
+
+// GetLongestChainHeadersFromHeight returns from db the headers from "longest chain" starting from given height.
+func (h *HeadersKv) GetLongestChainHeadersFromHeight(height int32) ([]*dto.DbBlockHeader, error) {
+	var headers []*dto.DbBlockHeader
+	err := h.db.View(func(tx *bbolt.Tx) (err error) {
+		headers, err = longestChainHeaders(tx, height, -1)
+		return err
+	})
+	return headers, err
+}
+
+// GetStaleHeadersBackFrom returns from db all the headers with state STALE, starting from header with hash and preceding that one.
+// Ancestors of a header of the longest chain are in the longest chain, so they are not visited.
+func (h *HeadersKv) GetStaleHeadersBackFrom(hash string) ([]*dto.DbBlockHeader, error) {
+	var headers []*dto.DbBlockHeader
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		header, err := getHeader(tx, dto.DbHash(hash))
+		for ; err == nil && header != nil && header.State != longestChainState; header, err = getHeader(tx, header.PreviousBlock) {
+			if header.State == string(domains.Stale) {
+				headers = append(headers, header)
+			}
+		}
+		return err
+	})
+	return headers, err
+}
+
+// GenesisExists check if genesis header is present in db.
+func (h *HeadersKv) GenesisExists(_ context.Context) bool {
+	exists := false
+	_ = h.db.View(func(tx *bbolt.Tx) error {
+		exists = tx.Bucket(heightsBucket).Get(heightKey(0)) != nil
+		return nil
+	})
+	return exists
+}
+
+// GetPreviousHeader will return previous header for this with given hash.
+func (h *HeadersKv) GetPreviousHeader(_ context.Context, hash string) (*dto.DbBlockHeader, error) {
+	var previous *dto.DbBlockHeader
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		header, err := getHeader(tx, dto.DbHash(hash))
+		if err != nil || header == nil {
+			return err
+		}
+		previous, err = getHeader(tx, header.PreviousBlock)
+		return err
+	})
+	if err != nil {
+		return nil, bhserrors.ErrHeaderNotFound.Wrap(err)
+	}
+	if previous == nil {
+		return nil, bhserrors.ErrHeaderNotFound
+	}
+	return previous, nil
+}
+
+// GetTip will return highest header of the longest chain from db.
+func (h *HeadersKv) GetTip(_ context.Context) (*dto.DbBlockHeader, error) {
+	var tip *dto.DbBlockHeader
+	err := h.db.View(func(tx *bbolt.Tx) (err error) {
+		tip, err = longestChainTip(tx)
+		return err
+	})
+	if err != nil {
+		h.log.Error().Msgf("kv error: %v", err)
+		return nil, errors.Join(errors.New("failed to get tip"), err)
+	}
+	if tip == nil {
+		return nil, errors.New("could not find tip")
+	}
+	return tip, nil
+}
+
+// GetAncestorOnHeight provides ancestor for a hash on a specified height.
+func (h *HeadersKv) GetAncestorOnHeight(hash string, height int32) (*dto.DbBlockHeader, error) {
+	var ancestor *dto.DbBlockHeader
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		header, err := getHeader(tx, dto.DbHash(hash))
+		for ; err == nil && header != nil && header.Height > height; header, err = getHeader(tx, header.PreviousBlock) {
+			// ancestors of a header of the longest chain are found by their height
+			if header.State == longestChainState {
+				header, err = longestChainHeader(tx, height)
+				break
+			}
+		}
+		if header != nil && header.Height == height {
+			ancestor = header
+		}
+		return err
+	})
+	if err != nil {
+		return nil, bhserrors.ErrAncestorNotFound.Wrap(err)
+	}
+	if ancestor == nil {
+		return nil, bhserrors.ErrAncestorNotFound
+	}
+	return ancestor, nil
+}
+
+// GetAllTips returns the tip of the longest chain and tips of all other branches from db.
+func (h *HeadersKv) GetAllTips() ([]*dto.DbBlockHeader, error) {
+	tips := make([]*dto.DbBlockHeader, 0)
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		tip, err := longestChainTip(tx)
+		if err != nil {
+			return err
+		}
+		if tip != nil {
+			tips = append(tips, tip)
+		}
+
+		branches, err := headersOfBranches(tx, 0)
+		if err != nil {
+			return err
+		}
+		previous := make(map[dto.DbHash]bool, len(branches))
+		for _, header := range branches {
+			if header.State != longestChainState {
+				previous[header.PreviousBlock] = true
+			}
+		}
+		for _, header := range branches {
+			if header.State != longestChainState && !previous[header.Hash] {
+				tips = append(tips, header)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, bhserrors.ErrGetTips.Wrap(err)
+	}
+	return tips, nil
+}
+
+// GetChainBetweenTwoHashes calculates and returnes chain between 2 hashes.
+func (h *HeadersKv) GetChainBetweenTwoHashes(low string, high string) ([]*dto.DbBlockHeader, error) {
+	var headers []*dto.DbBlockHeader
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		header, err := getHeader(tx, dto.DbHash(high))
+		for ; err == nil && header != nil && header.Hash != dto.DbHash(low); header, err = getHeader(tx, header.PreviousBlock) {
+			headers = append(headers, header)
+		}
+		if err != nil {
+			return err
+		}
+		lowHeader, err := getHeader(tx, dto.DbHash(low))
+		if lowHeader != nil {
+			headers = append(headers, lowHeader)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, bhserrors.ErrHeadersForGivenRangeNotFound.Wrap(err)
+	}
+	if len(headers) == 0 {
+		return nil, bhserrors.ErrHeadersForGivenRangeNotFound
+	}
+	return headers, nil
+}
+
+// GetMerkleRootsConfirmations returns confirmation of merkle roots inclusion in the longest chain.
+func (h *HeadersKv) GetMerkleRootsConfirmations(
+	request []domains.MerkleRootConfirmationRequestItem,
+) ([]*dto.DbMerkleRootConfirmation, error) {
+	confirmations := make([]*dto.DbMerkleRootConfirmation, 0, len(request))
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		tip, err := longestChainTip(tx)
+		if err != nil {
+			return bhserrors.ErrGetChainTipHeight.Wrap(err)
+		}
+		var tipHeight int32
+		if tip != nil {
+			tipHeight = tip.Height
+		}
+
+		for _, item := range request {
+			confirmation := &dto.DbMerkleRootConfirmation{
+				MerkleRoot:  item.MerkleRoot,
+				BlockHeight: item.BlockHeight,
+				TipHeight:   tipHeight,
+			}
+			// an invalid merkle root isn't stored, so it is unconfirmed
+			if _, err := dto.DbHash(item.MerkleRoot).Bytes(); err != nil {
+				confirmations = append(confirmations, confirmation)
+				continue
+			}
+			headers, err := headersWithMerkleRoot(tx, dto.DbHash(item.MerkleRoot))
+			if err != nil {
+				return err
+			}
+			for _, header := range headers {
+				if header.Height == item.BlockHeight && header.State == longestChainState {
+					confirmation.Hash.V, confirmation.Hash.Valid = header.Hash, true
+				}
+			}
+			confirmations = append(confirmations, confirmation)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return confirmations, nil
+}
+
+// GetHeadersStartHeight returns the height of the highest header of the longest chain with one of given hashes.
+func (h *HeadersKv) GetHeadersStartHeight(hashTable []string) (int, error) {
+	var startHeight int32
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		for _, hash := range hashTable {
+			header, err := getHeader(tx, dto.DbHash(hash))
+			if err != nil {
+				continue
+			}
+			if header != nil && header.State == longestChainState {
+				startHeight = max(startHeight, header.Height)
+			}
+		}
+		return nil
+	})
+	return int(startHeight), err
+}
+
+// GetHeadersStopHeight will return height of the header of the longest chain with given hash, 0 when there is no such header.
+func (h *HeadersKv) GetHeadersStopHeight(hashStop string) (int, error) {
+	var stopHeight int32
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		header, err := getHeader(tx, dto.DbHash(hashStop))
+		if err == nil && header != nil && header.State == longestChainState {
+			stopHeight = header.Height
+		}
+		return nil
+	})
+	return int(stopHeight), err
+}
+
+// GetHeadersByHeightRange returns headers of the longest chain from db in specified height range.
+func (h *HeadersKv) GetHeadersByHeightRange(from int, to int) ([]*dto.DbBlockHeader, error) {
+	var headers []*dto.DbBlockHeader
+	err := h.db.View(func(tx *bbolt.Tx) (err error) {
+		headers, err = longestChainHeaders(tx, int32(from), int32(to))
+		return err
+	})
+	return headers, err
+}
+
+// GetMerkleRoots method will retrieve as many merkleroots as batchSize from the db from lastEvaluatedKey exclusive
+func (h *HeadersKv) GetMerkleRoots(batchSize int, lastEvaluatedKey string) ([]*dto.DbMerkleRoot, error) {
+	var merkleroots []*dto.DbMerkleRoot
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		// last evaluated height starts with -1 to fetch from the beginning of the database
+		var lastEvaluatedHeight int32 = -1
+		if lastEvaluatedKey != "" {
+			headers, err := headersWithMerkleRoot(tx, dto.DbHash(lastEvaluatedKey))
+			if err != nil || len(headers) == 0 {
+				return bhserrors.ErrMerklerootNotFound
+			}
+			i := slices.IndexFunc(headers, func(h *dto.DbBlockHeader) bool { return h.State == longestChainState })
+			if i < 0 {
+				return bhserrors.ErrMerklerootNotInLongestChain
+			}
+			lastEvaluatedHeight = headers[i].Height
+		}
+
+		headers, err := longestChainHeaders(tx, lastEvaluatedHeight+1, -1, batchSize)
+		for _, header := range headers {
+			merkleroots = append(merkleroots, &dto.DbMerkleRoot{MerkleRoot: header.MerkleRoot, Height: header.Height})
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return merkleroots, nil
+}
+
+// getHeader returns the header with the hash, nil when it doesn't exist.
+func getHeader(tx *bbolt.Tx, hash dto.DbHash) (*dto.DbBlockHeader, error) {
+	key, err := hash.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	v := tx.Bucket(headersBucket).Get(key)
+	if v == nil {
+		return nil, nil
+	}
+	return decodeHeader(key, v)
+}
+
+// putHeader stores the header and updates indexes of its previous version. The time the header was stored
+// is kept from its previous version.
+func putHeader(tx *bbolt.Tx, header *dto.DbBlockHeader) error {
+	old, err := getHeader(tx, header.Hash)
+	if err != nil {
+		return err
+	}
+	if old != nil {
+		if err := unindexHeader(tx, old); err != nil {
+			return err
+		}
+		stored := *header
+		stored.CreatedAt = old.CreatedAt
+		header = &stored
+	} else if err := addToCounter(tx, headersCountKey, 1); err != nil {
+		return err
+	}
+	if header.CreatedAt.IsZero() {
+		stored := *header
+		stored.CreatedAt = time.Now()
+		header = &stored
+	}
+
+	hash, _ := header.Hash.Bytes()
+	v, err := encodeHeader(header)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(headersBucket).Put(hash, v); err != nil {
+		return err
+	}
+	return indexHeader(tx, header)
+}
+
+func deleteHeader(tx *bbolt.Tx, header *dto.DbBlockHeader) error {
+	if err := unindexHeader(tx, header); err != nil {
+		return err
+	}
+	if err := addToCounter(tx, headersCountKey, -1); err != nil {
+		return err
+	}
+	hash, _ := header.Hash.Bytes()
+	return tx.Bucket(headersBucket).Delete(hash)
+}
+
+// indexHeader adds the header to the merkle roots and states buckets, and to the heights bucket when it is in the longest chain
+// and the height has no other header of the longest chain, otherwise to the branches bucket.
+func indexHeader(tx *bbolt.Tx, header *dto.DbBlockHeader) error {
+	hash, _ := header.Hash.Bytes()
+	merkleRoot, _ := header.MerkleRoot.Bytes()
+	if err := tx.Bucket(merkleRootsBucket).Put(slices.Concat(merkleRoot, hash), nil); err != nil {
+		return err
+	}
+	if err := tx.Bucket(statesBucket).Put(stateKey(header.State, header.CreatedAt, hash), nil); err != nil {
+		return err
+	}
+
+	heights := tx.Bucket(heightsBucket)
+	key := heightKey(header.Height)
+	if indexed := heights.Get(key); header.State == longestChainState && (indexed == nil || bytes.Equal(indexed, hash)) {
+		return heights.Put(key, hash)
+	}
+	return tx.Bucket(branchesBucket).Put(slices.Concat(key, hash), nil)
+}
+
+// unindexHeader removes the header from the indexes. When it was in the heights bucket, other header of the longest
+// chain from the branches bucket on its height takes its place.
+func unindexHeader(tx *bbolt.Tx, header *dto.DbBlockHeader) error {
+	hash, _ := header.Hash.Bytes()
+	merkleRoot, _ := header.MerkleRoot.Bytes()
+	if err := tx.Bucket(merkleRootsBucket).Delete(slices.Concat(merkleRoot, hash)); err != nil {
+		return err
+	}
+	if err := tx.Bucket(statesBucket).Delete(stateKey(header.State, header.CreatedAt, hash)); err != nil {
+		return err
+	}
+
+	heights := tx.Bucket(heightsBucket)
+	key := heightKey(header.Height)
+	if !bytes.Equal(heights.Get(key), hash) {
+		return tx.Bucket(branchesBucket).Delete(slices.Concat(key, hash))
+	}
+	if err := heights.Delete(key); err != nil {
+		return err
+	}
+
+	branches, err := headersOfBranchesInRange(tx, header.Height, header.Height)
+	if err != nil {
+		return err
+	}
+	for _, other := range branches {
+		if other.State == longestChainState {
+			otherHash, _ := other.Hash.Bytes()
+			if err := tx.Bucket(branchesBucket).Delete(slices.Concat(key, otherHash)); err != nil {
+				return err
+			}
+			return heights.Put(key, otherHash)
+		}
+	}
+	return nil
+}
+
+// longestChainHeader returns the header of the longest chain on the height, nil when there is no such header.
+func longestChainHeader(tx *bbolt.Tx, height int32) (*dto.DbBlockHeader, error) {
+	hash := tx.Bucket(heightsBucket).Get(heightKey(height))
+	if hash == nil {
+		return nil, nil
+	}
+	return decodeHeader(hash, tx.Bucket(headersBucket).Get(hash))
+}
+
+func longestChainTip(tx *bbolt.Tx) (*dto.DbBlockHeader, error) {
+	_, hash := tx.Bucket(heightsBucket).Cursor().Last()
+	if hash == nil {
+		return nil, nil
+	}
+	return decodeHeader(hash, tx.Bucket(headersBucket).Get(hash))
+}
+
+// longestChainHeaders returns headers of the longest chain from the height up to the height to, -1 returns headers
+// up to the tip. The optional limit is the maximum number of returned headers.
+func longestChainHeaders(tx *bbolt.Tx, from, to int32, limit ...int) ([]*dto.DbBlockHeader, error) {
+	headers := make([]*dto.DbBlockHeader, 0)
+	c := tx.Bucket(heightsBucket).Cursor()
+	for k, hash := c.Seek(heightKey(max(from, 0))); k != nil; k, hash = c.Next() {
+		if to >= 0 && int32(binary.BigEndian.Uint32(k)) > to || len(limit) > 0 && len(headers) >= limit[0] {
+			break
+		}
+		header, err := decodeHeader(hash, tx.Bucket(headersBucket).Get(hash))
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
+// headersOfBranchesInRange returns headers from the branches bucket in the height range.
+func headersOfBranchesInRange(tx *bbolt.Tx, from, to int32) ([]*dto.DbBlockHeader, error) {
+	headers := make([]*dto.DbBlockHeader, 0)
+	c := tx.Bucket(branchesBucket).Cursor()
+	for k, _ := c.Seek(heightKey(max(from, 0))); k != nil; k, _ = c.Next() {
+		if to >= 0 && int32(binary.BigEndian.Uint32(k)) > to {
+			break
+		}
+		hash := k[4:]
+		header, err := decodeHeader(hash, tx.Bucket(headersBucket).Get(hash))
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
+func headersOfBranches(tx *bbolt.Tx, from int32) ([]*dto.DbBlockHeader, error) {
+	return headersOfBranchesInRange(tx, from, -1)
+}
+
+// headersInHeightRange returns headers in all states in the height range, sorted by height.
+func headersInHeightRange(tx *bbolt.Tx, from, to int32) ([]*dto.DbBlockHeader, error) {
+	headers, err := longestChainHeaders(tx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	branches, err := headersOfBranchesInRange(tx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	headers = append(headers, branches...)
+	slices.SortStableFunc(headers, func(a, b *dto.DbBlockHeader) int { return int(a.Height) - int(b.Height) })
+	return headers, nil
+}
+
+// headersWithMerkleRoot returns all headers with the merkle root.
+func headersWithMerkleRoot(tx *bbolt.Tx, merkleRoot dto.DbHash) ([]*dto.DbBlockHeader, error) {
+	prefix, err := merkleRoot.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]*dto.DbBlockHeader, 0, 1)
+	c := tx.Bucket(merkleRootsBucket).Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		hash := k[len(prefix):]
+		header, err := decodeHeader(hash, tx.Bucket(headersBucket).Get(hash))
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
+func getCounter(tx *bbolt.Tx, key []byte) uint64 {
+	v := tx.Bucket(metaBucket).Get(key)
+	if v == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(v)
+}
+
+func addToCounter(tx *bbolt.Tx, key []byte, delta int64) error {
+	count := int64(getCounter(tx, key)) + delta
+	return tx.Bucket(metaBucket).Put(key, binary.BigEndian.AppendUint64(nil, uint64(max(count, 0))))
+}
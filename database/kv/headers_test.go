@@ -0,0 +1,246 @@
+package kv
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/rs/zerolog"
+	"go.etcd.io/bbolt"
+)
+
+func TestHeadersOfLongestChain(t *testing.T) {
+	// given
+	chain, tip := fixtures.LongestChain()
+	store := createTestStore(t, chain)
+	ctx := context.Background()
+
+	// when
+	count, err := store.Count(ctx)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, count, 5)
+	height, _ := store.Height(ctx)
+	assert.Equal(t, height, 4)
+	assert.Equal(t, store.GenesisExists(ctx), true)
+
+	stored, err := store.GetTip(ctx)
+	assert.NoError(t, err)
+	expected := dto.ToDbBlockHeader(*tip)
+	assert.Equal(t, stored.Hash, expected.Hash)
+	assert.Equal(t, stored.PreviousBlock, expected.PreviousBlock)
+	assert.Equal(t, stored.MerkleRoot, expected.MerkleRoot)
+	assert.Equal(t, stored.Timestamp.Unix(), expected.Timestamp.Unix())
+	assert.Equal(t, stored.Bits, expected.Bits)
+	assert.Equal(t, stored.Nonce, expected.Nonce)
+	assert.Equal(t, stored.CumulatedWork, expected.CumulatedWork)
+	assert.Equal(t, stored.State, expected.State)
+
+	header, err := store.GetHeaderByHeight(ctx, 2, string(domains.LongestChain))
+	assert.NoError(t, err)
+	assert.Equal(t, header.Hash.String(), fixtures.HashHeight2.String())
+
+	ancestor, err := store.GetAncestorOnHeight(fixtures.HashHeight4.String(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, ancestor.Hash.String(), fixtures.HashHeight1.String())
+
+	previous, err := store.GetPreviousHeader(ctx, fixtures.HashHeight3.String())
+	assert.NoError(t, err)
+	assert.Equal(t, previous.Hash.String(), fixtures.HashHeight2.String())
+
+	headers, _ := store.GetHeadersByHeightRange(1, 3)
+	assert.Equal(t, len(headers), 3)
+	headers, _ = store.GetChainBetweenTwoHashes(fixtures.HashHeight1.String(), fixtures.HashHeight3.String())
+	assert.Equal(t, len(headers), 3)
+	assert.Equal(t, headers[0].Hash.String(), fixtures.HashHeight3.String())
+}
+
+func TestHeadersOfStaleBranch(t *testing.T) {
+	// given
+	chain, _ := fixtures.LongestChainWithFork()
+	store := createTestStore(t, chain)
+
+	// when
+	tips, err := store.GetAllTips()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(tips), 2)
+	assert.Equal(t, tips[0].Hash.String(), fixtures.HashHeight4.String())
+	assert.Equal(t, tips[1].Hash.String(), fixtures.StaleHashHeight4.String())
+
+	headers, err := store.GetHeaderByHeightRange(2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, len(headers), 4)
+
+	stale, err := store.GetStaleHeadersBackFrom(fixtures.StaleHashHeight4.String())
+	assert.NoError(t, err)
+	assert.Equal(t, len(stale), 2)
+}
+
+func TestHeaderOfLongestChainReplacedOnItsHeight(t *testing.T) {
+	// given
+	chain, _ := fixtures.LongestChainWithFork()
+	store := createTestStore(t, chain)
+	ctx := context.Background()
+
+	// when
+	err := store.UpdateState(ctx, []string{fixtures.StaleHashHeight4.String()}, string(domains.LongestChain))
+
+	// then
+	assert.NoError(t, err)
+	header, _ := store.GetHeaderByHeight(ctx, 3, string(domains.LongestChain))
+	assert.Equal(t, header.Hash.String(), fixtures.HashHeight3.String())
+	headers, _ := store.GetHeaderByHeightRange(3, 3)
+	assert.Equal(t, len(headers), 2)
+
+	// when
+	err = store.UpdateState(ctx, []string{fixtures.HashHeight3.String()}, string(domains.Stale))
+
+	// then
+	assert.NoError(t, err)
+	header, _ = store.GetHeaderByHeight(ctx, 3, string(domains.LongestChain))
+	assert.Equal(t, header.Hash.String(), fixtures.StaleHashHeight4.String())
+	header, _ = store.GetHeaderByHeight(ctx, 3, string(domains.Stale))
+	assert.Equal(t, header.Hash.String(), fixtures.HashHeight3.String())
+}
+
+func TestMerkleRootsOfLongestChain(t *testing.T) {
+	// given
+	chain, _ := fixtures.LongestChainWithFork()
+	store := createTestStore(t, chain)
+	merkleRoot := chain[2].MerkleRoot.String()
+
+	// when
+	confirmations, err := store.GetMerkleRootsConfirmations([]domains.MerkleRootConfirmationRequestItem{
+		{MerkleRoot: merkleRoot, BlockHeight: 2},
+		{MerkleRoot: merkleRoot, BlockHeight: 3},
+		{MerkleRoot: chainhash.Hash{}.String(), BlockHeight: 2},
+		{MerkleRoot: "invalid", BlockHeight: 2},
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(confirmations), 4)
+	assert.Equal(t, confirmations[0].Hash.V.String(), fixtures.HashHeight2.String())
+	assert.Equal(t, confirmations[1].Hash.Valid, false)
+	assert.Equal(t, confirmations[2].Hash.Valid, false)
+	assert.Equal(t, confirmations[3].Hash.Valid, false)
+	assert.Equal(t, confirmations[3].MerkleRoot, "invalid")
+	assert.Equal(t, confirmations[0].TipHeight, 4)
+
+	// when
+	merkleRoots, err := store.GetMerkleRoots(2, chain[1].MerkleRoot.String())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(merkleRoots), 2)
+	assert.Equal(t, merkleRoots[0].MerkleRoot.String(), merkleRoot)
+	assert.Equal(t, merkleRoots[1].Height, 3)
+}
+
+func TestDeleteHeaders(t *testing.T) {
+	// given
+	chain, _ := fixtures.LongestChainWithFork()
+	store := createTestStore(t, chain)
+	ctx := context.Background()
+
+	// when
+	deleted, err := store.DeleteOlderThan(ctx, string(domains.Stale), time.Now().Add(-time.Hour))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, deleted, 0)
+
+	// when
+	deleted, err = store.DeleteOlderThan(ctx, string(domains.Stale), time.Now())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, deleted, 2)
+	count, _ := store.Count(ctx)
+	assert.Equal(t, count, 5)
+	tips, _ := store.GetAllTips()
+	assert.Equal(t, len(tips), 1)
+
+	// when
+	deleted, err = store.Delete(ctx, []string{fixtures.HashHeight4.String(), fixtures.StaleHashHeight4.String()})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, deleted, 1)
+	tip, _ := store.GetTip(ctx)
+	assert.Equal(t, tip.Hash.String(), fixtures.HashHeight3.String())
+	_, err = store.GetHeaderByHash(ctx, fixtures.HashHeight4.String())
+	assert.IsError(t, err, "header not found")
+}
+
+func TestDeleteHeadersOfChangedState(t *testing.T) {
+	// given
+	chain, _ := fixtures.LongestChainWithFork()
+	store := createTestStore(t, chain)
+	ctx := context.Background()
+	// unknown hashes are skipped
+	stale := []string{fixtures.HashOf("00").String(), fixtures.StaleHashHeight3.String(), fixtures.StaleHashHeight4.String()}
+	assert.NoError(t, store.UpdateState(ctx, stale, string(domains.Orphan)))
+
+	// when
+	deletedStale, err := store.DeleteOlderThan(ctx, string(domains.Stale), time.Now())
+	assert.NoError(t, err)
+	deletedOrphans, err := store.DeleteOlderThan(ctx, string(domains.Orphan), time.Now())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, deletedStale, 0)
+	assert.Equal(t, deletedOrphans, 2)
+	count, _ := store.Count(ctx)
+	assert.Equal(t, count, 5)
+}
+
+func TestStoreReopened(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "blockheaders.bolt")
+	chain, _ := fixtures.LongestChain()
+	store := openTestStore(t, path)
+	assert.NoError(t, store.CreateMultiple(context.Background(), toDbHeaders(chain)))
+	assert.NoError(t, store.Close())
+
+	// when
+	store = openTestStore(t, path)
+	defer func() { _ = store.Close() }()
+
+	// then
+	count, _ := store.Count(context.Background())
+	assert.Equal(t, count, 5)
+}
+
+func createTestStore(t *testing.T, chain []domains.BlockHeader) *HeadersKv {
+	store := openTestStore(t, filepath.Join(t.TempDir(), "blockheaders.bolt"))
+	t.Cleanup(func() { _ = store.Close() })
+	assert.NoError(t, store.CreateMultiple(context.Background(), toDbHeaders(chain)))
+	return store
+}
+
+func openTestStore(t *testing.T, path string) *HeadersKv {
+	db, err := bbolt.Open(path, 0o600, nil)
+	assert.NoError(t, err)
+	logger := zerolog.Nop()
+	store, err := NewHeadersKv(db, &logger)
+	assert.NoError(t, err)
+	return store
+}
+
+func toDbHeaders(chain []domains.BlockHeader) []dto.DbBlockHeader {
+	headers := make([]dto.DbBlockHeader, 0, len(chain))
+	for _, h := range chain {
+		headers = append(headers, dto.ToDbBlockHeader(h))
+	}
+	return headers
+}
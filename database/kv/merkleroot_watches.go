@@ -0,0 +1,136 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"go.etcd.io/bbolt"
+)
+
+// merkleRootWatchesBucket has watches under keys of the block height, merkle root and owner, sorted by block height.
+var merkleRootWatchesBucket = []byte("merkleroot_watches")
+
+// CreateMerkleRootWatch method will add new merkle root watch into db.
+func (h *HeadersKv) CreateMerkleRootWatch(_ context.Context, w *dto.DbMerkleRootWatch) error {
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		return insertRecord(tx, merkleRootWatchesBucket, merkleRootWatchKey(w.Owner, w.MerkleRoot, w.BlockHeight), w)
+	})
+	if err != nil {
+		return bhserrors.ErrCreateMerklerootWatch.Wrap(err)
+	}
+	return nil
+}
+
+// GetAllMerkleRootWatches method will return all merkle root watches from db.
+func (h *HeadersKv) GetAllMerkleRootWatches(_ context.Context) ([]*dto.DbMerkleRootWatch, error) {
+	var watches []*dto.DbMerkleRootWatch
+	err := h.db.View(func(tx *bbolt.Tx) (err error) {
+		watches, err = allRecords[dto.DbMerkleRootWatch](tx, merkleRootWatchesBucket)
+		return err
+	})
+	if err != nil {
+		return nil, bhserrors.ErrGetAllMerklerootWatches.Wrap(err)
+	}
+	return watches, nil
+}
+
+// GetMerkleRootWatches method will return merkle root watches of the owner from db.
+func (h *HeadersKv) GetMerkleRootWatches(ctx context.Context, owner string) ([]*dto.DbMerkleRootWatch, error) {
+	watches, err := h.GetAllMerkleRootWatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(watches, func(w *dto.DbMerkleRootWatch) bool { return w.Owner != owner }), nil
+}
+
+// GetMerkleRootWatchesByHeight method will return merkle root watches at block heights from the given range from db.
+func (h *HeadersKv) GetMerkleRootWatchesByHeight(_ context.Context, from, to int32) ([]*dto.DbMerkleRootWatch, error) {
+	watches := make([]*dto.DbMerkleRootWatch, 0)
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(merkleRootWatchesBucket).Cursor()
+		end := heightKey(to + 1)
+		for k, v := c.Seek(heightKey(from)); k != nil && bytes.Compare(k, end) < 0; k, v = c.Next() {
+			watch := new(dto.DbMerkleRootWatch)
+			if err := json.Unmarshal(v, watch); err != nil {
+				return err
+			}
+			watches = append(watches, watch)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, bhserrors.ErrGetAllMerklerootWatches.Wrap(err)
+	}
+	return watches, nil
+}
+
+// DeleteMerkleRootWatch method will delete merkle root watch of the owner from db.
+func (h *HeadersKv) DeleteMerkleRootWatch(_ context.Context, owner, merkleRoot string, blockHeight int32) error {
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		watches := tx.Bucket(merkleRootWatchesBucket)
+		key := merkleRootWatchKey(owner, merkleRoot, blockHeight)
+		if watches.Get(key) == nil {
+			return bhserrors.ErrMerklerootWatchNotFound
+		}
+		if err := watches.Delete(key); err != nil {
+			return bhserrors.ErrDeleteMerklerootWatch.Wrap(err)
+		}
+		return nil
+	})
+}
+
+// UpdateMerkleRootWatch method will update state of merkle root watch in db.
+func (h *HeadersKv) UpdateMerkleRootWatch(_ context.Context, w *dto.DbMerkleRootWatch) error {
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		key := merkleRootWatchKey(w.Owner, w.MerkleRoot, w.BlockHeight)
+		var watch dto.DbMerkleRootWatch
+		found, err := getRecord(tx, merkleRootWatchesBucket, key, &watch)
+		if err != nil || !found {
+			return err
+		}
+		watch.Confirmation = w.Confirmation
+		watch.ConfirmationsReached = w.ConfirmationsReached
+		return putRecord(tx, merkleRootWatchesBucket, key, &watch)
+	})
+	if err != nil {
+		return bhserrors.ErrUpdateMerklerootWatch.Wrap(err)
+	}
+	return nil
+}
+
+// TransferMerkleRootWatches method will change the owner of merkle root watches in db.
+func (h *HeadersKv) TransferMerkleRootWatches(_ context.Context, from, to string) error {
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		owned, err := allRecords[dto.DbMerkleRootWatch](tx, merkleRootWatchesBucket)
+		if err != nil {
+			return err
+		}
+		for _, w := range owned {
+			if w.Owner != from {
+				continue
+			}
+			if err := tx.Bucket(merkleRootWatchesBucket).Delete(merkleRootWatchKey(w.Owner, w.MerkleRoot, w.BlockHeight)); err != nil {
+				return err
+			}
+			w.Owner = to
+			if err := putRecord(tx, merkleRootWatchesBucket, merkleRootWatchKey(w.Owner, w.MerkleRoot, w.BlockHeight), w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return bhserrors.ErrUpdateMerklerootWatch.Wrap(err)
+	}
+	return nil
+}
+
+// merkleRootWatchKey keys watches of the same merkle root and height by their owners, the key of a watch without
+// an owner is the same as it was before watches had owners.
+func merkleRootWatchKey(owner, merkleRoot string, blockHeight int32) []byte {
+	return slices.Concat(heightKey(blockHeight), []byte(merkleRoot), []byte(owner))
+}
@@ -0,0 +1,51 @@
+package kv
+
+import (
+	"encoding/json"
+	"errors"
+
+	"go.etcd.io/bbolt"
+)
+
+// errRecordExists is returned when a record with the same key is already stored.
+var errRecordExists = errors.New("record already exists")
+
+// getRecord decodes the JSON record stored under the key, it returns false when there is no such record.
+func getRecord[T any](tx *bbolt.Tx, bucket, key []byte, record *T) (bool, error) {
+	v := tx.Bucket(bucket).Get(key)
+	if v == nil {
+		return false, nil
+	}
+	return true, json.Unmarshal(v, record)
+}
+
+// putRecord stores the record encoded as JSON under the key.
+func putRecord[T any](tx *bbolt.Tx, bucket, key []byte, record *T) error {
+	v, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(bucket).Put(key, v)
+}
+
+// insertRecord stores the record under the key unless the key is already used.
+func insertRecord[T any](tx *bbolt.Tx, bucket, key []byte, record *T) error {
+	if tx.Bucket(bucket).Get(key) != nil {
+		return errRecordExists
+	}
+	return putRecord(tx, bucket, key, record)
+}
+
+// allRecords decodes all records of the bucket in the order of their keys.
+func allRecords[T any](tx *bbolt.Tx, bucket []byte) ([]*T, error) {
+	records := make([]*T, 0)
+	err := tx.Bucket(bucket).ForEach(func(_, v []byte) error {
+		record := new(T)
+		if err := json.Unmarshal(v, record); err != nil {
+			return err
+		}
+		records = append(records, record)
+		return nil
+	})
+	return records, err
+}
@@ -0,0 +1,154 @@
+package kv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+)
+
+func TestTokens(t *testing.T) {
+	// given
+	store := createTestStore(t, nil)
+	ctx := context.Background()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.CreateToken(ctx, &dto.DbToken{Token: "hash-b", Hashed: true, CreatedAt: created.Add(time.Hour)}))
+	assert.NoError(t, store.CreateToken(ctx, &dto.DbToken{Token: "hash-a", Hashed: true, CreatedAt: created}))
+
+	// when
+	err := store.RotateToken(ctx, "hash-b", &dto.DbToken{Token: "hash-c", Hashed: true, CreatedAt: created.Add(2 * time.Hour)})
+
+	// then
+	assert.NoError(t, err)
+	_, err = store.GetTokenByHash(ctx, "hash-b")
+	assert.IsError(t, err, "token not found")
+	assert.NoError(t, store.UpdateTokenQuotaUsage(ctx, "hash-c", "2024-01-01", 3))
+	token, err := store.GetTokenByHash(ctx, "hash-c")
+	assert.NoError(t, err)
+	assert.Equal(t, token.QuotaUsed, 3)
+	tokens, _ := store.GetTokens(ctx)
+	assert.Equal(t, len(tokens), 2)
+	assert.Equal(t, tokens[0].Token, "hash-a")
+
+	// when
+	err = store.RotateToken(ctx, "hash-b", &dto.DbToken{Token: "hash-d", Hashed: true})
+
+	// then
+	assert.IsError(t, err, "token not found")
+}
+
+func TestWebhooks(t *testing.T) {
+	// given
+	store := createTestStore(t, nil)
+	ctx := context.Background()
+	assert.NoError(t, store.CreateWebhook(ctx, &dto.DbWebhook{URL: "http://localhost:8080/hook"}))
+
+	// when
+	err := store.CreateWebhook(ctx, &dto.DbWebhook{URL: "http://localhost:8080/hook"})
+
+	// then
+	assert.IsError(t, err, "failed to create a webhook")
+
+	// when
+	err = store.UpdateWebhook(ctx, "http://localhost:8080/hook", time.Now(), "500", 1, false)
+
+	// then
+	assert.NoError(t, err)
+	webhook, err := store.GetWebhookByURL(ctx, "http://localhost:8080/hook")
+	assert.NoError(t, err)
+	assert.Equal(t, webhook.ErrorsCount, 1)
+	assert.Equal(t, webhook.Active, false)
+	assert.NoError(t, store.DeleteWebhookByURL(ctx, "http://localhost:8080/hook"))
+	webhooks, _ := store.GetAllWebhooks(ctx)
+	assert.Equal(t, len(webhooks), 0)
+}
+
+func TestMerkleRootWatches(t *testing.T) {
+	// given
+	store := createTestStore(t, nil)
+	ctx := context.Background()
+	assert.NoError(t, store.CreateMerkleRootWatch(ctx, &dto.DbMerkleRootWatch{Owner: "token-a", MerkleRoot: "root", BlockHeight: 5}))
+	assert.NoError(t, store.CreateMerkleRootWatch(ctx, &dto.DbMerkleRootWatch{Owner: "token-b", MerkleRoot: "root", BlockHeight: 5}))
+	assert.NoError(t, store.CreateMerkleRootWatch(ctx, &dto.DbMerkleRootWatch{Owner: "token-a", MerkleRoot: "root", BlockHeight: 300}))
+
+	// when
+	watches, err := store.GetMerkleRootWatchesByHeight(ctx, 4, 299)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(watches), 2)
+	owned, _ := store.GetMerkleRootWatches(ctx, "token-a")
+	assert.Equal(t, len(owned), 2)
+
+	// when
+	err = store.DeleteMerkleRootWatch(ctx, "token-b", "root", 300)
+
+	// then
+	assert.IsError(t, err, "merkleroot watch not found")
+	assert.NoError(t, store.DeleteMerkleRootWatch(ctx, "token-b", "root", 5))
+	watches, _ = store.GetAllMerkleRootWatches(ctx)
+	assert.Equal(t, len(watches), 2)
+}
+
+func TestConfirmationWatches(t *testing.T) {
+	// given
+	store := createTestStore(t, nil)
+	ctx := context.Background()
+	hash := fixtures.HashHeight2.String()
+	assert.NoError(t, store.CreateConfirmationWatches(ctx, "token-a", []string{hash, fixtures.HashHeight3.String()}))
+	assert.NoError(t, store.CreateConfirmationWatches(ctx, "token-b", []string{hash}))
+
+	// when
+	err := store.DeleteConfirmationWatches(ctx, "token-b", []string{hash})
+
+	// then
+	assert.NoError(t, err)
+	watches, _ := store.GetConfirmationWatches(ctx)
+	assert.Equal(t, len(watches), 2)
+	assert.Equal(t, watches[0].Owner, "token-a")
+
+	// when
+	err = store.TransferConfirmationWatches(ctx, "token-a", "token-c")
+
+	// then
+	assert.NoError(t, err)
+	watches, _ = store.GetConfirmationWatches(ctx)
+	assert.Equal(t, len(watches), 2)
+	for _, w := range watches {
+		assert.Equal(t, w.Owner, "token-c")
+	}
+}
+
+func TestAuditRecords(t *testing.T) {
+	// given
+	store := createTestStore(t, nil)
+	ctx := context.Background()
+	for _, record := range []dto.DbAuditRecord{
+		{ID: "01", Actor: "admin", Action: "token.create"},
+		{ID: "02", Actor: "alice", Action: "webhook.create"},
+		{ID: "03", Actor: "admin", Action: "webhook.delete"},
+		{ID: "04", Actor: "admin", Action: "token.create"},
+	} {
+		assert.NoError(t, store.CreateAuditRecord(ctx, &record))
+	}
+
+	// when
+	records, err := store.GetAuditRecords(ctx, domains.AuditFilter{Actor: "admin", Before: "04", Limit: 10})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(records), 2)
+	assert.Equal(t, records[0].ID, "03")
+
+	// when
+	records, err = store.GetAuditRecords(ctx, domains.AuditFilter{Action: "token.create", Limit: 1})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(records), 1)
+	assert.Equal(t, records[0].ID, "04")
+}
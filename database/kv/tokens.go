@@ -0,0 +1,130 @@
+package kv
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"go.etcd.io/bbolt"
+)
+
+// tokensBucket maps the hash of the token to the token.
+var tokensBucket = []byte("tokens")
+
+// CreateToken method will add new record into db, a token with the same hash is left unchanged.
+func (h *HeadersKv) CreateToken(_ context.Context, token *dto.DbToken) error {
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		if tx.Bucket(tokensBucket).Get([]byte(token.Token)) != nil {
+			return nil
+		}
+		return putRecord(tx, tokensBucket, []byte(token.Token), token)
+	})
+	if err != nil {
+		return bhserrors.ErrCreateToken.Wrap(err)
+	}
+	return nil
+}
+
+// GetTokenByHash method will search and return token by hash of its value.
+func (h *HeadersKv) GetTokenByHash(_ context.Context, hash string) (*dto.DbToken, error) {
+	var token dto.DbToken
+	var found bool
+	err := h.db.View(func(tx *bbolt.Tx) (err error) {
+		found, err = getRecord(tx, tokensBucket, []byte(hash), &token)
+		return err
+	})
+	if err != nil {
+		return nil, bhserrors.ErrTokenNotFound.Wrap(err)
+	}
+	if !found || !token.Hashed {
+		return nil, bhserrors.ErrTokenNotFound
+	}
+	return &token, nil
+}
+
+// GetTokens method will return all tokens from db.
+func (h *HeadersKv) GetTokens(_ context.Context) ([]*dto.DbToken, error) {
+	var tokens []*dto.DbToken
+	err := h.db.View(func(tx *bbolt.Tx) (err error) {
+		tokens, err = allRecords[dto.DbToken](tx, tokensBucket)
+		return err
+	})
+	if err != nil {
+		return nil, bhserrors.ErrGetTokens.Wrap(err)
+	}
+	tokens = slices.DeleteFunc(tokens, func(t *dto.DbToken) bool { return !t.Hashed })
+	slices.SortStableFunc(tokens, func(a, b *dto.DbToken) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return tokens, nil
+}
+
+// UpdateTokenLastUsed method will set the time when the token was used for the last time.
+func (h *HeadersKv) UpdateTokenLastUsed(_ context.Context, hash string, lastUsedAt time.Time) error {
+	err := h.updateToken(hash, func(token *dto.DbToken) {
+		token.LastUsedAt = sql.NullTime{Time: lastUsedAt, Valid: true}
+	})
+	if err != nil {
+		return bhserrors.ErrUpdateToken.Wrap(err)
+	}
+	return nil
+}
+
+// UpdateTokenQuotaUsage method will save the number of requests made by the token on given day.
+func (h *HeadersKv) UpdateTokenQuotaUsage(_ context.Context, hash string, day string, used int64) error {
+	err := h.updateToken(hash, func(token *dto.DbToken) {
+		token.QuotaDay = day
+		token.QuotaUsed = used
+	})
+	if err != nil {
+		return bhserrors.ErrUpdateToken.Wrap(err)
+	}
+	return nil
+}
+
+// RotateToken method will replace the token with a new one in a single transaction.
+func (h *HeadersKv) RotateToken(_ context.Context, hash string, token *dto.DbToken) error {
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		tokens := tx.Bucket(tokensBucket)
+		if tokens.Get([]byte(hash)) == nil {
+			return bhserrors.ErrTokenNotFound
+		}
+		if err := tokens.Delete([]byte(hash)); err != nil {
+			return bhserrors.ErrRotateToken.Wrap(err)
+		}
+		if err := insertRecord(tx, tokensBucket, []byte(token.Token), token); err != nil {
+			return bhserrors.ErrRotateToken.Wrap(err)
+		}
+		return nil
+	})
+	return err
+}
+
+// DeleteToken method will delete token from db.
+func (h *HeadersKv) DeleteToken(_ context.Context, hash string) error {
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(tokensBucket).Delete([]byte(hash))
+	})
+	if err != nil {
+		return bhserrors.ErrDeleteToken.Wrap(err)
+	}
+	return nil
+}
+
+// HashPlaintextTokens method does nothing, tokens were stored only as hashes since the key-value database was added.
+func (h *HeadersKv) HashPlaintextTokens(_ context.Context) (int, error) {
+	return 0, nil
+}
+
+func (h *HeadersKv) updateToken(hash string, update func(*dto.DbToken)) error {
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		var token dto.DbToken
+		found, err := getRecord(tx, tokensBucket, []byte(hash), &token)
+		if err != nil || !found {
+			return err
+		}
+		update(&token)
+		return putRecord(tx, tokensBucket, []byte(hash), &token)
+	})
+}
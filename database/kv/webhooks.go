@@ -0,0 +1,93 @@
+package kv
+
+import (
+	"context"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/pkg/errors"
+	"go.etcd.io/bbolt"
+)
+
+// webhooksBucket maps the url of the webhook to the webhook.
+var webhooksBucket = []byte("webhooks")
+
+// CreateWebhook method will add new webhook into db.
+func (h *HeadersKv) CreateWebhook(_ context.Context, rWebhook *dto.DbWebhook) error {
+	// the same defaults as the columns of the webhooks table
+	webhook := *rWebhook
+	webhook.LastEmitStatus = ""
+	webhook.LastEmitTimestamp = time.Unix(0, 0).UTC()
+	webhook.ErrorsCount = 0
+	webhook.Active = true
+	if webhook.CreatedAt.IsZero() {
+		webhook.CreatedAt = time.Now().UTC()
+	}
+
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		return insertRecord(tx, webhooksBucket, []byte(webhook.URL), &webhook)
+	})
+	if err != nil {
+		return bhserrors.ErrCreateWebhook.Wrap(err)
+	}
+	return nil
+}
+
+// GetWebhookByURL method will search and return webhook by url.
+func (h *HeadersKv) GetWebhookByURL(_ context.Context, url string) (*dto.DbWebhook, error) {
+	var webhook dto.DbWebhook
+	var found bool
+	err := h.db.View(func(tx *bbolt.Tx) (err error) {
+		found, err = getRecord(tx, webhooksBucket, []byte(url), &webhook)
+		return err
+	})
+	if err != nil {
+		return nil, bhserrors.ErrWebhookNotFound.Wrap(err)
+	}
+	if !found {
+		return nil, bhserrors.ErrWebhookNotFound
+	}
+	return &webhook, nil
+}
+
+// GetAllWebhooks method will return all webhooks from db.
+func (h *HeadersKv) GetAllWebhooks(_ context.Context) ([]*dto.DbWebhook, error) {
+	var webhooks []*dto.DbWebhook
+	err := h.db.View(func(tx *bbolt.Tx) (err error) {
+		webhooks, err = allRecords[dto.DbWebhook](tx, webhooksBucket)
+		return err
+	})
+	if err != nil {
+		return nil, bhserrors.ErrGetAllWebhooks.Wrap(err)
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhookByURL method will delete webhook by url from db.
+func (h *HeadersKv) DeleteWebhookByURL(_ context.Context, url string) error {
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(webhooksBucket).Delete([]byte(url))
+	})
+	if err != nil {
+		return bhserrors.ErrDeleteWebhook.Wrap(err)
+	}
+	return nil
+}
+
+// UpdateWebhook method will update webhook in db.
+func (h *HeadersKv) UpdateWebhook(_ context.Context, url string, lastEmitTimestamp time.Time, lastEmitStatus string, errorsCount int, active bool) error {
+	err := h.db.Update(func(tx *bbolt.Tx) error {
+		var webhook dto.DbWebhook
+		found, err := getRecord(tx, webhooksBucket, []byte(url), &webhook)
+		if err != nil || !found {
+			return err
+		}
+		webhook.LastEmitTimestamp = lastEmitTimestamp
+		webhook.LastEmitStatus = lastEmitStatus
+		webhook.ErrorsCount = errorsCount
+		webhook.Active = active
+		return putRecord(tx, webhooksBucket, []byte(url), &webhook)
+	})
+	return errors.Wrapf(err, "failed to update webhook with url %s", url)
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/jmoiron/sqlx"
+)
+
+// postgresLockKey is the key of the advisory lock of the PostgreSQL database.
+const postgresLockKey int64 = 0x6268735f6c6f636b
+
+// ErrDatabaseLocked is returned when the database is locked by another process, e.g. the running service.
+var ErrDatabaseLocked = errors.New("database is locked by another process")
+
+// lockDatabase takes the exclusive lock of the database held by the running service, so headers aren't repaired
+// while it synchronizes them. SQLite databases are locked by a file next to the database file, PostgreSQL databases
+// by an advisory lock of a dedicated connection. Bolt databases are locked by bbolt when they are opened.
+// Returns the unlock function if successful.
+func lockDatabase(cfg *config.DbConfig, db *sqlx.DB) (func() error, error) {
+	switch cfg.Engine {
+	case config.DBSQLite:
+		return lockFile(cfg.SQLite.FilePath + ".lock")
+	case config.DBPostgreSQL:
+		return lockPostgres(db)
+	default:
+		return func() error { return nil }, nil
+	}
+}
+
+func lockPostgres(db *sqlx.DB) (func() error, error) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", postgresLockKey).Scan(&locked); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if !locked {
+		_ = conn.Close()
+		return nil, ErrDatabaseLocked
+	}
+
+	return func() error {
+		// the lock is released with the connection as well
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", postgresLockKey)
+		if closeErr := conn.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package database
+
+// lockFile doesn't lock the file on systems without flock, so the database isn't protected from other processes.
+func lockFile(string) (func() error, error) {
+	return func() error { return nil }, nil
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/rs/zerolog"
+)
+
+// embeddedMigrations are migrations of the schema built into the binary, used when no schema path is configured.
+// Migrations specific to a database engine are kept in its subdirectory and replace common migrations of the same name.
+//
+//go:embed migrations/*.sql migrations/sqlite/*.sql migrations/postgres/*.sql
+var embeddedMigrations embed.FS
+
+// MigrationStatus describes the version of the database schema.
+type MigrationStatus struct {
+	// Version is the last applied migration, 0 when none was applied.
+	Version uint
+	// Dirty is set when the last migration failed and the schema has to be fixed manually.
+	Dirty bool
+	// Latest is the last available migration.
+	Latest uint
+	// Pending are available migrations which are not applied yet.
+	Pending []uint
+}
+
+// Migrator applies and rolls back migrations of the database schema.
+type Migrator struct {
+	m      *migrate.Migrate
+	source source.Driver
+}
+
+// NewMigrator connects to the database and returns Migrator of its schema.
+func NewMigrator(cfg *config.DbConfig, log *zerolog.Logger) (*Migrator, error) {
+	adapter, err := newDbAdapter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = adapter.connect(cfg); err != nil {
+		return nil, err
+	}
+
+	return newMigrator(adapter, cfg, log)
+}
+
+func newMigrator(adapter dbAdapter, cfg *config.DbConfig, log *zerolog.Logger) (*Migrator, error) {
+	src, err := openMigrationSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := adapter.migrationDriver()
+	if err != nil {
+		return nil, err
+	}
+
+	// the source is opened again by migrate, so listing migrations doesn't move its position
+	m, err := migrate.NewWithInstance("migrations", src, adapter.driverName(), driver)
+	if err != nil {
+		return nil, err
+	}
+	migrateLog := log.With().Str("subservice", "migrations").Logger()
+	m.Log = &migrateLogger{log: &migrateLog}
+
+	listing, err := openMigrationSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{m: m, source: listing}, nil
+}
+
+func openMigrationSource(cfg *config.DbConfig) (source.Driver, error) {
+	var migrations fs.FS = os.DirFS(cfg.SchemaPath)
+	if cfg.SchemaPath == "" {
+		var err error
+		if migrations, err = fs.Sub(embeddedMigrations, "migrations"); err != nil {
+			return nil, err
+		}
+	}
+	return iofs.New(engineMigrations{fsys: migrations, engine: string(cfg.Engine)}, ".")
+}
+
+// engineMigrations are common migrations together with migrations of the database engine from its subdirectory.
+type engineMigrations struct {
+	fsys   fs.FS
+	engine string
+}
+
+func (m engineMigrations) Open(name string) (fs.File, error) {
+	if name != "." {
+		if f, err := m.fsys.Open(path.Join(m.engine, name)); err == nil {
+			return f, nil
+		}
+	}
+	return m.fsys.Open(name)
+}
+
+// ReadDir lists migrations in the directory and the subdirectory of the engine, migrations of the engine replace common ones.
+func (m engineMigrations) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(m.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	engineEntries, err := fs.ReadDir(m.fsys, path.Join(name, m.engine))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	merged := make(map[string]fs.DirEntry, len(entries)+len(engineEntries))
+	for _, e := range append(entries, engineEntries...) {
+		if !e.IsDir() {
+			merged[e.Name()] = e
+		}
+	}
+	return slices.SortedFunc(maps.Values(merged), func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	}), nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up() error {
+	return ignoreNoChange(m.m.Up())
+}
+
+// Down rolls back the last applied migration.
+func (m *Migrator) Down() error {
+	return ignoreNoChange(m.m.Steps(-1))
+}
+
+// To applies or rolls back migrations until the schema has the version, 0 rolls back all migrations.
+func (m *Migrator) To(version uint) error {
+	if version == 0 {
+		return ignoreNoChange(m.m.Down())
+	}
+	r, _, err := m.source.ReadUp(version)
+	if err != nil {
+		return fmt.Errorf("migration %d doesn't exist", version)
+	}
+	_ = r.Close()
+	return ignoreNoChange(m.m.Migrate(version))
+}
+
+// Status returns the version of the schema and migrations which are not applied yet.
+func (m *Migrator) Status() (*MigrationStatus, error) {
+	status := &MigrationStatus{}
+
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, err
+	}
+	status.Version = version
+	status.Dirty = dirty
+
+	available, err := m.availableVersions()
+	if err != nil {
+		return nil, err
+	}
+	status.Pending = make([]uint, 0)
+	for _, v := range available {
+		status.Latest = v
+		if v > status.Version {
+			status.Pending = append(status.Pending, v)
+		}
+	}
+	return status, nil
+}
+
+// Close closes the migrations and the connection to the database.
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	if err := m.source.Close(); err != nil && sourceErr == nil {
+		sourceErr = err
+	}
+	return errors.Join(sourceErr, dbErr)
+}
+
+func (m *Migrator) availableVersions() ([]uint, error) {
+	versions := make([]uint, 0)
+	v, err := m.source.First()
+	for err == nil {
+		versions = append(versions, v)
+		v, err = m.source.Next(v)
+	}
+	if !errors.Is(err, os.ErrNotExist) && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// migrateUp applies all pending migrations of the database.
+func migrateUp(adapter dbAdapter, cfg *config.DbConfig, log *zerolog.Logger) error {
+	m, err := newMigrator(adapter, cfg, log)
+	if err != nil {
+		return err
+	}
+	// migrator is not closed, because it would close the connection used by the service
+	return m.Up()
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// migrateLogger writes logs of migrate to zerolog.
+type migrateLogger struct {
+	log *zerolog.Logger
+}
+
+func (l *migrateLogger) Printf(format string, v ...interface{}) {
+	l.log.Info().Msg(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (l *migrateLogger) Verbose() bool {
+	return false
+}
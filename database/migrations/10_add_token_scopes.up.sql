@@ -0,0 +1 @@
+ALTER TABLE tokens ADD COLUMN scopes VARCHAR(255) DEFAULT '';
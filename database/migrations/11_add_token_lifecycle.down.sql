@@ -0,0 +1,7 @@
+-- hashes of tokens can't be turned back into tokens, so hashed tokens have to be created again after the rollback
+ALTER TABLE tokens DROP COLUMN hashed;
+ALTER TABLE tokens DROP COLUMN prefix;
+ALTER TABLE tokens DROP COLUMN name;
+ALTER TABLE tokens DROP COLUMN owner;
+ALTER TABLE tokens DROP COLUMN expires_at;
+ALTER TABLE tokens DROP COLUMN last_used_at;
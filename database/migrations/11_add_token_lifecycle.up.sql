@@ -0,0 +1,6 @@
+ALTER TABLE tokens ADD COLUMN hashed BOOLEAN DEFAULT FALSE;
+ALTER TABLE tokens ADD COLUMN prefix VARCHAR(16) DEFAULT '';
+ALTER TABLE tokens ADD COLUMN name VARCHAR(255) DEFAULT '';
+ALTER TABLE tokens ADD COLUMN owner VARCHAR(255) DEFAULT '';
+ALTER TABLE tokens ADD COLUMN expires_at TIMESTAMP NULL;
+ALTER TABLE tokens ADD COLUMN last_used_at TIMESTAMP NULL;
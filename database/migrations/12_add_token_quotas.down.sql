@@ -0,0 +1,3 @@
+ALTER TABLE tokens DROP COLUMN daily_quota;
+ALTER TABLE tokens DROP COLUMN quota_day;
+ALTER TABLE tokens DROP COLUMN quota_used;
@@ -0,0 +1,3 @@
+ALTER TABLE tokens ADD COLUMN daily_quota BIGINT DEFAULT 0;
+ALTER TABLE tokens ADD COLUMN quota_day VARCHAR(10) DEFAULT '';
+ALTER TABLE tokens ADD COLUMN quota_used BIGINT DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP INDEX idx_audit_log_actor;
+DROP INDEX idx_audit_log_action;
+DROP TABLE audit_log;
@@ -0,0 +1,12 @@
+CREATE TABLE audit_log(
+    id          VARCHAR(32) PRIMARY KEY
+    ,actor      VARCHAR(255) DEFAULT ''
+    ,action     VARCHAR(64) NOT NULL
+    ,target     TEXT DEFAULT ''
+    ,source_ip  VARCHAR(64) DEFAULT ''
+    ,result     VARCHAR(16) NOT NULL
+    ,error      TEXT DEFAULT ''
+    ,created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_audit_log_action ON audit_log (action, id);
+CREATE INDEX idx_audit_log_actor ON audit_log (actor, id);
@@ -0,0 +1 @@
+DROP TABLE confirmation_watches;
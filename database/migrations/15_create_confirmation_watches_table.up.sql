@@ -0,0 +1,4 @@
+CREATE TABLE confirmation_watches(
+    hash        VARCHAR(64) PRIMARY KEY
+    ,created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,16 @@
+CREATE TABLE merkleroot_watches_shared(
+    merkle_root             VARCHAR(64) NOT NULL
+    ,block_height           INTEGER NOT NULL
+    ,confirmation           VARCHAR(32) DEFAULT ''
+    ,confirmations_reached  BOOLEAN DEFAULT FALSE
+    ,created_at             TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    ,PRIMARY KEY (merkle_root, block_height)
+);
+INSERT INTO merkleroot_watches_shared(merkle_root, block_height, confirmation, confirmations_reached, created_at)
+SELECT w.merkle_root, w.block_height, w.confirmation, w.confirmations_reached, w.created_at
+FROM merkleroot_watches w
+WHERE w.owner = (
+    SELECT MIN(o.owner) FROM merkleroot_watches o WHERE o.merkle_root = w.merkle_root AND o.block_height = w.block_height
+);
+DROP TABLE merkleroot_watches;
+ALTER TABLE merkleroot_watches_shared RENAME TO merkleroot_watches;
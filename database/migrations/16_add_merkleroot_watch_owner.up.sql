@@ -0,0 +1,14 @@
+CREATE TABLE merkleroot_watches_owned(
+    owner                   VARCHAR(64) NOT NULL DEFAULT ''
+    ,merkle_root            VARCHAR(64) NOT NULL
+    ,block_height           INTEGER NOT NULL
+    ,confirmation           VARCHAR(32) DEFAULT ''
+    ,confirmations_reached  BOOLEAN DEFAULT FALSE
+    ,created_at             TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    ,PRIMARY KEY (owner, merkle_root, block_height)
+);
+INSERT INTO merkleroot_watches_owned(owner, merkle_root, block_height, confirmation, confirmations_reached, created_at)
+SELECT '', merkle_root, block_height, confirmation, confirmations_reached, created_at FROM merkleroot_watches;
+DROP TABLE merkleroot_watches;
+ALTER TABLE merkleroot_watches_owned RENAME TO merkleroot_watches;
+CREATE INDEX idx_merkleroot_watches_block_height ON merkleroot_watches (block_height);
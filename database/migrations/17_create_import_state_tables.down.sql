@@ -0,0 +1,2 @@
+DROP TABLE imported_snapshots;
+DROP TABLE dropped_indexes;
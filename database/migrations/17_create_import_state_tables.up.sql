@@ -0,0 +1,10 @@
+CREATE TABLE dropped_indexes(
+    name        VARCHAR(255) PRIMARY KEY
+    ,definition TEXT NOT NULL
+);
+CREATE TABLE imported_snapshots(
+    path            TEXT PRIMARY KEY
+    ,size           BIGINT NOT NULL
+    ,modified_at    BIGINT NOT NULL
+    ,last_height    INTEGER NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP INDEX idx_headers_state_created_at;
+ALTER TABLE headers DROP COLUMN created_at;
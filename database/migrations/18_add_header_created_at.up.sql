@@ -0,0 +1,3 @@
+ALTER TABLE headers ADD COLUMN created_at TIMESTAMP;
+UPDATE headers SET created_at = CURRENT_TIMESTAMP;
+CREATE INDEX idx_headers_state_created_at ON headers (header_state, created_at);
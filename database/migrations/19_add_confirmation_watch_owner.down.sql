@@ -0,0 +1,8 @@
+CREATE TABLE confirmation_watches_shared(
+    hash        VARCHAR(64) PRIMARY KEY
+    ,created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO confirmation_watches_shared(hash, created_at)
+SELECT hash, MIN(created_at) FROM confirmation_watches GROUP BY hash;
+DROP TABLE confirmation_watches;
+ALTER TABLE confirmation_watches_shared RENAME TO confirmation_watches;
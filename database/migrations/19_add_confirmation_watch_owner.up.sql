@@ -0,0 +1,10 @@
+CREATE TABLE confirmation_watches_owned(
+    owner       VARCHAR(64) NOT NULL DEFAULT ''
+    ,hash       VARCHAR(64) NOT NULL
+    ,created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    ,PRIMARY KEY (owner, hash)
+);
+INSERT INTO confirmation_watches_owned(owner, hash, created_at)
+SELECT '', hash, created_at FROM confirmation_watches;
+DROP TABLE confirmation_watches;
+ALTER TABLE confirmation_watches_owned RENAME TO confirmation_watches;
@@ -0,0 +1,7 @@
+alter table headers add column isorphan BOOLEAN;
+alter table headers add column isconfirmed BOOLEAN;
+
+update headers
+set isorphan = (header_state = 'ORPHAN');
+
+alter table headers drop column header_state;
@@ -0,0 +1 @@
+DROP INDEX idx_height_state_hash;
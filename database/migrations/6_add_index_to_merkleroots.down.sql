@@ -0,0 +1 @@
+DROP INDEX idx_merkle_root_hash;
@@ -0,0 +1,9 @@
+ALTER TABLE headers RENAME COLUMN previous_block TO previousblock;
+ALTER TABLE headers RENAME COLUMN cumulated_work TO cumulatedWork;
+
+ALTER TABLE webhooks RENAME COLUMN token_header TO tokenHeader;
+ALTER TABLE webhooks RENAME COLUMN created_at TO createdAt;
+ALTER TABLE webhooks RENAME COLUMN last_emit_status TO lastEmitStatus;
+ALTER TABLE webhooks RENAME COLUMN last_emit_timestamp TO lastEmitTimestamp;
+ALTER TABLE webhooks RENAME COLUMN errors_count TO errorsCount;
+ALTER TABLE webhooks RENAME COLUMN is_active TO active;
@@ -0,0 +1 @@
+DROP TABLE merkleroot_watches;
@@ -0,0 +1,8 @@
+CREATE TABLE merkleroot_watches(
+    merkle_root             VARCHAR(64) NOT NULL
+    ,block_height           INTEGER NOT NULL
+    ,confirmation           VARCHAR(32) DEFAULT ''
+    ,confirmations_reached  BOOLEAN DEFAULT FALSE
+    ,created_at             TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    ,PRIMARY KEY (merkle_root, block_height)
+);
@@ -0,0 +1 @@
+ALTER TABLE webhooks DROP COLUMN event_format;
@@ -0,0 +1 @@
+ALTER TABLE webhooks ADD COLUMN event_format VARCHAR(32) DEFAULT 'json';
@@ -0,0 +1,7 @@
+ALTER TABLE headers
+    ALTER COLUMN hash TYPE VARCHAR(255) USING encode(hash, 'hex'),
+    ALTER COLUMN merkleroot TYPE VARCHAR(255) USING encode(merkleroot, 'hex'),
+    ALTER COLUMN previous_block TYPE VARCHAR(255) USING encode(previous_block, 'hex'),
+    ALTER COLUMN bits TYPE VARCHAR(255) USING bits::VARCHAR,
+    ALTER COLUMN chainwork TYPE VARCHAR(255) USING chainwork::VARCHAR,
+    ALTER COLUMN cumulated_work TYPE VARCHAR(255) USING cumulated_work::VARCHAR;
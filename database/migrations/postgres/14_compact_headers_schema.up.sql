@@ -0,0 +1,7 @@
+ALTER TABLE headers
+    ALTER COLUMN hash TYPE BYTEA USING decode(hash, 'hex'),
+    ALTER COLUMN merkleroot TYPE BYTEA USING decode(merkleroot, 'hex'),
+    ALTER COLUMN previous_block TYPE BYTEA USING decode(previous_block, 'hex'),
+    ALTER COLUMN bits TYPE BIGINT USING bits::BIGINT,
+    ALTER COLUMN chainwork TYPE NUMERIC(78, 0) USING COALESCE(NULLIF(chainwork, ''), '0')::NUMERIC,
+    ALTER COLUMN cumulated_work TYPE NUMERIC(78, 0) USING COALESCE(NULLIF(cumulated_work, ''), '0')::NUMERIC;
@@ -0,0 +1,24 @@
+CREATE TABLE headers_text(
+    hash VARCHAR(255) PRIMARY KEY
+    ,height INTEGER
+    ,version INTEGER
+    ,merkleroot VARCHAR(255)
+    ,nonce BIGINT
+    ,bits VARCHAR(255)
+    ,chainwork VARCHAR(255)
+    ,previous_block VARCHAR(255)
+    ,timestamp      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    ,cumulated_work VARCHAR(255)
+    ,header_state VARCHAR(50) DEFAULT 'LONGEST_CHAIN'
+);
+
+INSERT INTO headers_text(hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, cumulated_work, header_state)
+SELECT lower(hex(hash)), height, version, lower(hex(merkleroot)), nonce, CAST(bits AS VARCHAR), work_to_text(chainwork),
+    lower(hex(previous_block)), timestamp, work_to_text(cumulated_work), header_state
+FROM headers;
+
+DROP TABLE headers;
+ALTER TABLE headers_text RENAME TO headers;
+
+CREATE INDEX idx_height_state_hash ON headers (height, header_state);
+CREATE INDEX idx_merkle_root_hash ON headers (merkleroot, header_state, hash);
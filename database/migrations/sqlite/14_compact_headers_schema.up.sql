@@ -0,0 +1,24 @@
+CREATE TABLE headers_compact(
+    hash BLOB PRIMARY KEY
+    ,height INTEGER
+    ,version INTEGER
+    ,merkleroot BLOB
+    ,nonce BIGINT
+    ,bits INTEGER
+    ,chainwork BLOB
+    ,previous_block BLOB
+    ,timestamp      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    ,cumulated_work BLOB
+    ,header_state VARCHAR(50) DEFAULT 'LONGEST_CHAIN'
+);
+
+INSERT INTO headers_compact(hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, cumulated_work, header_state)
+SELECT unhex(hash), height, version, unhex(merkleroot), nonce, CAST(bits AS INTEGER), work_to_bytes(COALESCE(NULLIF(chainwork, ''), '0')),
+    unhex(previous_block), timestamp, work_to_bytes(COALESCE(NULLIF(cumulated_work, ''), '0')), header_state
+FROM headers;
+
+DROP TABLE headers;
+ALTER TABLE headers_compact RENAME TO headers;
+
+CREATE INDEX idx_height_state_hash ON headers (height, header_state);
+CREATE INDEX idx_merkle_root_hash ON headers (merkleroot, header_state, hash);
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// postgresNumericType is the type name of NUMERIC columns reported by lib/pq.
+const postgresNumericType = "NUMERIC"
+
+func init() {
+	sql.Register(postgresDriverName, &postgresDriver{})
+	sqlx.BindDriver(postgresDriverName, sqlx.DOLLAR)
+}
+
+// postgresDriver is the PostgreSQL driver returning NUMERIC values, work of headers, as decimal strings,
+// so they are scanned differently from work encoded in bytes by the SQLite driver.
+type postgresDriver struct {
+	pq.Driver
+}
+
+// pqConn is the set of interfaces implemented by connections of lib/pq.
+type pqConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+func (d *postgresDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := conn.(pqConn)
+	if !ok {
+		_ = conn.Close()
+		return nil, errors.New("unsupported connection of the PostgreSQL driver")
+	}
+	return &postgresConn{c}, nil
+}
+
+type postgresConn struct {
+	pqConn
+}
+
+// QueryContext implements driver.QueryerContext returning NUMERIC values as decimal strings.
+func (c *postgresConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.pqConn.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return newNumericRows(rows), nil
+}
+
+// PrepareContext implements driver.ConnPrepareContext returning NUMERIC values of queries as decimal strings.
+func (c *postgresConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.pqConn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresStmt{stmt}, nil
+}
+
+// Prepare implements driver.Conn the same way as PrepareContext.
+func (c *postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+type postgresStmt struct {
+	driver.Stmt
+}
+
+// Query implements driver.Stmt returning NUMERIC values as decimal strings.
+func (s *postgresStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows, err := s.Stmt.Query(args) //nolint:staticcheck // the statement is used by the driver without context
+	if err != nil {
+		return nil, err
+	}
+	return newNumericRows(rows), nil
+}
+
+// numericRows converts NUMERIC values, returned as bytes by lib/pq, to strings.
+type numericRows struct {
+	driver.Rows
+	numeric []bool
+}
+
+func newNumericRows(rows driver.Rows) driver.Rows {
+	typed, ok := rows.(driver.RowsColumnTypeDatabaseTypeName)
+	if !ok {
+		return rows
+	}
+	numeric := make([]bool, len(rows.Columns()))
+	for i := range numeric {
+		numeric[i] = typed.ColumnTypeDatabaseTypeName(i) == postgresNumericType
+	}
+	return &numericRows{Rows: rows, numeric: numeric}
+}
+
+func (r *numericRows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(dest); err != nil {
+		return err
+	}
+	for i, v := range dest {
+		if b, ok := v.([]byte); ok && r.numeric[i] {
+			dest[i] = string(b)
+		}
+	}
+	return nil
+}
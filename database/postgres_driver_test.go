@@ -0,0 +1,51 @@
+package database
+
+import (
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+)
+
+func TestPostgresNumericValuesAreScannedAsDecimalWork(t *testing.T) {
+	// given
+	rows := newNumericRows(&testRows{
+		columns: []string{"hash", "cumulated_work"},
+		types:   []string{"BYTEA", postgresNumericType},
+		values:  [][]driver.Value{{[]byte{0x01, 0x02}, []byte("12885098499")}},
+	})
+	dest := make([]driver.Value, 2)
+
+	// when
+	err := rows.Next(dest)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, string(dest[0].([]byte)), string([]byte{0x01, 0x02}))
+	var work dto.DbWork
+	assert.NoError(t, work.Scan(dest[1]))
+	assert.Equal(t, work, "12885098499")
+}
+
+type testRows struct {
+	columns []string
+	types   []string
+	values  [][]driver.Value
+}
+
+func (r *testRows) Columns() []string { return r.columns }
+
+func (r *testRows) Close() error { return nil }
+
+func (r *testRows) ColumnTypeDatabaseTypeName(index int) string { return r.types[index] }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
@@ -1,20 +1,15 @@
 package database
 
 import (
-	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/database/sql"
-	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
-	"github.com/bitcoin-sv/block-headers-service/repository/dto"
-	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
-	// use blank import to use file source driver with the migrate package.
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 	"github.com/rs/zerolog"
@@ -24,7 +19,7 @@ type postgreSQLAdapter struct {
 	db *sqlx.DB
 }
 
-const postgresDriverName = "postgres"
+const postgresDriverName = "postgres_bhs"
 const postgresBatchSize = 500_000
 
 func (a *postgreSQLAdapter) connect(cfg *config.DbConfig) error {
@@ -41,25 +36,12 @@ func (a *postgreSQLAdapter) connect(cfg *config.DbConfig) error {
 	return nil
 }
 
-func (a *postgreSQLAdapter) doMigrations(cfg *config.DbConfig) error {
-	driver, err := postgres.WithInstance(a.db.DB, &postgres.Config{})
-	if err != nil {
-		return err
-	}
-
-	sourceURL := fmt.Sprintf("file://%s", cfg.SchemaPath)
-
-	m, err := migrate.NewWithDatabaseInstance(sourceURL, postgresDriverName, driver)
-	if err != nil {
-		return err
-	}
-
-	err = m.Up()
-	if err != nil && err != migrate.ErrNoChange {
-		return err
-	}
+func (a *postgreSQLAdapter) migrationDriver() (migratedb.Driver, error) {
+	return postgres.WithInstance(a.db.DB, &postgres.Config{})
+}
 
-	return nil
+func (a *postgreSQLAdapter) driverName() string {
+	return postgresDriverName
 }
 
 func (a *postgreSQLAdapter) getDBx() *sqlx.DB {
@@ -69,50 +51,29 @@ func (a *postgreSQLAdapter) getDBx() *sqlx.DB {
 	return a.db
 }
 
-func (a *postgreSQLAdapter) importHeaders(inputFile *os.File, _ *zerolog.Logger) (affectedRows int, err error) {
+func (a *postgreSQLAdapter) importHeaders(snapshot *snapshotReader, withoutIndexes bool, _ *zerolog.Logger) (affectedRows int, err error) {
 	// prepare db for bulk insterts
-	restoreIndexes, err := a.dropTableIndexes(sql.HeadersTableName)
-	if err != nil {
-		return
-	}
-	defer func() {
-		if rErr := restoreIndexes(); rErr != nil {
-			err = wrapIfNeeded(err, rErr, "Resoring indexes failed")
+	if withoutIndexes {
+		var restoreIndexes func() error
+		restoreIndexes, err = a.dropTableIndexes(sql.HeadersTableName)
+		if err != nil {
+			return
 		}
-	}()
-
-	if _, err = inputFile.Seek(0, 0); err != nil {
-		return
-	}
-
-	reader := csv.NewReader(inputFile)
-	_, err = reader.Read() // Skipping the column headers line
-	if err != nil {
-		return
+		defer func() {
+			if rErr := restoreIndexes(); rErr != nil {
+				err = wrapIfNeeded(err, rErr, "Resoring indexes failed")
+			}
+		}()
 	}
 
-	// insert headers
-	previousBlockHash := chainhash.Hash{}.String()
-	var cumulatedChainWork string
-	rowIndex := 0
-	guard := 0
-
 	for {
-		rowIndex, previousBlockHash, cumulatedChainWork, err = a.copyHeaders(reader, postgresBatchSize, previousBlockHash, cumulatedChainWork, rowIndex)
-		if err != nil {
-			affectedRows = rowIndex
+		var copied int
+		copied, err = a.copyHeaders(snapshot, postgresBatchSize)
+		affectedRows += copied
+		if err != nil || copied == 0 {
 			return
 		}
-
-		if guard == rowIndex {
-			break
-		}
-
-		guard = rowIndex
-		affectedRows = rowIndex
 	}
-
-	return
 }
 
 // dropTableIndexes removes indexes from a table. Returns the index restore function if successful.
@@ -121,12 +82,11 @@ func (a *postgreSQLAdapter) dropTableIndexes(table string) (func() error, error)
 	return dropIndexes(a.db, &q)
 }
 
-func (a *postgreSQLAdapter) copyHeaders(reader *csv.Reader, batchSize int, previousBlockHash string, cumulatedLastBlockChainWork string, rowIndex int) (lastRowIndex int, lastBlockHash string, cumulatedChainWork string, err error) {
-	lastRowIndex = rowIndex
-	lastBlockHash = previousBlockHash
+// copyHeaders copies a batch of headers in one transaction, so an interrupted import can be resumed after the last batch.
+func (a *postgreSQLAdapter) copyHeaders(snapshot *snapshotReader, batchSize int) (copied int, err error) {
 	copyQuery := pq.CopyIn(
 		sql.HeadersTableName,
-		/* columns */ "height", "hash", "version", "merkleroot", "timestamp", "bits", "nonce", "header_state", "chainwork", "cumulated_work", "previous_block",
+		/* columns */ "height", "hash", "version", "merkleroot", "timestamp", "bits", "nonce", "header_state", "chainwork", "cumulated_work", "previous_block", "created_at",
 	)
 
 	dbTx, err := a.db.Begin()
@@ -139,27 +99,16 @@ func (a *postgreSQLAdapter) copyHeaders(reader *csv.Reader, batchSize int, previ
 	if err != nil {
 		return
 	}
+	defer stmt.Close() //nolint
 
-	cumulatedChainWork = cumulatedLastBlockChainWork
+	createdAt := time.Now()
 	for i := 0; i < batchSize; i++ {
-		record, readErr := reader.Read()
-		if readErr != nil {
-			if errors.Is(readErr, io.EOF) {
-				break
-			}
-
-			err = fmt.Errorf("error reading record: %v", readErr)
-			_ = stmt.Close() //nolint
-			return
-		}
-
-		if len(record) == 0 {
+		b, readErr := snapshot.next()
+		if errors.Is(readErr, io.EOF) {
 			break
 		}
-		var b *dto.DbBlockHeader
-		b, err = prepareRecord(record, lastBlockHash, cumulatedChainWork, lastRowIndex)
-		if err != nil {
-			return
+		if readErr != nil {
+			return 0, readErr
 		}
 
 		_, execErr := stmt.Exec(
@@ -173,31 +122,27 @@ func (a *postgreSQLAdapter) copyHeaders(reader *csv.Reader, batchSize int, previ
 			b.State,
 			b.Chainwork,
 			b.CumulatedWork,
-			b.PreviousBlock)
+			b.PreviousBlock,
+			sql.WithCreatedAt(*b, createdAt).CreatedAt)
 
 		if execErr != nil {
-			err = fmt.Errorf("error preparing copy statement after %d row: %v", lastRowIndex, execErr)
-			return
+			return 0, fmt.Errorf("error preparing copy statement at height %d: %v", b.Height, execErr)
 		}
-
-		cumulatedChainWork = b.CumulatedWork
-		lastBlockHash = b.Hash
-		lastRowIndex++
+		copied++
 	}
 
-	_, err = stmt.Exec()
-	if err != nil {
-		if closeErr := stmt.Close(); closeErr != nil {
-			err = fmt.Errorf("execution err: %w. Smt close err: %w", err, closeErr)
-		}
-		return
+	if copied == 0 {
+		return 0, nil
 	}
 
-	err = stmt.Close()
-	if err != nil {
-		return
+	if _, err = stmt.Exec(); err != nil {
+		return 0, err
 	}
-
-	err = dbTx.Commit()
-	return
+	if err = stmt.Close(); err != nil {
+		return 0, err
+	}
+	if err = dbTx.Commit(); err != nil {
+		return 0, err
+	}
+	return copied, nil
 }
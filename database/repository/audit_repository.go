@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+)
+
+// AuditRepository provide access to repositories and implements methods for the audit log.
+type AuditRepository struct {
+	db AuditStore
+}
+
+// AddAuditRecord appends record to the audit log in db.
+func (r *AuditRepository) AddAuditRecord(record *domains.AuditRecord) error {
+	return r.db.CreateAuditRecord(context.Background(), dto.ToDbAuditRecord(record))
+}
+
+// GetAuditRecords returns records of the audit log matching the filter, newest first.
+func (r *AuditRepository) GetAuditRecords(filter domains.AuditFilter) ([]*domains.AuditRecord, error) {
+	dbRecords, err := r.db.GetAuditRecords(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*domains.AuditRecord, 0, len(dbRecords))
+	for _, r := range dbRecords {
+		records = append(records, r.ToAuditRecord())
+	}
+	return records, nil
+}
+
+// NewAuditRepository creates and returns AuditRepository instance.
+func NewAuditRepository(db AuditStore) *AuditRepository {
+	return &AuditRepository{db: db}
+}
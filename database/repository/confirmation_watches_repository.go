@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// ConfirmationWatchesRepository provide access to repositories and implements methods for headers watched for confirmations.
+type ConfirmationWatchesRepository struct {
+	db ConfirmationWatchesStore
+}
+
+// AddConfirmationWatches adds hashes of headers watched by the owner to db.
+func (r *ConfirmationWatchesRepository) AddConfirmationWatches(owner string, hashes []string) error {
+	return r.db.CreateConfirmationWatches(context.Background(), owner, hashes)
+}
+
+// DeleteConfirmationWatches deletes hashes of headers watched by the owner from db.
+func (r *ConfirmationWatchesRepository) DeleteConfirmationWatches(owner string, hashes []string) error {
+	return r.db.DeleteConfirmationWatches(context.Background(), owner, hashes)
+}
+
+// GetConfirmationWatches returns all watched headers with their owners from db.
+func (r *ConfirmationWatchesRepository) GetConfirmationWatches() ([]domains.ConfirmationWatch, error) {
+	watches, err := r.db.GetConfirmationWatches(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domains.ConfirmationWatch, 0, len(watches))
+	for _, w := range watches {
+		result = append(result, w.ToConfirmationWatch())
+	}
+	return result, nil
+}
+
+// TransferConfirmationWatches changes the owner of watched headers, when the token owning them is rotated.
+func (r *ConfirmationWatchesRepository) TransferConfirmationWatches(from, to string) error {
+	return r.db.TransferConfirmationWatches(context.Background(), from, to)
+}
+
+// NewConfirmationWatchesRepository creates and returns ConfirmationWatchesRepository instance.
+func NewConfirmationWatchesRepository(db ConfirmationWatchesStore) *ConfirmationWatchesRepository {
+	return &ConfirmationWatchesRepository{db: db}
+}
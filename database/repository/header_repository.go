@@ -2,8 +2,8 @@ package repository
 
 import (
 	"context"
+	"time"
 
-	"github.com/bitcoin-sv/block-headers-service/database/sql"
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 	dto "github.com/bitcoin-sv/block-headers-service/repository/dto"
@@ -11,7 +11,7 @@ import (
 
 // HeaderRepository provide access to repositories and implements methods for headers.
 type HeaderRepository struct {
-	db *sql.HeadersDb
+	db HeadersStore
 }
 
 // AddHeaderToDatabase adds new header to db.
@@ -47,6 +47,29 @@ func (r *HeaderRepository) UpdateState(hashes []chainhash.Hash, state domains.He
 	return err
 }
 
+// UpdateChain changes height, state and work of headers to the provided ones.
+func (r *HeaderRepository) UpdateChain(headers []domains.BlockHeader) error {
+	dbHeaders := make([]dto.DbBlockHeader, 0, len(headers))
+	for _, header := range headers {
+		dbHeaders = append(dbHeaders, dto.ToDbBlockHeader(header))
+	}
+	return r.db.UpdateChain(context.Background(), dbHeaders)
+}
+
+// DeleteHeaders removes headers with provided hashes and returns the number of removed ones.
+func (r *HeaderRepository) DeleteHeaders(hashes []chainhash.Hash) (int, error) {
+	hs := make([]string, len(hashes))
+	for i, h := range hashes {
+		hs[i] = h.String()
+	}
+	return r.db.Delete(context.Background(), hs)
+}
+
+// DeleteHeadersOlderThan removes headers in provided state stored before provided time and returns the number of removed ones.
+func (r *HeaderRepository) DeleteHeadersOlderThan(state domains.HeaderState, before time.Time) (int, error) {
+	return r.db.DeleteOlderThan(context.Background(), state.String(), before)
+}
+
 // GetHeaderByHeight returns header from db by given height.
 func (r *HeaderRepository) GetHeaderByHeight(height int32) (*domains.BlockHeader, error) {
 	bh, err := r.db.GetHeaderByHeight(context.Background(), height, string(domains.LongestChain))
@@ -129,7 +152,7 @@ func (r *HeaderRepository) GenesisExists() bool {
 }
 
 // NewHeadersRepository creates and returns HeaderRepository instance.
-func NewHeadersRepository(db *sql.HeadersDb) *HeaderRepository {
+func NewHeadersRepository(db HeadersStore) *HeaderRepository {
 	return &HeaderRepository{db: db}
 }
 
@@ -159,7 +182,7 @@ func (r *HeaderRepository) GetMerkleRoots(batchSize int, lastEvaluatedKey string
 		return merkleroots, nil
 	}
 
-	lastEvaluatedKeyFromDb := merklerootsFromDb[len(merklerootsFromDb)-1].MerkleRoot
+	lastEvaluatedKeyFromDb := merklerootsFromDb[len(merklerootsFromDb)-1].MerkleRoot.String()
 
 	if tip.MerkleRoot.String() != lastEvaluatedKeyFromDb {
 		merkleroots.Page.LastEvaluatedKey = lastEvaluatedKeyFromDb //indicating we still have some data available from db
@@ -167,7 +190,7 @@ func (r *HeaderRepository) GetMerkleRoots(batchSize int, lastEvaluatedKey string
 
 	for i, merkleroot := range merklerootsFromDb {
 		merkleroots.Content[i].BlockHeight = merkleroot.Height
-		merkleroots.Content[i].MerkleRoot = merkleroot.MerkleRoot
+		merkleroots.Content[i].MerkleRoot = merkleroot.MerkleRoot.String()
 	}
 
 	return merkleroots, nil
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+)
+
+// MerkleRootWatchesRepository provide access to repositories and implements methods for merkle root watches.
+type MerkleRootWatchesRepository struct {
+	db MerkleRootWatchesStore
+}
+
+// AddMerkleRootWatch adds new merkle root watch to db.
+func (r *MerkleRootWatchesRepository) AddMerkleRootWatch(w *domains.MerkleRootWatch) error {
+	return r.db.CreateMerkleRootWatch(context.Background(), dto.ToDbMerkleRootWatch(w))
+}
+
+// DeleteMerkleRootWatch deletes merkle root watch of the owner from db.
+func (r *MerkleRootWatchesRepository) DeleteMerkleRootWatch(owner, merkleRoot string, blockHeight int32) error {
+	return r.db.DeleteMerkleRootWatch(context.Background(), owner, merkleRoot, blockHeight)
+}
+
+// GetAllMerkleRootWatches returns all merkle root watches from db.
+func (r *MerkleRootWatchesRepository) GetAllMerkleRootWatches() ([]*domains.MerkleRootWatch, error) {
+	return toMerkleRootWatches(r.db.GetAllMerkleRootWatches(context.Background()))
+}
+
+// GetMerkleRootWatches returns merkle root watches of the owner from db.
+func (r *MerkleRootWatchesRepository) GetMerkleRootWatches(owner string) ([]*domains.MerkleRootWatch, error) {
+	return toMerkleRootWatches(r.db.GetMerkleRootWatches(context.Background(), owner))
+}
+
+// GetMerkleRootWatchesByHeight returns merkle root watches at block heights from the given range from db.
+func (r *MerkleRootWatchesRepository) GetMerkleRootWatchesByHeight(from, to int32) ([]*domains.MerkleRootWatch, error) {
+	return toMerkleRootWatches(r.db.GetMerkleRootWatchesByHeight(context.Background(), from, to))
+}
+
+// UpdateMerkleRootWatch updates state of merkle root watch in db.
+func (r *MerkleRootWatchesRepository) UpdateMerkleRootWatch(w *domains.MerkleRootWatch) error {
+	return r.db.UpdateMerkleRootWatch(context.Background(), dto.ToDbMerkleRootWatch(w))
+}
+
+// TransferMerkleRootWatches changes the owner of merkle root watches, when the token owning them is rotated.
+func (r *MerkleRootWatchesRepository) TransferMerkleRootWatches(from, to string) error {
+	return r.db.TransferMerkleRootWatches(context.Background(), from, to)
+}
+
+func toMerkleRootWatches(watches []*dto.DbMerkleRootWatch, err error) ([]*domains.MerkleRootWatch, error) {
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*domains.MerkleRootWatch, 0, len(watches))
+	for _, w := range watches {
+		result = append(result, w.ToMerkleRootWatch())
+	}
+	return result, nil
+}
+
+// NewMerkleRootWatchesRepository creates and returns MerkleRootWatchesRepository instance.
+func NewMerkleRootWatchesRepository(db MerkleRootWatchesStore) *MerkleRootWatchesRepository {
+	return &MerkleRootWatchesRepository{db: db}
+}
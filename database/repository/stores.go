@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+)
+
+// HeadersStore is the database of headers used by HeaderRepository.
+type HeadersStore interface {
+	Create(ctx context.Context, req dto.DbBlockHeader) error
+	CreateMultiple(ctx context.Context, headers []dto.DbBlockHeader) error
+	UpdateState(ctx context.Context, hashes []string, state string) error
+	UpdateChain(ctx context.Context, headers []dto.DbBlockHeader) error
+	Delete(ctx context.Context, hashes []string) (int, error)
+	DeleteOlderThan(ctx context.Context, state string, before time.Time) (int, error)
+	Height(ctx context.Context) (int, error)
+	Count(ctx context.Context) (int, error)
+	GetHeaderByHash(ctx context.Context, hash string) (*dto.DbBlockHeader, error)
+	GetHeaderByHeight(ctx context.Context, height int32, state string) (*dto.DbBlockHeader, error)
+	GetHeaderByHeightRange(from int, to int) ([]*dto.DbBlockHeader, error)
+	GetLongestChainHeadersFromHeight(height int32) ([]*dto.DbBlockHeader, error)
+	GetStaleHeadersBackFrom(hash string) ([]*dto.DbBlockHeader, error)
+	GenesisExists(ctx context.Context) bool
+	GetPreviousHeader(ctx context.Context, hash string) (*dto.DbBlockHeader, error)
+	GetTip(ctx context.Context) (*dto.DbBlockHeader, error)
+	GetAncestorOnHeight(hash string, height int32) (*dto.DbBlockHeader, error)
+	GetAllTips() ([]*dto.DbBlockHeader, error)
+	GetChainBetweenTwoHashes(low string, high string) ([]*dto.DbBlockHeader, error)
+	GetMerkleRootsConfirmations(request []domains.MerkleRootConfirmationRequestItem) ([]*dto.DbMerkleRootConfirmation, error)
+	GetHeadersStartHeight(hashTable []string) (int, error)
+	GetHeadersStopHeight(hashStop string) (int, error)
+	GetHeadersByHeightRange(from int, to int) ([]*dto.DbBlockHeader, error)
+	GetMerkleRoots(batchSize int, lastEvaluatedKey string) ([]*dto.DbMerkleRoot, error)
+}
+
+// TokensStore is the database of tokens used by TokenRepository.
+type TokensStore interface {
+	CreateToken(ctx context.Context, token *dto.DbToken) error
+	GetTokenByHash(ctx context.Context, hash string) (*dto.DbToken, error)
+	GetTokens(ctx context.Context) ([]*dto.DbToken, error)
+	UpdateTokenLastUsed(ctx context.Context, hash string, lastUsedAt time.Time) error
+	UpdateTokenQuotaUsage(ctx context.Context, hash string, day string, used int64) error
+	RotateToken(ctx context.Context, hash string, token *dto.DbToken) error
+	DeleteToken(ctx context.Context, hash string) error
+}
+
+// WebhooksStore is the database of webhooks used by WebhooksRepository.
+type WebhooksStore interface {
+	CreateWebhook(ctx context.Context, rWebhook *dto.DbWebhook) error
+	GetWebhookByURL(ctx context.Context, url string) (*dto.DbWebhook, error)
+	GetAllWebhooks(ctx context.Context) ([]*dto.DbWebhook, error)
+	DeleteWebhookByURL(ctx context.Context, url string) error
+	UpdateWebhook(ctx context.Context, url string, lastEmitTimestamp time.Time, lastEmitStatus string, errorsCount int, active bool) error
+}
+
+// MerkleRootWatchesStore is the database of merkle root watches used by MerkleRootWatchesRepository.
+type MerkleRootWatchesStore interface {
+	CreateMerkleRootWatch(ctx context.Context, w *dto.DbMerkleRootWatch) error
+	GetAllMerkleRootWatches(ctx context.Context) ([]*dto.DbMerkleRootWatch, error)
+	GetMerkleRootWatches(ctx context.Context, owner string) ([]*dto.DbMerkleRootWatch, error)
+	GetMerkleRootWatchesByHeight(ctx context.Context, from, to int32) ([]*dto.DbMerkleRootWatch, error)
+	DeleteMerkleRootWatch(ctx context.Context, owner, merkleRoot string, blockHeight int32) error
+	UpdateMerkleRootWatch(ctx context.Context, w *dto.DbMerkleRootWatch) error
+	TransferMerkleRootWatches(ctx context.Context, from, to string) error
+}
+
+// ConfirmationWatchesStore is the database of headers watched for confirmations used by ConfirmationWatchesRepository.
+type ConfirmationWatchesStore interface {
+	CreateConfirmationWatches(ctx context.Context, owner string, hashes []string) error
+	DeleteConfirmationWatches(ctx context.Context, owner string, hashes []string) error
+	GetConfirmationWatches(ctx context.Context) ([]*dto.DbConfirmationWatch, error)
+	TransferConfirmationWatches(ctx context.Context, from, to string) error
+}
+
+// AuditStore is the database of the audit log used by AuditRepository.
+type AuditStore interface {
+	CreateAuditRecord(ctx context.Context, record *dto.DbAuditRecord) error
+	GetAuditRecords(ctx context.Context, filter domains.AuditFilter) ([]*dto.DbAuditRecord, error)
+}
+
+// Store is a database engine holding all data of the service, implemented by sql.HeadersDb and kv.HeadersKv.
+type Store interface {
+	HeadersStore
+	TokensStore
+	WebhooksStore
+	MerkleRootWatchesStore
+	ConfirmationWatchesStore
+	AuditStore
+	Close() error
+}
+
+// NewRepositories creates all repositories backed by the store.
+func NewRepositories(store Store) *repository.Repositories {
+	return &repository.Repositories{
+		Headers:             NewHeadersRepository(store),
+		Tokens:              NewTokensRepository(store),
+		Webhooks:            NewWebhooksRepository(store),
+		MerkleRootWatches:   NewMerkleRootWatchesRepository(store),
+		ConfirmationWatches: NewConfirmationWatchesRepository(store),
+		Audit:               NewAuditRepository(store),
+	}
+}
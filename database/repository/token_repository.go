@@ -2,15 +2,15 @@ package repository
 
 import (
 	"context"
+	"time"
 
-	"github.com/bitcoin-sv/block-headers-service/database/sql"
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	dto "github.com/bitcoin-sv/block-headers-service/repository/dto"
 )
 
 // TokenRepository provide access to repositories and implements methods for token.
 type TokenRepository struct {
-	db *sql.HeadersDb
+	db TokensStore
 }
 
 // AddTokenToDatabase adds new token to db.
@@ -20,9 +20,9 @@ func (r *TokenRepository) AddTokenToDatabase(token *domains.Token) error {
 	return err
 }
 
-// GetTokenByValue returns token from db by given value.
-func (r *TokenRepository) GetTokenByValue(token string) (*domains.Token, error) {
-	t, err := r.db.GetTokenByValue(context.Background(), token)
+// GetTokenByHash returns token from db by hash of its value.
+func (r *TokenRepository) GetTokenByHash(hash string) (*domains.Token, error) {
+	t, err := r.db.GetTokenByHash(context.Background(), hash)
 	if err != nil {
 		return nil, err
 	}
@@ -30,13 +30,41 @@ func (r *TokenRepository) GetTokenByValue(token string) (*domains.Token, error)
 	return dbt, err
 }
 
-// DeleteToken deletes token from db.
-func (r *TokenRepository) DeleteToken(token string) error {
-	err := r.db.DeleteToken(context.Background(), token)
+// GetTokens returns all tokens from db.
+func (r *TokenRepository) GetTokens() ([]*domains.Token, error) {
+	dbTokens, err := r.db.GetTokens(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]*domains.Token, 0, len(dbTokens))
+	for _, t := range dbTokens {
+		tokens = append(tokens, t.ToToken())
+	}
+	return tokens, nil
+}
+
+// UpdateTokenLastUsed sets the time when the token was used for the last time.
+func (r *TokenRepository) UpdateTokenLastUsed(hash string, lastUsedAt time.Time) error {
+	return r.db.UpdateTokenLastUsed(context.Background(), hash, lastUsedAt)
+}
+
+// UpdateTokenQuotaUsage saves the number of requests made by the token on given day.
+func (r *TokenRepository) UpdateTokenQuotaUsage(hash string, day string, used int64) error {
+	return r.db.UpdateTokenQuotaUsage(context.Background(), hash, day, used)
+}
+
+// RotateToken replaces token with given hash with a new token.
+func (r *TokenRepository) RotateToken(hash string, token *domains.Token) error {
+	return r.db.RotateToken(context.Background(), hash, dto.ToDbToken(token))
+}
+
+// DeleteToken deletes token with given hash from db.
+func (r *TokenRepository) DeleteToken(hash string) error {
+	err := r.db.DeleteToken(context.Background(), hash)
 	return err
 }
 
 // NewTokensRepository creates and returns TokenRepository instance.
-func NewTokensRepository(db *sql.HeadersDb) *TokenRepository {
+func NewTokensRepository(db TokensStore) *TokenRepository {
 	return &TokenRepository{db: db}
 }
@@ -3,14 +3,13 @@ package repository
 import (
 	"context"
 
-	"github.com/bitcoin-sv/block-headers-service/database/sql"
 	"github.com/bitcoin-sv/block-headers-service/notification"
 	"github.com/bitcoin-sv/block-headers-service/repository/dto"
 )
 
 // WebhooksRepository provide access to repositories and implements methods for webhooks.
 type WebhooksRepository struct {
-	db *sql.HeadersDb
+	db WebhooksStore
 }
 
 // AddWebhookToDatabase adds new webhook to db.
@@ -57,6 +56,6 @@ func (r *WebhooksRepository) UpdateWebhook(w *notification.Webhook) error {
 }
 
 // NewWebhooksRepository creates and returns WebhooksRepository instance.
-func NewWebhooksRepository(db *sql.HeadersDb) *WebhooksRepository {
+func NewWebhooksRepository(db WebhooksStore) *WebhooksRepository {
 	return &WebhooksRepository{db: db}
 }
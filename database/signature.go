@@ -0,0 +1,157 @@
+package database
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+)
+
+// signatureFileExtension is added to the path of a signed file to get the path of its detached signature.
+const signatureFileExtension = ".sig"
+
+// errInvalidSignature is returned when the signature doesn't match the prepared database file.
+var errInvalidSignature = errors.New("signature of the prepared database file is invalid")
+
+// snapshotSignature is the detached ed25519 signature of the prepared database file,
+// verified against the hash of the data read through its reader.
+type snapshotSignature struct {
+	publicKey     ed25519.PublicKey
+	signature     []byte
+	signaturePath string
+	filePath      string
+	hash          hash.Hash
+}
+
+// readSnapshotSignature reads the signature of the prepared database file when the public key is configured,
+// otherwise it returns nil and the file is imported without verification.
+func readSnapshotSignature(cfg *config.DbConfig) (*snapshotSignature, error) {
+	if cfg.PreparedDbPublicKey == "" {
+		return nil, nil
+	}
+
+	publicKey, err := hex.DecodeString(cfg.PreparedDbPublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("public key of the prepared database file should be hex encoded ed25519 public key")
+	}
+
+	signaturePath := cfg.PreparedDbSignatureFilePath
+	if signaturePath == "" {
+		signaturePath = cfg.PreparedDbFilePath + signatureFileExtension
+	}
+	signature, err := readHexFile(signaturePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read signature of the prepared database file: %w", err)
+	}
+
+	return &snapshotSignature{
+		publicKey:     publicKey,
+		signature:     signature,
+		signaturePath: signaturePath,
+		filePath:      cfg.PreparedDbFilePath,
+		hash:          sha256.New(),
+	}, nil
+}
+
+// reader returns a reader of r which hashes everything read from r, so the signature is verified
+// on the same data which is imported.
+func (s *snapshotSignature) reader(r io.Reader) io.Reader {
+	if s == nil {
+		return r
+	}
+	return io.TeeReader(r, s.hash)
+}
+
+// verify reads the rest of r returned by reader and checks the signature against the hash of the read data.
+func (s *snapshotSignature) verify(r io.Reader) error {
+	if s == nil {
+		return nil
+	}
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+	if !ed25519.Verify(s.publicKey, s.hash.Sum(nil), s.signature) {
+		return fmt.Errorf("%w: %s doesn't match %s", errInvalidSignature, s.signaturePath, s.filePath)
+	}
+	return nil
+}
+
+// signFile writes the detached ed25519 signature of the hash of the file next to it.
+// The key file contains the hex encoded seed or private key.
+func signFile(path, keyFile string) (string, error) {
+	key, err := readHexFile(keyFile)
+	if err != nil {
+		return "", fmt.Errorf("cannot read signing key: %w", err)
+	}
+
+	var privateKey ed25519.PrivateKey
+	switch len(key) {
+	case ed25519.SeedSize:
+		privateKey = ed25519.NewKeyFromSeed(key)
+	case ed25519.PrivateKeySize:
+		privateKey = key
+	default:
+		return "", fmt.Errorf("signing key should have %d or %d bytes, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(key))
+	}
+
+	digest, err := fileHash(path)
+	if err != nil {
+		return "", err
+	}
+
+	signaturePath := path + signatureFileExtension
+	signature := hex.EncodeToString(ed25519.Sign(privateKey, digest))
+	return signaturePath, os.WriteFile(signaturePath, []byte(signature+"\n"), 0o644)
+}
+
+// GenerateSigningKey writes a new hex encoded ed25519 seed to the key file and returns the hex encoded public key,
+// which is configured to verify files signed with the key.
+func GenerateSigningKey(keyFile string) (string, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.OpenFile(keyFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	if _, err = f.WriteString(hex.EncodeToString(privateKey.Seed()) + "\n"); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(publicKey), nil
+}
+
+func fileHash(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint: all
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func readHexFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimSpace(string(content)))
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestImportOfSignedSnapshot(t *testing.T) {
+	// setup
+	snapshot, publicKey := signedTestSnapshot(t)
+	cfg, adapter, log := createImportTestDatabase(t, snapshot)
+
+	// given
+	cfg.Db.PreparedDbPublicKey = publicKey
+
+	// when
+	err := importTestHeaders(adapter, cfg, log)
+
+	// then
+	assert.NoError(t, err)
+	assertHeadersInDatabase(t, adapter, len(exportTestHashes))
+}
+
+func TestImportRefusesTamperedSnapshot(t *testing.T) {
+	// setup
+	snapshot, publicKey := signedTestSnapshot(t)
+	cfg, adapter, log := createImportTestDatabase(t, snapshot)
+
+	// given
+	cfg.Db.PreparedDbPublicKey = publicKey
+	f, err := os.OpenFile(snapshot, os.O_APPEND|os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	_, err = f.Write([]byte{0})
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	// when
+	err = importTestHeaders(adapter, cfg, log)
+
+	// then
+	assert.Equal(t, errors.Is(err, errInvalidSignature), true)
+	assertHeadersInDatabase(t, adapter, 0)
+}
+
+func TestImportRefusesSnapshotWithoutSignature(t *testing.T) {
+	// setup
+	snapshot, publicKey := signedTestSnapshot(t)
+	cfg, adapter, log := createImportTestDatabase(t, snapshot)
+
+	// given
+	cfg.Db.PreparedDbPublicKey = publicKey
+	cfg.Db.PreparedDbSignatureFilePath = filepath.Join(t.TempDir(), "missing.sig")
+
+	// when
+	err := importTestHeaders(adapter, cfg, log)
+
+	// then
+	if err == nil {
+		t.Fatalf("Expected snapshot without signature to be refused")
+	}
+	assertHeadersInDatabase(t, adapter, 0)
+}
+
+func TestImportRefusesSnapshotSignedWithOtherKey(t *testing.T) {
+	// setup
+	snapshot, _ := signedTestSnapshot(t)
+	otherPublicKey, err := GenerateSigningKey(filepath.Join(t.TempDir(), "other.key"))
+	assert.NoError(t, err)
+	cfg, adapter, log := createImportTestDatabase(t, snapshot)
+
+	// given
+	cfg.Db.PreparedDbPublicKey = otherPublicKey
+
+	// when
+	err = importTestHeaders(adapter, cfg, log)
+
+	// then
+	assert.Equal(t, errors.Is(err, errInvalidSignature), true)
+}
+
+// signedTestSnapshot exports the prepared database file with its signature and returns it with the public key.
+func signedTestSnapshot(t *testing.T) (string, string) {
+	keyFile := filepath.Join(t.TempDir(), "signing.key")
+	publicKey, err := GenerateSigningKey(keyFile)
+	assert.NoError(t, err)
+
+	cfg, log := createExportTestDatabase(t)
+	output := filepath.Join(t.TempDir(), "snapshot.csv.gz")
+	assert.NoError(t, ExportHeaders(cfg, ExportOptions{Format: ExportCSVGzip, OutputPath: output, SigningKeyFile: keyFile}, log))
+	return output, publicKey
+}
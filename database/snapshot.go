@@ -0,0 +1,224 @@
+package database
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+)
+
+// Optional columns of the prepared database file describing where the snapshot starts, so it doesn't have to
+// start at genesis. Only values of the first row are used.
+const (
+	snapshotHeightColumn        = "height"
+	snapshotPreviousBlockColumn = "previous_block"
+	snapshotCumulatedWorkColumn = "cumulated_work"
+)
+
+// snapshotColumns are the required columns of the prepared database file, in the order expected by prepareRecord.
+var snapshotColumns = []string{"version", "merkleroot", "nonce", "bits", "timestamp"}
+
+// errSnapshotDoesNotConnect is returned when headers of the snapshot can't be appended to headers in the database.
+var errSnapshotDoesNotConnect = errors.New("snapshot doesn't connect to the headers in the database")
+
+// snapshotReader reads headers from the prepared database file, calculating their hashes, heights and chainwork.
+type snapshotReader struct {
+	reader    *csv.Reader
+	columns   map[string]int
+	validator *headerValidator
+
+	// pending is the record read ahead to find the start of the snapshot.
+	pending []string
+
+	height        int32
+	previousBlock string
+	cumulatedWork string
+	// startWork is the cumulated work of the first header when the snapshot defines it.
+	startWork string
+}
+
+func newSnapshotReader(r io.Reader, validator *headerValidator) (*snapshotReader, error) {
+	s := &snapshotReader{
+		reader:        csv.NewReader(r),
+		columns:       make(map[string]int),
+		validator:     validator,
+		previousBlock: chainhash.Hash{}.String(),
+	}
+
+	names, err := s.reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read columns of the snapshot: %w", err)
+	}
+	for i, name := range names {
+		s.columns[name] = i
+	}
+	for _, name := range snapshotColumns {
+		if _, ok := s.columns[name]; !ok {
+			return nil, fmt.Errorf("snapshot has no %s column", name)
+		}
+	}
+
+	first, err := s.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading record: %w", err)
+	}
+	s.pending = first
+
+	if err := s.readStart(first); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// readStart reads the height, previous block and cumulated work of the first header when the snapshot has them.
+func (s *snapshotReader) readStart(first []string) error {
+	if value := s.value(first, snapshotHeightColumn); value != "" {
+		height, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || height < 0 {
+			return fmt.Errorf("invalid height %s of the first header of the snapshot", value)
+		}
+		s.height = int32(height)
+	}
+	if value := s.value(first, snapshotPreviousBlockColumn); value != "" {
+		if _, err := chainhash.NewHashFromStr(value); err != nil {
+			return fmt.Errorf("invalid previous block of the first header of the snapshot: %w", err)
+		}
+		s.previousBlock = value
+	}
+	if value := s.value(first, snapshotCumulatedWorkColumn); value != "" {
+		if _, ok := new(big.Int).SetString(value, 10); !ok {
+			return fmt.Errorf("invalid cumulated work %s of the first header of the snapshot", value)
+		}
+		s.startWork = value
+	}
+
+	if s.height > 0 && s.previousBlock == (chainhash.Hash{}).String() {
+		return fmt.Errorf("snapshot starting at height %d has no previous block of its first header", s.height)
+	}
+	return nil
+}
+
+// startHeight returns height of the next header of the snapshot.
+func (s *snapshotReader) startHeight() int32 {
+	return s.height
+}
+
+// startOnEmptyDatabase checks that the snapshot can be the first headers in the database,
+// so it has to start at genesis or at a checkpoint.
+func (s *snapshotReader) startOnEmptyDatabase() error {
+	if s.pending == nil {
+		return errors.New("snapshot has no headers")
+	}
+	if s.height == 0 {
+		return nil
+	}
+	if _, ok := s.validator.checkpoints[s.height]; !ok {
+		return fmt.Errorf("snapshot starts at height %d which is not a checkpoint", s.height)
+	}
+	if s.startWork == "" {
+		return fmt.Errorf("snapshot starting at checkpoint %d has no cumulated work of its first header", s.height)
+	}
+	return nil
+}
+
+// continueFrom skips headers of the snapshot up to the tip of the database, so the next header is the one after the tip.
+// It returns io.EOF when the snapshot has no headers after the tip and errSnapshotDoesNotConnect when the snapshot
+// starts after the tip or has different header on the height of the tip.
+func (s *snapshotReader) continueFrom(tip *dto.DbBlockHeader) error {
+	if s.height > tip.Height+1 {
+		return fmt.Errorf("%w: it starts at height %d, but the tip is at height %d", errSnapshotDoesNotConnect, s.height, tip.Height)
+	}
+
+	if s.height == tip.Height+1 {
+		if s.previousBlock != tip.Hash.String() {
+			return fmt.Errorf("%w: previous block of its first header %s is not the tip %s", errSnapshotDoesNotConnect, s.previousBlock, tip.Hash)
+		}
+	} else {
+		for s.height <= tip.Height {
+			header, err := s.next()
+			if err != nil {
+				return err
+			}
+			if header.Height == tip.Height && header.Hash.String() != tip.Hash.String() {
+				return fmt.Errorf("%w: it has header %s at the height of the tip %s", errSnapshotDoesNotConnect, header.Hash, tip.Hash)
+			}
+		}
+	}
+
+	// work of headers in the database is used, so the snapshot doesn't have to define it
+	s.startWork = ""
+	s.cumulatedWork = tip.CumulatedWork.String()
+	return nil
+}
+
+// next returns the next validated header of the snapshot, or io.EOF when all headers were read.
+func (s *snapshotReader) next() (*dto.DbBlockHeader, error) {
+	record, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	// snapshots which don't start at genesis have previous block of every header, otherwise it is the previous row
+	if value := s.value(record, snapshotPreviousBlockColumn); value != "" && value != s.previousBlock {
+		return nil, fmt.Errorf("invalid header at height %d: previous block %s is not the previous header %s", s.height, value, s.previousBlock)
+	}
+
+	values := make([]string, len(snapshotColumns))
+	for i, name := range snapshotColumns {
+		values[i] = record[s.columns[name]]
+	}
+
+	header, err := prepareRecord(values, s.previousBlock, s.cumulatedWork, int(s.height))
+	if err != nil {
+		return nil, err
+	}
+	if s.startWork != "" {
+		header.CumulatedWork = dto.DbWork(s.startWork)
+		s.startWork = ""
+	}
+
+	if err := s.validator.validate(header); err != nil {
+		return nil, err
+	}
+
+	s.height++
+	s.previousBlock = header.Hash.String()
+	s.cumulatedWork = header.CumulatedWork.String()
+	return header, nil
+}
+
+func (s *snapshotReader) read() ([]string, error) {
+	if s.pending != nil {
+		record := s.pending
+		s.pending = nil
+		return record, nil
+	}
+
+	record, err := s.reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("error reading record: %w", err)
+	}
+	if len(record) == 0 {
+		return nil, io.EOF
+	}
+	return record, nil
+}
+
+func (s *snapshotReader) value(record []string, column string) string {
+	i, ok := s.columns[column]
+	if !ok {
+		return ""
+	}
+	return record[i]
+}
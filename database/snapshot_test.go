@@ -0,0 +1,264 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/rs/zerolog"
+)
+
+func TestImportIntoEmptyDatabase(t *testing.T) {
+	// setup
+	snapshot := exportTestSnapshot(t, 0)
+	cfg, adapter, log := createImportTestDatabase(t, snapshot)
+
+	// when
+	err := importTestHeaders(adapter, cfg, log)
+
+	// then
+	assert.NoError(t, err)
+	assertHeadersInDatabase(t, adapter, len(exportTestHashes))
+}
+
+func TestImportAppendsToTip(t *testing.T) {
+	for _, from := range []int32{0, 1, 2} {
+		t.Run(fmt.Sprintf("snapshot from height %d", from), func(t *testing.T) {
+			// setup
+			snapshot := exportTestSnapshot(t, from)
+			cfg, adapter, log := createImportTestDatabase(t, snapshot)
+
+			// given
+			insertTestHeaders(t, adapter, 2)
+
+			// when
+			err := importTestHeaders(adapter, cfg, log)
+
+			// then
+			assert.NoError(t, err)
+			assertHeadersInDatabase(t, adapter, len(exportTestHashes))
+			tip, err := sql.NewHeadersDb(adapter.getDBx(), log).GetTip(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tip.Hash.String(), exportTestHashes[2])
+			assert.Equal(t, tip.CumulatedWork, "12885098499")
+		})
+	}
+}
+
+func TestImportSkipsSnapshotWhichDoesNotConnect(t *testing.T) {
+	// setup
+	snapshot := exportTestSnapshot(t, 2)
+	cfg, adapter, log := createImportTestDatabase(t, snapshot)
+
+	// given
+	insertTestHeaders(t, adapter, 1)
+
+	// when
+	err := importTestHeaders(adapter, cfg, log)
+
+	// then
+	assert.NoError(t, err)
+	assertHeadersInDatabase(t, adapter, 1)
+}
+
+func TestImportWhenDatabaseIsUpToDate(t *testing.T) {
+	// setup
+	snapshot := exportTestSnapshot(t, 0)
+	cfg, adapter, log := createImportTestDatabase(t, snapshot)
+
+	// given
+	insertTestHeaders(t, adapter, len(exportTestHashes))
+
+	// when
+	err := importTestHeaders(adapter, cfg, log)
+
+	// then
+	assert.NoError(t, err)
+	assertHeadersInDatabase(t, adapter, len(exportTestHashes))
+}
+
+func TestImportSkipsSnapshotImportedBefore(t *testing.T) {
+	// setup
+	snapshot, publicKey := signedTestSnapshot(t)
+	cfg, adapter, log := createImportTestDatabase(t, snapshot)
+	cfg.Db.PreparedDbPublicKey = publicKey
+	assert.NoError(t, importTestHeaders(adapter, cfg, log))
+
+	// given
+	assert.NoError(t, os.Remove(snapshot+signatureFileExtension))
+
+	// when
+	err := importTestHeaders(adapter, cfg, log)
+
+	// then
+	assert.NoError(t, err)
+	assertHeadersInDatabase(t, adapter, len(exportTestHashes))
+
+	// when
+	modified := time.Now().Add(time.Hour)
+	assert.NoError(t, os.Chtimes(snapshot, modified, modified))
+	err = importTestHeaders(adapter, cfg, log)
+
+	// then
+	if err == nil {
+		t.Fatalf("Expected changed snapshot to be verified again")
+	}
+}
+
+func TestImportRestoresIndexesOfInterruptedImport(t *testing.T) {
+	// setup
+	snapshot := exportTestSnapshot(t, 0)
+	cfg, adapter, log := createImportTestDatabase(t, snapshot)
+
+	// given
+	_, err := adapter.(*sqLiteAdapter).dropTableIndexes(sql.HeadersTableName)
+	assert.NoError(t, err)
+	insertTestHeaders(t, adapter, len(exportTestHashes))
+
+	// when
+	err = importTestHeaders(adapter, cfg, log)
+
+	// then
+	assert.NoError(t, err)
+	var indexes int
+	assert.NoError(t, adapter.getDBx().Get(&indexes, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'idx_height_state_hash'"))
+	assert.Equal(t, indexes, 1)
+	var dropped int
+	assert.NoError(t, adapter.getDBx().Get(&dropped, "SELECT COUNT(*) FROM dropped_indexes"))
+	assert.Equal(t, dropped, 0)
+}
+
+func TestSnapshotStartingAtCheckpoint(t *testing.T) {
+	// setup
+	checkpoint := chaincfg.Checkpoint{Height: 1, Hash: mustHash(t, exportTestHashes[1])}
+	data := "version,merkleroot,nonce,bits,timestamp,height,previous_block,cumulated_work\n" +
+		strings.Join(exportTestRecords[1], ",") + ",1," + exportTestHashes[0] + ",8590065666\n" +
+		strings.Join(exportTestRecords[2], ",") + ",2," + exportTestHashes[1] + ",12885098499\n"
+
+	// when
+	snapshot, err := newSnapshotReader(strings.NewReader(data), newHeaderValidator(chaincfg.MainNetParams.PowLimit, []chaincfg.Checkpoint{checkpoint}))
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, snapshot.startOnEmptyDatabase())
+	first, err := snapshot.next()
+	assert.NoError(t, err)
+	assert.Equal(t, first.Hash.String(), exportTestHashes[1])
+	assert.Equal(t, first.CumulatedWork, "8590065666")
+	second, err := snapshot.next()
+	assert.NoError(t, err)
+	assert.Equal(t, second.Height, 2)
+	assert.Equal(t, second.CumulatedWork, "12885098499")
+	_, err = snapshot.next()
+	assert.Equal(t, errors.Is(err, io.EOF), true)
+}
+
+func TestInvalidSnapshots(t *testing.T) {
+	checkpoints := []chaincfg.Checkpoint{{Height: 1, Hash: mustHash(t, exportTestHashes[0])}}
+	columns := "version,merkleroot,nonce,bits,timestamp"
+	withStart := columns + ",height,previous_block,cumulated_work\n"
+
+	tests := map[string]string{
+		"missing column":          "version,merkleroot,nonce,bits\n1,2,3,4\n",
+		"not at checkpoint":       withStart + strings.Join(exportTestRecords[2], ",") + ",2," + exportTestHashes[1] + ",12885098499\n",
+		"without previous block":  withStart + strings.Join(exportTestRecords[1], ",") + ",1,,8590065666\n",
+		"different checkpoint":    columns + "\n" + strings.Join(exportTestRecords[0], ",") + "\n" + strings.Join(exportTestRecords[1], ",") + "\n",
+		"without cumulated work":  withStart + strings.Join(exportTestRecords[1], ",") + ",1," + exportTestHashes[0] + ",\n",
+		"without headers":         columns + "\n",
+		"invalid height of start": withStart + strings.Join(exportTestRecords[1], ",") + ",-1," + exportTestHashes[0] + ",1\n",
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when
+			err := readSnapshot(data, checkpoints)
+
+			// then
+			if err == nil {
+				t.Fatalf("Expected snapshot to be rejected")
+			}
+		})
+	}
+}
+
+func readSnapshot(data string, checkpoints []chaincfg.Checkpoint) error {
+	snapshot, err := newSnapshotReader(strings.NewReader(data), newHeaderValidator(chaincfg.MainNetParams.PowLimit, checkpoints))
+	if err != nil {
+		return err
+	}
+	if err := snapshot.startOnEmptyDatabase(); err != nil {
+		return err
+	}
+	for {
+		if _, err := snapshot.next(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// exportTestSnapshot returns path of the prepared database file with test headers from the height.
+func exportTestSnapshot(t *testing.T, from int32) string {
+	cfg, log := createExportTestDatabase(t)
+	output := filepath.Join(t.TempDir(), "snapshot.csv.gz")
+	assert.NoError(t, ExportHeaders(cfg, ExportOptions{Format: ExportCSVGzip, OutputPath: output, FromHeight: from}, log))
+	return output
+}
+
+func createImportTestDatabase(t *testing.T, snapshot string) (*config.AppConfig, dbAdapter, *zerolog.Logger) {
+	logger := zerolog.Nop()
+	cfg := config.GetDefaultAppConfig()
+	cfg.Db.SQLite.FilePath = filepath.Join(t.TempDir(), "blockheaders.db")
+	cfg.Db.PreparedDbFilePath = snapshot
+
+	adapter, err := newDbAdapter(cfg.Db)
+	assert.NoError(t, err)
+	assert.NoError(t, adapter.connect(cfg.Db))
+	t.Cleanup(func() { _ = adapter.getDBx().Close() })
+	assert.NoError(t, migrateUp(adapter, cfg.Db, &logger))
+	return cfg, adapter, &logger
+}
+
+// importTestHeaders imports the prepared database file into the SQL database like Init does.
+func importTestHeaders(adapter dbAdapter, cfg *config.AppConfig, log *zerolog.Logger) error {
+	return importHeaders(sql.NewHeadersDb(adapter.getDBx(), log), &sqlImport{adapter: adapter}, cfg, log)
+}
+
+// insertTestHeaders inserts the first test headers, as if they were imported or synchronized before.
+func insertTestHeaders(t *testing.T, adapter dbAdapter, count int) {
+	logger := zerolog.Nop()
+	snapshot, err := newSnapshotReader(strings.NewReader("version,merkleroot,nonce,bits,timestamp\n"), newHeaderValidator(chaincfg.MainNetParams.PowLimit, nil))
+	assert.NoError(t, err)
+	for i := 0; i < count; i++ {
+		snapshot.pending = exportTestRecords[i]
+		header, err := snapshot.next()
+		assert.NoError(t, err)
+		assert.NoError(t, sql.NewHeadersDb(adapter.getDBx(), &logger).Create(context.Background(), *header))
+	}
+}
+
+func assertHeadersInDatabase(t *testing.T, adapter dbAdapter, count int) {
+	logger := zerolog.Nop()
+	dbCount, err := sql.NewHeadersDb(adapter.getDBx(), &logger).Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, dbCount, count)
+}
+
+func mustHash(t *testing.T, hash string) *chainhash.Hash {
+	h, err := chainhash.NewHashFromStr(hash)
+	assert.NoError(t, err)
+	return h
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+)
+
+const (
+	// medianTimeHeaders is the number of previous headers used to calculate the median time a header has to be after.
+	medianTimeHeaders = 11
+	// maxTimeOffset is how far in the future timestamp of a header can be.
+	maxTimeOffset = 2 * time.Hour
+)
+
+// headerValidator checks headers of the snapshot in the order of the chain, the same way headers received from peers are.
+type headerValidator struct {
+	powLimit    *big.Int
+	checkpoints map[int32]*chainhash.Hash
+	maxTime     time.Time
+
+	// timestamps of the last validated headers, the oldest first.
+	timestamps []int64
+}
+
+func newHeaderValidator(powLimit *big.Int, checkpoints []chaincfg.Checkpoint) *headerValidator {
+	v := &headerValidator{
+		powLimit:    powLimit,
+		checkpoints: make(map[int32]*chainhash.Hash, len(checkpoints)),
+		maxTime:     time.Now().Add(maxTimeOffset),
+		timestamps:  make([]int64, 0, medianTimeHeaders),
+	}
+	for _, c := range checkpoints {
+		v.checkpoints[c.Height] = c.Hash
+	}
+	return v
+}
+
+// validate checks the proof of work, the timestamp and the checkpoint of the header, which has to follow the previously validated one.
+func (v *headerValidator) validate(header *dto.DbBlockHeader) error {
+	if err := v.validateProofOfWork(header); err != nil {
+		return fmt.Errorf("invalid header at height %d: %w", header.Height, err)
+	}
+	if err := v.validateTimestamp(header); err != nil {
+		return fmt.Errorf("invalid header at height %d: %w", header.Height, err)
+	}
+	if checkpoint, ok := v.checkpoints[header.Height]; ok && checkpoint.String() != header.Hash.String() {
+		return fmt.Errorf("invalid header at height %d: header %s doesn't match checkpoint %s", header.Height, header.Hash, checkpoint)
+	}
+
+	if len(v.timestamps) == medianTimeHeaders {
+		v.timestamps = v.timestamps[1:]
+	}
+	v.timestamps = append(v.timestamps, header.Timestamp.Unix())
+	return nil
+}
+
+func (v *headerValidator) validateProofOfWork(header *dto.DbBlockHeader) error {
+	target := domains.CompactToBig(header.Bits)
+	if target.Sign() <= 0 {
+		return fmt.Errorf("target difficulty %064x is too low", target)
+	}
+	if target.Cmp(v.powLimit) > 0 {
+		return fmt.Errorf("target difficulty %064x is higher than max of %064x", target, v.powLimit)
+	}
+
+	hash, err := chainhash.NewHashFromStr(header.Hash.String())
+	if err != nil {
+		return err
+	}
+	if hashToBig(hash).Cmp(target) > 0 {
+		return fmt.Errorf("hash %s is higher than target difficulty %064x", header.Hash, target)
+	}
+	return nil
+}
+
+// validateTimestamp checks that the header isn't too far in the future and is after the median time of previous headers.
+// The median time is checked only when all previous headers it depends on were validated, which isn't the case
+// for the first headers of a snapshot that doesn't start at genesis.
+func (v *headerValidator) validateTimestamp(header *dto.DbBlockHeader) error {
+	if header.Timestamp.After(v.maxTime) {
+		return fmt.Errorf("timestamp %d is too far in the future", header.Timestamp.Unix())
+	}
+
+	if len(v.timestamps) == 0 || len(v.timestamps) < min(medianTimeHeaders, int(header.Height)) {
+		return nil
+	}
+	sorted := slices.Clone(v.timestamps)
+	slices.Sort(sorted)
+	if median := sorted[len(sorted)/2]; header.Timestamp.Unix() <= median {
+		return fmt.Errorf("timestamp %d is not after median time %d of previous headers", header.Timestamp.Unix(), median)
+	}
+	return nil
+}
+
+// hashToBig converts the hash, which is stored in little-endian order, to a number to compare it with the target difficulty.
+func hashToBig(hash *chainhash.Hash) *big.Int {
+	buf := *hash
+	slices.Reverse(buf[:])
+	return new(big.Int).SetBytes(buf[:])
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	gz "github.com/klauspost/compress/gzip"
+)
+
+func TestSnapshotReportsFirstInvalidHeight(t *testing.T) {
+	columns := "version,merkleroot,nonce,bits,timestamp"
+	withStart := columns + ",height,previous_block,cumulated_work\n"
+	tamperedNonce := []string{exportTestRecords[2][0], exportTestRecords[2][1], "1", exportTestRecords[2][3], exportTestRecords[2][4]}
+	easierBits := []string{exportTestRecords[1][0], exportTestRecords[1][1], exportTestRecords[1][2], "545259519", exportTestRecords[1][4]}
+
+	tests := map[string]struct {
+		data   string
+		height string
+	}{
+		"proof of work": {
+			data:   columns + "\n" + strings.Join(exportTestRecords[0], ",") + "\n" + strings.Join(exportTestRecords[1], ",") + "\n" + strings.Join(tamperedNonce, ",") + "\n",
+			height: "height 2",
+		},
+		"target above limit": {
+			data:   columns + "\n" + strings.Join(exportTestRecords[0], ",") + "\n" + strings.Join(easierBits, ",") + "\n",
+			height: "height 1",
+		},
+		"previous block": {
+			data: withStart +
+				strings.Join(exportTestRecords[1], ",") + ",1," + exportTestHashes[0] + ",8590065666\n" +
+				strings.Join(exportTestRecords[2], ",") + ",2," + exportTestHashes[0] + ",12885098499\n",
+			height: "height 2",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when
+			err := readSnapshot(tc.data, []chaincfg.Checkpoint{{Height: 1, Hash: mustHash(t, exportTestHashes[1])}})
+
+			// then
+			if err == nil || !strings.Contains(err.Error(), "invalid header at "+tc.height) {
+				t.Fatalf("Expected invalid header at %s, got %v", tc.height, err)
+			}
+		})
+	}
+}
+
+func TestHeaderTimestampValidation(t *testing.T) {
+	genesis := time.Unix(1231006505, 0)
+
+	tests := map[string]struct {
+		timestamps []time.Time
+		valid      bool
+	}{
+		"after previous headers": {
+			timestamps: []time.Time{genesis, genesis.Add(time.Minute), genesis.Add(2 * time.Minute)},
+			valid:      true,
+		},
+		"not after median time": {
+			timestamps: []time.Time{genesis, genesis.Add(time.Hour), genesis.Add(2 * time.Hour), genesis.Add(time.Minute)},
+			valid:      false,
+		},
+		"too far in future": {
+			timestamps: []time.Time{genesis, time.Now().Add(3 * time.Hour)},
+			valid:      false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			validator := newHeaderValidator(chaincfg.MainNetParams.PowLimit, nil)
+
+			// when
+			var err error
+			for height, timestamp := range tc.timestamps {
+				header := &dto.DbBlockHeader{Height: int32(height), Timestamp: timestamp}
+				if err = validator.validateTimestamp(header); err != nil {
+					break
+				}
+				validator.timestamps = append(validator.timestamps, timestamp.Unix())
+			}
+
+			// then
+			assert.Equal(t, err == nil, tc.valid)
+		})
+	}
+}
+
+func TestMedianTimeIsNotCheckedWithoutPreviousHeaders(t *testing.T) {
+	// given
+	validator := newHeaderValidator(chaincfg.MainNetParams.PowLimit, nil)
+	validator.timestamps = append(validator.timestamps, time.Unix(1231469665, 0).Unix())
+
+	// when
+	err := validator.validateTimestamp(&dto.DbBlockHeader{Height: 100, Timestamp: time.Unix(1231006505, 0)})
+
+	// then
+	assert.NoError(t, err)
+}
+
+func TestImportRefusesInvalidSnapshot(t *testing.T) {
+	// setup
+	tamperedNonce := []string{exportTestRecords[2][0], exportTestRecords[2][1], "1", exportTestRecords[2][3], exportTestRecords[2][4]}
+	snapshot := writeTestSnapshot(t, "version,merkleroot,nonce,bits,timestamp\n"+
+		strings.Join(exportTestRecords[0], ",")+"\n"+
+		strings.Join(exportTestRecords[1], ",")+"\n"+
+		strings.Join(tamperedNonce, ",")+"\n")
+	cfg, adapter, log := createImportTestDatabase(t, snapshot)
+
+	// when
+	err := importTestHeaders(adapter, cfg, log)
+
+	// then
+	if err == nil || !strings.Contains(err.Error(), "invalid header at height 2") {
+		t.Fatalf("Expected invalid header at height 2, got %v", err)
+	}
+	assertHeadersInDatabase(t, adapter, 0)
+}
+
+// writeTestSnapshot writes the prepared database file with the csv data.
+func writeTestSnapshot(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "snapshot.csv.gz")
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	w := gz.NewWriter(f)
+	_, err = w.Write([]byte(data))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	assert.NoError(t, f.Close())
+	return path
+}
@@ -0,0 +1,61 @@
+package sql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+)
+
+const (
+	sqlInsertAuditRecord = `
+	INSERT INTO audit_log(id, actor, action, target, source_ip, result, error, created_at)
+	VALUES(:id, :actor, :action, :target, :source_ip, :result, :error, :created_at)
+	`
+
+	sqlSelectAuditRecords = `
+	SELECT id, actor, action, target, source_ip, result, error, created_at
+	FROM audit_log
+	`
+)
+
+// CreateAuditRecord method will append the record to the audit log. Records are never updated nor deleted.
+func (h *HeadersDb) CreateAuditRecord(ctx context.Context, record *dto.DbAuditRecord) error {
+	if _, err := h.db.NamedExecContext(ctx, h.db.Rebind(sqlInsertAuditRecord), *record); err != nil {
+		return bhserrors.ErrCreateAuditRecord.Wrap(err)
+	}
+	return nil
+}
+
+// GetAuditRecords method will return records of the audit log matching the filter, newest first.
+func (h *HeadersDb) GetAuditRecords(ctx context.Context, filter domains.AuditFilter) ([]*dto.DbAuditRecord, error) {
+	conditions := make([]string, 0, 3)
+	args := make([]interface{}, 0, 4)
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, string(filter.Action))
+	}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, filter.Actor)
+	}
+	if filter.Before != "" {
+		conditions = append(conditions, "id < ?")
+		args = append(args, filter.Before)
+	}
+
+	query := sqlSelectAuditRecords
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, filter.Limit)
+
+	var records []*dto.DbAuditRecord
+	if err := h.db.SelectContext(ctx, &records, h.db.Rebind(query), args...); err != nil {
+		return nil, bhserrors.ErrGetAuditRecords.Wrap(err)
+	}
+	return records, nil
+}
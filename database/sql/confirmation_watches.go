@@ -0,0 +1,81 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+)
+
+const (
+	sqlInsertConfirmationWatch = `
+	INSERT INTO confirmation_watches(owner, hash)
+	VALUES(:owner, :hash)
+	ON CONFLICT DO NOTHING
+	`
+
+	sqlDeleteConfirmationWatch = `
+	DELETE FROM confirmation_watches
+	WHERE owner = :owner AND hash = :hash
+	`
+
+	sqlGetConfirmationWatches = `
+	SELECT owner, hash
+	FROM confirmation_watches
+	ORDER BY hash, owner
+	`
+
+	sqlTransferConfirmationWatches = `
+	UPDATE confirmation_watches
+	SET owner = ?
+	WHERE owner = ?
+	`
+)
+
+// CreateConfirmationWatches method will add hashes of headers watched by the owner for reaching the confirmation depth into db.
+func (h *HeadersDb) CreateConfirmationWatches(ctx context.Context, owner string, hashes []string) error {
+	return h.execForHashes(ctx, sqlInsertConfirmationWatch, owner, hashes, bhserrors.ErrWatchHeaders)
+}
+
+// DeleteConfirmationWatches method will delete hashes of headers watched by the owner from db.
+func (h *HeadersDb) DeleteConfirmationWatches(ctx context.Context, owner string, hashes []string) error {
+	return h.execForHashes(ctx, sqlDeleteConfirmationWatch, owner, hashes, bhserrors.ErrWatchHeaders)
+}
+
+// GetConfirmationWatches method will return all watched headers with their owners from db.
+func (h *HeadersDb) GetConfirmationWatches(ctx context.Context) ([]*dto.DbConfirmationWatch, error) {
+	var watches []*dto.DbConfirmationWatch
+	if err := h.db.SelectContext(ctx, &watches, h.db.Rebind(sqlGetConfirmationWatches)); err != nil {
+		return nil, bhserrors.ErrWatchHeaders.Wrap(err)
+	}
+	return watches, nil
+}
+
+// TransferConfirmationWatches method will change the owner of watched headers in db.
+func (h *HeadersDb) TransferConfirmationWatches(ctx context.Context, from, to string) error {
+	if _, err := h.db.ExecContext(ctx, h.db.Rebind(sqlTransferConfirmationWatches), to, from); err != nil {
+		return bhserrors.ErrWatchHeaders.Wrap(err)
+	}
+	return nil
+}
+
+func (h *HeadersDb) execForHashes(ctx context.Context, query, owner string, hashes []string, bhsErr bhserrors.BHSError) error {
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, hash := range hashes {
+		if _, err := tx.NamedExecContext(ctx, h.db.Rebind(query), map[string]interface{}{"owner": owner, "hash": hash}); err != nil {
+			return bhsErr.Wrap(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return bhsErr.Wrap(err)
+	}
+	return nil
+}
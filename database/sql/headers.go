@@ -3,6 +3,7 @@ package sql
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/domains"
@@ -18,9 +19,12 @@ const (
 
 	longestChainState = "LONGEST_CHAIN"
 
+	// deleteBatchSize is the number of headers deleted by a single statement, to stay below the limit of query parameters.
+	deleteBatchSize = 500
+
 	sqlInsertHeader = `
-	INSERT INTO headers(hash, height, version, merkleroot, nonce, bits, header_state, chainwork, previous_block, timestamp , cumulated_work)
-	VALUES(:hash, :height, :version, :merkleroot, :nonce, :bits, :header_state, :chainwork, :previous_block, :timestamp, :cumulated_work)
+	INSERT INTO headers(hash, height, version, merkleroot, nonce, bits, header_state, chainwork, previous_block, timestamp , cumulated_work, created_at)
+	VALUES(:hash, :height, :version, :merkleroot, :nonce, :bits, :header_state, :chainwork, :previous_block, :timestamp, :cumulated_work, :created_at)
 	ON CONFLICT DO NOTHING
 	`
 
@@ -30,6 +34,22 @@ const (
 	WHERE hash IN (?)
 	`
 
+	sqlUpdateHeaderChain = `
+	UPDATE headers
+	SET height = :height, header_state = :header_state, chainwork = :chainwork, cumulated_work = :cumulated_work
+	WHERE hash = :hash
+	`
+
+	sqlDeleteHeaders = `
+	DELETE FROM headers
+	WHERE hash IN (?)
+	`
+
+	sqlDeleteHeadersOlderThan = `
+	DELETE FROM headers
+	WHERE header_state = ? AND created_at < ?
+	`
+
 	sqlHeader = `
 	SELECT hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work
 	FROM headers
@@ -202,6 +222,21 @@ func NewHeadersDb(db *sqlx.DB, log *zerolog.Logger) *HeadersDb {
 	}
 }
 
+// WithCreatedAt returns the header stored at the time, unless the time it was stored is set already.
+// Times are stored in UTC, so they are compared the same way by all engines.
+func WithCreatedAt(header dto.DbBlockHeader, createdAt time.Time) dto.DbBlockHeader {
+	if header.CreatedAt.IsZero() {
+		header.CreatedAt = createdAt
+	}
+	header.CreatedAt = header.CreatedAt.UTC()
+	return header
+}
+
+// Close closes the database.
+func (h *HeadersDb) Close() error {
+	return h.db.Close()
+}
+
 // Create method will add new record into db.
 func (h *HeadersDb) Create(ctx context.Context, req dto.DbBlockHeader) error {
 	tx, err := h.db.BeginTxx(ctx, nil)
@@ -211,7 +246,7 @@ func (h *HeadersDb) Create(ctx context.Context, req dto.DbBlockHeader) error {
 	defer func() {
 		_ = tx.Rollback()
 	}()
-	if _, err := tx.NamedExecContext(ctx, sqlInsertHeader, req); err != nil {
+	if _, err := tx.NamedExecContext(ctx, sqlInsertHeader, WithCreatedAt(req, time.Now())); err != nil {
 		return errors.Wrap(err, "failed to insert header")
 	}
 	return errors.Wrap(tx.Commit(), "failed to commit tx")
@@ -227,8 +262,9 @@ func (h *HeadersDb) CreateMultiple(ctx context.Context, headers []dto.DbBlockHea
 		_ = tx.Rollback()
 	}()
 
+	createdAt := time.Now()
 	for _, record := range headers {
-		if _, err := tx.NamedExecContext(ctx, sqlInsertHeader, record); err != nil {
+		if _, err := tx.NamedExecContext(ctx, sqlInsertHeader, WithCreatedAt(record, createdAt)); err != nil {
 			return errors.Wrap(err, "failed to insert header")
 		}
 	}
@@ -246,7 +282,7 @@ func (h *HeadersDb) UpdateState(ctx context.Context, hashes []string, state stri
 		_ = tx.Rollback()
 	}()
 
-	query, args, err := sqlx.In(sqlUpdateState, state, hashes)
+	query, args, err := sqlx.In(sqlUpdateState, state, toDbHashes(hashes))
 	if err != nil {
 		return errors.Wrapf(err, "failed to update headers state to %s", state)
 	}
@@ -256,6 +292,68 @@ func (h *HeadersDb) UpdateState(ctx context.Context, hashes []string, state stri
 	return errors.Wrap(tx.Commit(), "failed to commit tx")
 }
 
+// UpdateChain will update height, state and work of headers, which place them in the chain.
+func (h *HeadersDb) UpdateChain(ctx context.Context, headers []dto.DbBlockHeader) error {
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, record := range headers {
+		if _, err := tx.NamedExecContext(ctx, sqlUpdateHeaderChain, record); err != nil {
+			return errors.Wrapf(err, "failed to update header %s", record.Hash)
+		}
+	}
+
+	return errors.Wrap(tx.Commit(), "failed to commit tx")
+}
+
+// Delete will remove headers with given hashes and return the number of removed ones.
+func (h *HeadersDb) Delete(ctx context.Context, hashes []string) (int, error) {
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	deleted := 0
+	for from := 0; from < len(hashes); from += deleteBatchSize {
+		query, args, err := sqlx.In(sqlDeleteHeaders, toDbHashes(hashes[from:min(from+deleteBatchSize, len(hashes))]))
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to delete headers")
+		}
+		res, err := tx.ExecContext(ctx, h.db.Rebind(query), args...)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to delete headers")
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to count deleted headers")
+		}
+		deleted += int(n)
+	}
+
+	return deleted, errors.Wrap(tx.Commit(), "failed to commit tx")
+}
+
+// DeleteOlderThan will remove headers in given state stored before given time and return the number of removed ones.
+func (h *HeadersDb) DeleteOlderThan(ctx context.Context, state string, before time.Time) (int, error) {
+	res, err := h.db.ExecContext(ctx, h.db.Rebind(sqlDeleteHeadersOlderThan), state, before.UTC())
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to delete %s headers", state)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to count deleted %s headers", state)
+	}
+	return int(n), nil
+}
+
 // Height will return the current highest block height we have stored in the db.
 func (h *HeadersDb) Height(ctx context.Context) (int, error) {
 	var height int
@@ -278,7 +376,7 @@ func (h *HeadersDb) Count(ctx context.Context) (int, error) {
 // GetHeaderByHash will return header from db with given hash.
 func (h *HeadersDb) GetHeaderByHash(ctx context.Context, hash string) (*dto.DbBlockHeader, error) {
 	var bh dto.DbBlockHeader
-	if err := h.db.GetContext(ctx, &bh, h.db.Rebind(sqlHeader), hash); err != nil {
+	if err := h.db.GetContext(ctx, &bh, h.db.Rebind(sqlHeader), dto.DbHash(hash)); err != nil {
 		return nil, bhserrors.ErrHeaderNotFound.Wrap(err)
 	}
 	return &bh, nil
@@ -320,7 +418,7 @@ func (h *HeadersDb) GetLongestChainHeadersFromHeight(height int32) ([]*dto.DbBlo
 // GetStaleHeadersBackFrom returns from db all the headers with state STALE, starting from header with hash and preceding that one.
 func (h *HeadersDb) GetStaleHeadersBackFrom(hash string) ([]*dto.DbBlockHeader, error) {
 	var bh []*dto.DbBlockHeader
-	if err := h.db.Select(&bh, h.db.Rebind(sqlStaleHeadersFrom), hash); err != nil {
+	if err := h.db.Select(&bh, h.db.Rebind(sqlStaleHeadersFrom), dto.DbHash(hash)); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errors.Errorf("header with %s hash does not exist", hash)
 		}
@@ -338,7 +436,7 @@ func (h *HeadersDb) GenesisExists(_ context.Context) bool {
 // GetPreviousHeader will return previous header for this with given hash.
 func (h *HeadersDb) GetPreviousHeader(ctx context.Context, hash string) (*dto.DbBlockHeader, error) {
 	var bh dto.DbBlockHeader
-	if err := h.db.GetContext(ctx, &bh, h.db.Rebind(sqlSelectPreviousBlock), hash); err != nil {
+	if err := h.db.GetContext(ctx, &bh, h.db.Rebind(sqlSelectPreviousBlock), dto.DbHash(hash)); err != nil {
 		return nil, bhserrors.ErrHeaderNotFound.Wrap(err)
 	}
 	return &bh, nil
@@ -361,7 +459,7 @@ func (h *HeadersDb) GetTip(_ context.Context) (*dto.DbBlockHeader, error) {
 // GetAncestorOnHeight provides ancestor for a hash on a specified height.
 func (h *HeadersDb) GetAncestorOnHeight(hash string, height int32) (*dto.DbBlockHeader, error) {
 	var bh []*dto.DbBlockHeader
-	if err := h.db.Select(&bh, h.db.Rebind(sqlSelectAncestorOnHeight), hash, int(height), int(height)); err != nil {
+	if err := h.db.Select(&bh, h.db.Rebind(sqlSelectAncestorOnHeight), dto.DbHash(hash), int(height), int(height)); err != nil {
 		return nil, bhserrors.ErrAncestorNotFound.Wrap(err)
 	}
 	if len(bh) == 0 {
@@ -382,7 +480,7 @@ func (h *HeadersDb) GetAllTips() ([]*dto.DbBlockHeader, error) {
 // GetChainBetweenTwoHashes calculates and returnes chain between 2 hashes.
 func (h *HeadersDb) GetChainBetweenTwoHashes(low string, high string) ([]*dto.DbBlockHeader, error) {
 	var bh []*dto.DbBlockHeader
-	if err := h.db.Select(&bh, h.db.Rebind(sqlChainBetweenTwoHashes), high, low, low); err != nil {
+	if err := h.db.Select(&bh, h.db.Rebind(sqlChainBetweenTwoHashes), dto.DbHash(high), dto.DbHash(low), dto.DbHash(low)); err != nil {
 		return nil, bhserrors.ErrHeadersForGivenRangeNotFound.Wrap(err)
 	}
 	if len(bh) == 0 {
@@ -414,7 +512,7 @@ func (h *HeadersDb) GetMerkleRootsConfirmations(
 
 // GetHeadersStartHeight returns hash and height from db with given locators.
 func (h *HeadersDb) GetHeadersStartHeight(hashTable []string) (int, error) {
-	query, args, err := sqlx.In(sqlGetHeadersHeight, hashTable)
+	query, args, err := sqlx.In(sqlGetHeadersHeight, toDbHashes(hashTable))
 	if err != nil {
 		h.log.Error().Err(err).Msg("Error while constructing query")
 		return 0, err
@@ -432,7 +530,7 @@ func (h *HeadersDb) GetHeadersStartHeight(hashTable []string) (int, error) {
 // GetHeadersStopHeight will return header from db with given hash.
 func (h *HeadersDb) GetHeadersStopHeight(hashStop string) (int, error) {
 	var dbHashStopHeight int
-	if err := h.db.Get(&dbHashStopHeight, h.db.Rebind(sqlHeaderHeightFromHashAndState), hashStop, longestChainState); err != nil {
+	if err := h.db.Get(&dbHashStopHeight, h.db.Rebind(sqlHeaderHeightFromHashAndState), dto.DbHash(hashStop), longestChainState); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return 0, nil
 		}
@@ -464,8 +562,14 @@ func (h *HeadersDb) getMerkleRootConfirmation(item domains.MerkleRootConfirmatio
 		TipHeight:   tipHeight,
 	}
 
-	var hash sql.NullString
-	err := h.db.Get(&hash, sqlVerifyHash, item.MerkleRoot, item.BlockHeight)
+	// merkle root which isn't a hash can't be confirmed
+	merkleRoot := dto.DbHash(item.MerkleRoot)
+	if _, err := merkleRoot.Bytes(); err != nil {
+		return confirmation, nil
+	}
+
+	var hash sql.Null[dto.DbHash]
+	err := h.db.Get(&hash, sqlVerifyHash, merkleRoot, item.BlockHeight)
 
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, err
@@ -499,8 +603,13 @@ func (h *HeadersDb) getLastEvaluatedMerklerootHeight(lastEvaluatedKey string) (i
 		return -1, nil
 	}
 
+	merkleRoot := dto.DbHash(lastEvaluatedKey)
+	if _, err := merkleRoot.Bytes(); err != nil {
+		return 0, bhserrors.ErrMerklerootNotFound
+	}
+
 	var lastEvaluatedMerkleroot dto.DbBlockHeader
-	err := h.db.Get(&lastEvaluatedMerkleroot, h.db.Rebind(sqlGetSingleMerkleroot), lastEvaluatedKey)
+	err := h.db.Get(&lastEvaluatedMerkleroot, h.db.Rebind(sqlGetSingleMerkleroot), merkleRoot)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return 0, bhserrors.ErrMerklerootNotFound
@@ -518,3 +627,11 @@ func (h *HeadersDb) getLastEvaluatedMerklerootHeight(lastEvaluatedKey string) (i
 
 	return lastEvaluatedHeight, nil
 }
+
+func toDbHashes(hashes []string) []dto.DbHash {
+	dbHashes := make([]dto.DbHash, len(hashes))
+	for i, hash := range hashes {
+		dbHashes[i] = dto.DbHash(hash)
+	}
+	return dbHashes
+}
@@ -0,0 +1,159 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+)
+
+const (
+	sqlInsertMerkleRootWatch = `
+	INSERT INTO merkleroot_watches(owner, merkle_root, block_height, confirmation, confirmations_reached, created_at)
+	VALUES(:owner, :merkle_root, :block_height, :confirmation, :confirmations_reached, :created_at)
+	`
+
+	sqlGetAllMerkleRootWatches = `
+	SELECT owner, merkle_root, block_height, confirmation, confirmations_reached, created_at
+	FROM merkleroot_watches
+	ORDER BY block_height, merkle_root, owner
+	`
+
+	sqlGetMerkleRootWatchesOfOwner = `
+	SELECT owner, merkle_root, block_height, confirmation, confirmations_reached, created_at
+	FROM merkleroot_watches
+	WHERE owner = ?
+	ORDER BY block_height, merkle_root
+	`
+
+	sqlGetMerkleRootWatchesByHeight = `
+	SELECT owner, merkle_root, block_height, confirmation, confirmations_reached, created_at
+	FROM merkleroot_watches
+	WHERE block_height BETWEEN ? AND ?
+	ORDER BY block_height, merkle_root, owner
+	`
+
+	sqlDeleteMerkleRootWatch = `
+	DELETE FROM merkleroot_watches
+	WHERE owner = :owner AND merkle_root = :merkle_root AND block_height = :block_height
+	`
+
+	sqlUpdateMerkleRootWatch = `
+	UPDATE merkleroot_watches
+	SET confirmation = :confirmation, confirmations_reached = :confirmations_reached
+	WHERE owner = :owner AND merkle_root = :merkle_root AND block_height = :block_height
+	`
+
+	sqlTransferMerkleRootWatches = `
+	UPDATE merkleroot_watches
+	SET owner = ?
+	WHERE owner = ?
+	`
+)
+
+// CreateMerkleRootWatch method will add new merkle root watch into db.
+func (h *HeadersDb) CreateMerkleRootWatch(ctx context.Context, w *dto.DbMerkleRootWatch) error {
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.NamedExecContext(ctx, h.db.Rebind(sqlInsertMerkleRootWatch), *w); err != nil {
+		return bhserrors.ErrCreateMerklerootWatch.Wrap(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return bhserrors.ErrCreateMerklerootWatch.Wrap(err)
+	}
+
+	return nil
+}
+
+// GetAllMerkleRootWatches method will return all merkle root watches from db.
+func (h *HeadersDb) GetAllMerkleRootWatches(ctx context.Context) ([]*dto.DbMerkleRootWatch, error) {
+	var watches []*dto.DbMerkleRootWatch
+	if err := h.db.SelectContext(ctx, &watches, h.db.Rebind(sqlGetAllMerkleRootWatches)); err != nil {
+		return nil, bhserrors.ErrGetAllMerklerootWatches.Wrap(err)
+	}
+
+	return watches, nil
+}
+
+// GetMerkleRootWatches method will return merkle root watches of the owner from db.
+func (h *HeadersDb) GetMerkleRootWatches(ctx context.Context, owner string) ([]*dto.DbMerkleRootWatch, error) {
+	var watches []*dto.DbMerkleRootWatch
+	if err := h.db.SelectContext(ctx, &watches, h.db.Rebind(sqlGetMerkleRootWatchesOfOwner), owner); err != nil {
+		return nil, bhserrors.ErrGetAllMerklerootWatches.Wrap(err)
+	}
+
+	return watches, nil
+}
+
+// GetMerkleRootWatchesByHeight method will return merkle root watches at block heights from the given range from db.
+func (h *HeadersDb) GetMerkleRootWatchesByHeight(ctx context.Context, from, to int32) ([]*dto.DbMerkleRootWatch, error) {
+	var watches []*dto.DbMerkleRootWatch
+	if err := h.db.SelectContext(ctx, &watches, h.db.Rebind(sqlGetMerkleRootWatchesByHeight), from, to); err != nil {
+		return nil, bhserrors.ErrGetAllMerklerootWatches.Wrap(err)
+	}
+
+	return watches, nil
+}
+
+// DeleteMerkleRootWatch method will delete merkle root watch of the owner from db.
+func (h *HeadersDb) DeleteMerkleRootWatch(ctx context.Context, owner, merkleRoot string, blockHeight int32) error {
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	params := map[string]interface{}{"owner": owner, "merkle_root": merkleRoot, "block_height": blockHeight}
+
+	res, err := tx.NamedExecContext(ctx, h.db.Rebind(sqlDeleteMerkleRootWatch), params)
+	if err != nil {
+		return bhserrors.ErrDeleteMerklerootWatch.Wrap(err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return bhserrors.ErrMerklerootWatchNotFound
+	}
+
+	if err = tx.Commit(); err != nil {
+		return bhserrors.ErrDeleteMerklerootWatch.Wrap(err)
+	}
+
+	return nil
+}
+
+// UpdateMerkleRootWatch method will update state of merkle root watch in db.
+func (h *HeadersDb) UpdateMerkleRootWatch(ctx context.Context, w *dto.DbMerkleRootWatch) error {
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.NamedExecContext(ctx, h.db.Rebind(sqlUpdateMerkleRootWatch), *w); err != nil {
+		return bhserrors.ErrUpdateMerklerootWatch.Wrap(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return bhserrors.ErrUpdateMerklerootWatch.Wrap(err)
+	}
+
+	return nil
+}
+
+// TransferMerkleRootWatches method will change the owner of merkle root watches in db.
+func (h *HeadersDb) TransferMerkleRootWatches(ctx context.Context, from, to string) error {
+	if _, err := h.db.ExecContext(ctx, h.db.Rebind(sqlTransferMerkleRootWatches), to, from); err != nil {
+		return bhserrors.ErrUpdateMerklerootWatch.Wrap(err)
+	}
+	return nil
+}
@@ -2,29 +2,64 @@ package sql
 
 import (
 	"context"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/repository/dto"
 )
 
 const (
 	sqlInsertToken = `
-	INSERT INTO tokens(token, created_at)
-	VALUES(:token, :created_at)
+	INSERT INTO tokens(token, hashed, prefix, name, owner, scopes, created_at, expires_at, last_used_at, daily_quota, quota_day, quota_used)
+	VALUES(:token, :hashed, :prefix, :name, :owner, :scopes, :created_at, :expires_at, :last_used_at, :daily_quota, :quota_day, :quota_used)
 	ON CONFLICT DO NOTHING
 	`
 
-	//nolint:gosec
-	sqlGetToken = ` 
-	SELECT token, created_at
+	sqlSelectTokens = `
+	SELECT token, hashed, prefix, name, owner, COALESCE(scopes, '') AS scopes, created_at, expires_at, last_used_at,
+		COALESCE(daily_quota, 0) AS daily_quota, COALESCE(quota_day, '') AS quota_day, COALESCE(quota_used, 0) AS quota_used
 	FROM tokens
-	WHERE token = ?
+	`
+
+	//nolint:gosec
+	sqlGetToken = sqlSelectTokens + `
+	WHERE token = ? AND hashed = ?
+	`
+
+	sqlGetTokens = sqlSelectTokens + `
+	WHERE hashed = ?
+	ORDER BY created_at
+	`
+
+	sqlUpdateTokenLastUsed = `
+	UPDATE tokens
+	SET last_used_at = :last_used_at
+	WHERE token = :token
+	`
+
+	sqlUpdateTokenQuotaUsage = `
+	UPDATE tokens
+	SET quota_day = :quota_day, quota_used = :quota_used
+	WHERE token = :token
 	`
 
 	sqlDeleteToken = `
 	DELETE FROM tokens
 	WHERE token = :token
 	`
+
+	sqlGetPlaintextTokens = `
+	SELECT token
+	FROM tokens
+	WHERE hashed = ?
+	`
+
+	sqlHashToken = `
+	UPDATE tokens
+	SET token = :hash, prefix = :prefix, hashed = :hashed
+	WHERE token = :token
+	`
 )
 
 // CreateToken method will add new record into db.
@@ -48,17 +83,73 @@ func (h *HeadersDb) CreateToken(ctx context.Context, token *dto.DbToken) error {
 	return nil
 }
 
-// GetTokenByValue method will search and return token by value.
-func (h *HeadersDb) GetTokenByValue(ctx context.Context, token string) (*dto.DbToken, error) {
+// GetTokenByHash method will search and return token by hash of its value.
+func (h *HeadersDb) GetTokenByHash(ctx context.Context, hash string) (*dto.DbToken, error) {
 	var dbToken dto.DbToken
-	if err := h.db.GetContext(ctx, &dbToken, h.db.Rebind(sqlGetToken), token); err != nil {
+	if err := h.db.GetContext(ctx, &dbToken, h.db.Rebind(sqlGetToken), hash, true); err != nil {
 		return nil, bhserrors.ErrTokenNotFound.Wrap(err)
 	}
 	return &dbToken, nil
 }
 
+// GetTokens method will return all tokens from db.
+func (h *HeadersDb) GetTokens(ctx context.Context) ([]*dto.DbToken, error) {
+	var tokens []*dto.DbToken
+	if err := h.db.SelectContext(ctx, &tokens, h.db.Rebind(sqlGetTokens), true); err != nil {
+		return nil, bhserrors.ErrGetTokens.Wrap(err)
+	}
+	return tokens, nil
+}
+
+// UpdateTokenLastUsed method will set the time when the token was used for the last time.
+func (h *HeadersDb) UpdateTokenLastUsed(ctx context.Context, hash string, lastUsedAt time.Time) error {
+	params := map[string]interface{}{"token": hash, "last_used_at": lastUsedAt}
+	if _, err := h.db.NamedExecContext(ctx, h.db.Rebind(sqlUpdateTokenLastUsed), params); err != nil {
+		return bhserrors.ErrUpdateToken.Wrap(err)
+	}
+	return nil
+}
+
+// UpdateTokenQuotaUsage method will save the number of requests made by the token on given day.
+func (h *HeadersDb) UpdateTokenQuotaUsage(ctx context.Context, hash string, day string, used int64) error {
+	params := map[string]interface{}{"token": hash, "quota_day": day, "quota_used": used}
+	if _, err := h.db.NamedExecContext(ctx, h.db.Rebind(sqlUpdateTokenQuotaUsage), params); err != nil {
+		return bhserrors.ErrUpdateToken.Wrap(err)
+	}
+	return nil
+}
+
+// RotateToken method will replace the token with a new one in a single transaction.
+func (h *HeadersDb) RotateToken(ctx context.Context, hash string, token *dto.DbToken) error {
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.NamedExecContext(ctx, h.db.Rebind(sqlDeleteToken), map[string]interface{}{"token": hash})
+	if err != nil {
+		return bhserrors.ErrRotateToken.Wrap(err)
+	}
+	if deleted, err := res.RowsAffected(); err == nil && deleted == 0 {
+		return bhserrors.ErrTokenNotFound
+	}
+
+	if _, err := tx.NamedExecContext(ctx, h.db.Rebind(sqlInsertToken), *token); err != nil {
+		return bhserrors.ErrRotateToken.Wrap(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return bhserrors.ErrRotateToken.Wrap(err)
+	}
+
+	return nil
+}
+
 // DeleteToken method will delete token from db.
-func (h *HeadersDb) DeleteToken(ctx context.Context, token string) error {
+func (h *HeadersDb) DeleteToken(ctx context.Context, hash string) error {
 	tx, err := h.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
@@ -67,7 +158,7 @@ func (h *HeadersDb) DeleteToken(ctx context.Context, token string) error {
 		_ = tx.Rollback()
 	}()
 
-	if _, err = tx.NamedExecContext(ctx, h.db.Rebind(sqlDeleteToken), map[string]interface{}{"token": token}); err != nil {
+	if _, err = tx.NamedExecContext(ctx, h.db.Rebind(sqlDeleteToken), map[string]interface{}{"token": hash}); err != nil {
 		return bhserrors.ErrDeleteToken.Wrap(err)
 	}
 
@@ -78,3 +169,37 @@ func (h *HeadersDb) DeleteToken(ctx context.Context, token string) error {
 
 	return nil
 }
+
+// HashPlaintextTokens method will replace values of tokens created before only hashes were stored with their hashes.
+func (h *HeadersDb) HashPlaintextTokens(ctx context.Context) (int, error) {
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var values []string
+	if err := tx.SelectContext(ctx, &values, h.db.Rebind(sqlGetPlaintextTokens), false); err != nil {
+		return 0, bhserrors.ErrUpdateToken.Wrap(err)
+	}
+
+	for _, value := range values {
+		params := map[string]interface{}{
+			"token":  value,
+			"hash":   domains.HashToken(value),
+			"prefix": domains.TokenPrefix(value),
+			"hashed": true,
+		}
+		if _, err := tx.NamedExecContext(ctx, h.db.Rebind(sqlHashToken), params); err != nil {
+			return 0, bhserrors.ErrUpdateToken.Wrap(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, bhserrors.ErrUpdateToken.Wrap(err)
+	}
+
+	return len(values), nil
+}
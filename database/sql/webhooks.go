@@ -12,18 +12,18 @@ import (
 
 const (
 	sqlInsertWebhook = `
-	INSERT INTO webhooks(url, token_header, token, created_at)
-	VALUES(:url, :token_header, :token, :created_at)
+	INSERT INTO webhooks(url, token_header, token, created_at, event_format)
+	VALUES(:url, :token_header, :token, :created_at, :event_format)
 	`
 
 	sqlGetWebhookByURL = ` 
-	SELECT url, token_header, token, created_at, last_emit_status, last_emit_timestamp, errors_count, is_active
+	SELECT url, token_header, token, created_at, last_emit_status, last_emit_timestamp, errors_count, is_active, event_format
 	FROM webhooks
 	WHERE url = ?
 	`
 
 	sqlGetAllWebhooks = `
-	SELECT url, token_header, token, created_at, last_emit_status, last_emit_timestamp, errors_count, is_active
+	SELECT url, token_header, token, created_at, last_emit_status, last_emit_timestamp, errors_count, is_active, event_format
 	FROM webhooks
 	`
 
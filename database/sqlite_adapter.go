@@ -2,23 +2,16 @@ package database
 
 import (
 	"context"
-	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
-	"os"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/database/sql"
-	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 	"github.com/bitcoin-sv/block-headers-service/repository/dto"
-	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/sqlite3"
-	// use blank import to use file source driver with the migrate package.
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jmoiron/sqlx"
-	// use blank import to register sqlite driver.
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/rs/zerolog"
 )
 
@@ -32,7 +25,8 @@ type sqLitePragmaValues struct {
 	CacheSize   int
 }
 
-const sqliteDriverName = "sqlite3"
+// sqliteDriverName is the name of the driver registered by sqliteDriver.
+const sqliteDriverName = "sqlite3_bhs"
 const sqliteBatchSize = 500
 
 func (a *sqLiteAdapter) connect(cfg *config.DbConfig) error {
@@ -46,26 +40,12 @@ func (a *sqLiteAdapter) connect(cfg *config.DbConfig) error {
 	return nil
 }
 
-func (a *sqLiteAdapter) doMigrations(cfg *config.DbConfig) error {
-	driver, err := sqlite3.WithInstance(a.db.DB, &sqlite3.Config{})
-	if err != nil {
-		return err
-	}
-
-	sourceURL := fmt.Sprintf("file://%s", cfg.SchemaPath)
-	driverName := sqliteDriverName
-
-	m, err := migrate.NewWithDatabaseInstance(sourceURL, driverName, driver)
-	if err != nil {
-		return err
-	}
-
-	err = m.Up()
-	if err != nil && err != migrate.ErrNoChange {
-		return err
-	}
+func (a *sqLiteAdapter) migrationDriver() (migratedb.Driver, error) {
+	return sqlite3.WithInstance(a.db.DB, &sqlite3.Config{})
+}
 
-	return nil
+func (a *sqLiteAdapter) driverName() string {
+	return sqliteDriverName
 }
 
 func (a *sqLiteAdapter) getDBx() *sqlx.DB {
@@ -75,7 +55,7 @@ func (a *sqLiteAdapter) getDBx() *sqlx.DB {
 	return a.db
 }
 
-func (a *sqLiteAdapter) importHeaders(inputFile *os.File, log *zerolog.Logger) (affectedRows int, err error) {
+func (a *sqLiteAdapter) importHeaders(snapshot *snapshotReader, withoutIndexes bool, log *zerolog.Logger) (affectedRows int, err error) {
 	// prepare db to bulk insterts
 	restorePragmas, err := modifySqLitePragmas(a.db)
 	if err != nil {
@@ -87,50 +67,29 @@ func (a *sqLiteAdapter) importHeaders(inputFile *os.File, log *zerolog.Logger) (
 		}
 	}()
 
-	restoreIndexes, err := a.dropTableIndexes(sql.HeadersTableName)
-	if err != nil {
-		return
-	}
-	defer func() {
-		if rErr := restoreIndexes(); rErr != nil {
-			err = wrapIfNeeded(err, rErr, "Resoring indexes failed")
+	if withoutIndexes {
+		var restoreIndexes func() error
+		restoreIndexes, err = a.dropTableIndexes(sql.HeadersTableName)
+		if err != nil {
+			return
 		}
-	}()
-
-	// Read from the beginning of the file
-	if _, err = inputFile.Seek(0, 0); err != nil {
-		return
-	}
-
-	reader := csv.NewReader(inputFile)
-	_, err = reader.Read() // Skipping the column headers line
-	if err != nil {
-		return
+		defer func() {
+			if rErr := restoreIndexes(); rErr != nil {
+				err = wrapIfNeeded(err, rErr, "Resoring indexes failed")
+			}
+		}()
 	}
 
 	repo := sql.NewHeadersDb(a.db, log)
 
-	previousBlockHash := chainhash.Hash{}.String()
-	var cumulatedChainWork string
-	rowIndex := 0
-	guard := 0
-
 	for {
-		rowIndex, previousBlockHash, cumulatedChainWork, err = a.insertHeaders(reader, repo, sqliteBatchSize, previousBlockHash, cumulatedChainWork, rowIndex)
-		if err != nil {
-			affectedRows = rowIndex
+		var inserted int
+		inserted, err = a.insertHeaders(snapshot, repo, sqliteBatchSize)
+		affectedRows += inserted
+		if err != nil || inserted == 0 {
 			return
 		}
-
-		if guard == rowIndex {
-			break
-		}
-
-		guard = rowIndex
-		affectedRows = rowIndex
 	}
-
-	return
 }
 
 func modifySqLitePragmas(db *sqlx.DB) (func() error, error) {
@@ -199,41 +158,25 @@ func (a *sqLiteAdapter) dropTableIndexes(table string) (func() error, error) {
 	return dropIndexes(a.db, &q)
 }
 
-func (a *sqLiteAdapter) insertHeaders(reader *csv.Reader, repo *sql.HeadersDb, batchSize int, previousBlockHash string, cumulatedLastBlockChainWork string, rowIndex int) (lastRowIndex int, lastBlockHash string, cumulatedChainwork string, err error) {
-	lastRowIndex = rowIndex
-	lastBlockHash = previousBlockHash
+func (a *sqLiteAdapter) insertHeaders(snapshot *snapshotReader, repo *sql.HeadersDb, batchSize int) (int, error) {
 	batch := make([]dto.DbBlockHeader, 0, batchSize)
-	cumulatedChainwork = cumulatedLastBlockChainWork
 
 	for i := 0; i < batchSize; i++ {
-		var record []string
-		record, err = reader.Read()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			err = fmt.Errorf("error reading record: %v", err)
-			return
-		}
-
-		if len(record) == 0 {
+		block, err := snapshot.next()
+		if errors.Is(err, io.EOF) {
 			break
 		}
-		var block *dto.DbBlockHeader
-		block, err = prepareRecord(record, lastBlockHash, cumulatedChainwork, lastRowIndex)
 		if err != nil {
-			return
+			return 0, err
 		}
 		batch = append(batch, *block)
-
-		cumulatedChainwork = block.CumulatedWork
-		lastBlockHash = block.Hash
-		lastRowIndex++
 	}
 
-	if err = repo.CreateMultiple(context.Background(), batch); err != nil {
-		return
+	if len(batch) == 0 {
+		return 0, nil
 	}
-
-	return
+	if err := repo.CreateMultiple(context.Background(), batch); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
 }
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	sql.Register(sqliteDriverName, &sqliteDriver{&sqlite3.SQLiteDriver{ConnectHook: registerSqliteFunctions}})
+	sqlx.BindDriver(sqliteDriverName, sqlx.QUESTION)
+}
+
+// sqliteDriver is the SQLite driver storing work of headers as 32 bytes big-endian, which compare in SQL in the same order as the numbers.
+type sqliteDriver struct {
+	*sqlite3.SQLiteDriver
+}
+
+func (d *sqliteDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+type sqliteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+// CheckNamedValue implements driver.NamedValueChecker to encode work, other values are converted by the driver.
+func (c *sqliteConn) CheckNamedValue(nv *driver.NamedValue) error {
+	work, ok := nv.Value.(dto.DbWork)
+	if !ok {
+		return driver.ErrSkip
+	}
+	b, err := work.Bytes()
+	if err != nil {
+		return err
+	}
+	nv.Value = b
+	return nil
+}
+
+// registerSqliteFunctions registers functions converting work between decimal text and bytes, used by migrations of the schema.
+func registerSqliteFunctions(conn *sqlite3.SQLiteConn) error {
+	if err := conn.RegisterFunc("work_to_bytes", workToBytes, true); err != nil {
+		return err
+	}
+	return conn.RegisterFunc("work_to_text", workToText, true)
+}
+
+func workToBytes(v any) (any, error) {
+	switch w := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return dto.DbWork(w).Bytes()
+	case int64:
+		return dto.DbWork(fmt.Sprint(w)).Bytes()
+	default:
+		return nil, fmt.Errorf("cannot convert %T to work", v)
+	}
+}
+
+func workToText(v any) (any, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, nil
+	}
+	var work dto.DbWork
+	if err := work.SetBytes(b); err != nil {
+		return nil, err
+	}
+	return work.String(), nil
+}
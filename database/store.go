@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/database/kv"
+	sqlrepository "github.com/bitcoin-sv/block-headers-service/database/repository"
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/rs/zerolog"
+	"go.etcd.io/bbolt"
+)
+
+// boltBatchSize is the number of headers of the prepared database file inserted in a single transaction,
+// and of headers read at once when they are exported.
+const boltBatchSize = 10000
+
+// boltOpenTimeout is how long opening waits for the lock of the database file held by another process.
+const boltOpenTimeout = 5 * time.Second
+
+// Open initializes the database of the configured engine like Init does and returns the store of all data.
+// The bolt engine has no schema, so it has no migrations.
+func Open(cfg *config.AppConfig, log *zerolog.Logger) (sqlrepository.Store, error) {
+	if cfg.Db.Engine != config.DBBolt {
+		db, unlock, err := Init(cfg, log)
+		if err != nil {
+			return nil, err
+		}
+		return &lockedStore{Store: sql.NewHeadersDb(db, log), unlock: unlock}, nil
+	}
+
+	dbLog := log.With().Str("subservice", "database").Logger()
+
+	store, err := openBolt(cfg.Db, log)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Db.PreparedDb {
+		err = importHeaders(store, &storeImport{store: store, batchSize: boltBatchSize}, cfg, &dbLog)
+	} else {
+		err = store.Create(context.Background(), createGenesisHeaderBlock(cfg.P2P.GetNetParams().GenesisBlock.Header))
+	}
+	if err != nil {
+		_ = store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// ConnectStore opens the database of the configured engine without migrations nor importing headers, for commands
+// working with an existing database. An exclusive store takes the lock held by the running service, so it fails
+// with ErrDatabaseLocked while the service runs. Bolt databases are always opened exclusively.
+func ConnectStore(cfg *config.DbConfig, exclusive bool, log *zerolog.Logger) (sqlrepository.Store, error) {
+	if cfg.Engine == config.DBBolt {
+		return openBolt(cfg, log)
+	}
+	db, err := Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if !exclusive {
+		return sql.NewHeadersDb(db, log), nil
+	}
+
+	unlock, err := lockDatabase(cfg, db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &lockedStore{Store: sql.NewHeadersDb(db, log), unlock: unlock}, nil
+}
+
+// lockedStore releases the lock of the database when the store is closed.
+type lockedStore struct {
+	sqlrepository.Store
+	unlock func() error
+}
+
+func (s *lockedStore) Close() error {
+	unlockErr := s.unlock()
+	if err := s.Store.Close(); err != nil {
+		return err
+	}
+	return unlockErr
+}
+
+func openBolt(cfg *config.DbConfig, log *zerolog.Logger) (*kv.HeadersKv, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Bolt.FilePath), 0o750); err != nil {
+		return nil, err
+	}
+	db, err := bbolt.Open(cfg.Bolt.FilePath, 0o600, &bbolt.Options{Timeout: boltOpenTimeout})
+	if errors.Is(err, bbolt.ErrTimeout) {
+		return nil, fmt.Errorf("%w: %s", ErrDatabaseLocked, cfg.Bolt.FilePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open bolt database %s: %w", cfg.Bolt.FilePath, err)
+	}
+	store, err := kv.NewHeadersKv(db, log)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return store, nil
+}
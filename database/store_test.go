@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/rs/zerolog"
+)
+
+func TestOpenBoltWithPreparedDatabase(t *testing.T) {
+	// setup
+	logger := zerolog.Nop()
+	cfg := config.GetDefaultAppConfig()
+	cfg.Db.Engine = config.DBBolt
+	cfg.Db.Bolt.FilePath = filepath.Join(t.TempDir(), "data", "blockheaders.bolt")
+	cfg.Db.PreparedDb = true
+	cfg.Db.PreparedDbFilePath = exportTestSnapshot(t, 0)
+
+	// when
+	store, err := Open(cfg, &logger)
+
+	// then
+	assert.NoError(t, err)
+	count, _ := store.Count(context.Background())
+	assert.Equal(t, count, len(exportTestHashes))
+	tip, err := store.GetTip(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, tip.Hash.String(), exportTestHashes[len(exportTestHashes)-1])
+	assert.NoError(t, store.Close())
+
+	// when
+	store, err = Open(cfg, &logger)
+
+	// then
+	assert.NoError(t, err)
+	count, _ = store.Count(context.Background())
+	assert.Equal(t, count, len(exportTestHashes))
+	assert.NoError(t, store.Close())
+}
+
+func TestOpenBoltWithGenesis(t *testing.T) {
+	// setup
+	logger := zerolog.Nop()
+	cfg := config.GetDefaultAppConfig()
+	cfg.Db.Engine = config.DBBolt
+	cfg.Db.Bolt.FilePath = filepath.Join(t.TempDir(), "blockheaders.bolt")
+
+	// when
+	store, err := Open(cfg, &logger)
+
+	// then
+	assert.NoError(t, err)
+	defer func() { _ = store.Close() }()
+	assert.Equal(t, store.GenesisExists(context.Background()), true)
+	tip, err := store.GetTip(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, tip.Hash.String(), cfg.P2P.GetNetParams().GenesisHash.String())
+}
+
+func TestExclusiveStoreWhileServiceIsRunning(t *testing.T) {
+	// setup
+	logger := zerolog.Nop()
+	cfg := config.GetDefaultAppConfig()
+	cfg.Db.SQLite.FilePath = filepath.Join(t.TempDir(), "blockheaders.db")
+
+	// given
+	service, err := Open(cfg, &logger)
+	assert.NoError(t, err)
+
+	// when
+	_, err = ConnectStore(cfg.Db, true, &logger)
+
+	// then
+	assert.Equal(t, errors.Is(err, ErrDatabaseLocked), true)
+
+	// when
+	assert.NoError(t, service.Close())
+	store, err := ConnectStore(cfg.Db, true, &logger)
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, store.Close())
+}
+
+func TestDeleteHeadersStoredBefore(t *testing.T) {
+	// setup
+	_, adapter, log := createImportTestDatabase(t, "")
+	store := sql.NewHeadersDb(adapter.getDBx(), log)
+	ctx := context.Background()
+
+	// given
+	insertTestHeaders(t, adapter, len(exportTestHashes))
+
+	// when
+	deleted, err := store.DeleteOlderThan(ctx, string(domains.LongestChain), time.Now().Add(-time.Hour))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, deleted, 0)
+
+	// when
+	deleted, err = store.DeleteOlderThan(ctx, string(domains.LongestChain), time.Now().Add(time.Minute))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, deleted, len(exportTestHashes))
+}
+
+func TestConfirmationWatchesOfOwners(t *testing.T) {
+	// setup
+	_, adapter, log := createImportTestDatabase(t, "")
+	store := sql.NewHeadersDb(adapter.getDBx(), log)
+	ctx := context.Background()
+
+	// given
+	assert.NoError(t, store.CreateConfirmationWatches(ctx, "first", []string{exportTestHashes[0], exportTestHashes[1]}))
+	assert.NoError(t, store.CreateConfirmationWatches(ctx, "second", []string{exportTestHashes[0]}))
+
+	// when
+	err := store.DeleteConfirmationWatches(ctx, "second", []string{exportTestHashes[1]})
+
+	// then
+	assert.NoError(t, err)
+	watches, err := store.GetConfirmationWatches(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, len(watches), 3)
+
+	// when
+	err = store.TransferConfirmationWatches(ctx, "first", "rotated")
+
+	// then
+	assert.NoError(t, err)
+	watches, _ = store.GetConfirmationWatches(ctx)
+	owners := make(map[string]int)
+	for _, w := range watches {
+		owners[w.Owner]++
+	}
+	assert.Equal(t, owners["rotated"], 2)
+	assert.Equal(t, owners["second"], 1)
+}
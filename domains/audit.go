@@ -0,0 +1,88 @@
+package domains
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+)
+
+// AuditAction is an administrative or security-relevant action recorded in the audit log.
+type AuditAction string
+
+// Audited actions.
+const (
+	AuditTokenCreate       AuditAction = "token.create"
+	AuditTokenRotate       AuditAction = "token.rotate"
+	AuditTokenDelete       AuditAction = "token.delete"
+	AuditWebhookRegister   AuditAction = "webhook.register"
+	AuditWebhookRevoke     AuditAction = "webhook.revoke"
+	AuditMerkleRootWatch   AuditAction = "merkleroot.watch"
+	AuditMerkleRootUnwatch AuditAction = "merkleroot.unwatch"
+	AuditHeaderWatch       AuditAction = "header.watch"
+	AuditHeaderUnwatch     AuditAction = "header.unwatch"
+	AuditHeaderPrune       AuditAction = "header.prune"
+)
+
+// Results of audited actions.
+const (
+	AuditResultSuccess = "success"
+	AuditResultFailure = "failure"
+)
+
+// AuditActor identifies who performed an action.
+type AuditActor struct {
+	// TokenID is the id of the token of the request, "admin" for the admin token and empty when authorization is disabled.
+	TokenID string
+	// IP is the address the request came from.
+	IP string
+}
+
+// AuditRecord is an entry of the audit log.
+type AuditRecord struct {
+	// ID is ordered by the time of the record.
+	ID        string      `json:"id"`
+	Actor     string      `json:"actor"`
+	Action    AuditAction `json:"action"`
+	Target    string      `json:"target"`
+	SourceIP  string      `json:"sourceIp"`
+	Result    string      `json:"result"`
+	Error     string      `json:"error,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// AuditFilter selects records of the audit log, newest first.
+type AuditFilter struct {
+	Action AuditAction
+	Actor  string
+	// Before is the id of the record before which records are returned, for paging.
+	Before string
+	Limit  int
+}
+
+// CreateAuditRecord creates record of the action of the actor, err is the reason why the action failed.
+func CreateAuditRecord(actor AuditActor, action AuditAction, target string, err error) *AuditRecord {
+	now := time.Now().UTC()
+	record := &AuditRecord{
+		ID:        newAuditRecordID(now),
+		Actor:     actor.TokenID,
+		Action:    action,
+		Target:    target,
+		SourceIP:  actor.IP,
+		Result:    AuditResultSuccess,
+		CreatedAt: now,
+	}
+	if err != nil {
+		record.Result = AuditResultFailure
+		record.Error = err.Error()
+	}
+	return record
+}
+
+// newAuditRecordID returns id starting with the time of the record, so ids are sorted in the order of records.
+func newAuditRecordID(now time.Time) string {
+	var id [12]byte
+	binary.BigEndian.PutUint64(id[:8], uint64(now.UnixNano())) // #nosec G115
+	_, _ = rand.Read(id[8:])
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,120 @@
+package domains
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+)
+
+// bumpFlagDuplicate marks BUMP leaf which has no hash because it duplicates its sibling.
+const bumpFlagDuplicate = 0x01
+
+type bumpLeaf struct {
+	offset    uint64
+	duplicate bool
+	hash      chainhash.Hash
+}
+
+// MerkleRootFromBUMP computes merkle root and block height from BUMP (BRC-74) encoded as hex string.
+func MerkleRootFromBUMP(bump string) (*MerkleRootConfirmationRequestItem, error) {
+	b, err := hex.DecodeString(bump)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BUMP hex: %w", err)
+	}
+	r := bytes.NewReader(b)
+
+	blockHeight, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BUMP block height: %w", err)
+	}
+	treeHeight, err := r.ReadByte()
+	if err != nil || treeHeight == 0 {
+		return nil, errors.New("invalid BUMP tree height")
+	}
+
+	levels := make([]map[uint64]bumpLeaf, treeHeight)
+	for i := range levels {
+		if levels[i], err = readBUMPLevel(r); err != nil {
+			return nil, err
+		}
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("invalid BUMP: unexpected trailing bytes")
+	}
+
+	var leaf *bumpLeaf
+	for _, l := range levels[0] {
+		if l.duplicate {
+			continue
+		}
+		if leaf == nil || l.offset < leaf.offset {
+			leaf = &l
+		}
+	}
+	if leaf == nil {
+		return nil, errors.New("invalid BUMP: no leaves at the lowest level")
+	}
+
+	offset, hash := leaf.offset, leaf.hash
+	for _, level := range levels {
+		sibling, ok := level[offset^1]
+		if !ok {
+			return nil, fmt.Errorf("invalid BUMP: missing sibling of offset %d", offset)
+		}
+		siblingHash := sibling.hash
+		if sibling.duplicate {
+			siblingHash = hash
+		}
+
+		var buf [chainhash.HashSize * 2]byte
+		if offset%2 == 0 {
+			copy(buf[:chainhash.HashSize], hash[:])
+			copy(buf[chainhash.HashSize:], siblingHash[:])
+		} else {
+			copy(buf[:chainhash.HashSize], siblingHash[:])
+			copy(buf[chainhash.HashSize:], hash[:])
+		}
+		hash = chainhash.DoubleHashH(buf[:])
+		offset >>= 1
+	}
+
+	return &MerkleRootConfirmationRequestItem{
+		MerkleRoot:  hash.String(),
+		BlockHeight: int32(blockHeight),
+	}, nil
+}
+
+func readBUMPLevel(r *bytes.Reader) (map[uint64]bumpLeaf, error) {
+	nLeaves, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BUMP leaves count: %w", err)
+	}
+	if nLeaves > uint64(r.Len()) {
+		return nil, errors.New("invalid BUMP leaves count")
+	}
+
+	level := make(map[uint64]bumpLeaf, nLeaves)
+	for i := uint64(0); i < nLeaves; i++ {
+		var l bumpLeaf
+		if l.offset, err = wire.ReadVarInt(r, 0); err != nil {
+			return nil, fmt.Errorf("invalid BUMP leaf offset: %w", err)
+		}
+		flags, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("invalid BUMP leaf flags: %w", err)
+		}
+		l.duplicate = flags&bumpFlagDuplicate != 0
+		if !l.duplicate {
+			if _, err := io.ReadFull(r, l.hash[:]); err != nil {
+				return nil, fmt.Errorf("invalid BUMP leaf hash: %w", err)
+			}
+		}
+		level[l.offset] = l
+	}
+	return level, nil
+}
@@ -0,0 +1,63 @@
+package domains
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+const (
+	block170MerkleRoot = "7dac2c5666815c17a3b36427de37bb9d2e2c5ccec3f8633eb91a4205cb4c10ff"
+	block170Coinbase   = "b1fea52486ce0c62bb442b530a3f0132b826c74e473d1f2c220bfa78111c5082"
+	block170Tx         = "f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16"
+)
+
+func TestMerkleRootFromBUMP(t *testing.T) {
+	coinbase, _ := chainhash.NewHashFromStr(block170Coinbase)
+	tx, _ := chainhash.NewHashFromStr(block170Tx)
+
+	testCases := map[string]struct {
+		bump          string
+		expectedRoot  string
+		expectedError bool
+	}{
+		"path of second transaction": {
+			bump:         "aa01" + "02" + "0000" + hex.EncodeToString(coinbase[:]) + "0102" + hex.EncodeToString(tx[:]),
+			expectedRoot: block170MerkleRoot,
+		},
+		"path with duplicated sibling": {
+			bump:         "aa01" + "02" + "0002" + hex.EncodeToString(tx[:]) + "0101",
+			expectedRoot: chainhash.DoubleHashH(append(tx[:], tx[:]...)).String(),
+		},
+		"missing sibling": {
+			bump:          "aa01" + "01" + "0002" + hex.EncodeToString(tx[:]),
+			expectedError: true,
+		},
+		"not a hex": {
+			bump:          "zz",
+			expectedError: true,
+		},
+		"truncated": {
+			bump:          "aa01" + "02" + "0002" + hex.EncodeToString(tx[:16]),
+			expectedError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			item, err := MerkleRootFromBUMP(tc.bump)
+
+			// then
+			if tc.expectedError {
+				assert.Equal(t, err != nil, true)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, item.MerkleRoot, tc.expectedRoot)
+			assert.Equal(t, item.BlockHeight, int32(170))
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package domains
+
+// ConfirmationWatch represents header for which the owner wants to be notified
+// when it reaches (or drops below) the configured confirmation depth.
+type ConfirmationWatch struct {
+	// Owner is the ID of the token which created the watch, only the owner can see and delete it.
+	Owner string
+	Hash  string
+}
@@ -11,12 +11,27 @@ type HeaderEventType string
 const (
 	// EventHeaderAdded event type for header added.
 	EventHeaderAdded HeaderEventType = "ADD"
+	// EventHeaderConfirmed event type for header which reached the configured confirmation depth.
+	EventHeaderConfirmed HeaderEventType = "CONFIRMED"
+	// EventHeaderUnconfirmed event type for header which dropped below the configured confirmation depth.
+	EventHeaderUnconfirmed HeaderEventType = "UNCONFIRMED"
+	// EventChainReorganized event type for switching the longest chain to another branch.
+	EventChainReorganized HeaderEventType = "REORG"
 )
 
 // HeaderEvent represents header event data.
 type HeaderEvent struct {
-	Operation HeaderEventType     `json:"operation"`
-	Header    *HeaderEventDetails `json:"header"`
+	EventMetadata
+	Operation     HeaderEventType     `json:"operation"`
+	Header        *HeaderEventDetails `json:"header"`
+	Confirmations int32               `json:"confirmations,omitempty"`
+	Reorg         *ReorgDetails       `json:"reorg,omitempty"`
+}
+
+// ReorgDetails defines details of the longest chain reorganization.
+type ReorgDetails struct {
+	ForkHeight   int32    `json:"forkHeight"`
+	StaleHeaders []string `json:"staleHeaders"`
 }
 
 // HeaderEventDetails defines a header as a detailed part of an event.
@@ -36,16 +51,78 @@ type HeaderEventDetails struct {
 func HeaderAdded(h *BlockHeader) *HeaderEvent {
 	return &HeaderEvent{
 		Operation: EventHeaderAdded,
-		Header: &HeaderEventDetails{
-			Height:        h.Height,
-			Hash:          h.Hash.String(),
-			Version:       h.Version,
-			MerkleRoot:    h.MerkleRoot.String(),
-			Timestamp:     h.Timestamp,
-			Nonce:         h.Nonce,
-			State:         h.State,
-			CumulatedWork: h.CumulatedWork,
-			PreviousBlock: h.PreviousBlock.String(),
+		Header:    newHeaderEventDetails(h),
+	}
+}
+
+// HeaderConfirmed makes event from block header which reached the confirmation depth.
+func HeaderConfirmed(h *BlockHeader, confirmations int32) *HeaderEvent {
+	return &HeaderEvent{
+		Operation:     EventHeaderConfirmed,
+		Header:        newHeaderEventDetails(h),
+		Confirmations: confirmations,
+	}
+}
+
+// HeaderUnconfirmed makes event from block header which dropped below the confirmation depth.
+func HeaderUnconfirmed(h *BlockHeader, confirmations int32) *HeaderEvent {
+	return &HeaderEvent{
+		Operation:     EventHeaderUnconfirmed,
+		Header:        newHeaderEventDetails(h),
+		Confirmations: confirmations,
+	}
+}
+
+// ChainReorganized makes event from the new tip of the longest chain and headers which became stale.
+func ChainReorganized(tip *BlockHeader, forkHeight int32, staleHeaders []string) *HeaderEvent {
+	return &HeaderEvent{
+		Operation: EventChainReorganized,
+		Header:    newHeaderEventDetails(tip),
+		Reorg: &ReorgDetails{
+			ForkHeight:   forkHeight,
+			StaleHeaders: staleHeaders,
 		},
 	}
 }
+
+// ChainPosition represents the last header known by a client.
+type ChainPosition struct {
+	Height int32  `json:"height"`
+	Hash   string `json:"hash,omitempty"`
+}
+
+// MissedEvents represents header events missed by a client since its last known position.
+type MissedEvents struct {
+	Events []*HeaderEvent `json:"events"`
+	// Complete is false when there are more missed events than could be returned at once.
+	Complete bool `json:"complete"`
+	// Resync is set when the events since the position can't be recreated, ex. the header of the position
+	// is unknown or its branch is longer than the limit, so the client has to fetch the chain state again.
+	Resync bool `json:"resync,omitempty"`
+}
+
+func newHeaderEventDetails(h *BlockHeader) *HeaderEventDetails {
+	return &HeaderEventDetails{
+		Height:        h.Height,
+		Hash:          h.Hash.String(),
+		Version:       h.Version,
+		MerkleRoot:    h.MerkleRoot.String(),
+		Timestamp:     h.Timestamp,
+		Nonce:         h.Nonce,
+		State:         h.State,
+		CumulatedWork: h.CumulatedWork,
+		PreviousBlock: h.PreviousBlock.String(),
+	}
+}
+
+// EventMetadata identifies a single emitted event. It is assigned once when the event is emitted,
+// so every channel and format delivers the event with the same id and time.
+type EventMetadata struct {
+	ID   string    `json:"-"`
+	Time time.Time `json:"-"`
+}
+
+// Metadata returns metadata of the event.
+func (m *EventMetadata) Metadata() *EventMetadata {
+	return m
+}
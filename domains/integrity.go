@@ -0,0 +1,48 @@
+package domains
+
+// IntegrityIssueKind is the kind of inconsistency found in stored headers.
+type IntegrityIssueKind string
+
+// Kinds of integrity issues.
+const (
+	// IntegrityLongestChainCount is a height up to the tip without exactly one header of the longest chain.
+	IntegrityLongestChainCount IntegrityIssueKind = "longest_chain_count"
+	// IntegrityLongestChainLink is a header of the longest chain whose previous header isn't part of the longest chain.
+	IntegrityLongestChainLink IntegrityIssueKind = "longest_chain_link"
+	// IntegrityMissingParent is a header whose previous header isn't stored at the height below it.
+	IntegrityMissingParent IntegrityIssueKind = "missing_parent"
+	// IntegrityChainwork is a header whose chainwork doesn't match its bits.
+	IntegrityChainwork IntegrityIssueKind = "chainwork"
+	// IntegrityCumulatedWork is a header whose cumulated work isn't the cumulated work of its previous header plus its chainwork.
+	IntegrityCumulatedWork IntegrityIssueKind = "cumulated_work"
+	// IntegrityStaleBranchWork is a tip of a stale branch with more cumulated work than the tip of the longest chain.
+	IntegrityStaleBranchWork IntegrityIssueKind = "stale_branch_work"
+	// IntegrityOrphanWithParent is an orphan header whose previous header is stored in the chain.
+	IntegrityOrphanWithParent IntegrityIssueKind = "orphan_with_parent"
+)
+
+// IntegrityIssue is an inconsistency of a stored header.
+type IntegrityIssue struct {
+	Kind    IntegrityIssueKind `json:"kind"`
+	Height  int32              `json:"height"`
+	Hash    string             `json:"hash,omitempty"`
+	Details string             `json:"details"`
+}
+
+// IntegrityReport is the result of checking all stored headers.
+type IntegrityReport struct {
+	// TipHeight is the height of the tip of the longest chain.
+	TipHeight int32 `json:"tipHeight"`
+	// HeadersChecked is the number of checked headers.
+	HeadersChecked int `json:"headersChecked"`
+	// IssuesCount is the number of all found issues, only the first of them are listed in Issues.
+	IssuesCount int              `json:"issuesCount"`
+	Issues      []IntegrityIssue `json:"issues"`
+	// Repaired is the number of headers updated by the repair.
+	Repaired int `json:"repaired"`
+}
+
+// Consistent is true when no issues were found.
+func (r *IntegrityReport) Consistent() bool {
+	return r.IssuesCount == 0
+}
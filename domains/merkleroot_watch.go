@@ -0,0 +1,49 @@
+package domains
+
+import "time"
+
+// MerkleRootWatch represents merkle root for which clients want to be notified
+// when it is confirmed, invalidated or reaches the configured number of confirmations.
+type MerkleRootWatch struct {
+	// Owner is the ID of the token which created the watch, only the owner can see and delete it.
+	Owner                string                      `json:"-"`
+	MerkleRoot           string                      `json:"merkleRoot"`
+	BlockHeight          int32                       `json:"blockHeight"`
+	Confirmation         MerkleRootConfirmationState `json:"confirmation"`
+	ConfirmationsReached bool                        `json:"confirmationsReached"`
+	CreatedAt            time.Time                   `json:"createdAt"`
+}
+
+// NewMerkleRootWatch creates MerkleRootWatch of the owner for given merkle root and block height.
+func NewMerkleRootWatch(owner string, item MerkleRootConfirmationRequestItem) *MerkleRootWatch {
+	return &MerkleRootWatch{
+		Owner:       owner,
+		MerkleRoot:  item.MerkleRoot,
+		BlockHeight: item.BlockHeight,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// MerkleRootEventType type of merkle root event.
+type MerkleRootEventType string
+
+const (
+	// EventMerkleRootConfirmed event type for watched merkle root found in the longest chain.
+	EventMerkleRootConfirmed MerkleRootEventType = "MERKLEROOT_CONFIRMED"
+	// EventMerkleRootInvalid event type for watched merkle root which is not in the longest chain.
+	EventMerkleRootInvalid MerkleRootEventType = "MERKLEROOT_INVALID"
+	// EventMerkleRootConfirmationsReached event type for watched merkle root which reached the configured number of confirmations.
+	EventMerkleRootConfirmationsReached MerkleRootEventType = "MERKLEROOT_CONFIRMATIONS_REACHED"
+)
+
+// MerkleRootEvent represents watched merkle root event data.
+type MerkleRootEvent struct {
+	EventMetadata
+	Operation     MerkleRootEventType `json:"operation"`
+	MerkleRoot    string              `json:"merkleRoot"`
+	BlockHeight   int32               `json:"blockHeight"`
+	Hash          string              `json:"hash,omitempty"`
+	Confirmations int32               `json:"confirmations,omitempty"`
+	// Watcher is the id of the token which watches the merkle root, the event is published only to its channel.
+	Watcher string `json:"-"`
+}
@@ -0,0 +1,18 @@
+package domains
+
+// PruneReport is the result of pruning headers outside the longest chain.
+type PruneReport struct {
+	// TipHeight is the height of the tip of the longest chain, from which the depth of stale branches is counted.
+	TipHeight int32 `json:"tipHeight"`
+	// Stale is the number of pruned headers of stale branches.
+	Stale int `json:"stale"`
+	// Orphan is the number of pruned orphan headers.
+	Orphan int `json:"orphan"`
+	// Rejected is the number of pruned rejected headers.
+	Rejected int `json:"rejected"`
+}
+
+// Total returns the number of all pruned headers.
+func (r *PruneReport) Total() int {
+	return r.Stale + r.Orphan + r.Rejected
+}
@@ -0,0 +1,41 @@
+package domains
+
+import "time"
+
+// SyncEventType type of synchronization event.
+type SyncEventType string
+
+const (
+	// EventSyncProgress event type for progress of the initial synchronization of headers.
+	EventSyncProgress SyncEventType = "SYNC_PROGRESS"
+	// EventSyncCaughtUp event type for headers which became synchronized with the network.
+	EventSyncCaughtUp SyncEventType = "SYNC_CAUGHT_UP"
+)
+
+// SyncEvent represents synchronization event data, sent instead of header events
+// while headers are being synchronized.
+type SyncEvent struct {
+	EventMetadata
+	Operation SyncEventType `json:"operation"`
+	Height    int32         `json:"height"`
+	Hash      string        `json:"hash"`
+	Timestamp time.Time     `json:"creationTimestamp"`
+}
+
+// SyncProgressed makes event from the latest synchronized header.
+func SyncProgressed(h *HeaderEventDetails) *SyncEvent {
+	return newSyncEvent(EventSyncProgress, h)
+}
+
+// SyncCaughtUp makes event from the header with which headers became synchronized.
+func SyncCaughtUp(h *HeaderEventDetails) *SyncEvent {
+	return newSyncEvent(EventSyncCaughtUp, h)
+}
+
+func newSyncEvent(operation SyncEventType, h *HeaderEventDetails) *SyncEvent {
+	e := &SyncEvent{Operation: operation}
+	if h != nil {
+		e.Height, e.Hash, e.Timestamp = h.Height, h.Hash, h.Timestamp
+	}
+	return e
+}
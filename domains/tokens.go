@@ -1,28 +1,185 @@
 package domains
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
+// Scope is a permission granted to a token.
+type Scope string
+
+const (
+	// ScopeHeadersRead allows reading headers, tips and header events.
+	ScopeHeadersRead Scope = "headers:read"
+	// ScopeMerkleRootsVerify allows verifying and watching merkle roots.
+	ScopeMerkleRootsVerify Scope = "merkleroots:verify"
+	// ScopeWebhooksWrite allows registering and revoking webhooks and header confirmation watches.
+	ScopeWebhooksWrite Scope = "webhooks:write"
+	// ScopePeersAdmin allows managing and inspecting peers of the node.
+	ScopePeersAdmin Scope = "peers:admin"
+	// ScopeTokensAdmin allows creating and revoking tokens.
+	ScopeTokensAdmin Scope = "tokens:admin"
+)
+
+// AllScopes lists all scopes which can be granted to a token.
+var AllScopes = []Scope{ScopeHeadersRead, ScopeMerkleRootsVerify, ScopeWebhooksWrite, ScopePeersAdmin, ScopeTokensAdmin}
+
+// DefaultScopes are granted to tokens created without scopes, which gives them the same access as before scopes were introduced.
+var DefaultScopes = []Scope{ScopeHeadersRead, ScopeMerkleRootsVerify, ScopeWebhooksWrite, ScopePeersAdmin}
+
+// TokenPrefixLength is the number of characters of the token value kept to recognize the token.
+const TokenPrefixLength = 6
+
 // Token represents authorization token.
+// Only the hash of the token value is stored, so the value is known only when the token is created or used.
 type Token struct {
-	Token     string    `json:"token"`
-	CreatedAt time.Time `json:"createdAt"`
-	IsAdmin   bool      `json:"isAdmin"`
+	// Token is the value of the token.
+	Token string `json:"token,omitempty"`
+	// ID is the hash of the token value, which identifies the token without revealing it.
+	ID string `json:"id,omitempty"`
+	// Prefix is the beginning of the token value.
+	Prefix     string     `json:"prefix,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Owner      string     `json:"owner,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	IsAdmin    bool       `json:"isAdmin"`
+	Scopes     []Scope    `json:"scopes"`
+	// DailyQuota is the number of requests the token can make per day, 0 means the configured quota.
+	DailyQuota int64 `json:"dailyQuota,omitempty"`
+	// QuotaDay is the day (UTC) of QuotaUsed.
+	QuotaDay string `json:"-"`
+	// QuotaUsed is the number of requests made on QuotaDay.
+	QuotaUsed int64 `json:"-"`
+	// External is set for tokens which aren't stored by the service, ex. JWTs issued by an identity provider.
+	External bool `json:"external,omitempty"`
+}
+
+// TokenOptions describes token to create.
+type TokenOptions struct {
+	Name      string
+	Owner     string
+	ExpiresAt *time.Time
+	// DailyQuota is the number of requests the token can make per day, 0 means the configured quota.
+	DailyQuota int64
+	// Scopes granted to the token, DefaultScopes are granted when empty.
+	Scopes []Scope
 }
 
-// CreateToken creates new token.
-func CreateToken(value string) *Token {
+// CreateToken creates new token with given options.
+func CreateToken(value string, opts TokenOptions) *Token {
+	scopes := opts.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
 	return &Token{
-		Token:     value,
-		CreatedAt: time.Now(),
+		Token:      value,
+		ID:         HashToken(value),
+		Prefix:     TokenPrefix(value),
+		Name:       opts.Name,
+		Owner:      opts.Owner,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  opts.ExpiresAt,
+		DailyQuota: opts.DailyQuota,
+		Scopes:     slices.Clone(scopes),
 	}
 }
 
+// AdminTokenID identifies the admin token, which has no id of its own, in audit records and watches.
+const AdminTokenID = "admin"
+
+// ActorID returns id identifying the token in audit records and as the owner of watches, AdminTokenID for the admin token.
+func (t *Token) ActorID() string {
+	if t.IsAdmin {
+		return AdminTokenID
+	}
+	return t.ID
+}
+
 // CreateAdminToken creates admin token.
 func CreateAdminToken(value string) *Token {
 	return &Token{
 		Token:   value,
 		IsAdmin: true,
+		Scopes:  slices.Clone(AllScopes),
+	}
+}
+
+// Rotate creates token with new value replacing this token. It keeps name, owner, scopes, quota
+// and expiration time of the token, so rotation doesn't extend the lifetime of the token.
+func (t *Token) Rotate(value string) *Token {
+	opts := TokenOptions{Name: t.Name, Owner: t.Owner, Scopes: t.Scopes, DailyQuota: t.DailyQuota}
+	rotated := CreateToken(value, opts)
+	rotated.QuotaDay, rotated.QuotaUsed = t.QuotaDay, t.QuotaUsed
+	rotated.ExpiresAt = t.ExpiresAt
+	return rotated
+}
+
+// IsExpired checks if the token expired at given time, tokens without expiration time never expire.
+func (t *Token) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// HashToken returns hex encoded SHA-256 hash of the token value, under which the token is stored.
+// Token values are random, so they don't need a salt.
+func HashToken(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+// TokenPrefix returns the beginning of the token value.
+func TokenPrefix(value string) string {
+	if len(value) <= TokenPrefixLength {
+		return value
+	}
+	return value[:TokenPrefixLength]
+}
+
+// HasScope checks if the token was granted the scope, admin token has all scopes.
+func (t *Token) HasScope(scope Scope) bool {
+	return t.IsAdmin || slices.Contains(t.Scopes, scope)
+}
+
+// ParseScopes parses and deduplicates scopes, returning an error for unknown ones.
+func ParseScopes(values []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(values))
+	for _, v := range values {
+		scope := Scope(strings.TrimSpace(v))
+		if !slices.Contains(AllScopes, scope) {
+			return nil, fmt.Errorf("unknown scope %s", v)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// JoinScopes returns scopes as a comma separated list.
+func JoinScopes(scopes []Scope) string {
+	values := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		values = append(values, string(s))
+	}
+	return strings.Join(values, ",")
+}
+
+// SplitScopes parses comma separated list of scopes, skipping unknown ones.
+// Empty list means the token was created before scopes were introduced, so it gets DefaultScopes.
+func SplitScopes(value string) []Scope {
+	if strings.TrimSpace(value) == "" {
+		return slices.Clone(DefaultScopes)
+	}
+	scopes := make([]Scope, 0)
+	for _, v := range strings.Split(value, ",") {
+		if scope := Scope(strings.TrimSpace(v)); slices.Contains(AllScopes, scope) {
+			scopes = append(scopes, scope)
+		}
 	}
+	return scopes
 }
@@ -1,13 +1,16 @@
 package testapp
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
 	"github.com/bitcoin-sv/block-headers-service/notification"
 	"github.com/bitcoin-sv/block-headers-service/repository"
@@ -74,6 +77,8 @@ func NewTestBlockHeaderService(t *testing.T, ops ...bhsOpt) (*TestBlockHeaderSer
 	}
 	defaultConfig := config.GetDefaultAppConfig()
 	cfg, _, _ := config.Load(defaultConfig)
+	// test webhooks are served locally
+	cfg.Webhook.Policy.DeniedCIDRs = nil
 
 	for _, opt := range ops {
 		switch opt := opt.(type) {
@@ -97,6 +102,10 @@ func NewTestBlockHeaderService(t *testing.T, ops ...bhsOpt) (*TestBlockHeaderSer
 		AdminToken:   cfg.HTTP.AuthToken,
 		Logger:       &testLog,
 		Config:       cfg,
+		// test chains are far below the checkpoints and were mined in 2009, so headers of the test chains
+		// are considered synchronized only without checkpoints and with the clock of the test chains
+		Checkpoints: []chaincfg.Checkpoint{},
+		TimeSource:  testChainClock{},
 	})
 
 	for _, opt := range ops {
@@ -113,14 +122,16 @@ func NewTestBlockHeaderService(t *testing.T, ops ...bhsOpt) (*TestBlockHeaderSer
 	server.ApplyConfiguration(endpoints.SetupRoutes(hs, cfg.HTTP))
 	engine := hijackEngine(server)
 
-	ws, err := websocket.NewServer(&testLog, hs, cfg.HTTP.UseAuth)
+	ws, err := websocket.NewServer(&testLog, hs, cfg.HTTP.UseAuth, cfg.Websocket)
 	if err != nil {
 		t.Fatalf("failed to init a new websocket server: %v\n", err)
 	}
 	server.ApplyConfiguration(ws.SetupEntrypoint)
 
-	hs.Notifier.AddChannel(hs.Webhooks)
-	hs.Notifier.AddChannel(notification.NewWebsocketChannel(&testLog, ws.Publisher(), cfg.Websocket))
+	hs.Notifier.AddChannel("webhooks", hs.Webhooks)
+	hs.Notifier.AddChannel("merkleroot_watches", hs.MerkleRootWatches)
+	hs.Notifier.AddChannel("events", hs.EventStream)
+	hs.Notifier.AddChannel("websocket", notification.NewWebsocketChannel(&testLog, ws.Publisher(), ws.Subscriptions(), hs.EventEncoder, cfg.Websocket))
 
 	if err := ws.Start(); err != nil {
 		panic(fmt.Sprintf("cannot start websocket server because of an error: %v", err))
@@ -146,6 +157,12 @@ func NewTestBlockHeaderService(t *testing.T, ops ...bhsOpt) (*TestBlockHeaderSer
 	}
 
 	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := hs.Notifier.Shutdown(ctx); err != nil {
+			t.Fatalf("failed to deliver pending notifications: %v", err)
+		}
+
 		if err := ws.Shutdown(); err != nil {
 			t.Fatalf("failed to stop websocket server: %v", err)
 		}
@@ -165,3 +182,16 @@ func hijackEngine(server *httpserver.HTTPServer) *gin.Engine {
 	})
 	return engine
 }
+
+// testChainClock is the time source of the day after test chains were mined.
+type testChainClock struct{}
+
+func (testChainClock) AdjustedTime() time.Time {
+	return time.Date(2009, time.January, 10, 0, 0, 0, 0, time.UTC)
+}
+
+func (testChainClock) AddTimeSample(string, time.Time) {}
+
+func (testChainClock) Offset() time.Duration {
+	return 0
+}
@@ -2,6 +2,8 @@ package testapp
 
 import (
 	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testjwt"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
 )
 
@@ -25,3 +27,29 @@ func WithLongestChainFork() RepoOpt {
 		r.Headers.FillWithLongestChainWithFork()
 	}
 }
+
+// WithTokens adds tokens to the initialized tokens test repository.
+func WithTokens(tokens ...*domains.Token) RepoOpt {
+	return func(r *testrepository.TestRepositories) {
+		for _, t := range tokens {
+			_ = r.Tokens.AddTokenToDatabase(t)
+		}
+	}
+}
+
+// WithJWT enables authorization with JWTs signed by keys from the JWKS file.
+func WithJWT(jwksFile string) ConfigOpt {
+	return func(c *config.AppConfig) {
+		c.HTTP.JWT.Enabled = true
+		c.HTTP.JWT.JWKSFile = jwksFile
+		c.HTTP.JWT.Issuer = testjwt.IssuerURL
+		c.HTTP.JWT.Audience = testjwt.Audience
+	}
+}
+
+// WithClientCerts maps subjects of client certificates to scopes or tokens.
+func WithClientCerts(mappings ...config.ClientCertMapping) ConfigOpt {
+	return func(c *config.AppConfig) {
+		c.HTTP.TLS.ClientCerts = mappings
+	}
+}
@@ -0,0 +1,141 @@
+// Package testjwt provides an identity provider signing JWTs in tests.
+package testjwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	// IssuerURL is the issuer of JWTs signed in tests.
+	IssuerURL = "https://sso.example.com"
+	// Audience is the audience of JWTs signed in tests.
+	Audience = "block-headers-service"
+)
+
+// Issuer signs JWTs with a generated key.
+type Issuer struct {
+	Kid string
+	alg string
+	key crypto.Signer
+}
+
+// NewIssuer creates issuer signing with a new key for the algorithm [RS256|ES256|EdDSA].
+func NewIssuer(t *testing.T, kid, alg string) *Issuer {
+	t.Helper()
+	var key crypto.Signer
+	var err error
+	switch alg {
+	case "RS256":
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "EdDSA":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		t.Fatalf("unsupported algorithm %s", alg)
+	}
+	if err != nil {
+		t.Fatalf("cannot generate key: %v", err)
+	}
+	return &Issuer{Kid: kid, alg: alg, key: key}
+}
+
+// Claims returns valid claims of the subject with given scopes.
+func Claims(subject, scope string) map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss":   IssuerURL,
+		"aud":   Audience,
+		"sub":   subject,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"scope": scope,
+	}
+}
+
+// Sign returns JWT with the claims.
+func (i *Issuer) Sign(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	header := encodePart(t, map[string]any{"alg": i.alg, "typ": "JWT", "kid": i.Kid})
+	input := header + "." + encodePart(t, claims)
+
+	var signature []byte
+	var err error
+	switch k := i.key.(type) {
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(input))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(input))
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(k, []byte(input))
+	}
+	if err != nil {
+		t.Fatalf("cannot sign jwt: %v", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// JWK returns public key of the issuer as a JSON Web Key.
+func (i *Issuer) JWK() map[string]any {
+	jwk := map[string]any{"kid": i.Kid, "use": "sig", "alg": i.alg}
+	switch k := i.key.Public().(type) {
+	case *rsa.PublicKey:
+		jwk["kty"] = "RSA"
+		jwk["n"] = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+		jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+	case *ecdsa.PublicKey:
+		jwk["kty"] = "EC"
+		jwk["crv"] = "P-256"
+		jwk["x"] = base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, 32)))
+		jwk["y"] = base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		jwk["kty"] = "OKP"
+		jwk["crv"] = "Ed25519"
+		jwk["x"] = base64.RawURLEncoding.EncodeToString(k)
+	}
+	return jwk
+}
+
+// WriteJWKS writes key set with public keys of the issuers to a temporary file and returns its path.
+func WriteJWKS(t *testing.T, issuers ...*Issuer) string {
+	t.Helper()
+	keys := make([]map[string]any, 0, len(issuers))
+	for _, i := range issuers {
+		keys = append(keys, i.JWK())
+	}
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatalf("cannot encode jwks: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("cannot write jwks: %v", err)
+	}
+	return path
+}
+
+func encodePart(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("cannot encode jwt: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
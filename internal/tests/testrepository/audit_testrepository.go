@@ -0,0 +1,45 @@
+package testrepository
+
+import (
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// AuditTestRepository in memory AuditRepository representation for unit testing.
+type AuditTestRepository struct {
+	mu sync.Mutex
+	db *[]domains.AuditRecord
+}
+
+// AddAuditRecord appends record to the audit log.
+func (r *AuditTestRepository) AddAuditRecord(record *domains.AuditRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*r.db = append(*r.db, *record)
+	return nil
+}
+
+// GetAuditRecords returns records of the audit log matching the filter, newest first.
+func (r *AuditTestRepository) GetAuditRecords(filter domains.AuditFilter) ([]*domains.AuditRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	records := make([]*domains.AuditRecord, 0)
+	for i := len(*r.db) - 1; i >= 0 && len(records) < filter.Limit; i-- {
+		record := (*r.db)[i]
+		if (filter.Action != "" && record.Action != filter.Action) ||
+			(filter.Actor != "" && record.Actor != filter.Actor) ||
+			(filter.Before != "" && record.ID >= filter.Before) {
+			continue
+		}
+		records = append(records, &record)
+	}
+	return records, nil
+}
+
+// NewAuditTestRepository constructor for AuditTestRepository.
+func NewAuditTestRepository(db *[]domains.AuditRecord) *AuditTestRepository {
+	return &AuditTestRepository{
+		db: db,
+	}
+}
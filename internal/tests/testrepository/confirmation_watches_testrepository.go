@@ -0,0 +1,61 @@
+package testrepository
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// ConfirmationWatchesTestRepository in memory ConfirmationWatchesRepository representation for unit testing.
+type ConfirmationWatchesTestRepository struct {
+	mu sync.Mutex
+	db *[]domains.ConfirmationWatch
+}
+
+// AddConfirmationWatches adds hashes of headers watched by the owner to db.
+func (r *ConfirmationWatchesTestRepository) AddConfirmationWatches(owner string, hashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, hash := range hashes {
+		w := domains.ConfirmationWatch{Owner: owner, Hash: hash}
+		if !slices.Contains(*r.db, w) {
+			*r.db = append(*r.db, w)
+		}
+	}
+	return nil
+}
+
+// DeleteConfirmationWatches deletes hashes of headers watched by the owner from db.
+func (r *ConfirmationWatchesTestRepository) DeleteConfirmationWatches(owner string, hashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*r.db = slices.DeleteFunc(*r.db, func(w domains.ConfirmationWatch) bool {
+		return w.Owner == owner && slices.Contains(hashes, w.Hash)
+	})
+	return nil
+}
+
+// GetConfirmationWatches returns all watched headers with their owners from db.
+func (r *ConfirmationWatchesTestRepository) GetConfirmationWatches() ([]domains.ConfirmationWatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(*r.db), nil
+}
+
+// TransferConfirmationWatches changes the owner of watched headers in db.
+func (r *ConfirmationWatchesTestRepository) TransferConfirmationWatches(from, to string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range *r.db {
+		if (*r.db)[i].Owner == from {
+			(*r.db)[i].Owner = to
+		}
+	}
+	return nil
+}
+
+// NewConfirmationWatchesTestRepository constructor for ConfirmationWatchesTestRepository.
+func NewConfirmationWatchesTestRepository(db *[]domains.ConfirmationWatch) *ConfirmationWatchesTestRepository {
+	return &ConfirmationWatchesTestRepository{db: db}
+}
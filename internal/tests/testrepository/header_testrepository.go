@@ -4,6 +4,7 @@ import (
 	"errors"
 	"slices"
 	"sort"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/domains"
@@ -14,6 +15,13 @@ import (
 // HeaderTestRepository in memory HeadersRepository representation for unit testing.
 type HeaderTestRepository struct {
 	db *[]domains.BlockHeader
+	// storedAt is the time headers were stored, headers without it are treated as stored just now.
+	storedAt map[chainhash.Hash]time.Time
+}
+
+// SetStoredAt sets the time the header was stored, which is compared by DeleteHeadersOlderThan.
+func (r *HeaderTestRepository) SetStoredAt(hash chainhash.Hash, storedAt time.Time) {
+	r.storedAt[hash] = storedAt
 }
 
 // AddHeaderToDatabase adds new header to db.
@@ -46,6 +54,40 @@ func (r *HeaderTestRepository) UpdateState(hs []chainhash.Hash, s domains.Header
 	return nil
 }
 
+// UpdateChain changes height, state and work of headers to the provided ones.
+func (r *HeaderTestRepository) UpdateChain(headers []domains.BlockHeader) error {
+	for _, h := range headers {
+		for i, hdb := range *r.db {
+			if h.Hash == hdb.Hash {
+				(*r.db)[i].Height = h.Height
+				(*r.db)[i].State = h.State
+				(*r.db)[i].Chainwork = h.Chainwork
+				(*r.db)[i].CumulatedWork = h.CumulatedWork
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteHeaders removes headers with provided hashes and returns the number of removed ones.
+func (r *HeaderTestRepository) DeleteHeaders(hashes []chainhash.Hash) (int, error) {
+	before := len(*r.db)
+	*r.db = slices.DeleteFunc(*r.db, func(h domains.BlockHeader) bool {
+		return slices.Contains(hashes, h.Hash)
+	})
+	return before - len(*r.db), nil
+}
+
+// DeleteHeadersOlderThan removes headers in provided state stored before provided time and returns the number of removed ones.
+func (r *HeaderTestRepository) DeleteHeadersOlderThan(state domains.HeaderState, before time.Time) (int, error) {
+	count := len(*r.db)
+	*r.db = slices.DeleteFunc(*r.db, func(h domains.BlockHeader) bool {
+		storedAt, ok := r.storedAt[h.Hash]
+		return h.State == state && ok && storedAt.Before(before)
+	})
+	return count - len(*r.db), nil
+}
+
 // GetHeaderByHeight returns header from db by given height.
 func (r *HeaderTestRepository) GetHeaderByHeight(height int32) (*domains.BlockHeader, error) {
 	for _, header := range *r.db {
@@ -308,7 +350,7 @@ func (r *HeaderTestRepository) GetMerkleRootsConfirmations(
 		}
 
 		for _, h := range *r.db {
-			if h.MerkleRoot.String() == rq.MerkleRoot && h.Height == rq.BlockHeight {
+			if h.MerkleRoot.String() == rq.MerkleRoot && h.Height == rq.BlockHeight && h.State == domains.LongestChain {
 				found = true
 				confm.Hash = h.Hash.String()
 				confm.Confirmation = domains.Confirmed
@@ -403,6 +445,7 @@ func findHeader(hash string, headers []domains.BlockHeader) *domains.BlockHeader
 // NewHeadersTestRepository constructor for HeaderTestRepository.
 func NewHeadersTestRepository(db *[]domains.BlockHeader) *HeaderTestRepository {
 	return &HeaderTestRepository{
-		db: db,
+		db:       db,
+		storedAt: make(map[chainhash.Hash]time.Time),
 	}
 }
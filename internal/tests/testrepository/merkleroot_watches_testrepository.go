@@ -0,0 +1,106 @@
+package testrepository
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// MerkleRootWatchesTestRepository in memory MerkleRootWatchesRepository representation for unit testing.
+type MerkleRootWatchesTestRepository struct {
+	mu sync.Mutex
+	db *[]domains.MerkleRootWatch
+}
+
+// AddMerkleRootWatch adds new merkle root watch to db.
+func (r *MerkleRootWatchesTestRepository) AddMerkleRootWatch(watch *domains.MerkleRootWatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.find(watch.Owner, watch.MerkleRoot, watch.BlockHeight) >= 0 {
+		return fmt.Errorf("merkle root watch %s at height %d already exists", watch.MerkleRoot, watch.BlockHeight)
+	}
+	*r.db = append(*r.db, *watch)
+	return nil
+}
+
+// DeleteMerkleRootWatch deletes merkle root watch of the owner from db.
+func (r *MerkleRootWatchesTestRepository) DeleteMerkleRootWatch(owner, merkleRoot string, blockHeight int32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.find(owner, merkleRoot, blockHeight)
+	if i < 0 {
+		return bhserrors.ErrMerklerootWatchNotFound
+	}
+	*r.db = append((*r.db)[:i], (*r.db)[i+1:]...)
+	return nil
+}
+
+// GetAllMerkleRootWatches returns all merkle root watches from db.
+func (r *MerkleRootWatchesTestRepository) GetAllMerkleRootWatches() ([]*domains.MerkleRootWatch, error) {
+	return r.filter(func(*domains.MerkleRootWatch) bool { return true }), nil
+}
+
+// GetMerkleRootWatches returns merkle root watches of the owner from db.
+func (r *MerkleRootWatchesTestRepository) GetMerkleRootWatches(owner string) ([]*domains.MerkleRootWatch, error) {
+	return r.filter(func(w *domains.MerkleRootWatch) bool { return w.Owner == owner }), nil
+}
+
+// GetMerkleRootWatchesByHeight returns merkle root watches at block heights from the given range from db.
+func (r *MerkleRootWatchesTestRepository) GetMerkleRootWatchesByHeight(from, to int32) ([]*domains.MerkleRootWatch, error) {
+	return r.filter(func(w *domains.MerkleRootWatch) bool { return w.BlockHeight >= from && w.BlockHeight <= to }), nil
+}
+
+// UpdateMerkleRootWatch updates state of merkle root watch in db.
+func (r *MerkleRootWatchesTestRepository) UpdateMerkleRootWatch(watch *domains.MerkleRootWatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.find(watch.Owner, watch.MerkleRoot, watch.BlockHeight)
+	if i < 0 {
+		return bhserrors.ErrMerklerootWatchNotFound
+	}
+	(*r.db)[i] = *watch
+	return nil
+}
+
+// TransferMerkleRootWatches changes the owner of merkle root watches.
+func (r *MerkleRootWatchesTestRepository) TransferMerkleRootWatches(from, to string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range *r.db {
+		if (*r.db)[i].Owner == from {
+			(*r.db)[i].Owner = to
+		}
+	}
+	return nil
+}
+
+func (r *MerkleRootWatchesTestRepository) filter(matches func(*domains.MerkleRootWatch) bool) []*domains.MerkleRootWatch {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	watches := make([]*domains.MerkleRootWatch, 0, len(*r.db))
+	for _, w := range *r.db {
+		watch := w
+		if matches(&watch) {
+			watches = append(watches, &watch)
+		}
+	}
+	return watches
+}
+
+func (r *MerkleRootWatchesTestRepository) find(owner, merkleRoot string, blockHeight int32) int {
+	for i, w := range *r.db {
+		if w.Owner == owner && w.MerkleRoot == merkleRoot && w.BlockHeight == blockHeight {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewMerkleRootWatchesTestRepository constructor for MerkleRootWatchesTestRepository.
+func NewMerkleRootWatchesTestRepository(db *[]domains.MerkleRootWatch) *MerkleRootWatchesTestRepository {
+	return &MerkleRootWatchesTestRepository{
+		db: db,
+	}
+}
@@ -2,6 +2,7 @@ package testrepository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/domains"
 )
@@ -13,24 +14,66 @@ type TokensTestRepository struct {
 
 // AddTokenToDatabase adds new token to db.
 func (r *TokensTestRepository) AddTokenToDatabase(token *domains.Token) error {
-	*r.db = append(*r.db, *token)
+	stored := *token
+	// only the hash of the token value is stored
+	stored.Token = ""
+	*r.db = append(*r.db, stored)
 	return nil
 }
 
-// GetTokenByValue returns token from db by given value.
-func (r *TokensTestRepository) GetTokenByValue(token string) (*domains.Token, error) {
+// GetTokenByHash returns token from db by hash of its value.
+func (r *TokensTestRepository) GetTokenByHash(hash string) (*domains.Token, error) {
 	for _, t := range *r.db {
-		if t.Token == token {
+		if t.ID == hash {
 			return &t, nil
 		}
 	}
 	return nil, errors.New("could not find token")
 }
 
+// GetTokens returns all tokens from db.
+func (r *TokensTestRepository) GetTokens() ([]*domains.Token, error) {
+	tokens := make([]*domains.Token, 0, len(*r.db))
+	for _, t := range *r.db {
+		tokens = append(tokens, &t)
+	}
+	return tokens, nil
+}
+
+// UpdateTokenLastUsed sets the time when the token was used for the last time.
+func (r *TokensTestRepository) UpdateTokenLastUsed(hash string, lastUsedAt time.Time) error {
+	for i := range *r.db {
+		if (*r.db)[i].ID == hash {
+			(*r.db)[i].LastUsedAt = &lastUsedAt
+			return nil
+		}
+	}
+	return errors.New("could not find token")
+}
+
+// UpdateTokenQuotaUsage saves the number of requests made by the token on given day.
+func (r *TokensTestRepository) UpdateTokenQuotaUsage(hash string, day string, used int64) error {
+	for i := range *r.db {
+		if (*r.db)[i].ID == hash {
+			(*r.db)[i].QuotaDay, (*r.db)[i].QuotaUsed = day, used
+			return nil
+		}
+	}
+	return errors.New("could not find token")
+}
+
+// RotateToken replaces token with given hash with a new token.
+func (r *TokensTestRepository) RotateToken(hash string, token *domains.Token) error {
+	if err := r.DeleteToken(hash); err != nil {
+		return err
+	}
+	return r.AddTokenToDatabase(token)
+}
+
 // DeleteToken deletes token from db.
-func (r *TokensTestRepository) DeleteToken(token string) error {
+func (r *TokensTestRepository) DeleteToken(hash string) error {
 	for i, t := range *r.db {
-		if t.Token == token {
+		if t.ID == hash {
 			arr := *r.db
 			// Replace the element at index i with the last element.
 			arr[i] = arr[len(arr)-1]
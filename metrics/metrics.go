@@ -10,6 +10,9 @@ type Metrics struct {
 	registerer   prometheus.Registerer
 	httpRequests *RequestMetrics
 	latestBlock  *latestBlockMetrics
+	notification *notificationMetrics
+	rateLimit    *rateLimitMetrics
+	prune        *pruneMetrics
 }
 
 func newMetrics() *Metrics {
@@ -22,6 +25,9 @@ func newMetrics() *Metrics {
 		registerer:   registererWithLabels,
 		httpRequests: registerRequestMetrics(registererWithLabels),
 		latestBlock:  registerLatestBlockMetrics(registererWithLabels),
+		notification: registerNotificationMetrics(registererWithLabels),
+		rateLimit:    registerRateLimitMetrics(registererWithLabels),
+		prune:        registerPruneMetrics(registererWithLabels),
 	}
 
 	return m
@@ -11,3 +11,15 @@ const domainPrefix = "bsv_"
 const latestBlockBaseName = domainPrefix + "latest_block"
 const latestBlockHeightName = latestBlockBaseName + "_height"
 const latestBlockTimestampName = latestBlockBaseName + "_timestamp"
+
+const notificationBaseName = domainPrefix + "notification"
+const notificationQueueLengthName = notificationBaseName + "_queue_length"
+const notificationEventsName = notificationBaseName + "_events_total"
+const notificationDeliveryDurationSecName = notificationBaseName + "_delivery_duration_seconds"
+
+const rateLimitBaseName = domainPrefix + "rate_limit"
+const rateLimitThrottledName = rateLimitBaseName + "_throttled_total"
+
+const pruneBaseName = domainPrefix + "prune"
+const prunedHeadersName = pruneBaseName + "_headers_total"
+const pruneDurationSecName = pruneBaseName + "_duration_seconds"
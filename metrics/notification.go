@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type notificationMetrics struct {
+	queueLength      *prometheus.GaugeVec
+	events           *prometheus.CounterVec
+	deliveryDuration *prometheus.HistogramVec
+}
+
+func registerNotificationMetrics(reg prometheus.Registerer) *notificationMetrics {
+	return &notificationMetrics{
+		queueLength:      registerGaugeVec(reg, notificationQueueLengthName, []string{"channel"}),
+		events:           registerCounterVec(reg, notificationEventsName, []string{"channel", "result"}),
+		deliveryDuration: registerDurationHistogram(reg, notificationDeliveryDurationSecName, []string{"channel"}),
+	}
+}
+
+// SetNotificationQueueLength sets the number of events waiting in the queue of the notification channel.
+func SetNotificationQueueLength(channel string, length int) {
+	if metrics, enabled := Get(); enabled {
+		metrics.notification.queueLength.WithLabelValues(channel).Set(float64(length))
+	}
+}
+
+// AddNotificationEvents counts events of the notification channel with given result [delivered|dropped|coalesced].
+func AddNotificationEvents(channel, result string, count int) {
+	if metrics, enabled := Get(); enabled {
+		metrics.notification.events.WithLabelValues(channel, result).Add(float64(count))
+	}
+}
+
+// ObserveNotificationDelivery records the duration of passing a single event to the notification channel.
+func ObserveNotificationDelivery(channel string, duration time.Duration) {
+	if metrics, enabled := Get(); enabled {
+		metrics.notification.deliveryDuration.WithLabelValues(channel).Observe(duration.Seconds())
+	}
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type pruneMetrics struct {
+	headers  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func registerPruneMetrics(reg prometheus.Registerer) *pruneMetrics {
+	return &pruneMetrics{
+		headers:  registerCounterVec(reg, prunedHeadersName, []string{"state"}),
+		duration: registerDurationHistogram(reg, pruneDurationSecName, []string{"trigger"}),
+	}
+}
+
+// AddPrunedHeaders counts headers in the state [STALE|ORPHAN|REJECTED] removed by the prune.
+func AddPrunedHeaders(state string, count int) {
+	if metrics, enabled := Get(); enabled {
+		metrics.prune.headers.WithLabelValues(state).Add(float64(count))
+	}
+}
+
+// ObservePrune records the duration of the prune started by the trigger [periodic|admin].
+func ObservePrune(trigger string, duration time.Duration) {
+	if metrics, enabled := Get(); enabled {
+		metrics.prune.duration.WithLabelValues(trigger).Observe(duration.Seconds())
+	}
+}
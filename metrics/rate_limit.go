@@ -0,0 +1,20 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type rateLimitMetrics struct {
+	throttled *prometheus.CounterVec
+}
+
+func registerRateLimitMetrics(reg prometheus.Registerer) *rateLimitMetrics {
+	return &rateLimitMetrics{
+		throttled: registerCounterVec(reg, rateLimitThrottledName, []string{"transport", "group", "reason"}),
+	}
+}
+
+// AddThrottledRequest counts a request of the transport [http|websocket] rejected in the group for the reason [rate|quota].
+func AddThrottledRequest(transport, group, reason string) {
+	if metrics, enabled := Get(); enabled {
+		metrics.rateLimit.throttled.WithLabelValues(transport, group, reason).Inc()
+	}
+}
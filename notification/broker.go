@@ -0,0 +1,216 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/rs/zerolog"
+)
+
+// brokerClientID identifies the service in connections to brokers.
+const brokerClientID = "block-headers-service"
+
+// BrokerMessage represents a single event published to the message broker.
+type BrokerMessage struct {
+	// ID is the unique id of the event, the same for all retries of the event, so brokers can deduplicate them.
+	ID string
+	// Subject is the NATS subject, Redis stream or Kafka topic of the message.
+	Subject string
+	// Key identifies the source of the event, ex. hash of the header, used for partitioning and deduplication.
+	Key string
+	// Name is the type of the event.
+	Name string
+	// Data is the event encoded in the configured format.
+	Data []byte
+}
+
+// BrokerPublisher is the interface of a message broker client.
+type BrokerPublisher interface {
+	// Publish sends the message and returns after the broker acknowledged it was persisted.
+	Publish(ctx context.Context, msg *BrokerMessage) error
+	// Close closes the connection to the broker.
+	Close() error
+}
+
+// BrokerChannel is a Channel publishing events to a message broker with at-least-once semantics:
+// every event is retried until the broker acknowledges it or max retries is reached, before the next event is published.
+type BrokerChannel struct {
+	publisher BrokerPublisher
+	cfg       *config.BrokerConfig
+	encoder   *EventEncoder
+	format    EventFormat
+	done      chan struct{}
+	closeOnce sync.Once
+	log       *zerolog.Logger
+}
+
+// NewBrokerChannel creates BrokerChannel publishing to the broker configured in cfg.
+func NewBrokerChannel(cfg *config.BrokerConfig, encoder *EventEncoder, log *zerolog.Logger) (*BrokerChannel, error) {
+	publisher, err := newBrokerPublisher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewBrokerChannelWithPublisher(publisher, cfg, encoder, log), nil
+}
+
+// NewBrokerChannelWithPublisher creates BrokerChannel publishing with given publisher.
+// Events are published in the format from cfg, unknown formats fall back to plain JSON.
+func NewBrokerChannelWithPublisher(publisher BrokerPublisher, cfg *config.BrokerConfig, encoder *EventEncoder, log *zerolog.Logger) *BrokerChannel {
+	brokerLogger := log.With().Str("subservice", "broker").Str("broker", string(cfg.Type)).Logger()
+	format, err := ParseEventFormat(cfg.Format)
+	if err != nil || format == FormatCloudEventsBinary {
+		brokerLogger.Warn().Msgf("Unsupported broker format %s, publishing events as %s", cfg.Format, FormatJSON)
+		format = FormatJSON
+	}
+	return &BrokerChannel{
+		publisher: publisher,
+		cfg:       cfg,
+		encoder:   encoder,
+		format:    format,
+		done:      make(chan struct{}),
+		log:       &brokerLogger,
+	}
+}
+
+func newBrokerPublisher(cfg *config.BrokerConfig) (BrokerPublisher, error) {
+	switch cfg.Type {
+	case config.BrokerNATS:
+		return newNatsPublisher(cfg)
+	case config.BrokerRedis:
+		return newRedisPublisher(cfg)
+	case config.BrokerKafka:
+		return newKafkaPublisher(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported broker type %s", cfg.Type)
+	}
+}
+
+// brokerTLSConfig returns TLS config of the connection to the broker, or nil when TLS is not enabled.
+func brokerTLSConfig(cfg *config.BrokerTLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec // explicitly enabled in the config for testing
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read broker ca file: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in broker ca file %s", cfg.CAFile)
+		}
+	}
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load broker client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// Close stops publishing and closes the connection to the broker.
+func (b *BrokerChannel) Close() error {
+	b.closeOnce.Do(func() { close(b.done) })
+	return b.publisher.Close()
+}
+
+// Notify publishes event and returns when the broker acknowledged it or retries are exhausted, so while the broker
+// is unavailable following events wait in the notifier queue of the channel.
+func (b *BrokerChannel) Notify(event Event) {
+	select {
+	case <-b.done:
+		b.log.Warn().Msgf("Broker channel is closed, event %s was not published", EventName(event))
+		return
+	default:
+	}
+
+	msg, err := b.message(event)
+	if err != nil {
+		b.log.Error().Msgf("Cannot prepare event %s for publishing: %v", EventName(event), err)
+		return
+	}
+
+	for attempt := 0; attempt <= b.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(b.cfg.RetryInterval):
+			case <-b.done:
+				b.log.Warn().Msgf("Broker channel closed before %s was acknowledged", msg.Subject)
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), b.cfg.PublishTimeout)
+		err = b.publisher.Publish(ctx, msg)
+		cancel()
+		if err == nil {
+			return
+		}
+		b.log.Warn().Msgf("Publishing to %s failed (attempt %d): %v", msg.Subject, attempt+1, err)
+	}
+	b.log.Error().Msgf("Event %s was not published to %s after %d retries", msg.Name, msg.Subject, b.cfg.MaxRetries)
+}
+
+func (b *BrokerChannel) message(event Event) (*BrokerMessage, error) {
+	data, err := b.encoder.Encode(event, b.format)
+	if err != nil {
+		return nil, err
+	}
+	id, _ := eventIdentity(event)
+	return &BrokerMessage{
+		ID:      id,
+		Subject: BrokerSubject(b.cfg.SubjectTemplate, event),
+		Key:     eventKey(event),
+		Name:    EventName(event),
+		Data:    data,
+	}, nil
+}
+
+// BrokerSubject renders subject template for the event. Supported placeholders are
+// {type} (header, merkleroot, sync or event), {operation} and {state}, all in lower case.
+func BrokerSubject(template string, event Event) string {
+	eventType, state := "event", ""
+	switch e := event.(type) {
+	case *domains.HeaderEvent:
+		eventType = "header"
+		if e.Header != nil {
+			state = string(e.Header.State)
+		}
+	case *domains.MerkleRootEvent:
+		eventType = "merkleroot"
+	case *domains.SyncEvent:
+		eventType = "sync"
+	}
+
+	return strings.NewReplacer(
+		"{type}", eventType,
+		"{operation}", strings.ToLower(EventName(event)),
+		"{state}", strings.ToLower(state),
+	).Replace(template)
+}
+
+func eventKey(event Event) string {
+	switch e := event.(type) {
+	case *domains.HeaderEvent:
+		if e.Header != nil {
+			return e.Header.Hash
+		}
+	case *domains.MerkleRootEvent:
+		return e.MerkleRoot
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// kafkaPublisher produces messages to Kafka with acks=all to the partition chosen by the message key,
+// so events of a single header stay in order.
+type kafkaPublisher struct {
+	client *kgo.Client
+}
+
+func newKafkaPublisher(cfg *config.BrokerConfig) (*kafkaPublisher, error) {
+	opts, err := kafkaOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create kafka client: %w", err)
+	}
+	return &kafkaPublisher{client: client}, nil
+}
+
+func kafkaOptions(cfg *config.BrokerConfig) ([]kgo.Opt, error) {
+	seeds := kafkaSeedBrokers(cfg.URL)
+	if len(seeds) == 0 {
+		return nil, errors.New("kafka url should contain at least one broker address")
+	}
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(seeds...),
+		kgo.ClientID(brokerClientID),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.ProduceRequestTimeout(cfg.PublishTimeout),
+	}
+	tlsConfig, err := brokerTLSConfig(&cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, kgo.DialTLSConfig(tlsConfig))
+	}
+	if mechanism := kafkaSASL(cfg); mechanism != nil {
+		opts = append(opts, kgo.SASL(mechanism))
+	}
+	return opts, nil
+}
+
+// kafkaSeedBrokers returns addresses of brokers from url, ex. kafka://host1:9092,host2:9092.
+func kafkaSeedBrokers(url string) []string {
+	hosts := strings.TrimPrefix(url, "kafka://")
+	hosts, _, _ = strings.Cut(hosts, "/")
+	seeds := make([]string, 0)
+	for _, host := range strings.Split(hosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			seeds = append(seeds, host)
+		}
+	}
+	return seeds
+}
+
+func kafkaSASL(cfg *config.BrokerConfig) sasl.Mechanism {
+	if cfg.Username == "" {
+		return nil
+	}
+	switch cfg.KafkaSASLMechanism {
+	case config.KafkaSASLScramSHA256:
+		return scram.Auth{User: cfg.Username, Pass: cfg.Password}.AsSha256Mechanism()
+	case config.KafkaSASLScramSHA512:
+		return scram.Auth{User: cfg.Username, Pass: cfg.Password}.AsSha512Mechanism()
+	default:
+		return plain.Auth{User: cfg.Username, Pass: cfg.Password}.AsMechanism()
+	}
+}
+
+// Publish produces the message to the topic named after the message subject and waits
+// until all in-sync replicas acknowledged it.
+func (p *kafkaPublisher) Publish(ctx context.Context, msg *BrokerMessage) error {
+	record := &kgo.Record{
+		Topic: msg.Subject,
+		Key:   []byte(msg.Key),
+		Value: msg.Data,
+		Headers: []kgo.RecordHeader{
+			{Key: "id", Value: []byte(msg.ID)},
+			{Key: "event", Value: []byte(msg.Name)},
+		},
+	}
+	return p.client.ProduceSync(ctx, record).FirstErr()
+}
+
+// Close flushes and closes connections to Kafka.
+func (p *kafkaPublisher) Close() error {
+	p.client.Close()
+	return nil
+}
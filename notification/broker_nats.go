@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/nats-io/nats.go"
+)
+
+// natsPublisher publishes messages to NATS JetStream. A message is considered published
+// only after JetStream acknowledged it was stored in a stream.
+type natsPublisher struct {
+	conn *nats.Conn
+	js   nats.JetStreamContext
+}
+
+// newNatsPublisher connects to NATS in the background, so the service starts while NATS is unavailable
+// and publishing fails until the connection is established.
+func newNatsPublisher(cfg *config.BrokerConfig) (*natsPublisher, error) {
+	opts := []nats.Option{
+		nats.Name(brokerClientID),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+	}
+	if cfg.Username != "" {
+		opts = append(opts, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+	if cfg.NATSCredentialsFile != "" {
+		opts = append(opts, nats.UserCredentials(cfg.NATSCredentialsFile))
+	}
+	tlsConfig, err := brokerTLSConfig(&cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, nats.Secure(tlsConfig))
+	}
+
+	conn, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to nats: %w", err)
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("cannot use nats jetstream: %w", err)
+	}
+	return &natsPublisher{conn: conn, js: js}, nil
+}
+
+// Publish sends the message to JetStream and waits for the acknowledgment. The message id header
+// is the id of the event, so JetStream deduplicates messages retried after a lost acknowledgment.
+func (p *natsPublisher) Publish(ctx context.Context, msg *BrokerMessage) error {
+	m := nats.NewMsg(msg.Subject)
+	m.Data = msg.Data
+	m.Header.Set("Bhs-Event", msg.Name)
+	if msg.Key != "" {
+		m.Header.Set("Bhs-Key", msg.Key)
+	}
+	_, err := p.js.PublishMsg(m, nats.MsgId(msg.ID), nats.Context(ctx))
+	if err != nil {
+		return fmt.Errorf("jetstream did not acknowledge message of %s: %w", msg.Subject, err)
+	}
+	return nil
+}
+
+// Close closes the connection to NATS.
+func (p *natsPublisher) Close() error {
+	p.conn.Close()
+	return nil
+}
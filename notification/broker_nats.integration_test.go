@@ -0,0 +1,101 @@
+//go:build integration
+
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/nats-io/nats.go"
+)
+
+const natsTestURLEnv = "BHS_TEST_NATS_URL"
+
+func TestNatsPublisherWaitsForJetStreamAck(t *testing.T) {
+	// setup
+	js, subject := natsTestStream(t)
+	publisher, err := newNatsPublisher(&config.BrokerConfig{URL: os.Getenv(natsTestURLEnv)})
+	assert.NoError(t, err)
+	defer publisher.Close() //nolint: errcheck
+
+	// given
+	msg := brokerTestMessage()
+	msg.Subject = subject
+
+	// when
+	err = publisher.Publish(testContext(t), msg)
+
+	// then
+	assert.NoError(t, err)
+	stored, err := js.GetLastMsg(natsTestStreamName(t), subject)
+	assert.NoError(t, err)
+	assert.Equal(t, stored.Header.Get(nats.MsgIdHdr), msg.ID)
+	assert.Equal(t, stored.Header.Get("Bhs-Event"), "ADD")
+	assert.Equal(t, string(stored.Data), `{"operation":"ADD"}`)
+
+	// when
+	err = publisher.Publish(testContext(t), msg)
+
+	// then
+	assert.NoError(t, err)
+	info, err := js.StreamInfo(natsTestStreamName(t))
+	assert.NoError(t, err)
+	assert.Equal(t, info.State.Msgs, uint64(1))
+}
+
+func TestNatsPublisherFailsWithoutStream(t *testing.T) {
+	// setup
+	natsTestConn(t)
+	publisher, err := newNatsPublisher(&config.BrokerConfig{URL: os.Getenv(natsTestURLEnv)})
+	assert.NoError(t, err)
+	defer publisher.Close() //nolint: errcheck
+
+	// given
+	msg := brokerTestMessage()
+	msg.Subject = fmt.Sprintf("bhs-test.%d.header.add", time.Now().UnixNano())
+
+	// when
+	err = publisher.Publish(testContext(t), msg)
+
+	// then
+	assert.Equal(t, errors.Is(err, nats.ErrNoStreamResponse), true)
+}
+
+// natsTestConn connects to the NATS server with JetStream given by BHS_TEST_NATS_URL, the test is skipped when it isn't set.
+func natsTestConn(t *testing.T) *nats.Conn {
+	url := os.Getenv(natsTestURLEnv)
+	if url == "" {
+		t.Skipf("%s is not set", natsTestURLEnv)
+	}
+	conn, err := nats.Connect(url)
+	assert.NoError(t, err)
+	t.Cleanup(conn.Close)
+	return conn
+}
+
+// natsTestStream creates the stream capturing the subject of the test, which is deleted after the test.
+func natsTestStream(t *testing.T) (nats.JetStreamContext, string) {
+	js, err := natsTestConn(t).JetStream()
+	assert.NoError(t, err)
+
+	subject := fmt.Sprintf("bhs-test.%d.header.add", time.Now().UnixNano())
+	// stream left by an interrupted run
+	_ = js.DeleteStream(natsTestStreamName(t))
+	_, err = js.AddStream(&nats.StreamConfig{
+		Name:       natsTestStreamName(t),
+		Subjects:   []string{subject},
+		Duplicates: time.Minute,
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = js.DeleteStream(natsTestStreamName(t)) })
+	return js, subject
+}
+
+func natsTestStreamName(t *testing.T) string {
+	return "BHS_" + t.Name()
+}
@@ -0,0 +1,53 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisPublisher appends messages to Redis Streams with XADD.
+// A message is considered published when Redis replied with the id of the stream entry.
+type redisPublisher struct {
+	client *redis.Client
+}
+
+func newRedisPublisher(cfg *config.BrokerConfig) (*redisPublisher, error) {
+	opts, err := redis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	opts.ClientName = brokerClientID
+	if cfg.Username != "" {
+		opts.Username = cfg.Username
+	}
+	if cfg.Password != "" {
+		opts.Password = cfg.Password
+	}
+	tlsConfig, err := brokerTLSConfig(&cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		if opts.TLSConfig != nil {
+			tlsConfig.ServerName = opts.TLSConfig.ServerName
+		}
+		opts.TLSConfig = tlsConfig
+	}
+	return &redisPublisher{client: redis.NewClient(opts)}, nil
+}
+
+// Publish appends the message to the stream named after the message subject.
+func (p *redisPublisher) Publish(ctx context.Context, msg *BrokerMessage) error {
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: msg.Subject,
+		Values: []any{"id", msg.ID, "event", msg.Name, "key", msg.Key, "data", msg.Data},
+	}).Err()
+}
+
+// Close closes connections to Redis.
+func (p *redisPublisher) Close() error {
+	return p.client.Close()
+}
@@ -0,0 +1,270 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/rs/zerolog"
+)
+
+func TestBrokerSubject(t *testing.T) {
+	testCases := map[string]struct {
+		template string
+		event    Event
+		expected string
+	}{
+		"header event": {
+			template: "bhs.{type}.{operation}.{state}",
+			event:    headerEvent(domains.EventHeaderAdded, 1, domains.LongestChain),
+			expected: "bhs.header.add.longest_chain",
+		},
+		"merkle root event": {
+			template: "bhs:{type}:{operation}",
+			event:    &domains.MerkleRootEvent{Operation: domains.EventMerkleRootConfirmed},
+			expected: "bhs:merkleroot:merkleroot_confirmed",
+		},
+		"template without placeholders": {
+			template: "headers",
+			event:    headerEvent(domains.EventChainReorganized, 1, domains.LongestChain),
+			expected: "headers",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, BrokerSubject(tc.template, tc.event), tc.expected)
+		})
+	}
+}
+
+func TestBrokerChannelRetriesUntilAcknowledged(t *testing.T) {
+	// given
+	publisher := &flakyPublisher{failures: 2, published: make(chan *BrokerMessage, 10)}
+	channel := NewBrokerChannelWithPublisher(publisher, brokerTestConfig(), NewEventEncoder(""), testLogger())
+	defer channel.Close() //nolint: errcheck
+
+	// when
+	channel.Notify(headerEvent(domains.EventHeaderAdded, 1, domains.LongestChain))
+	channel.Notify(headerEvent(domains.EventHeaderAdded, 2, domains.LongestChain))
+
+	// then
+	first, second := receive(t, publisher.published), receive(t, publisher.published)
+	assert.Equal(t, publisher.attempts(), 4)
+	assert.Equal(t, first.Subject, "bhs.header.add")
+	assert.Equal(t, strings.Contains(string(first.Data), `"height":1`), true)
+	assert.Equal(t, strings.Contains(string(second.Data), `"height":2`), true)
+}
+
+func TestBrokerChannelDropsEventAfterMaxRetries(t *testing.T) {
+	// given
+	publisher := &flakyPublisher{failures: 7, published: make(chan *BrokerMessage, 10)}
+	channel := NewBrokerChannelWithPublisher(publisher, brokerTestConfig(), NewEventEncoder(""), testLogger())
+	defer channel.Close() //nolint: errcheck
+
+	// when
+	channel.Notify(headerEvent(domains.EventHeaderAdded, 1, domains.LongestChain))
+	channel.Notify(headerEvent(domains.EventHeaderAdded, 2, domains.LongestChain))
+
+	// then
+	published := receive(t, publisher.published)
+	assert.Equal(t, publisher.attempts(), 8)
+	assert.Equal(t, strings.Contains(string(published.Data), `"height":2`), true)
+}
+
+func TestBrokerChannelPublishesCloudEvents(t *testing.T) {
+	// given
+	publisher := &flakyPublisher{published: make(chan *BrokerMessage, 10)}
+	cfg := brokerTestConfig()
+	cfg.Format = "cloudevents"
+	channel := NewBrokerChannelWithPublisher(publisher, cfg, NewEventEncoder("bhs-test"), testLogger())
+	defer channel.Close() //nolint: errcheck
+	event := headerEvent(domains.EventHeaderAdded, 1, domains.LongestChain)
+	stampEvent(event)
+
+	// when
+	channel.Notify(event)
+
+	// then
+	published := receive(t, publisher.published)
+	var envelope map[string]any
+	assert.NoError(t, json.Unmarshal(published.Data, &envelope))
+	assert.Equal(t, published.ID, event.ID)
+	assert.Equal(t, envelope["id"].(string), event.ID)
+	assert.Equal(t, envelope["type"], "bsv.header.added")
+}
+
+func TestRedisPublisherAddsToStream(t *testing.T) {
+	// given
+	server := miniredis.RunT(t)
+	server.RequireUserAuth("bhs", "secret")
+	publisher, err := newRedisPublisher(&config.BrokerConfig{
+		URL:      "redis://" + server.Addr(),
+		Username: "bhs",
+		Password: "secret",
+	})
+	assert.NoError(t, err)
+	defer publisher.Close() //nolint: errcheck
+	msg := brokerTestMessage()
+
+	// when
+	err = publisher.Publish(testContext(t), msg)
+
+	// then
+	assert.NoError(t, err)
+	entries, err := server.Stream("bhs.header.add")
+	assert.NoError(t, err)
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, strings.Join(entries[0].Values, " "), "id "+msg.ID+` event ADD key 00000000 data {"operation":"ADD"}`)
+}
+
+func TestRedisPublisherRequiresCredentials(t *testing.T) {
+	// given
+	server := miniredis.RunT(t)
+	server.RequireUserAuth("bhs", "secret")
+	publisher, err := newRedisPublisher(&config.BrokerConfig{URL: "redis://" + server.Addr()})
+	assert.NoError(t, err)
+	defer publisher.Close() //nolint: errcheck
+
+	// when
+	err = publisher.Publish(testContext(t), brokerTestMessage())
+
+	// then
+	assert.Equal(t, err != nil, true)
+	assert.Equal(t, server.Exists("bhs.header.add"), false)
+}
+
+func TestKafkaSeedBrokers(t *testing.T) {
+	testCases := map[string]struct {
+		url      string
+		expected string
+	}{
+		"single broker": {
+			url:      "kafka://localhost:9092",
+			expected: "localhost:9092",
+		},
+		"many brokers": {
+			url:      "kafka://host1:9092, host2:9093/",
+			expected: "host1:9092,host2:9093",
+		},
+		"no brokers": {
+			url:      "kafka://",
+			expected: "",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, strings.Join(kafkaSeedBrokers(tc.url), ","), tc.expected)
+		})
+	}
+}
+
+func TestKafkaSASL(t *testing.T) {
+	testCases := map[string]struct {
+		cfg      *config.BrokerConfig
+		expected string
+	}{
+		"no credentials": {
+			cfg:      &config.BrokerConfig{KafkaSASLMechanism: config.KafkaSASLScramSHA256},
+			expected: "",
+		},
+		"plain by default": {
+			cfg:      &config.BrokerConfig{Username: "bhs", Password: "secret"},
+			expected: "PLAIN",
+		},
+		"scram sha 512": {
+			cfg:      &config.BrokerConfig{Username: "bhs", Password: "secret", KafkaSASLMechanism: config.KafkaSASLScramSHA512},
+			expected: "SCRAM-SHA-512",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mechanism := kafkaSASL(tc.cfg)
+			name := ""
+			if mechanism != nil {
+				name = mechanism.Name()
+			}
+			assert.Equal(t, name, tc.expected)
+		})
+	}
+}
+
+func brokerTestConfig() *config.BrokerConfig {
+	return &config.BrokerConfig{
+		Type:            config.BrokerNATS,
+		SubjectTemplate: "bhs.{type}.{operation}",
+		PublishTimeout:  time.Second,
+		RetryInterval:   time.Millisecond,
+		MaxRetries:      5,
+	}
+}
+
+func brokerTestMessage() *BrokerMessage {
+	return &BrokerMessage{
+		ID:      "4b9f0a4e-9a0c-4b47-8f5b-8f9f9a3c0a11",
+		Subject: "bhs.header.add",
+		Key:     "00000000",
+		Name:    "ADD",
+		Data:    []byte(`{"operation":"ADD"}`),
+	}
+}
+
+func testLogger() *zerolog.Logger {
+	log := zerolog.Nop()
+	return &log
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func receive(t *testing.T, messages <-chan *BrokerMessage) *BrokerMessage {
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was not published")
+		return nil
+	}
+}
+
+// flakyPublisher fails given number of times before accepting messages.
+type flakyPublisher struct {
+	mu        sync.Mutex
+	failures  int
+	calls     int
+	published chan *BrokerMessage
+}
+
+func (p *flakyPublisher) Publish(_ context.Context, msg *BrokerMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.calls <= p.failures {
+		return errors.New("broker unavailable")
+	}
+	p.published <- msg
+	return nil
+}
+
+func (p *flakyPublisher) Close() error {
+	return nil
+}
+
+func (p *flakyPublisher) attempts() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
@@ -0,0 +1,195 @@
+package notification
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/metrics"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultQueueSize    = 1000
+	defaultBlockTimeout = time.Second
+)
+
+// Results of events counted in notification metrics.
+const (
+	eventDelivered = "delivered"
+	eventDropped   = "dropped"
+	eventCoalesced = "coalesced"
+)
+
+// channelQueue is a bounded queue of events passed one by one to a single channel.
+type channelQueue struct {
+	name    string
+	channel Channel
+	size    int
+	policy  config.OverflowPolicy
+	// blockTimeout is the maximum time push waits for room in the queue with block policy
+	blockTimeout time.Duration
+	syncPolicy   config.SyncPolicy
+	mu           sync.Mutex
+	notEmpty     *sync.Cond
+	notFull      *sync.Cond
+	events       []Event
+	closed       bool
+	// lastProgress is the height of the last synchronization progress event passed to the channel
+	lastProgress int32
+	log          *zerolog.Logger
+}
+
+func newChannelQueue(name string, ch Channel, settings config.ChannelConfig, log *zerolog.Logger) *channelQueue {
+	if settings.QueueSize <= 0 {
+		settings.QueueSize = defaultQueueSize
+	}
+	if settings.BlockTimeout <= 0 {
+		settings.BlockTimeout = defaultBlockTimeout
+	}
+	if settings.SyncPolicy == "" {
+		settings.SyncPolicy = config.SyncDeliver
+	}
+	queueLogger := log.With().Str("channel", name).Logger()
+	q := &channelQueue{
+		name:         name,
+		channel:      ch,
+		size:         settings.QueueSize,
+		policy:       settings.OverflowPolicy,
+		blockTimeout: settings.BlockTimeout,
+		syncPolicy:   settings.SyncPolicy,
+		events:       make([]Event, 0, settings.QueueSize),
+		log:          &queueLogger,
+	}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	return q
+}
+
+// offer pushes event to the queue if the channel accepts it.
+func (q *channelQueue) offer(event Event) {
+	if f, ok := q.channel.(Filter); ok && !f.Accepts(event) {
+		return
+	}
+	q.push(event)
+}
+
+// push adds event to the queue, applying overflow policy when the queue is full.
+// With block policy it waits for room at most for the block timeout, so a stuck channel
+// never stops the notifying component, and then drops the oldest event.
+func (q *channelQueue) push(event Event) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.policy == config.OverflowBlock && len(q.events) >= q.size {
+		q.waitNotFull()
+	}
+	if q.closed {
+		q.log.Warn().Msgf("Notification channel is closed, event %s was dropped", EventName(event))
+		return
+	}
+
+	if len(q.events) >= q.size && q.policy == config.OverflowCoalesce {
+		if coalesced := q.coalesce(); coalesced > 0 {
+			metrics.AddNotificationEvents(q.name, eventCoalesced, coalesced)
+		}
+	}
+	if len(q.events) >= q.size {
+		q.log.Warn().Msgf("Notification queue is full, dropping the oldest event %s", EventName(q.events[0]))
+		q.events[0] = nil
+		q.events = q.events[1:]
+		metrics.AddNotificationEvents(q.name, eventDropped, 1)
+	}
+
+	q.events = append(q.events, event)
+	metrics.SetNotificationQueueLength(q.name, len(q.events))
+	q.notEmpty.Signal()
+}
+
+// waitNotFull waits until the queue has room, is closed or the block timeout passes. It must be called with q.mu held.
+func (q *channelQueue) waitNotFull() {
+	timedOut := false
+	timer := time.AfterFunc(q.blockTimeout, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		timedOut = true
+		q.notFull.Broadcast()
+	})
+	defer timer.Stop()
+
+	for len(q.events) >= q.size && !q.closed && !timedOut {
+		q.notFull.Wait()
+	}
+	if timedOut && len(q.events) >= q.size && !q.closed {
+		q.log.Warn().Msgf("Notification channel did not catch up within %s", q.blockTimeout)
+	}
+}
+
+// coalesce removes queued events superseded by a newer event of the same kind and returns number of removed events.
+func (q *channelQueue) coalesce() int {
+	seen := make(map[string]bool, len(q.events))
+	kept := make([]Event, len(q.events))
+	i := len(kept)
+	for j := len(q.events) - 1; j >= 0; j-- {
+		key := coalesceKey(q.events[j])
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		i--
+		kept[i] = q.events[j]
+	}
+	removed := i
+	q.events = append(q.events[:0], kept[i:]...)
+	return removed
+}
+
+// coalesceKey returns kind of the event, ex. all headers added to the longest chain are of the same kind.
+func coalesceKey(event Event) string {
+	switch e := event.(type) {
+	case *domains.HeaderEvent:
+		if e.Header == nil {
+			return "header:" + string(e.Operation)
+		}
+		return "header:" + string(e.Operation) + ":" + string(e.Header.State)
+	case *domains.MerkleRootEvent:
+		return "merkleroot:" + string(e.Operation) + ":" + e.MerkleRoot
+	default:
+		return fmt.Sprintf("%T", event)
+	}
+}
+
+// run passes queued events to the channel until the queue is closed and drained.
+func (q *channelQueue) run() {
+	for {
+		q.mu.Lock()
+		for len(q.events) == 0 && !q.closed {
+			q.notEmpty.Wait()
+		}
+		if len(q.events) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		event := q.events[0]
+		q.events[0] = nil
+		q.events = q.events[1:]
+		metrics.SetNotificationQueueLength(q.name, len(q.events))
+		q.notFull.Signal()
+		q.mu.Unlock()
+
+		start := time.Now()
+		q.channel.Notify(event)
+		metrics.ObserveNotificationDelivery(q.name, time.Since(start))
+		metrics.AddNotificationEvents(q.name, eventDelivered, 1)
+	}
+}
+
+func (q *channelQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+}
@@ -0,0 +1,95 @@
+package notification
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitState is a state of the circuit breaker of a webhook.
+type CircuitState string
+
+const (
+	// CircuitClosed means the webhook is notified about every event.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen means notifications of the failing webhook are skipped until the open duration passes.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen means a single trial notification decides if the circuit closes or opens again.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// circuitBreaker stops notifying a webhook after consecutive failures and retries it with exponential backoff.
+type circuitBreaker struct {
+	mu              sync.Mutex
+	state           CircuitState
+	failures        int
+	threshold       int
+	openDuration    time.Duration
+	minOpenDuration time.Duration
+	maxOpenDuration time.Duration
+	openUntil       time.Time
+	now             func() time.Time
+}
+
+func newCircuitBreaker(threshold int, minOpenDuration, maxOpenDuration time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &circuitBreaker{
+		state:           CircuitClosed,
+		threshold:       threshold,
+		openDuration:    minOpenDuration,
+		minOpenDuration: minOpenDuration,
+		maxOpenDuration: max(minOpenDuration, maxOpenDuration),
+		now:             time.Now,
+	}
+}
+
+// allow checks if the webhook should be notified, switching open circuit to half-open when the open duration passed.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Before(b.openUntil) {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		return true
+	case CircuitHalfOpen:
+		// the trial notification is still in progress
+		return false
+	default:
+		return true
+	}
+}
+
+// success closes the circuit.
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = CircuitClosed
+	b.failures = 0
+	b.openDuration = b.minOpenDuration
+}
+
+// failure opens the circuit after too many consecutive failures or a failed trial notification.
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	switch {
+	case b.state == CircuitHalfOpen:
+		b.openDuration = min(2*b.openDuration, b.maxOpenDuration)
+	case b.failures < b.threshold:
+		return
+	}
+	b.state = CircuitOpen
+	b.openUntil = b.now().Add(b.openDuration)
+}
+
+// current returns state of the circuit.
+func (b *circuitBreaker) current() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
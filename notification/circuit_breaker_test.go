@@ -0,0 +1,74 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	// given
+	now := time.Now()
+	breaker := newCircuitBreaker(2, time.Minute, 3*time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	// when
+	breaker.failure()
+
+	// then
+	assert.Equal(t, breaker.current(), CircuitClosed)
+	assert.Equal(t, breaker.allow(), true)
+
+	// when
+	breaker.failure()
+
+	// then
+	assert.Equal(t, breaker.current(), CircuitOpen)
+	assert.Equal(t, breaker.allow(), false)
+
+	// when
+	now = now.Add(time.Minute)
+
+	// then
+	assert.Equal(t, breaker.allow(), true)
+	assert.Equal(t, breaker.current(), CircuitHalfOpen)
+	assert.Equal(t, breaker.allow(), false)
+
+	// when failed trial doubles the open duration
+	breaker.failure()
+	now = now.Add(time.Minute)
+
+	// then
+	assert.Equal(t, breaker.allow(), false)
+
+	// when
+	now = now.Add(time.Minute)
+
+	// then
+	assert.Equal(t, breaker.allow(), true)
+
+	// when
+	breaker.success()
+
+	// then
+	assert.Equal(t, breaker.current(), CircuitClosed)
+	assert.Equal(t, breaker.allow(), true)
+}
+
+func TestCircuitBreakerLimitsOpenDuration(t *testing.T) {
+	// given
+	now := time.Now()
+	breaker := newCircuitBreaker(1, time.Minute, 90*time.Second)
+	breaker.now = func() time.Time { return now }
+	breaker.failure()
+
+	for i := 0; i < 5; i++ {
+		now = now.Add(breaker.openDuration)
+		assert.Equal(t, breaker.allow(), true)
+		breaker.failure()
+	}
+
+	// then
+	assert.Equal(t, breaker.openDuration, 90*time.Second)
+}
@@ -0,0 +1,195 @@
+package notification
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// EventFormat is a format in which events are delivered to clients.
+type EventFormat string
+
+const (
+	// FormatJSON delivers events as plain JSON, the default format.
+	FormatJSON EventFormat = "json"
+	// FormatCloudEvents delivers events wrapped in CloudEvents 1.0 envelope in structured content mode.
+	FormatCloudEvents EventFormat = "cloudevents"
+	// FormatCloudEventsBinary delivers events in CloudEvents 1.0 binary content mode,
+	// where event attributes are passed in ce-* HTTP headers. It is supported only by webhooks.
+	FormatCloudEventsBinary EventFormat = "cloudevents-binary"
+)
+
+const (
+	// CloudEventsSpecVersion is the version of CloudEvents specification of the envelope.
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventsContentType is the content type of events in CloudEvents structured content mode.
+	CloudEventsContentType = "application/cloudevents+json; charset=UTF-8"
+	// EventSchemaVersion is the version of the event data schema, changed on every breaking change of event data.
+	EventSchemaVersion = "v1"
+
+	eventDataContentType = "application/json"
+	eventSchemaPrefix    = "urn:bsv:block-headers-service:schema:"
+)
+
+// cloudEventTypes maps operations of events to CloudEvents types.
+var cloudEventTypes = map[string]string{
+	string(domains.EventHeaderAdded):                    "bsv.header.added",
+	string(domains.EventHeaderConfirmed):                "bsv.header.confirmed",
+	string(domains.EventHeaderUnconfirmed):              "bsv.header.unconfirmed",
+	string(domains.EventChainReorganized):               "bsv.chain.reorganized",
+	string(domains.EventMerkleRootConfirmed):            "bsv.merkleroot.confirmed",
+	string(domains.EventMerkleRootInvalid):              "bsv.merkleroot.invalidated",
+	string(domains.EventMerkleRootConfirmationsReached): "bsv.merkleroot.confirmations.reached",
+	string(domains.EventSyncProgress):                   "bsv.sync.progressed",
+	string(domains.EventSyncCaughtUp):                   "bsv.sync.caughtup",
+}
+
+// ParseEventFormat parses format of events, empty value means FormatJSON.
+func ParseEventFormat(value string) (EventFormat, error) {
+	switch format := EventFormat(value); format {
+	case "":
+		return FormatJSON, nil
+	case FormatJSON, FormatCloudEvents, FormatCloudEventsBinary:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown event format %s", value)
+	}
+}
+
+// CloudEvent is an event wrapped in CloudEvents 1.0 envelope.
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Time            time.Time `json:"time"`
+	Subject         string    `json:"subject,omitempty"`
+	DataContentType string    `json:"datacontenttype"`
+	DataSchema      string    `json:"dataschema"`
+	Data            Event     `json:"data"`
+}
+
+// EventEncoder encodes events in one of the supported formats.
+type EventEncoder struct {
+	source string
+}
+
+// NewEventEncoder creates EventEncoder. Source identifies this instance in CloudEvents envelopes,
+// the application name is used when it is empty.
+func NewEventEncoder(source string) *EventEncoder {
+	if source == "" {
+		source = config.ApplicationName
+	}
+	return &EventEncoder{source: source}
+}
+
+// CloudEvent wraps event in CloudEvents envelope.
+func (enc *EventEncoder) CloudEvent(event Event) *CloudEvent {
+	kind := "event"
+	switch event.(type) {
+	case *domains.HeaderEvent:
+		kind = "header"
+	case *domains.MerkleRootEvent:
+		kind = "merkleroot"
+	case *domains.SyncEvent:
+		kind = "sync"
+	}
+
+	eventType, ok := cloudEventTypes[EventName(event)]
+	if !ok {
+		eventType = "bsv." + kind
+	}
+
+	id, emitted := eventIdentity(event)
+	return &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              id,
+		Source:          enc.source,
+		Type:            eventType,
+		Time:            emitted,
+		Subject:         eventSubject(event),
+		DataContentType: eventDataContentType,
+		DataSchema:      eventSchemaPrefix + kind + ":" + EventSchemaVersion,
+		Data:            event,
+	}
+}
+
+// Encode returns JSON of the event in given format.
+func (enc *EventEncoder) Encode(event Event, format EventFormat) ([]byte, error) {
+	if format == FormatCloudEvents {
+		return json.Marshal(enc.CloudEvent(event))
+	}
+	return json.Marshal(event)
+}
+
+// HTTPRequest returns headers and body of http request delivering event in given format.
+func (enc *EventEncoder) HTTPRequest(event Event, format EventFormat) (map[string]string, any) {
+	switch format {
+	case FormatCloudEvents:
+		return map[string]string{"Content-Type": CloudEventsContentType}, enc.CloudEvent(event)
+	case FormatCloudEventsBinary:
+		ce := enc.CloudEvent(event)
+		headers := map[string]string{
+			"Content-Type":   ce.DataContentType,
+			"ce-specversion": ce.SpecVersion,
+			"ce-id":          ce.ID,
+			"ce-source":      ce.Source,
+			"ce-type":        ce.Type,
+			"ce-time":        ce.Time.Format(time.RFC3339Nano),
+			"ce-dataschema":  ce.DataSchema,
+		}
+		if ce.Subject != "" {
+			headers["ce-subject"] = ce.Subject
+		}
+		return headers, ce.Data
+	default:
+		return map[string]string{"Content-Type": "application/json"}, event
+	}
+}
+
+// eventSubject returns hash of the header or merkle root which the event is about.
+func eventSubject(event Event) string {
+	if e, ok := event.(*domains.SyncEvent); ok {
+		return e.Hash
+	}
+	return eventKey(event)
+}
+
+// identifiedEvent is implemented by events with metadata assigned when they are emitted.
+type identifiedEvent interface {
+	Metadata() *domains.EventMetadata
+}
+
+// stampEvent assigns id and time to the emitted event, unless it already has them.
+// It must be called before the event is passed to channels, which read the metadata concurrently.
+func stampEvent(event Event) {
+	if e, ok := event.(identifiedEvent); ok {
+		if m := e.Metadata(); m.ID == "" {
+			m.ID, m.Time = newEventUUID(), time.Now().UTC()
+		}
+	}
+}
+
+// eventIdentity returns id and time of the event assigned when it was emitted.
+// Events which were not emitted by the notifier, ex. recovered from the database, get new ones.
+func eventIdentity(event Event) (string, time.Time) {
+	if e, ok := event.(identifiedEvent); ok {
+		if m := e.Metadata(); m.ID != "" {
+			return m.ID, m.Time
+		}
+	}
+	return newEventUUID(), time.Now().UTC()
+}
+
+// newEventUUID returns random UUID (version 4) used as id of the event.
+func newEventUUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
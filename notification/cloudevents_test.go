@@ -0,0 +1,118 @@
+package notification
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+const testHash = "000000000000000001f7e3b6a5bd8bda2d0e1cf8d1f0b3f9d2c1e0b3f9d2c1e0"
+
+func TestCloudEventEnvelope(t *testing.T) {
+	// given
+	encoder := NewEventEncoder("bhs-test")
+	event := headerEvent(domains.EventHeaderAdded, 10, domains.LongestChain)
+	event.Header.Hash = testHash
+
+	// when
+	data, err := encoder.Encode(event, FormatCloudEvents)
+
+	// then
+	assert.NoError(t, err)
+	var envelope map[string]any
+	assert.NoError(t, json.Unmarshal(data, &envelope))
+	assert.Equal(t, envelope["specversion"], CloudEventsSpecVersion)
+	assert.Equal(t, envelope["source"], "bhs-test")
+	assert.Equal(t, envelope["type"], "bsv.header.added")
+	assert.Equal(t, envelope["subject"], testHash)
+	assert.Equal(t, envelope["datacontenttype"], "application/json")
+	assert.Equal(t, envelope["dataschema"], "urn:bsv:block-headers-service:schema:header:v1")
+	assert.Equal(t, len(envelope["id"].(string)), 36)
+	assert.Equal(t, envelope["time"] != "", true)
+	assert.Equal(t, envelope["data"].(map[string]any)["operation"], "ADD")
+}
+
+func TestCloudEventTypes(t *testing.T) {
+	testCases := map[string]struct {
+		event        Event
+		expectedType string
+	}{
+		"reorg": {
+			event:        headerEvent(domains.EventChainReorganized, 1, domains.LongestChain),
+			expectedType: "bsv.chain.reorganized",
+		},
+		"merkle root confirmed": {
+			event:        &domains.MerkleRootEvent{Operation: domains.EventMerkleRootConfirmed},
+			expectedType: "bsv.merkleroot.confirmed",
+		},
+		"sync caught up": {
+			event:        &domains.SyncEvent{Operation: domains.EventSyncCaughtUp},
+			expectedType: "bsv.sync.caughtup",
+		},
+	}
+	encoder := NewEventEncoder("")
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			envelope := encoder.CloudEvent(tc.event)
+
+			// then
+			assert.Equal(t, envelope.Type, tc.expectedType)
+			assert.Equal(t, envelope.Source, "block-headers-service")
+		})
+	}
+}
+
+func TestWebhookRequestInBinaryMode(t *testing.T) {
+	// given
+	encoder := NewEventEncoder("bhs-test")
+	event := headerEvent(domains.EventHeaderAdded, 10, domains.LongestChain)
+	event.Header.Hash = testHash
+
+	// when
+	headers, body := encoder.HTTPRequest(event, FormatCloudEventsBinary)
+
+	// then
+	assert.Equal(t, headers["Content-Type"], "application/json")
+	assert.Equal(t, headers["ce-specversion"], "1.0")
+	assert.Equal(t, headers["ce-type"], "bsv.header.added")
+	assert.Equal(t, headers["ce-source"], "bhs-test")
+	assert.Equal(t, headers["ce-subject"], testHash)
+	assert.Equal(t, headers["ce-dataschema"], "urn:bsv:block-headers-service:schema:header:v1")
+	assert.Equal(t, headers["ce-id"] != "", true)
+	assert.Equal(t, body.(*domains.HeaderEvent), event)
+}
+
+func TestWebhookRequestInStructuredMode(t *testing.T) {
+	// given
+	encoder := NewEventEncoder("bhs-test")
+	event := headerEvent(domains.EventHeaderAdded, 10, domains.LongestChain)
+
+	// when
+	headers, body := encoder.HTTPRequest(event, FormatCloudEvents)
+
+	// then
+	assert.Equal(t, headers["Content-Type"], CloudEventsContentType)
+	assert.Equal(t, body.(*CloudEvent).Data.(*domains.HeaderEvent), event)
+}
+
+func TestCloudEventIDIsAssignedOncePerEvent(t *testing.T) {
+	// given
+	encoder := NewEventEncoder("bhs-test")
+	event := headerEvent(domains.EventHeaderAdded, 10, domains.LongestChain)
+	stampEvent(event)
+
+	// when
+	first := encoder.CloudEvent(event)
+	second := encoder.CloudEvent(event)
+	headers, _ := encoder.HTTPRequest(event, FormatCloudEventsBinary)
+
+	// then
+	assert.Equal(t, first.ID, event.ID)
+	assert.Equal(t, second.ID, event.ID)
+	assert.Equal(t, headers["ce-id"], event.ID)
+	assert.Equal(t, second.Time, first.Time)
+}
@@ -0,0 +1,127 @@
+package notification
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/rs/zerolog"
+)
+
+// streamBufferSize is the number of events buffered for a single stream subscriber
+// before it is considered too slow and disconnected.
+const streamBufferSize = 256
+
+// EventStream is a Channel passing events to clients of streaming transports like Server-Sent Events.
+type EventStream struct {
+	mu          sync.RWMutex
+	subscribers map[*StreamSubscriber]struct{}
+	log         *zerolog.Logger
+}
+
+// StreamSubscriber represents a single client of the EventStream.
+type StreamSubscriber struct {
+	subscription *WebsocketSubscription
+	events       chan Event
+	closeOnce    sync.Once
+}
+
+// NewEventStream creates EventStream.
+func NewEventStream(log *zerolog.Logger) *EventStream {
+	streamLogger := log.With().Str("subservice", "event-stream").Logger()
+	return &EventStream{
+		subscribers: make(map[*StreamSubscriber]struct{}),
+		log:         &streamLogger,
+	}
+}
+
+// Subscribe registers a new subscriber receiving events accepted by given subscription.
+func (s *EventStream) Subscribe(subscription *WebsocketSubscription) *StreamSubscriber {
+	sub := &StreamSubscriber{
+		subscription: subscription,
+		events:       make(chan Event, streamBufferSize),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe removes the subscriber and closes its events.
+func (s *EventStream) Unsubscribe(sub *StreamSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers, sub)
+	sub.close()
+}
+
+// Notify passes event to all subscribers accepting it. Subscribers which cannot keep up are disconnected,
+// so they can resume from their last event instead of silently missing events.
+func (s *EventStream) Notify(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subscribers {
+		if !sub.subscription.Accepts(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			s.log.Warn().Msgf("Stream subscriber of %s cannot keep up with events, disconnecting it", sub.subscription.Channel)
+			delete(s.subscribers, sub)
+			sub.close()
+		}
+	}
+}
+
+// Events returns events of the subscriber, closed when the subscriber is disconnected.
+func (sub *StreamSubscriber) Events() <-chan Event {
+	return sub.events
+}
+
+func (sub *StreamSubscriber) close() {
+	sub.closeOnce.Do(func() { close(sub.events) })
+}
+
+// EventID returns position in the longest chain which event leads to, in format "<height>:<hash>",
+// or empty string when the event doesn't change the tip of the longest chain.
+func EventID(event Event) string {
+	e, ok := event.(*domains.HeaderEvent)
+	if !ok || e.Header == nil {
+		return ""
+	}
+	if e.Operation == domains.EventChainReorganized ||
+		(e.Operation == domains.EventHeaderAdded && e.Header.State == domains.LongestChain) {
+		return fmt.Sprintf("%d:%s", e.Header.Height, e.Header.Hash)
+	}
+	return ""
+}
+
+// ParseEventID parses event id created by EventID to a position in the chain.
+func ParseEventID(id string) (*domains.ChainPosition, error) {
+	rawHeight, hash, ok := strings.Cut(id, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid event id %s", id)
+	}
+	height, err := strconv.ParseInt(rawHeight, 10, 32)
+	if err != nil || height < 0 {
+		return nil, fmt.Errorf("invalid height in event id %s", id)
+	}
+	return &domains.ChainPosition{Height: int32(height), Hash: hash}, nil
+}
+
+// EventName returns type of the event.
+func EventName(event Event) string {
+	switch e := event.(type) {
+	case *domains.HeaderEvent:
+		return string(e.Operation)
+	case *domains.MerkleRootEvent:
+		return string(e.Operation)
+	case *domains.SyncEvent:
+		return string(e.Operation)
+	default:
+		return "message"
+	}
+}
@@ -0,0 +1,65 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/rs/zerolog"
+)
+
+func TestEventStreamPassesAcceptedEvents(t *testing.T) {
+	// given
+	log := zerolog.Nop()
+	stream := NewEventStream(&log)
+	sub, _ := ParseWebsocketChannel(TipChannel)
+	subscriber := stream.Subscribe(sub)
+
+	// when
+	stream.Notify(headerEvent(domains.EventHeaderAdded, 1, domains.Stale))
+	stream.Notify(headerEvent(domains.EventHeaderAdded, 2, domains.LongestChain))
+	stream.Unsubscribe(subscriber)
+
+	// then
+	received := make([]Event, 0)
+	for e := range subscriber.Events() {
+		received = append(received, e)
+	}
+	assert.Equal(t, len(received), 1)
+	assert.Equal(t, received[0].(*domains.HeaderEvent).Header.Height, int32(2))
+}
+
+func TestEventStreamDisconnectsSlowSubscriber(t *testing.T) {
+	// given
+	log := zerolog.Nop()
+	stream := NewEventStream(&log)
+	sub, _ := ParseWebsocketChannel(HeadersChannel)
+	subscriber := stream.Subscribe(sub)
+
+	// when
+	for i := 0; i <= streamBufferSize; i++ {
+		stream.Notify(headerEvent(domains.EventHeaderAdded, int32(i), domains.LongestChain))
+	}
+
+	// then
+	received := 0
+	for range subscriber.Events() {
+		received++
+	}
+	assert.Equal(t, received, streamBufferSize)
+}
+
+func TestEventIDRoundTrip(t *testing.T) {
+	// given
+	event := headerEvent(domains.EventHeaderAdded, 10, domains.LongestChain)
+	event.Header.Hash = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+
+	// when
+	position, err := ParseEventID(EventID(event))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, position.Height, int32(10))
+	assert.Equal(t, position.Hash, event.Header.Hash)
+	assert.Equal(t, EventID(headerEvent(domains.EventHeaderAdded, 10, domains.Stale)), "")
+}
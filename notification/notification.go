@@ -1,5 +1,14 @@
 package notification
 
+import (
+	"context"
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/rs/zerolog"
+)
+
 // Event represents event to notify with.
 type Event any
 
@@ -9,26 +18,126 @@ type Channel interface {
 	Notify(Event)
 }
 
+// Filter can be implemented by a Channel interested only in some events, so other events are not queued for it.
+type Filter interface {
+	// Accepts checks if event should be passed to the channel.
+	Accepts(Event) bool
+}
+
 // Notifier is representing component that can be used to notify clients about important events.
+// Every channel has its own bounded queue processed by a single worker, so events reach
+// each channel in the order they were emitted and a slow channel doesn't delay the others.
+// While headers are being synchronized, header events are passed to channels according to their sync policy.
 type Notifier struct {
-	channels []Channel
+	mu     sync.RWMutex
+	queues []*channelQueue
+	cfg    *config.NotificationConfig
+	sync   *syncTracker
+	wg     sync.WaitGroup
+	log    *zerolog.Logger
 }
 
-// NewNotifier create Notifier.
-func NewNotifier() *Notifier {
+// NewNotifier create Notifier. Status is checked once for the initial synchronization status of headers,
+// later updated with UpdateSyncStatus, nil treats headers as always synchronized.
+func NewNotifier(cfg *config.NotificationConfig, status SyncStatus, log *zerolog.Logger) *Notifier {
+	if cfg == nil {
+		cfg = &config.NotificationConfig{QueueSize: defaultQueueSize, OverflowPolicy: config.OverflowDropOldest, SyncPolicy: config.SyncDeliver}
+	}
+	notifierLogger := log.With().Str("subservice", "notifier").Logger()
 	return &Notifier{
-		channels: make([]Channel, 0),
+		queues: make([]*channelQueue, 0),
+		cfg:    cfg,
+		sync:   newSyncTracker(status),
+		log:    &notifierLogger,
 	}
 }
 
-// AddChannel register communication channel in notifier.
-func (n *Notifier) AddChannel(ch Channel) {
-	n.channels = append(n.channels, ch)
+// AddChannel register communication channel in notifier under given name, which selects its queue settings.
+func (n *Notifier) AddChannel(name string, ch Channel) {
+	q := newChannelQueue(name, ch, n.cfg.ForChannel(name), n.log)
+
+	n.mu.Lock()
+	n.queues = append(n.queues, q)
+	n.mu.Unlock()
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		q.run()
+	}()
 }
 
-// Notify send event notification via registered channels.
+// Notify queues event notification for registered channels. A channel with block overflow policy
+// can delay it until the channel has room for the event, at most for the block timeout of the channel.
 func (n *Notifier) Notify(event any) {
-	for _, ch := range n.channels {
-		go ch.Notify(event)
+	stampEvent(event)
+
+	n.mu.RLock()
+	queues := n.queues
+	n.mu.RUnlock()
+
+	header, isHeaderEvent := event.(*domains.HeaderEvent)
+	syncing := isHeaderEvent && !n.sync.isCurrent()
+
+	for _, q := range queues {
+		if q.syncPolicy == config.SyncDeliver {
+			q.offer(event)
+			continue
+		}
+		if syncing {
+			if progress := q.syncProgress(header, n.cfg.SyncProgressInterval); progress != nil {
+				q.offer(progress)
+			}
+			continue
+		}
+		q.offer(event)
+	}
+}
+
+// UpdateSyncStatus stores synchronization status of headers checked by the loop synchronizing them.
+// When headers have just caught up with the network, channels not receiving header events during
+// synchronization are notified with SYNC_CAUGHT_UP of the tip.
+func (n *Notifier) UpdateSyncStatus(current bool, tip *domains.BlockHeader) {
+	if !n.sync.update(current) {
+		return
+	}
+
+	n.mu.RLock()
+	queues := n.queues
+	n.mu.RUnlock()
+
+	var details *domains.HeaderEventDetails
+	if tip != nil {
+		details = domains.HeaderAdded(tip).Header
+	}
+	caughtUp := domains.SyncCaughtUp(details)
+	stampEvent(caughtUp)
+	for _, q := range queues {
+		if q.syncPolicy != config.SyncDeliver {
+			q.offer(caughtUp)
+		}
+	}
+}
+
+// Shutdown stops accepting new events and waits until channels process the already queued ones
+// or the context is done.
+func (n *Notifier) Shutdown(ctx context.Context) error {
+	n.mu.RLock()
+	for _, q := range n.queues {
+		q.close()
+	}
+	n.mu.RUnlock()
+
+	drained := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
@@ -0,0 +1,344 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestNotifierPreservesOrderOfEvents(t *testing.T) {
+	// given
+	notifier := newTestNotifier(10, config.OverflowBlock)
+	channel := newRecordingChannel()
+	notifier.AddChannel("test", channel)
+
+	// when
+	for i := int32(1); i <= 100; i++ {
+		notifier.Notify(headerEvent(domains.EventHeaderAdded, i, domains.LongestChain))
+	}
+	shutdown(t, notifier)
+
+	// then
+	assert.Equal(t, len(channel.heights()), 100)
+	for i, height := range channel.heights() {
+		assert.Equal(t, height, int32(i+1))
+	}
+}
+
+func TestNotifierBlocksWhenQueueIsFull(t *testing.T) {
+	// given
+	notifier := newTestNotifier(1, config.OverflowBlock)
+	channel := newRecordingChannel().blocked()
+	notifier.AddChannel("test", channel)
+	notifier.Notify(headerEvent(domains.EventHeaderAdded, 1, domains.LongestChain))
+	channel.waitForDelivery(t)
+	notifier.Notify(headerEvent(domains.EventHeaderAdded, 2, domains.LongestChain))
+
+	// when
+	notified := make(chan struct{})
+	go func() {
+		notifier.Notify(headerEvent(domains.EventHeaderAdded, 3, domains.LongestChain))
+		close(notified)
+	}()
+
+	// then
+	select {
+	case <-notified:
+		t.Fatal("notify should block until the channel catches up")
+	case <-time.After(50 * time.Millisecond):
+	}
+	channel.release()
+	<-notified
+	shutdown(t, notifier)
+	assert.Equal(t, len(channel.heights()), 3)
+}
+
+func TestNotifierStopsBlockingAfterBlockTimeout(t *testing.T) {
+	// given
+	notifier := NewNotifier(&config.NotificationConfig{
+		QueueSize:      1,
+		OverflowPolicy: config.OverflowBlock,
+		BlockTimeout:   50 * time.Millisecond,
+	}, nil, testLogger())
+	channel := newRecordingChannel().blocked()
+	notifier.AddChannel("test", channel)
+	notifier.Notify(headerEvent(domains.EventHeaderAdded, 1, domains.LongestChain))
+	channel.waitForDelivery(t)
+	notifier.Notify(headerEvent(domains.EventHeaderAdded, 2, domains.LongestChain))
+
+	// when
+	notified := make(chan struct{})
+	go func() {
+		notifier.Notify(headerEvent(domains.EventHeaderAdded, 3, domains.LongestChain))
+		close(notified)
+	}()
+
+	// then
+	select {
+	case <-notified:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notify should stop blocking after the block timeout")
+	}
+	channel.release()
+	shutdown(t, notifier)
+	heights := channel.heights()
+	assert.Equal(t, len(heights), 2)
+	assert.Equal(t, heights[1], int32(3))
+}
+
+func TestNotifierDropsOldestEvents(t *testing.T) {
+	// given
+	notifier := newTestNotifier(2, config.OverflowDropOldest)
+	channel := newRecordingChannel().blocked()
+	notifier.AddChannel("test", channel)
+	notifier.Notify(headerEvent(domains.EventHeaderAdded, 1, domains.LongestChain))
+	channel.waitForDelivery(t)
+
+	// when
+	for i := int32(2); i <= 4; i++ {
+		notifier.Notify(headerEvent(domains.EventHeaderAdded, i, domains.LongestChain))
+	}
+	channel.release()
+	shutdown(t, notifier)
+
+	// then
+	heights := channel.heights()
+	assert.Equal(t, len(heights), 3)
+	assert.Equal(t, heights[0], int32(1))
+	assert.Equal(t, heights[1], int32(3))
+	assert.Equal(t, heights[2], int32(4))
+}
+
+func TestNotifierCoalescesEvents(t *testing.T) {
+	// given
+	notifier := newTestNotifier(2, config.OverflowCoalesce)
+	channel := newRecordingChannel().blocked()
+	notifier.AddChannel("test", channel)
+	notifier.Notify(headerEvent(domains.EventHeaderAdded, 1, domains.LongestChain))
+	channel.waitForDelivery(t)
+
+	// when
+	notifier.Notify(headerEvent(domains.EventHeaderAdded, 2, domains.LongestChain))
+	notifier.Notify(headerEvent(domains.EventHeaderAdded, 3, domains.LongestChain))
+	notifier.Notify(headerEvent(domains.EventChainReorganized, 3, domains.LongestChain))
+	channel.release()
+	shutdown(t, notifier)
+
+	// then
+	events := channel.received()
+	assert.Equal(t, len(events), 3)
+	assert.Equal(t, events[1].Header.Height, int32(3))
+	assert.Equal(t, events[1].Operation, domains.EventHeaderAdded)
+	assert.Equal(t, events[2].Operation, domains.EventChainReorganized)
+}
+
+func TestNotifierSkipsEventsNotAcceptedByChannel(t *testing.T) {
+	// given
+	notifier := newTestNotifier(10, config.OverflowBlock)
+	channel := &filteringChannel{recordingChannel: newRecordingChannel()}
+	notifier.AddChannel("test", channel)
+
+	// when
+	notifier.Notify(headerEvent(domains.EventHeaderAdded, 1, domains.Stale))
+	notifier.Notify(headerEvent(domains.EventHeaderAdded, 2, domains.LongestChain))
+	shutdown(t, notifier)
+
+	// then
+	heights := channel.heights()
+	assert.Equal(t, len(heights), 1)
+	assert.Equal(t, heights[0], int32(2))
+}
+
+func TestChannelSettingsOverrideNotificationDefaults(t *testing.T) {
+	// given
+	cfg := &config.NotificationConfig{
+		QueueSize:      10,
+		OverflowPolicy: config.OverflowBlock,
+		Channels: map[string]*config.ChannelConfig{
+			"websocket": {OverflowPolicy: config.OverflowDropOldest},
+		},
+	}
+
+	// then
+	assert.Equal(t, cfg.ForChannel("websocket").OverflowPolicy, config.OverflowDropOldest)
+	assert.Equal(t, cfg.ForChannel("websocket").QueueSize, 10)
+	assert.Equal(t, cfg.ForChannel("webhooks").OverflowPolicy, config.OverflowBlock)
+}
+
+func newTestNotifier(size int, policy config.OverflowPolicy) *Notifier {
+	return NewNotifier(&config.NotificationConfig{QueueSize: size, OverflowPolicy: policy}, nil, testLogger())
+}
+
+func shutdown(t *testing.T, notifier *Notifier) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, notifier.Shutdown(ctx))
+}
+
+// recordingChannel records received header events, optionally blocking until released.
+type recordingChannel struct {
+	mu        sync.Mutex
+	events    []*domains.HeaderEvent
+	gate      chan struct{}
+	delivered chan struct{}
+}
+
+func newRecordingChannel() *recordingChannel {
+	return &recordingChannel{delivered: make(chan struct{}, 1000)}
+}
+
+func (c *recordingChannel) blocked() *recordingChannel {
+	c.gate = make(chan struct{})
+	return c
+}
+
+func (c *recordingChannel) release() {
+	close(c.gate)
+}
+
+func (c *recordingChannel) Notify(event Event) {
+	c.mu.Lock()
+	c.events = append(c.events, event.(*domains.HeaderEvent))
+	c.mu.Unlock()
+	c.delivered <- struct{}{}
+	if c.gate != nil {
+		<-c.gate
+	}
+}
+
+func (c *recordingChannel) waitForDelivery(t *testing.T) {
+	select {
+	case <-c.delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+}
+
+func (c *recordingChannel) received() []*domains.HeaderEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*domains.HeaderEvent{}, c.events...)
+}
+
+func (c *recordingChannel) heights() []int32 {
+	heights := make([]int32, 0)
+	for _, e := range c.received() {
+		heights = append(heights, e.Header.Height)
+	}
+	return heights
+}
+
+type filteringChannel struct {
+	*recordingChannel
+}
+
+func (c *filteringChannel) Accepts(event Event) bool {
+	e, ok := event.(*domains.HeaderEvent)
+	return ok && e.Header.State == domains.LongestChain
+}
+
+func TestNotifierAppliesSyncPolicyOfChannels(t *testing.T) {
+	// given
+	status := &fakeSyncStatus{}
+	notifier := NewNotifier(&config.NotificationConfig{
+		QueueSize:            10,
+		OverflowPolicy:       config.OverflowBlock,
+		SyncPolicy:           config.SyncDeliver,
+		SyncProgressInterval: 2,
+		Channels: map[string]*config.ChannelConfig{
+			"suppressed": {SyncPolicy: config.SyncSuppress},
+			"progress":   {SyncPolicy: config.SyncProgress},
+		},
+	}, status, testLogger())
+	delivered, suppressed, progress := &collectingChannel{}, &collectingChannel{}, &collectingChannel{}
+	notifier.AddChannel("delivered", delivered)
+	notifier.AddChannel("suppressed", suppressed)
+	notifier.AddChannel("progress", progress)
+
+	// when
+	for i := int32(1); i <= 5; i++ {
+		notifier.Notify(headerEvent(domains.EventHeaderAdded, i, domains.LongestChain))
+	}
+	notifier.UpdateSyncStatus(true, &domains.BlockHeader{Height: 5})
+	notifier.Notify(headerEvent(domains.EventHeaderAdded, 6, domains.LongestChain))
+	shutdown(t, notifier)
+
+	// then
+	assert.Equal(t, delivered.names(), "ADD:1,ADD:2,ADD:3,ADD:4,ADD:5,ADD:6")
+	assert.Equal(t, suppressed.names(), "SYNC_CAUGHT_UP:5,ADD:6")
+	assert.Equal(t, progress.names(), "SYNC_PROGRESS:2,SYNC_PROGRESS:4,SYNC_CAUGHT_UP:5,ADD:6")
+	assert.Equal(t, status.checks(), 1)
+}
+
+func TestNotifierDoesNotReportCaughtUpWhenStartedSynchronized(t *testing.T) {
+	// given
+	status := &fakeSyncStatus{current: true}
+	notifier := NewNotifier(&config.NotificationConfig{
+		QueueSize:            10,
+		OverflowPolicy:       config.OverflowBlock,
+		SyncPolicy:           config.SyncProgress,
+		SyncProgressInterval: 1,
+	}, status, testLogger())
+	channel := &collectingChannel{}
+	notifier.AddChannel("test", channel)
+
+	// when
+	notifier.Notify(headerEvent(domains.EventHeaderAdded, 1, domains.LongestChain))
+	notifier.UpdateSyncStatus(true, &domains.BlockHeader{Height: 1})
+	notifier.Notify(headerEvent(domains.EventHeaderAdded, 2, domains.LongestChain))
+	shutdown(t, notifier)
+
+	// then
+	assert.Equal(t, channel.names(), "ADD:1,ADD:2")
+}
+
+type fakeSyncStatus struct {
+	mu      sync.Mutex
+	current bool
+	calls   int
+}
+
+func (s *fakeSyncStatus) IsCurrent() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return s.current
+}
+
+func (s *fakeSyncStatus) checks() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// collectingChannel collects names and heights of received events.
+type collectingChannel struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (c *collectingChannel) Notify(event Event) {
+	var height int32
+	switch e := event.(type) {
+	case *domains.HeaderEvent:
+		height = e.Header.Height
+	case *domains.SyncEvent:
+		height = e.Height
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, fmt.Sprintf("%s:%d", EventName(event), height))
+}
+
+func (c *collectingChannel) names() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return strings.Join(c.events, ",")
+}
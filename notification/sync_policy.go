@@ -0,0 +1,61 @@
+package notification
+
+import (
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// SyncStatus reports if headers are synchronized with the network.
+type SyncStatus interface {
+	// IsCurrent checks if the headers are synchronized and up to date.
+	IsCurrent() bool
+}
+
+// syncTracker caches synchronization status of headers. It is updated by the loop synchronizing headers,
+// so notifying about events never checks the status in the database.
+type syncTracker struct {
+	mu      sync.Mutex
+	current bool
+}
+
+// newSyncTracker creates syncTracker with the initial status, nil status treats headers as always synchronized.
+func newSyncTracker(status SyncStatus) *syncTracker {
+	return &syncTracker{current: status == nil || status.IsCurrent()}
+}
+
+// isCurrent returns the last known synchronization status.
+func (s *syncTracker) isCurrent() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+// update stores synchronization status and reports if headers have just caught up with the network.
+func (s *syncTracker) update(current bool) (caughtUp bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	caughtUp = !s.current && current
+	s.current = current
+	return caughtUp
+}
+
+// syncProgress returns progress event which should replace the header event during synchronization,
+// or nil when the event should be dropped.
+func (q *channelQueue) syncProgress(e *domains.HeaderEvent, interval int32) *domains.SyncEvent {
+	if q.syncPolicy != config.SyncProgress || e.Operation != domains.EventHeaderAdded ||
+		e.Header == nil || e.Header.State != domains.LongestChain {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if e.Header.Height-q.lastProgress < interval {
+		return nil
+	}
+	q.lastProgress = e.Header.Height
+	progress := domains.SyncProgressed(e.Header)
+	stampEvent(progress)
+	return progress
+}
@@ -0,0 +1,161 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+)
+
+// WebhookPolicy decides to which urls webhooks can send events. Urls are checked on registration,
+// and addresses again right before connecting to them, so a hostname can't be re-pointed to a denied address later.
+type WebhookPolicy struct {
+	schemes      map[string]struct{}
+	allowedHosts []string
+	deniedHosts  []string
+	deniedCIDRs  []netip.Prefix
+	allowedCIDRs []netip.Prefix
+	resolver     *net.Resolver
+	// invalid is set when the policy couldn't be parsed, so every url is denied instead of allowing too much
+	invalid error
+}
+
+// NewWebhookPolicy creates WebhookPolicy. Nil config allows any url.
+func NewWebhookPolicy(cfg *config.WebhookPolicyConfig) *WebhookPolicy {
+	p := &WebhookPolicy{resolver: net.DefaultResolver}
+	if cfg == nil {
+		return p
+	}
+
+	p.schemes = make(map[string]struct{}, len(cfg.AllowedSchemes))
+	for _, s := range cfg.AllowedSchemes {
+		p.schemes[strings.ToLower(s)] = struct{}{}
+	}
+	p.allowedHosts = normalizeHosts(cfg.AllowedHosts)
+	p.deniedHosts = normalizeHosts(cfg.DeniedHosts)
+	p.deniedCIDRs, p.invalid = parsePrefixes(cfg.DeniedCIDRs)
+	if p.invalid == nil {
+		p.allowedCIDRs, p.invalid = parsePrefixes(cfg.AllowedCIDRs)
+	}
+	return p
+}
+
+// CheckURL checks scheme and host of the url and all addresses the host resolves to.
+func (p *WebhookPolicy) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if err := p.CheckTarget(u); err != nil {
+		return err
+	}
+
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("cannot resolve host %s: %w", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if err := p.CheckAddr(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckTarget checks scheme and hostname of the url without resolving it.
+func (p *WebhookPolicy) CheckTarget(u *url.URL) error {
+	if p.invalid != nil {
+		return p.invalid
+	}
+	if p.schemes != nil {
+		if _, ok := p.schemes[strings.ToLower(u.Scheme)]; !ok {
+			return fmt.Errorf("scheme %s is not allowed", u.Scheme)
+		}
+	}
+
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return errors.New("url must contain a host")
+	}
+	if matchesHost(p.deniedHosts, host) {
+		return fmt.Errorf("host %s is denied", host)
+	}
+	if len(p.allowedHosts) > 0 && !matchesHost(p.allowedHosts, host) {
+		return fmt.Errorf("host %s is not allowed", host)
+	}
+	return nil
+}
+
+// CheckAddr checks if webhooks can connect to the address.
+func (p *WebhookPolicy) CheckAddr(addr netip.Addr) error {
+	if p.invalid != nil {
+		return p.invalid
+	}
+	addr = addr.Unmap()
+	for _, prefix := range p.allowedCIDRs {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	for _, prefix := range p.deniedCIDRs {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("address %s is denied", addr)
+		}
+	}
+	return nil
+}
+
+// Control checks the address of a connection before it is made, it's meant to be used as net.Dialer Control.
+func (p *WebhookPolicy) Control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("unexpected address %s: %w", address, err)
+	}
+	return p.CheckAddr(addrPort.Addr())
+}
+
+func parsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook policy cidr %s: %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func normalizeHosts(hosts []string) []string {
+	normalized := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		normalized = append(normalized, normalizeHost(h))
+	}
+	return normalized
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// matchesHost checks if host is one of the patterns, where "*.example.com" matches every subdomain of example.com.
+func matchesHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
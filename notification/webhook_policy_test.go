@@ -0,0 +1,111 @@
+package notification
+
+import (
+	"context"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestWebhookPolicyCheckURL(t *testing.T) {
+	testCases := map[string]struct {
+		url           string
+		policy        config.WebhookPolicyConfig
+		expectedError bool
+	}{
+		"public address": {
+			url: "https://93.184.216.34/hook",
+		},
+		"not allowed scheme": {
+			url:           "ftp://93.184.216.34/hook",
+			expectedError: true,
+		},
+		"loopback address": {
+			url:           "http://127.0.0.1:8080/admin",
+			expectedError: true,
+		},
+		"localhost": {
+			url:           "http://localhost:8080/admin",
+			expectedError: true,
+		},
+		"cloud metadata address": {
+			url:           "http://169.254.169.254/latest/meta-data",
+			expectedError: true,
+		},
+		"ipv4 mapped ipv6 private address": {
+			url:           "http://[::ffff:10.0.0.1]/hook",
+			expectedError: true,
+		},
+		"denied network excluded by allowed cidr": {
+			url:    "http://10.1.2.3/hook",
+			policy: config.WebhookPolicyConfig{AllowedCIDRs: []string{"10.1.2.0/24"}},
+		},
+		"denied host": {
+			url:           "https://93.184.216.34/hook",
+			policy:        config.WebhookPolicyConfig{DeniedHosts: []string{"93.184.216.34"}},
+			expectedError: true,
+		},
+		"host not in allowed hosts": {
+			url:           "https://93.184.216.34/hook",
+			policy:        config.WebhookPolicyConfig{AllowedHosts: []string{"*.example.com"}},
+			expectedError: true,
+		},
+		"invalid policy denies everything": {
+			url:           "https://93.184.216.34/hook",
+			policy:        config.WebhookPolicyConfig{AllowedCIDRs: []string{"not-a-cidr"}},
+			expectedError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			cfg := defaultWebhookPolicy()
+			cfg.AllowedHosts = tc.policy.AllowedHosts
+			cfg.DeniedHosts = tc.policy.DeniedHosts
+			cfg.AllowedCIDRs = tc.policy.AllowedCIDRs
+			policy := NewWebhookPolicy(cfg)
+
+			// when
+			err := policy.CheckURL(context.Background(), tc.url)
+
+			// then
+			assert.Equal(t, err != nil, tc.expectedError)
+		})
+	}
+}
+
+func TestWebhookPolicyMatchesSubdomains(t *testing.T) {
+	// given
+	cfg := defaultWebhookPolicy()
+	cfg.AllowedHosts = []string{"*.example.com"}
+	policy := NewWebhookPolicy(cfg)
+
+	// then
+	assert.NoError(t, policy.CheckTarget(mustParseURL(t, "https://hooks.Example.com./new")))
+	assert.Equal(t, policy.CheckTarget(mustParseURL(t, "https://example.com.evil.net/new")) != nil, true)
+}
+
+func TestWebhookPolicyControlChecksDialedAddress(t *testing.T) {
+	// given
+	policy := NewWebhookPolicy(defaultWebhookPolicy())
+
+	// then
+	assert.NoError(t, policy.Control("tcp4", "93.184.216.34:443", nil))
+	assert.Equal(t, policy.Control("tcp4", "127.0.0.1:443", nil) != nil, true)
+	assert.Equal(t, policy.Control("tcp6", "[fe80::1]:443", nil) != nil, true)
+	assert.Equal(t, policy.CheckAddr(netip.MustParseAddr("192.168.1.1")) != nil, true)
+}
+
+func mustParseURL(t *testing.T, rawURL string) *url.URL {
+	u, err := url.Parse(rawURL)
+	assert.NoError(t, err)
+	return u
+}
+
+func defaultWebhookPolicy() *config.WebhookPolicyConfig {
+	return config.GetDefaultAppConfig().Webhook.Policy
+}
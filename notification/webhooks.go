@@ -7,6 +7,10 @@ import (
 	"time"
 )
 
+// maxWebhookResponseSize is the maximum number of bytes read from the response of a webhook
+// and stored as its last emit status.
+const maxWebhookResponseSize = 4 << 10
+
 // Webhook represents webhook.
 type Webhook struct {
 	URL               string    `json:"url"`
@@ -17,7 +21,10 @@ type Webhook struct {
 	LastEmitTimestamp time.Time `json:"lastEmitTimestamp"`
 	ErrorsCount       int       `json:"errorsCount"`
 	Active            bool      `json:"active"`
-	MaxTries          int       `json:"-"`
+	// Format is the format in which events are sent to the webhook.
+	Format EventFormat `json:"format"`
+	// Circuit is the state of the circuit breaker of the webhook, notifications are skipped while it's open.
+	Circuit CircuitState `json:"circuit,omitempty"`
 }
 
 // WebhookTargetClient is the interface for the webhooks http calls.
@@ -25,15 +32,13 @@ type WebhookTargetClient interface {
 	Call(headers map[string]string, method string, url string, body any) (*http.Response, error)
 }
 
-// Notify sends notification to webhook.
-func (w *Webhook) Notify(event Event, client WebhookTargetClient) error {
+// Notify sends notification to webhook in the format of the webhook.
+func (w *Webhook) Notify(event Event, client WebhookTargetClient, encoder *EventEncoder) error {
 	// Prepare headers
-	headers := map[string]string{
-		w.TokenHeader:  w.Token,
-		"Content-Type": "application/json",
-	}
+	headers, payload := encoder.HTTPRequest(event, w.Format)
+	headers[w.TokenHeader] = w.Token
 
-	res, err := client.Call(headers, http.MethodPost, w.URL, event)
+	res, err := client.Call(headers, http.MethodPost, w.URL, payload)
 
 	if err != nil {
 		// Update the webhook after failed notification.
@@ -43,8 +48,8 @@ func (w *Webhook) Notify(event Event, client WebhookTargetClient) error {
 
 	defer res.Body.Close() //nolint: all
 
-	// Read the response.
-	body, err := io.ReadAll(res.Body)
+	// Read the response, a webhook is not trusted to respond with a small body.
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxWebhookResponseSize))
 	if err != nil {
 		w.updateWebhookAfterNotification(0, "", err)
 		return err
@@ -54,6 +59,9 @@ func (w *Webhook) Notify(event Event, client WebhookTargetClient) error {
 	strBody := string(body)
 	w.updateWebhookAfterNotification(res.StatusCode, strBody, err)
 
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
 	return nil
 }
 
@@ -66,13 +74,9 @@ func (w *Webhook) updateWebhookAfterNotification(sCode int, body string, err err
 		w.LastEmitStatus = fmt.Sprint(sCode, " ", body)
 	}
 
-	// If status code is not 200, increment errors count and set active to false if errors count is more than max tries
+	// If status code is not 200, increment errors count, retrying the webhook is up to its circuit breaker
 	if sCode != http.StatusOK {
 		w.ErrorsCount = w.ErrorsCount + 1
-
-		if w.ErrorsCount >= w.MaxTries {
-			w.Active = false
-		}
 	} else {
 		// If status code is 200, reset errors count and set active to true
 		w.ErrorsCount = 0
@@ -81,7 +85,7 @@ func (w *Webhook) updateWebhookAfterNotification(sCode int, body string, err err
 }
 
 // CreateWebhook creates new webhook.
-func CreateWebhook(url, tokenHeader, token string, maxTries int) *Webhook {
+func CreateWebhook(url, tokenHeader, token string, format EventFormat) *Webhook {
 	return &Webhook{
 		URL:         url,
 		TokenHeader: tokenHeader,
@@ -89,6 +93,6 @@ func CreateWebhook(url, tokenHeader, token string, maxTries int) *Webhook {
 		CreatedAt:   time.Now(),
 		ErrorsCount: 0,
 		Active:      true,
-		MaxTries:    maxTries,
+		Format:      format,
 	}
 }
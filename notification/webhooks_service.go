@@ -1,41 +1,111 @@
 package notification
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/rs/zerolog"
 )
 
+// defaultWebhookWorkers is the number of webhooks notified concurrently when it's not configured.
+const defaultWebhookWorkers = 10
+
+// maxChallengeResponseSize limits the response of a webhook read during verification.
+const maxChallengeResponseSize = 4096
+
+// WebhookChallenge is sent to a webhook on registration when verification is enabled,
+// the webhook has to respond with the challenge (as plain text or the same JSON) to become active.
+type WebhookChallenge struct {
+	Challenge string `json:"challenge"`
+}
+
+// Auditor records registration and revocation of webhooks in the audit log.
+type Auditor interface {
+	Record(actor domains.AuditActor, action domains.AuditAction, target string, err error)
+}
+
 // WebhooksService represents Webhooks service and provide access to repositories.
 type WebhooksService struct {
 	webhooks Webhooks
 	client   WebhookTargetClient
+	encoder  *EventEncoder
+	policy   *WebhookPolicy
+	audit    Auditor
 	log      *zerolog.Logger
 	cfg      *config.WebhookConfig
+	workers  int
+	mu       sync.Mutex
+	// breakers are kept in memory only, every circuit is closed after a restart.
+	breakers map[string]*circuitBreaker
 }
 
 // NewWebhooksService creates and returns WebhooksService instance.
-func NewWebhooksService(repo Webhooks, client WebhookTargetClient, log *zerolog.Logger, cfg *config.WebhookConfig) *WebhooksService {
+// Policy is checked on registration of webhooks, the client should enforce it when connecting.
+func NewWebhooksService(
+	repo Webhooks,
+	client WebhookTargetClient,
+	policy *WebhookPolicy,
+	encoder *EventEncoder,
+	audit Auditor,
+	log *zerolog.Logger,
+	cfg *config.WebhookConfig,
+) *WebhooksService {
 	webhhoksLogger := log.With().Str("service", "webhooks").Logger()
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = defaultWebhookWorkers
+	}
 	return &WebhooksService{
 		webhooks: repo,
 		client:   client,
+		encoder:  encoder,
+		policy:   policy,
+		audit:    audit,
 		log:      &webhhoksLogger,
 		cfg:      cfg,
+		workers:  workers,
+		breakers: make(map[string]*circuitBreaker),
 	}
 }
 
-// CreateWebhook creates and save new webhook.
-func (s *WebhooksService) CreateWebhook(authType, header, token, url string) (*Webhook, error) {
+// CreateWebhook creates and save new webhook receiving events in given format.
+// Url has to be allowed by the webhook policy, and the webhook has to echo a challenge when verification is enabled.
+func (s *WebhooksService) CreateWebhook(actor domains.AuditActor, authType, header, token, url string, format EventFormat) (*Webhook, error) {
+	webhook, err := s.createWebhook(authType, header, token, url, format)
+	s.record(actor, domains.AuditWebhookRegister, url, err)
+	return webhook, err
+}
+
+func (s *WebhooksService) createWebhook(authType, header, token, url string, format EventFormat) (*Webhook, error) {
+	if err := s.policy.CheckURL(context.Background(), url); err != nil {
+		return nil, bhserrors.ErrWebhookURLNotAllowed.Wrap(err)
+	}
+
 	// If custom header is specified, use it, otherwise use default
 	if strings.ToLower(authType) == "bearer" {
 		header = "Authorization"
 		token = "Bearer " + token
 	}
 
-	webhook := CreateWebhook(url, header, token, s.cfg.MaxTries)
+	webhook := CreateWebhook(url, header, token, format)
+
+	if s.cfg.Verification {
+		if err := s.verify(webhook); err != nil {
+			return nil, bhserrors.ErrWebhookVerification.Wrap(err)
+		}
+	}
 
 	err := s.webhooks.AddWebhookToDatabase(webhook)
 	if err != nil {
@@ -45,7 +115,19 @@ func (s *WebhooksService) CreateWebhook(authType, header, token, url string) (*W
 }
 
 // DeleteWebhook deletes webhook by name or url.
-func (s *WebhooksService) DeleteWebhook(value string) error {
+func (s *WebhooksService) DeleteWebhook(actor domains.AuditActor, value string) error {
+	err := s.deleteWebhook(value)
+	s.record(actor, domains.AuditWebhookRevoke, value, err)
+	return err
+}
+
+func (s *WebhooksService) record(actor domains.AuditActor, action domains.AuditAction, target string, err error) {
+	if s.audit != nil {
+		s.audit.Record(actor, action, target, err)
+	}
+}
+
+func (s *WebhooksService) deleteWebhook(value string) error {
 	// Try to get and delete webhook by url
 	_, err := s.webhooks.GetWebhookByURL(value)
 	if err == nil {
@@ -53,12 +135,14 @@ func (s *WebhooksService) DeleteWebhook(value string) error {
 		if err != nil {
 			return err
 		}
+		s.resetBreaker(value)
 		return nil
 	}
 	return err
 }
 
-// Notify notifies all active webhooks.
+// Notify notifies all active webhooks concurrently and waits until all of them are notified,
+// so every webhook receives events in order. Webhooks with open circuit are skipped.
 func (s *WebhooksService) Notify(event Event) {
 	webhooks, err := s.webhooks.GetAllWebhooks()
 
@@ -67,33 +151,114 @@ func (s *WebhooksService) Notify(event Event) {
 		return
 	}
 
-	// Notify all active webhooks
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, s.workers)
+	notified := make([]*Webhook, 0, len(webhooks))
 	for _, webhook := range webhooks {
-		if webhook.Active {
-			if err := webhook.Notify(event, s.client); err != nil {
-				s.log.Warn().Msgf("Error during notification of the webhook: %v", err)
-			}
+		if !webhook.Active {
+			continue
+		}
+		breaker := s.breaker(webhook.URL)
+		if !breaker.allow() {
+			continue
+		}
 
-			if err := s.webhooks.UpdateWebhook(webhook); err != nil {
-				s.log.Error().Msgf("Error has happened during updating webhook state: %v", err)
+		notified = append(notified, webhook)
+		workers <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+			if err := webhook.Notify(event, s.client, s.encoder); err != nil {
+				s.log.Warn().Msgf("Error during notification of the webhook %s: %v", webhook.URL, err)
+				breaker.failure()
+				return
 			}
+			breaker.success()
+		}()
+	}
+	wg.Wait()
+
+	// webhooks are updated one by one, so the database doesn't have to handle concurrent writes
+	for _, webhook := range notified {
+		if err := s.webhooks.UpdateWebhook(webhook); err != nil {
+			s.log.Error().Msgf("Error has happened during updating webhook state: %v", err)
 		}
 	}
 }
 
 // GetWebhookByURL returns webhook by url.
 func (s *WebhooksService) GetWebhookByURL(url string) (*Webhook, error) {
-	return s.webhooks.GetWebhookByURL(url)
+	w, err := s.webhooks.GetWebhookByURL(url)
+	if err != nil {
+		return nil, err
+	}
+	w.Circuit = s.breaker(url).current()
+	return w, nil
+}
+
+// breaker returns circuit breaker of the webhook.
+func (s *WebhooksService) breaker(url string) *circuitBreaker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.breakers[url]
+	if !ok {
+		b = newCircuitBreaker(s.cfg.MaxTries, s.cfg.CircuitOpenDuration, s.cfg.CircuitMaxOpenDuration)
+		s.breakers[url] = b
+	}
+	return b
+}
+
+// resetBreaker forgets failures of the webhook, ex. when it's registered again.
+func (s *WebhooksService) resetBreaker(url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.breakers, url)
+}
+
+// verify sends a random challenge to the webhook and checks if the webhook echoed it.
+func (s *WebhooksService) verify(w *Webhook) error {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	challenge := hex.EncodeToString(nonce)
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	if w.TokenHeader != "" {
+		headers[w.TokenHeader] = w.Token
+	}
+	res, err := s.client.Call(headers, http.MethodPost, w.URL, &WebhookChallenge{Challenge: challenge})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() //nolint: all
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxChallengeResponseSize))
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+
+	body = bytes.TrimSpace(body)
+	var echoed WebhookChallenge
+	if string(body) == challenge || (json.Unmarshal(body, &echoed) == nil && echoed.Challenge == challenge) {
+		return nil
+	}
+	return errors.New("webhook responded without the challenge")
 }
 
-// refreshWebhook refresh webhook by resetting ErrorsCount and Active fields.
+// refreshWebhook refresh inactive webhook or webhook with open circuit by resetting its ErrorsCount, Active and circuit.
 func (s *WebhooksService) refreshWebhook(url string) (*Webhook, error) {
 	w, err := s.webhooks.GetWebhookByURL(url)
 	if err != nil {
 		return nil, err
 	}
 
-	if w != nil && !w.Active {
+	if w != nil && (!w.Active || s.breaker(url).current() != CircuitClosed) {
+		s.resetBreaker(url)
 		w.Active = true
 		w.ErrorsCount = 0
 		err = s.webhooks.UpdateWebhook(w)
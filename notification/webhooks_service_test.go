@@ -0,0 +1,268 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestWebhookVerification(t *testing.T) {
+	testCases := map[string]struct {
+		respond       func(w http.ResponseWriter, challenge string)
+		expectedError error
+	}{
+		"challenge echoed as text": {
+			respond: func(w http.ResponseWriter, challenge string) {
+				_, _ = w.Write([]byte(challenge))
+			},
+		},
+		"challenge echoed as json": {
+			respond: func(w http.ResponseWriter, challenge string) {
+				_ = json.NewEncoder(w).Encode(WebhookChallenge{Challenge: challenge})
+			},
+		},
+		"challenge not echoed": {
+			respond: func(w http.ResponseWriter, _ string) {
+				_, _ = w.Write([]byte("ok"))
+			},
+			expectedError: bhserrors.ErrWebhookVerification,
+		},
+		"endpoint failure": {
+			respond: func(w http.ResponseWriter, challenge string) {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(challenge))
+			},
+			expectedError: bhserrors.ErrWebhookVerification,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var challenge WebhookChallenge
+				_ = json.NewDecoder(r.Body).Decode(&challenge)
+				tc.respond(w, challenge.Challenge)
+			}))
+			defer target.Close()
+			repo := &webhooksMemoryRepository{}
+			service := newTestWebhooksService(repo, &config.WebhookConfig{MaxTries: 1, Verification: true})
+
+			// when
+			webhook, err := service.CreateWebhook(domains.AuditActor{}, "", "", "", target.URL, FormatJSON)
+
+			// then
+			if tc.expectedError != nil {
+				assert.IsError(t, err, tc.expectedError.Error())
+				assert.Equal(t, len(repo.webhooks), 0)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, webhook.Active, true)
+			assert.Equal(t, len(repo.webhooks), 1)
+		})
+	}
+}
+
+func TestWebhookURLNotAllowedByPolicy(t *testing.T) {
+	// given
+	repo := &webhooksMemoryRepository{}
+	service := newTestWebhooksService(repo, config.GetDefaultAppConfig().Webhook)
+
+	// when
+	_, err := service.CreateWebhook(domains.AuditActor{}, "", "", "", "http://169.254.169.254/latest/meta-data", FormatJSON)
+
+	// then
+	assert.IsError(t, err, bhserrors.ErrWebhookURLNotAllowed.Error())
+	assert.Equal(t, len(repo.webhooks), 0)
+}
+
+func TestWebhooksAreNotifiedConcurrently(t *testing.T) {
+	// given
+	const webhooks = 3
+	client := newBarrierClient(webhooks)
+	repo := &webhooksMemoryRepository{}
+	for i := 0; i < webhooks; i++ {
+		_ = repo.AddWebhookToDatabase(CreateWebhook(fmt.Sprintf("http://hook-%d.example.com", i), "", "", FormatJSON))
+	}
+	service := NewWebhooksService(repo, client, NewWebhookPolicy(nil), NewEventEncoder(""), nil, testLogger(), &config.WebhookConfig{MaxTries: 1, Workers: webhooks})
+
+	// when
+	service.Notify(headerEvent(domains.EventHeaderAdded, 1, domains.LongestChain))
+
+	// then every webhook was waiting for the others, which is possible only when they are notified at the same time
+	for _, w := range repo.webhooks {
+		assert.Equal(t, w.ErrorsCount, 0)
+	}
+}
+
+func TestFailingWebhookCircuitOpens(t *testing.T) {
+	// given
+	client := &statusClient{statuses: map[string]int{"http://127.0.0.1/failing": http.StatusInternalServerError}, calls: map[string]int{}}
+	repo := &webhooksMemoryRepository{}
+	_ = repo.AddWebhookToDatabase(CreateWebhook("http://127.0.0.1/failing", "", "", FormatJSON))
+	_ = repo.AddWebhookToDatabase(CreateWebhook("http://127.0.0.1/working", "", "", FormatJSON))
+	cfg := &config.WebhookConfig{MaxTries: 2, CircuitOpenDuration: time.Hour, CircuitMaxOpenDuration: time.Hour}
+	service := NewWebhooksService(repo, client, NewWebhookPolicy(nil), NewEventEncoder(""), nil, testLogger(), cfg)
+
+	// when
+	for i := int32(1); i <= 4; i++ {
+		service.Notify(headerEvent(domains.EventHeaderAdded, i, domains.LongestChain))
+	}
+
+	// then
+	assert.Equal(t, client.calls["http://127.0.0.1/failing"], 2)
+	assert.Equal(t, client.calls["http://127.0.0.1/working"], 4)
+	failing, err := service.GetWebhookByURL("http://127.0.0.1/failing")
+	assert.NoError(t, err)
+	assert.Equal(t, failing.Circuit, CircuitOpen)
+	assert.Equal(t, failing.Active, true)
+
+	// when webhook is registered again
+	_, err = service.CreateWebhook(domains.AuditActor{}, "", "", "", "http://127.0.0.1/failing", FormatJSON)
+
+	// then
+	assert.NoError(t, err)
+	failing, _ = service.GetWebhookByURL("http://127.0.0.1/failing")
+	assert.Equal(t, failing.Circuit, CircuitClosed)
+}
+
+func TestWebhookResponseIsLimited(t *testing.T) {
+	// given
+	webhook := CreateWebhook("http://127.0.0.1/large", "", "", FormatJSON)
+	client := largeResponseClient{}
+
+	// when
+	err := webhook.Notify(headerEvent(domains.EventHeaderAdded, 1, domains.LongestChain), client, NewEventEncoder(""))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(webhook.LastEmitStatus), len("200 ")+maxWebhookResponseSize)
+}
+
+// largeResponseClient responds with a body larger than a webhook response can be.
+type largeResponseClient struct{}
+
+func (largeResponseClient) Call(map[string]string, string, string, any) (*http.Response, error) {
+	body := strings.Repeat("a", 2*maxWebhookResponseSize)
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+// barrierClient responds to a call only when the expected number of calls is in progress.
+type barrierClient struct {
+	mu       sync.Mutex
+	expected int
+	calls    int
+	all      chan struct{}
+}
+
+func newBarrierClient(expected int) *barrierClient {
+	return &barrierClient{expected: expected, all: make(chan struct{})}
+}
+
+func (c *barrierClient) Call(map[string]string, string, string, any) (*http.Response, error) {
+	c.mu.Lock()
+	c.calls++
+	if c.calls == c.expected {
+		close(c.all)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.all:
+		return okResponse(http.StatusOK), nil
+	case <-time.After(2 * time.Second):
+		return nil, errors.New("webhooks are not notified concurrently")
+	}
+}
+
+// statusClient responds with configured status of the url or 200.
+type statusClient struct {
+	mu       sync.Mutex
+	statuses map[string]int
+	calls    map[string]int
+}
+
+func (c *statusClient) Call(_ map[string]string, _ string, url string, _ any) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[url]++
+	if status, ok := c.statuses[url]; ok {
+		return okResponse(status), nil
+	}
+	return okResponse(http.StatusOK), nil
+}
+
+func okResponse(status int) *http.Response {
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(""))}
+}
+
+func newTestWebhooksService(repo Webhooks, cfg *config.WebhookConfig) *WebhooksService {
+	log := testLogger()
+	policy := NewWebhookPolicy(cfg.Policy)
+	return NewWebhooksService(repo, httpTargetClient{}, policy, NewEventEncoder(""), nil, log, cfg)
+}
+
+type httpTargetClient struct{}
+
+func (httpTargetClient) Call(headers map[string]string, method string, url string, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(context.Background(), method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	for header, value := range headers {
+		req.Header.Set(header, value)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+type webhooksMemoryRepository struct {
+	webhooks []*Webhook
+}
+
+func (r *webhooksMemoryRepository) AddWebhookToDatabase(w *Webhook) error {
+	if _, err := r.GetWebhookByURL(w.URL); err == nil {
+		return fmt.Errorf("webhook with url %s already exists", w.URL)
+	}
+	r.webhooks = append(r.webhooks, w)
+	return nil
+}
+
+func (r *webhooksMemoryRepository) DeleteWebhookByURL(string) error {
+	return nil
+}
+
+func (r *webhooksMemoryRepository) GetWebhookByURL(url string) (*Webhook, error) {
+	for _, w := range r.webhooks {
+		if w.URL == url {
+			return w, nil
+		}
+	}
+	return nil, bhserrors.ErrWebhookNotFound
+}
+
+func (r *webhooksMemoryRepository) GetAllWebhooks() ([]*Webhook, error) {
+	return r.webhooks, nil
+}
+
+func (r *webhooksMemoryRepository) UpdateWebhook(*Webhook) error {
+	return nil
+}
@@ -1,7 +1,6 @@
 package notification
 
 import (
-	"encoding/json"
 	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
@@ -9,6 +8,9 @@ import (
 	"github.com/rs/zerolog"
 )
 
+// EventIDTag is the tag of websocket publications with id of the event, see EventID.
+const EventIDTag = "id"
+
 // WebsocketPublisher represents websocket server entrypoint used to publish messages via websocket communication.
 type WebsocketPublisher interface {
 	Publish(channel string, data []byte, opts ...centrifuge.PublishOption) (centrifuge.PublishResult, error)
@@ -16,16 +18,27 @@ type WebsocketPublisher interface {
 
 type wsChan struct {
 	publisher      WebsocketPublisher
+	subscriptions  *WebsocketSubscriptions
+	encoder        *EventEncoder
 	log            *zerolog.Logger
 	historySize    int
 	historySeconds int
 }
 
 // NewWebsocketChannel create Channel implementation communicating via websocket.
-func NewWebsocketChannel(log *zerolog.Logger, publisher WebsocketPublisher, cfg *config.WebsocketConfig) Channel {
+// Encoder is used for channels which subscribers requested another format than plain JSON.
+func NewWebsocketChannel(
+	log *zerolog.Logger,
+	publisher WebsocketPublisher,
+	subscriptions *WebsocketSubscriptions,
+	encoder *EventEncoder,
+	cfg *config.WebsocketConfig,
+) Channel {
 	channelLogger := log.With().Str("subservice", "ws-channel").Logger()
 	return &wsChan{
 		publisher:      publisher,
+		subscriptions:  subscriptions,
+		encoder:        encoder,
 		log:            &channelLogger,
 		historySize:    cfg.HistoryMax,
 		historySeconds: cfg.HistoryTTL,
@@ -33,20 +46,35 @@ func NewWebsocketChannel(log *zerolog.Logger, publisher WebsocketPublisher, cfg
 }
 
 func (w *wsChan) Notify(event Event) {
-	bytes, err := json.Marshal(event)
-	if err != nil {
-		w.log.Error().Msgf("Error when creating json from event %v: %v", event, err)
-		return
-	}
+	// every format is encoded once, so all channels of the format get the same message
+	encoded := make(map[EventFormat][]byte, 1)
+	for _, channel := range w.subscriptions.channelsFor(event) {
+		format := w.subscriptions.formatOf(channel)
+		bytes, ok := encoded[format]
+		if !ok {
+			var err error
+			if bytes, err = w.encoder.Encode(event, format); err != nil {
+				// subscribers of other formats still get the event
+				w.log.Error().Msgf("Error when creating %s from event %v: %v", format, event, err)
+			}
+			encoded[format] = bytes
+		}
+		if bytes == nil {
+			continue
+		}
 
-	if err := w.publishToHeadersChannel(bytes); err != nil {
-		w.log.Error().Msgf("Error when sending event %v to channel: %v", event, err)
-		return
+		if err := w.publish(channel, bytes, EventID(event)); err != nil {
+			w.log.Error().Msgf("Error when sending event %v to channel %s: %v", event, channel, err)
+		}
 	}
 }
 
-func (w *wsChan) publishToHeadersChannel(bytes []byte) error {
-	_, err := w.publisher.Publish("headers", bytes,
-		centrifuge.WithHistory(w.historySize, time.Duration(w.historySeconds)*time.Minute))
+func (w *wsChan) publish(channel string, bytes []byte, id string) error {
+	opts := []centrifuge.PublishOption{centrifuge.WithHistory(w.historySize, time.Duration(w.historySeconds)*time.Minute)}
+	if id != "" {
+		// recovery of missed events skips events which are recovered from the history of the channel too
+		opts = append(opts, centrifuge.WithTags(map[string]string{EventIDTag: id}))
+	}
+	_, err := w.publisher.Publish(channel, bytes, opts...)
 	return err
 }
@@ -0,0 +1,271 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// Websocket channels to which events are published.
+const (
+	// HeadersChannel is a legacy channel receiving all events.
+	HeadersChannel = "headers"
+	// HeadersAllChannel is a channel receiving all header events.
+	HeadersAllChannel = "headers:all"
+	// TipChannel is a channel receiving only headers which became the tip of the longest chain.
+	TipChannel = "tip"
+	// ReorgChannel is a channel receiving only reorganizations of the longest chain.
+	ReorgChannel = "reorg"
+	// MerkleRootsChannel is a channel receiving events of merkle roots watched without a token, when authorization is disabled.
+	MerkleRootsChannel = "merkleroots"
+	// MerkleRootsChannelPrefix is a prefix of channels receiving events of merkle roots watched by a single token,
+	// followed by the id of the token.
+	MerkleRootsChannelPrefix = MerkleRootsChannel + ":"
+)
+
+// WebsocketSubscription represents websocket channel with filters requested by a subscriber,
+// ex. "headers:all?minHeight=100&states=LONGEST_CHAIN,STALE".
+type WebsocketSubscription struct {
+	// Channel is the full name of the channel including filters.
+	Channel string
+	// Base is the name of the channel without filters.
+	Base string
+	// MerkleRoots marks the merkleroots channel of a watcher.
+	MerkleRoots bool
+	// Watcher is the id of the token which watched merkle roots are published to the merkleroots channel.
+	Watcher string
+	// MinHeight filters out headers below given height.
+	MinHeight int32
+	// States filters out headers which are not in one of given states.
+	States []domains.HeaderState
+	// Format is the format in which events are published to the channel.
+	Format EventFormat
+}
+
+// ParseWebsocketChannel parses channel name with optional filters to WebsocketSubscription.
+func ParseWebsocketChannel(channel string) (*WebsocketSubscription, error) {
+	base, rawFilters, hasFilters := strings.Cut(channel, "?")
+	sub := &WebsocketSubscription{Channel: channel, Base: base, Format: FormatJSON}
+
+	switch {
+	case base == HeadersChannel, base == HeadersAllChannel, base == TipChannel, base == ReorgChannel:
+	case base == MerkleRootsChannel, strings.HasPrefix(base, MerkleRootsChannelPrefix):
+		sub.MerkleRoots = true
+		sub.Watcher = strings.TrimPrefix(strings.TrimPrefix(base, MerkleRootsChannel), ":")
+		if base != MerkleRootsChannel && sub.Watcher == "" {
+			return nil, fmt.Errorf("missing token id in channel %s", channel)
+		}
+		if hasFilters && !onlyFormatFilter(rawFilters) {
+			return nil, fmt.Errorf("channel %s supports only format filter", base)
+		}
+	default:
+		return nil, fmt.Errorf("unknown channel %s", channel)
+	}
+
+	if hasFilters {
+		if err := sub.parseFilters(rawFilters); err != nil {
+			return nil, fmt.Errorf("invalid filters of channel %s: %w", channel, err)
+		}
+	}
+	return sub, nil
+}
+
+func (s *WebsocketSubscription) parseFilters(rawFilters string) error {
+	filters, err := url.ParseQuery(rawFilters)
+	if err != nil {
+		return err
+	}
+
+	for name, values := range filters {
+		value := values[len(values)-1]
+		switch name {
+		case "minHeight":
+			h, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || h < 0 {
+				return errors.New("minHeight must be a non-negative integer")
+			}
+			s.MinHeight = int32(h)
+		case "states":
+			for _, st := range strings.Split(value, ",") {
+				state := domains.HeaderState(strings.ToUpper(strings.TrimSpace(st)))
+				if state != domains.LongestChain && state != domains.Stale && state != domains.Orphan && state != domains.Rejected {
+					return fmt.Errorf("unknown state %s", st)
+				}
+				s.States = append(s.States, state)
+			}
+		case "format":
+			format, err := ParseEventFormat(value)
+			if err != nil || format == FormatCloudEventsBinary {
+				return errors.New("format must be json or cloudevents")
+			}
+			s.Format = format
+		default:
+			return fmt.Errorf("unknown filter %s", name)
+		}
+	}
+	return nil
+}
+
+func onlyFormatFilter(rawFilters string) bool {
+	filters, err := url.ParseQuery(rawFilters)
+	if err != nil {
+		return false
+	}
+	for name := range filters {
+		if name != "format" {
+			return false
+		}
+	}
+	return true
+}
+
+// Scope returns the token scope required to subscribe on the channel.
+func (s *WebsocketSubscription) Scope() domains.Scope {
+	if s.MerkleRoots {
+		return domains.ScopeMerkleRootsVerify
+	}
+	return domains.ScopeHeadersRead
+}
+
+// IsFiltered checks if subscriber requested any filters.
+func (s *WebsocketSubscription) IsFiltered() bool {
+	return s.Channel != s.Base
+}
+
+// Accepts checks if event should be published to the channel.
+func (s *WebsocketSubscription) Accepts(event Event) bool {
+	switch e := event.(type) {
+	case *domains.HeaderEvent:
+		switch s.Base {
+		case TipChannel:
+			if e.Operation != domains.EventHeaderAdded || e.Header.State != domains.LongestChain {
+				return false
+			}
+		case ReorgChannel:
+			if e.Operation != domains.EventChainReorganized {
+				return false
+			}
+		case HeadersAllChannel, HeadersChannel:
+		default:
+			return false
+		}
+		return s.acceptsHeader(e.Header)
+	case *domains.MerkleRootEvent:
+		return s.MerkleRoots && s.Watcher == e.Watcher
+	case *domains.SyncEvent:
+		return s.Base == HeadersChannel || s.Base == HeadersAllChannel || s.Base == TipChannel
+	default:
+		return s.Base == HeadersChannel
+	}
+}
+
+func (s *WebsocketSubscription) acceptsHeader(h *domains.HeaderEventDetails) bool {
+	if h == nil {
+		return true
+	}
+	if h.Height < s.MinHeight {
+		return false
+	}
+	if len(s.States) == 0 {
+		return true
+	}
+	for _, st := range s.States {
+		if h.State == st {
+			return true
+		}
+	}
+	return false
+}
+
+// MerkleRootsChannelOf returns the channel receiving events of merkle roots watched by the token with given id.
+func MerkleRootsChannelOf(watcher string) string {
+	if watcher == "" {
+		return MerkleRootsChannel
+	}
+	return MerkleRootsChannelPrefix + watcher
+}
+
+// WebsocketSubscriptions keeps track of filtered channels which have at least one subscriber,
+// so events can be filtered before publishing them.
+type WebsocketSubscriptions struct {
+	mu       sync.RWMutex
+	filtered map[string]*filteredChannel
+}
+
+type filteredChannel struct {
+	subscription *WebsocketSubscription
+	subscribers  int
+}
+
+// NewWebsocketSubscriptions creates WebsocketSubscriptions.
+func NewWebsocketSubscriptions() *WebsocketSubscriptions {
+	return &WebsocketSubscriptions{
+		filtered: make(map[string]*filteredChannel),
+	}
+}
+
+// Subscribe registers subscriber of the filtered channel.
+func (ws *WebsocketSubscriptions) Subscribe(s *WebsocketSubscription) {
+	if !s.IsFiltered() {
+		return
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	fc, ok := ws.filtered[s.Channel]
+	if !ok {
+		fc = &filteredChannel{subscription: s}
+		ws.filtered[s.Channel] = fc
+	}
+	fc.subscribers++
+}
+
+// Unsubscribe removes subscriber of the filtered channel.
+func (ws *WebsocketSubscriptions) Unsubscribe(channel string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	fc, ok := ws.filtered[channel]
+	if !ok {
+		return
+	}
+	fc.subscribers--
+	if fc.subscribers <= 0 {
+		delete(ws.filtered, channel)
+	}
+}
+
+// formatOf returns format of events published to the channel.
+func (ws *WebsocketSubscriptions) formatOf(channel string) EventFormat {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	if fc, ok := ws.filtered[channel]; ok {
+		return fc.subscription.Format
+	}
+	return FormatJSON
+}
+
+// channelsFor returns channels to which event should be published.
+func (ws *WebsocketSubscriptions) channelsFor(event Event) []string {
+	channels := make([]string, 0)
+	for _, base := range []string{HeadersChannel, HeadersAllChannel, TipChannel, ReorgChannel} {
+		if (&WebsocketSubscription{Channel: base, Base: base}).Accepts(event) {
+			channels = append(channels, base)
+		}
+	}
+	if e, ok := event.(*domains.MerkleRootEvent); ok {
+		channels = append(channels, MerkleRootsChannelOf(e.Watcher))
+	}
+
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	for channel, fc := range ws.filtered {
+		if fc.subscription.Accepts(event) {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
@@ -0,0 +1,248 @@
+package notification
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/centrifugal/centrifuge"
+	"github.com/rs/zerolog"
+)
+
+var watcherID = strings.Repeat("ab", 32)
+
+func TestParseWebsocketChannel(t *testing.T) {
+	testCases := map[string]struct {
+		channel           string
+		expectedError     bool
+		expectedBase      string
+		expectedMinHeight int32
+		expectedStates    []domains.HeaderState
+		expectedFormat    EventFormat
+	}{
+		"legacy headers channel": {
+			channel:      "headers",
+			expectedBase: HeadersChannel,
+		},
+		"tip with min height": {
+			channel:           "tip?minHeight=100",
+			expectedBase:      TipChannel,
+			expectedMinHeight: 100,
+		},
+		"all headers with states": {
+			channel:        "headers:all?states=longest_chain,STALE",
+			expectedBase:   HeadersAllChannel,
+			expectedStates: []domains.HeaderState{domains.LongestChain, domains.Stale},
+		},
+		"merkle roots channel of a token": {
+			channel:      MerkleRootsChannelPrefix + watcherID,
+			expectedBase: MerkleRootsChannelPrefix + watcherID,
+		},
+		"merkle roots channel without a token": {
+			channel:      MerkleRootsChannel,
+			expectedBase: MerkleRootsChannel,
+		},
+		"unknown channel": {
+			channel:       "test",
+			expectedError: true,
+		},
+		"unknown filter": {
+			channel:       "headers:all?maxHeight=1",
+			expectedError: true,
+		},
+		"unknown state": {
+			channel:       "headers:all?states=VALID",
+			expectedError: true,
+		},
+		"negative min height": {
+			channel:       "tip?minHeight=-1",
+			expectedError: true,
+		},
+		"merkle roots channel with empty token id": {
+			channel:       MerkleRootsChannelPrefix,
+			expectedError: true,
+		},
+		"merkle root channel with filters": {
+			channel:       MerkleRootsChannelPrefix + watcherID + "?minHeight=1",
+			expectedError: true,
+		},
+		"cloudevents format": {
+			channel:        "headers:all?format=cloudevents",
+			expectedBase:   HeadersAllChannel,
+			expectedFormat: FormatCloudEvents,
+		},
+		"merkle root channel with format": {
+			channel:        MerkleRootsChannelPrefix + watcherID + "?format=cloudevents",
+			expectedBase:   MerkleRootsChannelPrefix + watcherID,
+			expectedFormat: FormatCloudEvents,
+		},
+		"binary cloudevents format": {
+			channel:       "tip?format=cloudevents-binary",
+			expectedError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			sub, err := ParseWebsocketChannel(tc.channel)
+
+			// then
+			if tc.expectedError {
+				assert.Equal(t, err != nil, true)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, sub.Base, tc.expectedBase)
+			assert.Equal(t, sub.MinHeight, tc.expectedMinHeight)
+			if tc.expectedFormat == "" {
+				tc.expectedFormat = FormatJSON
+			}
+			assert.Equal(t, sub.Format, tc.expectedFormat)
+			assert.Equal(t, len(sub.States), len(tc.expectedStates))
+			for i, st := range tc.expectedStates {
+				assert.Equal(t, sub.States[i], st)
+			}
+		})
+	}
+}
+
+func TestChannelsForEvent(t *testing.T) {
+	// given
+	subscriptions := NewWebsocketSubscriptions()
+	for _, channel := range []string{"headers:all?minHeight=10", "tip?minHeight=10", "headers:all?states=STALE"} {
+		sub, err := ParseWebsocketChannel(channel)
+		assert.NoError(t, err)
+		subscriptions.Subscribe(sub)
+	}
+
+	testCases := map[string]struct {
+		event            Event
+		expectedChannels []string
+	}{
+		"longest chain header": {
+			event:            headerEvent(domains.EventHeaderAdded, 10, domains.LongestChain),
+			expectedChannels: []string{"headers", "headers:all", "headers:all?minHeight=10", "tip", "tip?minHeight=10"},
+		},
+		"longest chain header below min height": {
+			event:            headerEvent(domains.EventHeaderAdded, 9, domains.LongestChain),
+			expectedChannels: []string{"headers", "headers:all", "tip"},
+		},
+		"stale header": {
+			event:            headerEvent(domains.EventHeaderAdded, 5, domains.Stale),
+			expectedChannels: []string{"headers", "headers:all", "headers:all?states=STALE"},
+		},
+		"reorg": {
+			event:            headerEvent(domains.EventChainReorganized, 12, domains.LongestChain),
+			expectedChannels: []string{"headers", "headers:all", "headers:all?minHeight=10", "reorg"},
+		},
+		"merkle root event": {
+			event:            &domains.MerkleRootEvent{Operation: domains.EventMerkleRootConfirmed, Watcher: watcherID},
+			expectedChannels: []string{MerkleRootsChannelPrefix + watcherID},
+		},
+		"merkle root event watched without a token": {
+			event:            &domains.MerkleRootEvent{Operation: domains.EventMerkleRootConfirmed},
+			expectedChannels: []string{MerkleRootsChannel},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			channels := subscriptions.channelsFor(tc.event)
+
+			// then
+			sort.Strings(channels)
+			sort.Strings(tc.expectedChannels)
+			assert.Equal(t, strings.Join(channels, " "), strings.Join(tc.expectedChannels, " "))
+		})
+	}
+}
+
+func TestSubscriptionScope(t *testing.T) {
+	testCases := map[string]struct {
+		channel       string
+		expectedScope domains.Scope
+	}{
+		"headers channel": {
+			channel:       "headers:all?minHeight=10",
+			expectedScope: domains.ScopeHeadersRead,
+		},
+		"tip channel": {
+			channel:       TipChannel,
+			expectedScope: domains.ScopeHeadersRead,
+		},
+		"merkle root channel": {
+			channel:       MerkleRootsChannelPrefix + watcherID,
+			expectedScope: domains.ScopeMerkleRootsVerify,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			sub, err := ParseWebsocketChannel(tc.channel)
+			assert.NoError(t, err)
+
+			// then
+			assert.Equal(t, sub.Scope(), tc.expectedScope)
+		})
+	}
+}
+
+func TestUnsubscribedFilteredChannelIsNotPublished(t *testing.T) {
+	// given
+	subscriptions := NewWebsocketSubscriptions()
+	sub, _ := ParseWebsocketChannel("tip?minHeight=1")
+	subscriptions.Subscribe(sub)
+	subscriptions.Subscribe(sub)
+
+	// when
+	subscriptions.Unsubscribe(sub.Channel)
+	afterFirst := subscriptions.channelsFor(headerEvent(domains.EventHeaderAdded, 1, domains.LongestChain))
+	subscriptions.Unsubscribe(sub.Channel)
+	afterLast := subscriptions.channelsFor(headerEvent(domains.EventHeaderAdded, 1, domains.LongestChain))
+
+	// then
+	assert.Equal(t, len(afterFirst), 4)
+	assert.Equal(t, len(afterLast), 3)
+}
+
+func TestWebsocketPublicationsTaggedWithEventID(t *testing.T) {
+	// given
+	logger := zerolog.Nop()
+	publisher := &recordingWebsocketPublisher{tags: make(map[string]map[string]string)}
+	channel := NewWebsocketChannel(&logger, publisher, NewWebsocketSubscriptions(), NewEventEncoder(""), &config.WebsocketConfig{})
+	event := headerEvent(domains.EventHeaderAdded, 10, domains.LongestChain)
+	event.Header.Hash = "hash"
+
+	// when
+	channel.Notify(event)
+
+	// then
+	assert.Equal(t, publisher.tags[TipChannel][EventIDTag], "10:hash")
+	assert.Equal(t, len(publisher.tags[HeadersChannel]), 1)
+}
+
+type recordingWebsocketPublisher struct {
+	tags map[string]map[string]string
+}
+
+func (p *recordingWebsocketPublisher) Publish(channel string, _ []byte, opts ...centrifuge.PublishOption) (centrifuge.PublishResult, error) {
+	var options centrifuge.PublishOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	p.tags[channel] = options.Tags
+	return centrifuge.PublishResult{}, nil
+}
+
+func headerEvent(operation domains.HeaderEventType, height int32, state domains.HeaderState) *domains.HeaderEvent {
+	return &domains.HeaderEvent{
+		Operation: operation,
+		Header:    &domains.HeaderEventDetails{Height: height, State: state},
+	}
+}
@@ -225,14 +225,12 @@ func setPostgresEnvs(cmd *exec.Cmd, mappedPort string) {
 	cmd.Env = append(cmd.Env, "BHS_DB_ENGINE=postgres")
 	cmd.Env = append(cmd.Env, "BHS_DB_PREPARED_DB=true")
 	cmd.Env = append(cmd.Env, "BHS_DB_PREPARED_DB_FILE_PATH=../data/blockheaders.csv.gz")
-	cmd.Env = append(cmd.Env, "BHS_DB_SCHEMA_PATH=../database/migrations")
 }
 
 func setSQLiteEnvs(cmd *exec.Cmd) {
 	cmd.Env = append(cmd.Env, "BHS_DB_ENGINE=sqlite")
 	cmd.Env = append(cmd.Env, "BHS_DB_PREPARED_DB=true")
 	cmd.Env = append(cmd.Env, "BHS_DB_PREPARED_DB_FILE_PATH=../data/blockheaders.csv.gz")
-	cmd.Env = append(cmd.Env, "BHS_DB_SCHEMA_PATH=../database/migrations")
 	cmd.Env = append(cmd.Env, "BHS_DB_SQLITE_FILE_PATH=blockheaders.db")
 }
 
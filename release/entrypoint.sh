@@ -28,6 +28,9 @@ function clean_db() {
     echo "Cleaning existing preloaded database archive"
     rm $BHS_DB_PREPARED_DB_FILE_PATH
   fi
+  if [[ -e $BHS_DB_PREPARED_DB_FILE_PATH.sig ]]; then
+    rm $BHS_DB_PREPARED_DB_FILE_PATH.sig
+  fi
 }
 
 function preload() {
@@ -38,6 +41,10 @@ function preload() {
   else
     echo "Downloading preloaded database ..."
     wget -nc -O $BHS_DB_PREPARED_DB_FILE_PATH $PRELOADED_DB_URL
+    if [[ -n $BHS_DB_PREPARED_DB_PUBLIC_KEY ]]; then
+      echo "Downloading signature of preloaded database ..."
+      wget -nc -O ${BHS_DB_PREPARED_DB_SIGNATURE_FILE_PATH:-$BHS_DB_PREPARED_DB_FILE_PATH.sig} $PRELOADED_DB_URL.sig
+    fi
     export BHS_DB_PREPARED_DB=true
   fi
 }
@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// DbAuditRecord represent record of the audit log saved in db.
+type DbAuditRecord struct {
+	ID        string    `db:"id"`
+	Actor     string    `db:"actor"`
+	Action    string    `db:"action"`
+	Target    string    `db:"target"`
+	SourceIP  string    `db:"source_ip"`
+	Result    string    `db:"result"`
+	Error     string    `db:"error"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// ToAuditRecord converts DbAuditRecord to AuditRecord.
+func (dbr *DbAuditRecord) ToAuditRecord() *domains.AuditRecord {
+	return &domains.AuditRecord{
+		ID:        dbr.ID,
+		Actor:     dbr.Actor,
+		Action:    domains.AuditAction(dbr.Action),
+		Target:    dbr.Target,
+		SourceIP:  dbr.SourceIP,
+		Result:    dbr.Result,
+		Error:     dbr.Error,
+		CreatedAt: dbr.CreatedAt,
+	}
+}
+
+// ToDbAuditRecord converts AuditRecord to DbAuditRecord.
+func ToDbAuditRecord(r *domains.AuditRecord) *DbAuditRecord {
+	return &DbAuditRecord{
+		ID:        r.ID,
+		Actor:     r.Actor,
+		Action:    string(r.Action),
+		Target:    r.Target,
+		SourceIP:  r.SourceIP,
+		Result:    r.Result,
+		Error:     r.Error,
+		CreatedAt: r.CreatedAt,
+	}
+}
@@ -0,0 +1,105 @@
+package dto
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+)
+
+// WorkSize is the number of bytes of work encoded by DbWork.Bytes.
+const WorkSize = 32
+
+// DbHash is a hex encoded hash of a header or a merkle root, stored as 32 bytes in the order of its hex representation.
+type DbHash string
+
+// Value implements driver.Valuer.
+func (h DbHash) Value() (driver.Value, error) {
+	return h.Bytes()
+}
+
+// Bytes decodes the hash to chainhash.HashSize bytes.
+func (h DbHash) Bytes() ([]byte, error) {
+	b, err := hex.DecodeString(string(h))
+	if err != nil || len(b) != chainhash.HashSize {
+		return nil, fmt.Errorf("invalid hash %s", h)
+	}
+	return b, nil
+}
+
+// Scan implements sql.Scanner.
+func (h *DbHash) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		*h = DbHash(hex.EncodeToString(v))
+	case nil:
+		*h = ""
+	default:
+		return fmt.Errorf("cannot scan %T into hash", src)
+	}
+	return nil
+}
+
+// String returns the hex encoded hash.
+func (h DbHash) String() string {
+	return string(h)
+}
+
+// DbWork is a decimal chainwork or cumulated work of a header. It is stored as NUMERIC in PostgreSQL,
+// and as 32 bytes big-endian in SQLite, which compare in the same order as the numbers. The encoding is picked
+// by the driver of the engine: the PostgreSQL driver returns decimal strings and the SQLite driver returns bytes.
+type DbWork string
+
+// Value implements driver.Valuer.
+func (w DbWork) Value() (driver.Value, error) {
+	if w == "" {
+		return "0", nil
+	}
+	return string(w), nil
+}
+
+// Scan implements sql.Scanner.
+func (w *DbWork) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return w.SetBytes(v)
+	case string:
+		*w = DbWork(v)
+	case int64:
+		*w = DbWork(big.NewInt(v).String())
+	case nil:
+		*w = "0"
+	default:
+		return fmt.Errorf("cannot scan %T into work", src)
+	}
+	return nil
+}
+
+// Bytes encodes the work in WorkSize bytes big-endian.
+func (w DbWork) Bytes() ([]byte, error) {
+	work, ok := new(big.Int).SetString(string(w), 10)
+	if w == "" {
+		work, ok = new(big.Int), true
+	}
+	if !ok || work.Sign() < 0 || work.BitLen() > WorkSize*8 {
+		return nil, fmt.Errorf("invalid work %s", w)
+	}
+	return work.FillBytes(make([]byte, WorkSize)), nil
+}
+
+// SetBytes decodes the work from WorkSize bytes big-endian.
+func (w *DbWork) SetBytes(b []byte) error {
+	if len(b) != WorkSize {
+		return errors.New("encoded work must have 32 bytes")
+	}
+	*w = DbWork(new(big.Int).SetBytes(b).String())
+	return nil
+}
+
+// String returns the decimal work.
+func (w DbWork) String() string {
+	return string(w)
+}
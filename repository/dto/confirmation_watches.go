@@ -0,0 +1,14 @@
+package dto
+
+import "github.com/bitcoin-sv/block-headers-service/domains"
+
+// DbConfirmationWatch represent header watched for confirmations saved in db.
+type DbConfirmationWatch struct {
+	Owner string `db:"owner"`
+	Hash  string `db:"hash"`
+}
+
+// ToConfirmationWatch converts DbConfirmationWatch to ConfirmationWatch.
+func (dbw *DbConfirmationWatch) ToConfirmationWatch() domains.ConfirmationWatch {
+	return domains.ConfirmationWatch{Owner: dbw.Owner, Hash: dbw.Hash}
+}
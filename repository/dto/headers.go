@@ -11,23 +11,25 @@ import (
 
 // DbMerkleRoot is a database representation of a Merkle Root and it's height
 type DbMerkleRoot struct {
-	MerkleRoot string `db:"merkleroot"`
+	MerkleRoot DbHash `db:"merkleroot"`
 	Height     int32  `db:"height"`
 }
 
 // DbBlockHeader represent header saved in db.
 type DbBlockHeader struct {
 	Height        int32     `db:"height"`
-	Hash          string    `db:"hash"`
+	Hash          DbHash    `db:"hash"`
 	Version       int32     `db:"version"`
-	MerkleRoot    string    `db:"merkleroot"`
+	MerkleRoot    DbHash    `db:"merkleroot"`
 	Timestamp     time.Time `db:"timestamp"`
 	Bits          uint32    `db:"bits"`
 	Nonce         uint32    `db:"nonce"`
 	State         string    `db:"header_state"`
-	Chainwork     string    `db:"chainwork"`
-	CumulatedWork string    `db:"cumulated_work"`
-	PreviousBlock string    `db:"previous_block"`
+	Chainwork     DbWork    `db:"chainwork"`
+	CumulatedWork DbWork    `db:"cumulated_work"`
+	PreviousBlock DbHash    `db:"previous_block"`
+	// CreatedAt is the time the header was stored, it is set when the header is inserted.
+	CreatedAt time.Time `db:"created_at"`
 }
 
 // ToBlockHeader converts work from string to big.Int and return BlockHeader.
@@ -35,19 +37,19 @@ func (dbh *DbBlockHeader) ToBlockHeader() *domains.BlockHeader {
 	if dbh.CumulatedWork == "" {
 		dbh.CumulatedWork = "0"
 	}
-	cumulatedWork, ok := new(big.Int).SetString(dbh.CumulatedWork, 10)
+	cumulatedWork, ok := new(big.Int).SetString(dbh.CumulatedWork.String(), 10)
 	if !ok {
 		cumulatedWork = big.NewInt(0)
 	}
 
-	chainWork, ok := new(big.Int).SetString(dbh.Chainwork, 10)
+	chainWork, ok := new(big.Int).SetString(dbh.Chainwork.String(), 10)
 	if !ok {
 		chainWork = big.NewInt(0)
 	}
 
-	hash, _ := chainhash.NewHashFromStr(dbh.Hash)
-	merkleTree, _ := chainhash.NewHashFromStr(dbh.MerkleRoot)
-	prevBlock, _ := chainhash.NewHashFromStr(dbh.PreviousBlock)
+	hash, _ := chainhash.NewHashFromStr(dbh.Hash.String())
+	merkleTree, _ := chainhash.NewHashFromStr(dbh.MerkleRoot.String())
+	prevBlock, _ := chainhash.NewHashFromStr(dbh.PreviousBlock.String())
 
 	return &domains.BlockHeader{
 		Height:        dbh.Height,
@@ -84,26 +86,26 @@ func ConvertToBlockHeader(dbBlockHeaders []*DbBlockHeader) []*domains.BlockHeade
 func ToDbBlockHeader(bh domains.BlockHeader) DbBlockHeader {
 	return DbBlockHeader{
 		Height:        bh.Height,
-		Hash:          bh.Hash.String(),
+		Hash:          DbHash(bh.Hash.String()),
 		Version:       bh.Version,
-		MerkleRoot:    bh.MerkleRoot.String(),
+		MerkleRoot:    DbHash(bh.MerkleRoot.String()),
 		Timestamp:     bh.Timestamp,
 		Bits:          bh.Bits,
 		Nonce:         bh.Nonce,
 		State:         bh.State.String(),
-		Chainwork:     bh.Chainwork.String(),
-		CumulatedWork: bh.CumulatedWork.String(),
-		PreviousBlock: bh.PreviousBlock.String(),
+		Chainwork:     DbWork(bh.Chainwork.String()),
+		CumulatedWork: DbWork(bh.CumulatedWork.String()),
+		PreviousBlock: DbHash(bh.PreviousBlock.String()),
 	}
 }
 
 // DbMerkleRootConfirmation is a database representation of a Confirmation
 // of Merkle Root inclusion in the longest chain.
 type DbMerkleRootConfirmation struct {
-	MerkleRoot  string         `db:"merkleroot"`
-	BlockHeight int32          `db:"blockheight"`
-	Hash        sql.NullString `db:"hash"`
-	TipHeight   int32          `db:"tipheight"`
+	MerkleRoot  string           `db:"merkleroot"`
+	BlockHeight int32            `db:"blockheight"`
+	Hash        sql.Null[DbHash] `db:"hash"`
+	TipHeight   int32            `db:"tipheight"`
 }
 
 // ToMerkleRootConfirmation converts DbMerkleRootConfirmation to domain's
@@ -125,7 +127,7 @@ func (dbMerkleConfm *DbMerkleRootConfirmation) ToMerkleRootConfirmation(
 	c := &domains.MerkleRootConfirmation{
 		MerkleRoot:   dbMerkleConfm.MerkleRoot,
 		BlockHeight:  dbMerkleConfm.BlockHeight,
-		Hash:         dbMerkleConfm.Hash.String,
+		Hash:         dbMerkleConfm.Hash.V.String(),
 		Confirmation: confmState,
 	}
 
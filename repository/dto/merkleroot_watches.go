@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// DbMerkleRootWatch represent merkle root watch saved in db.
+type DbMerkleRootWatch struct {
+	Owner                string    `db:"owner"`
+	MerkleRoot           string    `db:"merkle_root"`
+	BlockHeight          int32     `db:"block_height"`
+	Confirmation         string    `db:"confirmation"`
+	ConfirmationsReached bool      `db:"confirmations_reached"`
+	CreatedAt            time.Time `db:"created_at"`
+}
+
+// ToMerkleRootWatch converts DbMerkleRootWatch to MerkleRootWatch.
+func (dbw *DbMerkleRootWatch) ToMerkleRootWatch() *domains.MerkleRootWatch {
+	return &domains.MerkleRootWatch{
+		Owner:                dbw.Owner,
+		MerkleRoot:           dbw.MerkleRoot,
+		BlockHeight:          dbw.BlockHeight,
+		Confirmation:         domains.MerkleRootConfirmationState(dbw.Confirmation),
+		ConfirmationsReached: dbw.ConfirmationsReached,
+		CreatedAt:            dbw.CreatedAt,
+	}
+}
+
+// ToDbMerkleRootWatch converts MerkleRootWatch to DbMerkleRootWatch.
+func ToDbMerkleRootWatch(w *domains.MerkleRootWatch) *DbMerkleRootWatch {
+	return &DbMerkleRootWatch{
+		Owner:                w.Owner,
+		MerkleRoot:           w.MerkleRoot,
+		BlockHeight:          w.BlockHeight,
+		Confirmation:         string(w.Confirmation),
+		ConfirmationsReached: w.ConfirmationsReached,
+		CreatedAt:            w.CreatedAt,
+	}
+}
@@ -1,29 +1,73 @@
 package dto
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/domains"
 )
 
-// DbToken represent authorization token saved in db.
+// DbToken represent authorization token saved in db. Token holds the hash of the token value.
 type DbToken struct {
-	Token     string    `db:"token"`
-	CreatedAt time.Time `db:"created_at"`
+	Token      string       `db:"token"`
+	Hashed     bool         `db:"hashed"`
+	Prefix     string       `db:"prefix"`
+	Name       string       `db:"name"`
+	Owner      string       `db:"owner"`
+	CreatedAt  time.Time    `db:"created_at"`
+	ExpiresAt  sql.NullTime `db:"expires_at"`
+	LastUsedAt sql.NullTime `db:"last_used_at"`
+	Scopes     string       `db:"scopes"`
+	DailyQuota int64        `db:"daily_quota"`
+	QuotaDay   string       `db:"quota_day"`
+	QuotaUsed  int64        `db:"quota_used"`
 }
 
 // ToToken converts DbToken to Token.
 func (dbt *DbToken) ToToken() *domains.Token {
 	return &domains.Token{
-		Token:     dbt.Token,
-		CreatedAt: dbt.CreatedAt,
+		ID:         dbt.Token,
+		Prefix:     dbt.Prefix,
+		Name:       dbt.Name,
+		Owner:      dbt.Owner,
+		CreatedAt:  dbt.CreatedAt,
+		ExpiresAt:  fromNullTime(dbt.ExpiresAt),
+		LastUsedAt: fromNullTime(dbt.LastUsedAt),
+		Scopes:     domains.SplitScopes(dbt.Scopes),
+		DailyQuota: dbt.DailyQuota,
+		QuotaDay:   dbt.QuotaDay,
+		QuotaUsed:  dbt.QuotaUsed,
 	}
 }
 
 // ToDbToken converts Token to DbToken.
 func ToDbToken(t *domains.Token) *DbToken {
 	return &DbToken{
-		Token:     t.Token,
-		CreatedAt: t.CreatedAt,
+		Token:      t.ID,
+		Hashed:     true,
+		Prefix:     t.Prefix,
+		Name:       t.Name,
+		Owner:      t.Owner,
+		CreatedAt:  t.CreatedAt,
+		ExpiresAt:  toNullTime(t.ExpiresAt),
+		LastUsedAt: toNullTime(t.LastUsedAt),
+		Scopes:     domains.JoinScopes(t.Scopes),
+		DailyQuota: t.DailyQuota,
+		QuotaDay:   t.QuotaDay,
+		QuotaUsed:  t.QuotaUsed,
 	}
 }
+
+func fromNullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func toNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
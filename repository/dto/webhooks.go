@@ -16,6 +16,7 @@ type DbWebhook struct {
 	LastEmitTimestamp time.Time `db:"last_emit_timestamp"`
 	ErrorsCount       int       `db:"errors_count"`
 	Active            bool      `db:"is_active"`
+	EventFormat       string    `db:"event_format"`
 }
 
 // ToWebhook converts DbWebhook to Webhook.
@@ -27,6 +28,7 @@ func (dbt *DbWebhook) ToWebhook() *notification.Webhook {
 		CreatedAt:   dbt.CreatedAt,
 		ErrorsCount: dbt.ErrorsCount,
 		Active:      dbt.Active,
+		Format:      notification.EventFormat(dbt.EventFormat),
 	}
 }
 
@@ -39,5 +41,6 @@ func ToDbWebhook(t *notification.Webhook) *DbWebhook {
 		CreatedAt:   t.CreatedAt,
 		ErrorsCount: t.ErrorsCount,
 		Active:      t.Active,
+		EventFormat: string(t.Format),
 	}
 }
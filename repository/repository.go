@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 	"github.com/bitcoin-sv/block-headers-service/notification"
@@ -11,6 +13,9 @@ type Headers interface {
 	AddHeaderToDatabase(domains.BlockHeader) error
 	AddMultipleHeadersToDatabase([]domains.BlockHeader) error
 	UpdateState([]chainhash.Hash, domains.HeaderState) error
+	UpdateChain([]domains.BlockHeader) error
+	DeleteHeaders(hashes []chainhash.Hash) (int, error)
+	DeleteHeadersOlderThan(state domains.HeaderState, before time.Time) (int, error)
 	GetHeaderByHeight(height int32) (*domains.BlockHeader, error)
 	GetHeaderByHeightRange(from int, to int) ([]*domains.BlockHeader, error)
 	GetLongestChainHeadersFromHeight(height int32) ([]*domains.BlockHeader, error)
@@ -34,13 +39,46 @@ type Headers interface {
 // Tokens is a interface which represents methods performed on tokens table in defined storage.
 type Tokens interface {
 	AddTokenToDatabase(token *domains.Token) error
-	GetTokenByValue(token string) (*domains.Token, error)
-	DeleteToken(token string) error
+	GetTokenByHash(hash string) (*domains.Token, error)
+	GetTokens() ([]*domains.Token, error)
+	UpdateTokenLastUsed(hash string, lastUsedAt time.Time) error
+	UpdateTokenQuotaUsage(hash string, day string, used int64) error
+	RotateToken(hash string, token *domains.Token) error
+	DeleteToken(hash string) error
+}
+
+// MerkleRootWatches is a interface which represents methods performed on merkleroot_watches table in defined storage.
+type MerkleRootWatches interface {
+	AddMerkleRootWatch(watch *domains.MerkleRootWatch) error
+	DeleteMerkleRootWatch(owner, merkleRoot string, blockHeight int32) error
+	GetAllMerkleRootWatches() ([]*domains.MerkleRootWatch, error)
+	GetMerkleRootWatches(owner string) ([]*domains.MerkleRootWatch, error)
+	GetMerkleRootWatchesByHeight(from, to int32) ([]*domains.MerkleRootWatch, error)
+	UpdateMerkleRootWatch(watch *domains.MerkleRootWatch) error
+	TransferMerkleRootWatches(from, to string) error
+}
+
+// ConfirmationWatches is a interface which represents methods performed on confirmation_watches table in defined storage.
+type ConfirmationWatches interface {
+	AddConfirmationWatches(owner string, hashes []string) error
+	DeleteConfirmationWatches(owner string, hashes []string) error
+	GetConfirmationWatches() ([]domains.ConfirmationWatch, error)
+	TransferConfirmationWatches(from, to string) error
+}
+
+// Audit is a interface which represents methods performed on audit_log table in defined storage.
+// The audit log is append-only, so records can't be updated nor deleted.
+type Audit interface {
+	AddAuditRecord(record *domains.AuditRecord) error
+	GetAuditRecords(filter domains.AuditFilter) ([]*domains.AuditRecord, error)
 }
 
 // Repositories represents all repositories in app and provide access to them.
 type Repositories struct {
-	Headers  Headers
-	Tokens   Tokens
-	Webhooks notification.Webhooks
+	Headers             Headers
+	Tokens              Tokens
+	Webhooks            notification.Webhooks
+	MerkleRootWatches   MerkleRootWatches
+	ConfirmationWatches ConfirmationWatches
+	Audit               Audit
 }
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditService records administrative and security-relevant actions in the audit log.
+type AuditService struct {
+	repo *repository.Repositories
+	log  *zerolog.Logger
+}
+
+// NewAuditService creates and returns AuditService instance.
+func NewAuditService(repo *repository.Repositories, log *zerolog.Logger) *AuditService {
+	auditLogger := log.With().Str("service", "audit").Logger()
+	return &AuditService{
+		repo: repo,
+		log:  &auditLogger,
+	}
+}
+
+// Record appends the action of the actor to the audit log, err is the reason why the action failed.
+// Failing to save the record doesn't fail the action, but is logged.
+func (s *AuditService) Record(actor domains.AuditActor, action domains.AuditAction, target string, err error) {
+	if s == nil {
+		return
+	}
+	record := domains.CreateAuditRecord(actor, action, target, err)
+	s.log.Info().
+		Str("actor", record.Actor).
+		Str("action", string(record.Action)).
+		Str("target", record.Target).
+		Str("sourceIp", record.SourceIP).
+		Str("result", record.Result).
+		Msg("audit")
+
+	if s.repo == nil || s.repo.Audit == nil {
+		return
+	}
+	if err := s.repo.Audit.AddAuditRecord(record); err != nil {
+		s.log.Error().Msgf("Cannot save audit record of %s on %s: %v", record.Action, record.Target, err)
+	}
+}
+
+// GetRecords returns records of the audit log matching the filter, newest first.
+func (s *AuditService) GetRecords(filter domains.AuditFilter) ([]*domains.AuditRecord, error) {
+	if filter.Limit < 0 || filter.Limit > maxAuditLimit {
+		return nil, bhserrors.ErrInvalidAuditFilter
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	return s.repo.Audit.GetAuditRecords(filter)
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"strings"
+	"sync"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
@@ -23,13 +25,20 @@ type BlockHasher interface {
 type Notification interface {
 	// Notify notifies about new header stored.
 	Notify(any)
+	// UpdateSyncStatus reports if headers are synchronized with the network after the tip of the longest chain changed.
+	UpdateSyncStatus(current bool, tip *domains.BlockHeader)
 }
 
 type chainService struct {
 	*repository.Repositories
-	chainParams  *chaincfg.Params
-	log          *zerolog.Logger
-	notification Notification
+	chainParams   *chaincfg.Params
+	log           *zerolog.Logger
+	notification  Notification
+	confirmations *ConfirmationsService
+	checkpoints   []chaincfg.Checkpoint
+	timeSource    config.MedianTimeSource
+	// lock is held while a header is added, so stale branches aren't pruned while they become the longest chain.
+	lock sync.Locker
 	BlockHasher
 }
 
@@ -49,18 +58,42 @@ func NewChainsService(
 	log *zerolog.Logger,
 	hasher BlockHasher,
 	notification Notification,
+	confirmations *ConfirmationsService,
+	lock sync.Locker,
 ) Chains {
+	return newChainsService(repos, params, log, hasher, notification, confirmations, lock, config.Checkpoints, config.TimeSource)
+}
+
+// newChainsService creates Chains service telling if headers are synchronized with given checkpoints and time source.
+func newChainsService(
+	repos *repository.Repositories,
+	params *chaincfg.Params,
+	log *zerolog.Logger,
+	hasher BlockHasher,
+	notification Notification,
+	confirmations *ConfirmationsService,
+	lock sync.Locker,
+	checkpoints []chaincfg.Checkpoint,
+	timeSource config.MedianTimeSource,
+) *chainService {
 	serviceLogger := log.With().Str("service", "chain").Logger()
 	return &chainService{
-		Repositories: repos,
-		chainParams:  params,
-		log:          &serviceLogger,
-		BlockHasher:  hasher,
-		notification: notification,
+		Repositories:  repos,
+		chainParams:   params,
+		log:           &serviceLogger,
+		BlockHasher:   hasher,
+		notification:  notification,
+		confirmations: confirmations,
+		checkpoints:   checkpoints,
+		timeSource:    timeSource,
+		lock:          lock,
 	}
 }
 
 func (cs *chainService) Add(bs domains.BlockHeaderSource) (*domains.BlockHeader, error) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
 	hash := cs.BlockHasher.BlockHash(&bs)
 
 	existingHeader, err := cs.Headers.GetHeaderByHash(hash.String())
@@ -80,11 +113,14 @@ func (cs *chainService) Add(bs domains.BlockHeaderSource) (*domains.BlockHeader,
 
 	isConcurrentChain := cs.hasConcurrentHeaderFromLongestChain(h)
 
+	// without concurrent chain the longest chain header is placed just on top of the current tip.
+	previousTipHeight := h.Height - 1
 	if isConcurrentChain {
 		tip, err := cs.Repositories.Headers.GetTip()
 		if err != nil {
 			return nil, HeaderCreationFail.causedBy(&err)
 		}
+		previousTipHeight = tip.Height
 
 		if tip.CumulatedWork.Cmp(h.CumulatedWork) < 0 {
 			h.State = domains.LongestChain
@@ -93,8 +129,9 @@ func (cs *chainService) Add(bs domains.BlockHeaderSource) (*domains.BlockHeader,
 		}
 	}
 
+	r := newChainExtension(h)
 	if isConcurrentChain && h.IsLongestChain() {
-		err := cs.switchChainsStates(h)
+		r, err = cs.switchChainsStates(h)
 		if err != nil {
 			return h, err
 		}
@@ -106,7 +143,17 @@ func (cs *chainService) Add(bs domains.BlockHeaderSource) (*domains.BlockHeader,
 	}
 
 	metrics.SetLatestBlock(h.Height, h.Timestamp, h.State.String())
+	if h.IsLongestChain() {
+		// the sync status is checked against the new tip, so notifying doesn't query it from the database
+		cs.notification.UpdateSyncStatus(isCurrentTip(h, cs.checkpoints, cs.timeSource), h)
+	}
 	cs.notification.Notify(domains.HeaderAdded(h))
+	if len(r.demoted) > 0 {
+		cs.notification.Notify(domains.ChainReorganized(h, r.forkHeight, r.demoted.hashStrings()))
+	}
+	if h.IsLongestChain() {
+		cs.notifyConfirmations(h, previousTipHeight, r)
+	}
 	return h, err
 }
 
@@ -123,30 +170,85 @@ func (cs *chainService) hasConcurrentHeaderFromLongestChain(h *domains.BlockHead
 
 // switchChainsStates marking chain connected to given block as longest chain
 // and concurrent part of (currently) "longest chain" as STALE.
-func (cs *chainService) switchChainsStates(h *domains.BlockHeader) error {
+func (cs *chainService) switchChainsStates(h *domains.BlockHeader) (*chainSwitch, error) {
 	cs.log.Warn().Msgf("Promoting currently stale chain to be LONGEST chain ending on header %s", h.Hash)
 	headerStaleChain, err := cs.stalePartOfChainOf(h)
 	if err != nil {
-		return ChainUpdateFail.causedBy(&err)
+		return nil, ChainUpdateFail.causedBy(&err)
 	}
 
 	lh := lowestHeightOf(&headerStaleChain, h)
 
 	concurrentChain, err := cs.longestChainFromHeight(lh)
 	if err != nil {
-		return ChainUpdateFail.causedBy(&err)
+		return nil, ChainUpdateFail.causedBy(&err)
 	}
 
 	err = cs.Headers.UpdateState(concurrentChain.hashes(), domains.Stale)
 	if err != nil {
-		return ChainUpdateFail.causedBy(&err)
+		return nil, ChainUpdateFail.causedBy(&err)
 	}
 
 	err = cs.Headers.UpdateState(headerStaleChain.hashes(), domains.LongestChain)
 	if err != nil {
-		return ChainUpdateFail.causedBy(&err)
+		return nil, ChainUpdateFail.causedBy(&err)
+	}
+
+	concurrentChain.setState(domains.Stale)
+	headerStaleChain.setState(domains.LongestChain)
+	return &chainSwitch{
+		forkHeight: lh,
+		demoted:    concurrentChain,
+		promoted:   append(headerStaleChain, h),
+	}, nil
+}
+
+// notifyConfirmations notifies about headers which reached or dropped below the confirmation depth
+// because of the new tip of the longest chain.
+func (cs *chainService) notifyConfirmations(tip *domains.BlockHeader, previousTipHeight int32, s *chainSwitch) {
+	if cs.confirmations == nil || !cs.confirmations.isEnabled() {
+		return
+	}
+
+	depth := cs.confirmations.Depth()
+	confirmedUpTo := tip.Height - depth + 1
+	previouslyConfirmedUpTo := previousTipHeight - depth + 1
+
+	for _, h := range s.demoted {
+		if h.Height <= previouslyConfirmedUpTo {
+			cs.notifyConfirmation(domains.HeaderUnconfirmed(h, 0))
+		}
+	}
+
+	// headers below the fork stay in the longest chain, only their number of confirmations changes.
+	from, to := previouslyConfirmedUpTo+1, confirmedUpTo
+	if confirmedUpTo < previouslyConfirmedUpTo {
+		from, to = confirmedUpTo+1, previouslyConfirmedUpTo
+	}
+	for height := max(from, 0); height <= to && height < s.forkHeight; height++ {
+		h, err := cs.Headers.GetHeaderByHeight(height)
+		if err != nil {
+			cs.log.Error().Msgf("Cannot get header on height %d to notify about its confirmation: %v", height, err)
+			continue
+		}
+		if confirmedUpTo > previouslyConfirmedUpTo {
+			cs.notifyConfirmation(domains.HeaderConfirmed(h, tip.Height-h.Height+1))
+		} else {
+			cs.notifyConfirmation(domains.HeaderUnconfirmed(h, tip.Height-h.Height+1))
+		}
+	}
+
+	for _, h := range s.promoted {
+		if h.Height <= confirmedUpTo {
+			cs.notifyConfirmation(domains.HeaderConfirmed(h, tip.Height-h.Height+1))
+		}
+	}
+}
+
+func (cs *chainService) notifyConfirmation(e *domains.HeaderEvent) {
+	if cs.confirmations.isWatched(e.Header.Hash) {
+		cs.notification.Notify(e)
 	}
-	return nil
 }
 
 func (cs *chainService) longestChainFromHeight(smallestHeight int32) (chain, error) {
@@ -203,6 +305,23 @@ func (cs *chainService) insert(h *domains.BlockHeader) (*domains.BlockHeader, er
 
 type chain []*domains.BlockHeader
 
+// chainSwitch describes changes of the longest chain caused by adding a new tip.
+type chainSwitch struct {
+	// forkHeight is the lowest height on which the longest chain has changed.
+	forkHeight int32
+	// demoted headers which are no longer part of the longest chain.
+	demoted chain
+	// promoted headers which became part of the longest chain, including the new tip.
+	promoted chain
+}
+
+func newChainExtension(tip *domains.BlockHeader) *chainSwitch {
+	return &chainSwitch{
+		forkHeight: tip.Height,
+		promoted:   chain{tip},
+	}
+}
+
 func lowestHeightOf(c *chain, oh *domains.BlockHeader) int32 {
 	f := c.first()
 	if f.Height < oh.Height {
@@ -226,6 +345,12 @@ func (c *chain) first() *domains.BlockHeader {
 	return f
 }
 
+func (c *chain) setState(s domains.HeaderState) {
+	for _, ch := range *c {
+		ch.State = s
+	}
+}
+
 func (c *chain) hashes() []chainhash.Hash {
 	hs := make([]chainhash.Hash, len(*c))
 	for i, ch := range *c {
@@ -234,6 +359,14 @@ func (c *chain) hashes() []chainhash.Hash {
 	return hs
 }
 
+func (c *chain) hashStrings() []string {
+	hs := make([]string, len(*c))
+	for i, ch := range *c {
+		hs[i] = ch.Hash.String()
+	}
+	return hs
+}
+
 // AddBlockError errors that could occur during adding a header.
 type AddBlockError struct {
 	cause *error
@@ -1,9 +1,11 @@
 package service
 
 import (
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
@@ -233,6 +235,124 @@ func TestAddConcurrentChainBlock(t *testing.T) {
 	}
 }
 
+func TestNotifyWatchedHeaderConfirmed(t *testing.T) {
+	// given
+	r, longestChainTip := givenLongestChainInRepository()
+	h := givenHeaderToAddNextTo(longestChainTip)
+
+	confirmations := NewConfirmationsService(&config.NotificationConfig{ConfirmationDepth: 3}, nil, nil)
+	_ = confirmations.Watch("", []string{fixtures.HashHeight3.String()})
+	notification := newRecordingNotification()
+
+	cs := createChainsService(serviceSetup{Repositories: &r, Notification: notification, Confirmations: confirmations})
+
+	// when
+	_, addErr := cs.Add(h)
+
+	// then
+	assert.NoError(t, addErr)
+
+	confirmed := notification.HeaderEvents(domains.EventHeaderConfirmed)
+	assert.Equal(t, len(confirmed), 1)
+	assert.Equal(t, confirmed[0].Header.Hash, fixtures.HashHeight3.String())
+	assert.Equal(t, confirmed[0].Confirmations, int32(3))
+}
+
+func TestUpdateSyncStatusWithNewTip(t *testing.T) {
+	// given
+	r, longestChainTip := givenLongestChainInRepository()
+	notification := newRecordingNotification()
+	cs := createChainsService(serviceSetup{Repositories: &r, Notification: notification})
+
+	// when
+	h, addErr := cs.Add(givenHeaderToAddNextTo(longestChainTip))
+
+	// then
+	assert.NoError(t, addErr)
+	assert.Equal(t, len(notification.SyncTips), 1)
+	assert.Equal(t, notification.SyncTips[0].Hash, h.Hash)
+	assert.Equal(t, notification.Current, false)
+}
+
+func TestNotNotifyUnwatchedHeaderConfirmed(t *testing.T) {
+	// given
+	r, longestChainTip := givenLongestChainInRepository()
+	h := givenHeaderToAddNextTo(longestChainTip)
+
+	confirmations := NewConfirmationsService(&config.NotificationConfig{ConfirmationDepth: 3}, nil, nil)
+	_ = confirmations.Watch("", []string{fixtures.HashHeight2.String()})
+	notification := newRecordingNotification()
+
+	cs := createChainsService(serviceSetup{Repositories: &r, Notification: notification, Confirmations: confirmations})
+
+	// when
+	_, addErr := cs.Add(h)
+
+	// then
+	assert.NoError(t, addErr)
+	assert.Equal(t, len(notification.HeaderEvents(domains.EventHeaderConfirmed)), 0)
+}
+
+func TestNotifyUnconfirmedAfterChainSwitch(t *testing.T) {
+	// given
+	const bitsExceedingCumulatedChainWork uint32 = 0x180f0dc7
+	r, _ := givenLongestChainInRepository()
+	givenStaleChainInRepository(&r)
+
+	prev, _ := r.Headers.GetHeaderByHash(fixtures.StaleHashHeight4.String())
+	h := givenHeaderToAddNextTo(prev)
+	h.Bits = bitsExceedingCumulatedChainWork
+
+	confirmations := NewConfirmationsService(&config.NotificationConfig{ConfirmationDepth: 2, ConfirmAllHeaders: true}, nil, nil)
+	notification := newRecordingNotification()
+
+	cs := createChainsService(serviceSetup{Repositories: &r, Notification: notification, Confirmations: confirmations})
+
+	// when
+	_, addErr := cs.Add(h)
+
+	// then
+	assert.NoError(t, addErr)
+
+	unconfirmed := notification.HeaderEvents(domains.EventHeaderUnconfirmed)
+	assert.Equal(t, len(unconfirmed), 3)
+	for _, e := range unconfirmed {
+		assert.Equal(t, e.Header.State, domains.Stale)
+	}
+
+	confirmed := notification.HeaderEvents(domains.EventHeaderConfirmed)
+	assert.Equal(t, len(confirmed), 4)
+	for _, e := range confirmed {
+		assert.Equal(t, e.Header.State, domains.LongestChain)
+	}
+}
+
+func TestNotifyReorgAfterChainSwitch(t *testing.T) {
+	// given
+	const bitsExceedingCumulatedChainWork uint32 = 0x180f0dc7
+	r, _ := givenLongestChainInRepository()
+	givenStaleChainInRepository(&r)
+
+	prev, _ := r.Headers.GetHeaderByHash(fixtures.StaleHashHeight4.String())
+	h := givenHeaderToAddNextTo(prev)
+	h.Bits = bitsExceedingCumulatedChainWork
+
+	notification := newRecordingNotification()
+	cs := createChainsService(serviceSetup{Repositories: &r, Notification: notification})
+
+	// when
+	tip, addErr := cs.Add(h)
+
+	// then
+	assert.NoError(t, addErr)
+
+	reorgs := notification.HeaderEvents(domains.EventChainReorganized)
+	assert.Equal(t, len(reorgs), 1)
+	assert.Equal(t, reorgs[0].Header.Hash, tip.Hash.String())
+	assert.Equal(t, reorgs[0].Reorg.ForkHeight, int32(1))
+	assert.Equal(t, len(reorgs[0].Reorg.StaleHeaders), 4)
+}
+
 func givenStaleChainInRepository(r *repository.Repositories) {
 	sc, _ := fixtures.StaleChain()
 	for _, h := range sc {
@@ -347,18 +467,26 @@ func givenOrphanedHeaderToAdd() domains.BlockHeaderSource {
 
 func createChainsService(s serviceSetup) Chains {
 	log := zerolog.Nop()
+	notification := s.Notification
+	if notification == nil {
+		notification = newRecordingNotification()
+	}
 	return NewChainsService(
 		s.Repositories,
 		s.Params(),
 		&log,
 		DefaultBlockHasher(),
-		newRecordingNotification(),
+		notification,
+		s.Confirmations,
+		&sync.Mutex{},
 	)
 }
 
 type serviceSetup struct {
 	*repository.Repositories
-	IgnoredHash domains.BlockHash
+	IgnoredHash   domains.BlockHash
+	Notification  *recordingNotification
+	Confirmations *ConfirmationsService
 }
 
 func (s *serviceSetup) Params() *chaincfg.Params {
@@ -371,6 +499,9 @@ func (s *serviceSetup) Params() *chaincfg.Params {
 
 type recordingNotification struct {
 	Events []interface{}
+	// SyncTips are tips of the longest chain reported with synchronization status.
+	SyncTips []*domains.BlockHeader
+	Current  bool
 }
 
 func newRecordingNotification() *recordingNotification {
@@ -383,6 +514,21 @@ func (r *recordingNotification) Notify(event any) {
 	r.Events = append(r.Events, event)
 }
 
+func (r *recordingNotification) UpdateSyncStatus(current bool, tip *domains.BlockHeader) {
+	r.Current = current
+	r.SyncTips = append(r.SyncTips, tip)
+}
+
 func (r *recordingNotification) Clear() {
 	r.Events = make([]interface{}, 0)
 }
+
+func (r *recordingNotification) HeaderEvents(operation domains.HeaderEventType) []*domains.HeaderEvent {
+	events := make([]*domains.HeaderEvent, 0)
+	for _, e := range r.Events {
+		if he, ok := e.(*domains.HeaderEvent); ok && he.Operation == operation {
+			events = append(events, he)
+		}
+	}
+	return events
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"crypto/tls"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+// clientCertTokenIDPrefix distinguishes ids of client certificates from ids of tokens created by the service.
+const clientCertTokenIDPrefix = "cert:"
+
+// ClientCertService authenticates clients by subjects of their verified TLS certificates.
+type ClientCertService struct {
+	repo     *repository.Repositories
+	mappings map[string]clientCertMapping
+	log      *zerolog.Logger
+}
+
+type clientCertMapping struct {
+	scopes  []domains.Scope
+	tokenID string
+}
+
+// NewClientCertService creates and returns ClientCertService instance.
+// Certificates are verified only when TLS with client CAs is enabled, otherwise clients have no verified certificates.
+func NewClientCertService(repo *repository.Repositories, cfg *config.TLSConfig, log *zerolog.Logger) *ClientCertService {
+	certLogger := log.With().Str("service", "client-certs").Logger()
+	s := &ClientCertService{
+		repo:     repo,
+		mappings: make(map[string]clientCertMapping),
+		log:      &certLogger,
+	}
+	if cfg == nil {
+		return s
+	}
+
+	for _, m := range cfg.ClientCerts {
+		scopes, err := domains.ParseScopes(m.Scopes)
+		if err != nil {
+			s.log.Warn().Msgf("Ignoring mapping of client certificate %s: %v", m.Subject, err)
+			continue
+		}
+		s.mappings[m.Subject] = clientCertMapping{scopes: scopes, tokenID: m.TokenID}
+	}
+	return s
+}
+
+// Authenticate returns token of the client with verified certificate mapped to scopes or a token.
+// It returns nil when the client has no verified certificate or its subject isn't mapped.
+// Tokens of certificates mapped to tokens can be expired, so callers should check expiration.
+func (s *ClientCertService) Authenticate(state *tls.ConnectionState) (*domains.Token, error) {
+	if s == nil || state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, nil
+	}
+	subject := state.VerifiedChains[0][0].Subject.String()
+	m, ok := s.mappings[subject]
+	if !ok {
+		return nil, nil
+	}
+
+	if m.tokenID != "" {
+		t, err := s.repo.Tokens.GetTokenByHash(m.tokenID)
+		if err != nil {
+			s.log.Warn().Msgf("Client certificate %s is mapped to unknown token: %v", subject, err)
+			return nil, bhserrors.ErrInvalidAccessToken.Wrap(err)
+		}
+		return t, nil
+	}
+
+	return &domains.Token{
+		ID:       clientCertTokenIDPrefix + subject,
+		Name:     subject,
+		Scopes:   m.scopes,
+		External: true,
+	}, nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+// ConfirmationsService keeps track of headers for which clients want to be notified
+// when they reach (or drop below) the configured confirmation depth.
+// Watched headers are stored in the database and cached in memory, so they are checked for every header without queries.
+// Every watch has an owner, the ID of the token which created it, and only the owner can see and delete it.
+type ConfirmationsService struct {
+	depth      int32
+	allHeaders bool
+	repo       repository.ConfirmationWatches
+	mu         sync.RWMutex
+	// watched maps hashes of watched headers to their owners.
+	watched map[string]map[string]struct{}
+}
+
+// NewConfirmationsService creates and returns ConfirmationsService instance with headers watched before restart.
+func NewConfirmationsService(cfg *config.NotificationConfig, repo repository.ConfirmationWatches, log *zerolog.Logger) *ConfirmationsService {
+	s := &ConfirmationsService{repo: repo, watched: make(map[string]map[string]struct{})}
+	if cfg != nil {
+		s.depth = int32(cfg.ConfirmationDepth)
+		s.allHeaders = cfg.ConfirmAllHeaders
+	}
+
+	if repo != nil {
+		watches, err := repo.GetConfirmationWatches()
+		if err != nil {
+			log.Error().Msgf("Cannot load headers watched for confirmations. %v", err)
+		}
+		for _, w := range watches {
+			s.add(w.Owner, w.Hash)
+		}
+	}
+	return s
+}
+
+// Depth returns number of confirmations after which a header is reported as confirmed.
+func (s *ConfirmationsService) Depth() int32 {
+	return s.depth
+}
+
+// Watch registers headers with given hashes watched by the owner to be reported when they reach confirmation depth.
+func (s *ConfirmationsService) Watch(owner string, hashes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo != nil {
+		if err := s.repo.AddConfirmationWatches(owner, hashes); err != nil {
+			return err
+		}
+	}
+	for _, h := range hashes {
+		s.add(owner, h)
+	}
+	return nil
+}
+
+// Unwatch removes header with given hash from the ones watched by the owner.
+func (s *ConfirmationsService) Unwatch(owner, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.watched[hash][owner]; !ok {
+		return bhserrors.ErrHeaderWatchNotFound
+	}
+	if s.repo != nil {
+		if err := s.repo.DeleteConfirmationWatches(owner, []string{hash}); err != nil {
+			return err
+		}
+	}
+	delete(s.watched[hash], owner)
+	if len(s.watched[hash]) == 0 {
+		delete(s.watched, hash)
+	}
+	return nil
+}
+
+// Watched returns hashes of headers watched by the owner.
+func (s *ConfirmationsService) Watched(owner string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	hashes := make([]string, 0)
+	for h, owners := range s.watched {
+		if _, ok := owners[owner]; ok {
+			hashes = append(hashes, h)
+		}
+	}
+	sort.Strings(hashes)
+	return hashes
+}
+
+// Transfer changes the owner of watched headers, when the token owning them is rotated.
+func (s *ConfirmationsService) Transfer(from, to string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo != nil {
+		if err := s.repo.TransferConfirmationWatches(from, to); err != nil {
+			return err
+		}
+	}
+	for _, owners := range s.watched {
+		if _, ok := owners[from]; ok {
+			delete(owners, from)
+			owners[to] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (s *ConfirmationsService) add(owner, hash string) {
+	if s.watched[hash] == nil {
+		s.watched[hash] = make(map[string]struct{})
+	}
+	s.watched[hash][owner] = struct{}{}
+}
+
+// isEnabled checks if there is any header that confirmation should be reported for.
+func (s *ConfirmationsService) isEnabled() bool {
+	if s.depth <= 0 {
+		return false
+	}
+	if s.allHeaders {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.watched) > 0
+}
+
+// isWatched checks if confirmation of header with given hash should be reported.
+func (s *ConfirmationsService) isWatched(hash string) bool {
+	if s.allHeaders {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.watched[hash]
+	return ok
+}
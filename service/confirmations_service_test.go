@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/rs/zerolog"
+)
+
+func TestWatchedHeadersKeptAfterRestart(t *testing.T) {
+	// given
+	logger := zerolog.Nop()
+	cfg := &config.NotificationConfig{ConfirmationDepth: 3}
+	repo := testrepository.NewConfirmationWatchesTestRepository(&[]domains.ConfirmationWatch{})
+	confirmations := NewConfirmationsService(cfg, repo, &logger)
+	assert.NoError(t, confirmations.Watch("owner", []string{fixtures.HashHeight2.String(), fixtures.HashHeight3.String()}))
+	assert.NoError(t, confirmations.Unwatch("owner", fixtures.HashHeight2.String()))
+
+	// when
+	restarted := NewConfirmationsService(cfg, repo, &logger)
+
+	// then
+	assert.Equal(t, len(restarted.Watched("owner")), 1)
+	assert.Equal(t, restarted.isWatched(fixtures.HashHeight3.String()), true)
+	assert.Equal(t, restarted.isWatched(fixtures.HashHeight2.String()), false)
+}
+
+func TestWatchedHeadersOfOtherOwner(t *testing.T) {
+	// given
+	logger := zerolog.Nop()
+	repo := testrepository.NewConfirmationWatchesTestRepository(&[]domains.ConfirmationWatch{})
+	confirmations := NewConfirmationsService(&config.NotificationConfig{ConfirmationDepth: 3}, repo, &logger)
+	assert.NoError(t, confirmations.Watch("first", []string{fixtures.HashHeight2.String()}))
+	assert.NoError(t, confirmations.Watch("second", []string{fixtures.HashHeight2.String(), fixtures.HashHeight3.String()}))
+
+	// when
+	err := confirmations.Unwatch("first", fixtures.HashHeight3.String())
+
+	// then
+	assert.IsError(t, err, bhserrors.ErrHeaderWatchNotFound.Message)
+	assert.Equal(t, len(confirmations.Watched("first")), 1)
+	assert.Equal(t, len(confirmations.Watched("second")), 2)
+
+	// when
+	err = confirmations.Unwatch("first", fixtures.HashHeight2.String())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(confirmations.Watched("first")), 0)
+	assert.Equal(t, len(confirmations.Watched("second")), 2)
+	assert.Equal(t, confirmations.isWatched(fixtures.HashHeight2.String()), true)
+
+	// when
+	err = confirmations.Transfer("second", "rotated")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(confirmations.Watched("second")), 0)
+	assert.Equal(t, len(confirmations.Watched("rotated")), 2)
+	restarted := NewConfirmationsService(&config.NotificationConfig{ConfirmationDepth: 3}, repo, &logger)
+	assert.Equal(t, len(restarted.Watched("rotated")), 2)
+}
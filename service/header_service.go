@@ -61,15 +61,18 @@ func (hs *HeaderService) BackElement() (domains.BlockHeader, error) {
 
 // IsCurrent checks if the headers are synchronized and up to date.
 func (hs *HeaderService) IsCurrent() bool {
-	// Not current if the latest main (best) chain height is before the
-	// latest known good checkpoint (when checkpoints are enabled).
-	checkpoints := hs.checkpoints
-	checkpoint := &checkpoints[len(checkpoints)-1]
 	tip := hs.GetTip()
 	if tip == nil {
 		return true
 	}
-	if checkpoint != nil && tip.Height < checkpoint.Height {
+	return isCurrentTip(tip, hs.checkpoints, hs.timeSource)
+}
+
+// isCurrentTip checks if the tip of the longest chain is synchronized and up to date.
+func isCurrentTip(tip *domains.BlockHeader, checkpoints []chaincfg.Checkpoint, timeSource config.MedianTimeSource) bool {
+	// Not current if the latest main (best) chain height is before the
+	// latest known good checkpoint (when checkpoints are enabled).
+	if len(checkpoints) > 0 && tip.Height < checkpoints[len(checkpoints)-1].Height {
 		return false
 	}
 
@@ -78,7 +81,11 @@ func (hs *HeaderService) IsCurrent() bool {
 	//
 	// The chain appears to be current if none of the checks reported
 	// otherwise.
-	minus24Hours := hs.timeSource.AdjustedTime().Add(-24 * time.Hour).Unix()
+	now := time.Now()
+	if timeSource != nil {
+		now = timeSource.AdjustedTime()
+	}
+	minus24Hours := now.Add(-24 * time.Hour).Unix()
 	return tip.Timestamp.Unix() >= minus24Hours
 }
 
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// integrityBatchSize is the number of heights read, and the number of repaired headers written, at once.
+	integrityBatchSize = 2000
+	// maxIntegrityIssues is the number of issues listed in the report, all of them are counted.
+	maxIntegrityIssues = 1000
+)
+
+// IntegrityService checks consistency of stored headers and repairs their states and work.
+type IntegrityService struct {
+	repo      *repository.Repositories
+	batchSize int
+	log       *zerolog.Logger
+}
+
+// NewIntegrityService creates and returns IntegrityService instance.
+func NewIntegrityService(repo *repository.Repositories, log *zerolog.Logger) *IntegrityService {
+	integrityLogger := log.With().Str("service", "integrity").Logger()
+	return &IntegrityService{
+		repo:      repo,
+		batchSize: integrityBatchSize,
+		log:       &integrityLogger,
+	}
+}
+
+// Check scans all stored headers height by height and reports inconsistencies of the chain.
+func (s *IntegrityService) Check() (*domains.IntegrityReport, error) {
+	return s.run(false)
+}
+
+// Repair reports inconsistencies like Check, and recomputes work of headers, attaches orphans whose previous header
+// is stored and moves the longest chain to the branch with the most work. It shouldn't run while headers are synchronized.
+func (s *IntegrityService) Repair() (*domains.IntegrityReport, error) {
+	return s.run(true)
+}
+
+func (s *IntegrityService) run(repair bool) (*domains.IntegrityReport, error) {
+	maxHeight, err := s.repo.Headers.GetCurrentHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	scan := &integrityScan{
+		service:         s,
+		repair:          repair,
+		report:          &domains.IntegrityReport{Issues: make([]domains.IntegrityIssue, 0)},
+		previous:        make(map[chainhash.Hash]*domains.BlockHeader),
+		staleWork:       make(map[chainhash.Hash]*big.Int),
+		repaired:        make(map[chainhash.Hash]struct{}),
+		lowestLinkIssue: -1,
+	}
+
+	for from := 0; from <= maxHeight; from += s.batchSize {
+		to := min(from+s.batchSize-1, maxHeight)
+		headers, err := s.repo.Headers.GetHeaderByHeightRange(from, to)
+		if err != nil && !errors.Is(err, bhserrors.ErrHeadersForGivenRangeNotFound) {
+			return nil, err
+		}
+		if err := scan.scanHeights(int32(from), int32(to), headers); err != nil {
+			return nil, err
+		}
+	}
+	if err := scan.flush(); err != nil {
+		return nil, err
+	}
+
+	if err := scan.checkStaleBranches(); err != nil {
+		return nil, err
+	}
+	if err := scan.checkOrphans(); err != nil {
+		return nil, err
+	}
+	if repair {
+		if err := scan.repairStates(); err != nil {
+			return nil, err
+		}
+	}
+
+	if scan.longestChainTip != nil {
+		scan.report.TipHeight = scan.longestChainTip.Height
+	}
+	scan.report.Repaired = len(scan.repaired)
+	s.log.Info().Msgf("Checked %d headers up to height %d, found %d issues, repaired %d headers",
+		scan.report.HeadersChecked, maxHeight, scan.report.IssuesCount, scan.report.Repaired)
+	return scan.report, nil
+}
+
+// integrityScan holds state of a scan of headers in the order of heights.
+type integrityScan struct {
+	service *IntegrityService
+	repair  bool
+	report  *domains.IntegrityReport
+
+	// previous are headers at the previous height with recomputed work.
+	previous map[chainhash.Hash]*domains.BlockHeader
+	// staleWork is recomputed cumulated work of stale headers.
+	staleWork map[chainhash.Hash]*big.Int
+	orphans   []*domains.BlockHeader
+	// longestChainTip is the highest header of the longest chain, with recomputed work.
+	longestChainTip *domains.BlockHeader
+	// best is the header with the most recomputed cumulated work.
+	best *domains.BlockHeader
+	// lowestLinkIssue is the lowest height where the longest chain is broken, -1 when it isn't.
+	lowestLinkIssue int32
+	// started is set at the lowest height with headers, which is above genesis when headers were imported from a checkpoint.
+	started bool
+
+	pending  []domains.BlockHeader
+	repaired map[chainhash.Hash]struct{}
+}
+
+func (scan *integrityScan) scanHeights(from, to int32, headers []*domains.BlockHeader) error {
+	byHeight := make(map[int32][]*domains.BlockHeader, to-from+1)
+	for _, h := range headers {
+		byHeight[h.Height] = append(byHeight[h.Height], h)
+	}
+
+	for height := from; height <= to; height++ {
+		scan.scanHeight(height, byHeight[height])
+		if len(scan.pending) >= scan.service.batchSize {
+			if err := scan.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (scan *integrityScan) scanHeight(height int32, headers []*domains.BlockHeader) {
+	current := make(map[chainhash.Hash]*domains.BlockHeader, len(headers))
+	longestChainCount := 0
+	previousTip := scan.longestChainTip
+	// headers at the lowest height have no previous headers, so their stored cumulated work is trusted
+	lowest := !scan.started
+
+	for _, stored := range headers {
+		if stored.State == domains.Orphan {
+			scan.orphans = append(scan.orphans, stored)
+			continue
+		}
+		if stored.State == domains.Rejected {
+			continue
+		}
+		scan.report.HeadersChecked++
+		scan.started = true
+
+		// work of the stored header isn't changed, because it is compared with the recomputed one
+		h := *stored
+		current[h.Hash] = &h
+		if h.State == domains.LongestChain {
+			longestChainCount++
+			scan.longestChainTip = &h
+		}
+		if !scan.recomputeWork(&h, lowest) {
+			continue
+		}
+
+		if h.State == domains.Stale {
+			scan.staleWork[h.Hash] = h.CumulatedWork
+		}
+		scan.updateBest(&h)
+	}
+
+	if longestChainCount > 1 {
+		scan.addIssue(domains.IntegrityLongestChainCount, height, "",
+			fmt.Sprintf("height has %d headers of the longest chain", longestChainCount))
+		scan.markLinkIssue(height)
+	}
+	// heights without the longest chain are found when it continues above them
+	if longestChainCount > 0 && previousTip != nil && previousTip.Height < height-1 {
+		details := fmt.Sprintf("heights from %d to %d have no header of the longest chain", previousTip.Height+1, height-1)
+		if previousTip.Height+1 == height-1 {
+			details = "height has no header of the longest chain"
+		}
+		scan.addIssue(domains.IntegrityLongestChainCount, previousTip.Height+1, "", details)
+		scan.markLinkIssue(previousTip.Height + 1)
+	}
+	scan.previous = current
+}
+
+// recomputeWork sets work of the header calculated from its bits and its previous header, reporting differences
+// from stored work. It returns false when the previous header is missing, so the work can't be calculated.
+func (scan *integrityScan) recomputeWork(h *domains.BlockHeader, lowest bool) bool {
+	parentWork := big.NewInt(0)
+	chainwork := domains.CalculateWork(h.Bits).BigInt()
+	if lowest && h.Height > 0 && h.CumulatedWork != nil {
+		parentWork = new(big.Int).Sub(h.CumulatedWork, chainwork)
+	} else if h.Height > 0 {
+		parent, ok := scan.previous[h.PreviousBlock]
+		if !ok {
+			scan.addIssue(domains.IntegrityMissingParent, h.Height, h.Hash.String(),
+				fmt.Sprintf("previous header %s isn't stored at height %d", h.PreviousBlock, h.Height-1))
+			if h.State == domains.LongestChain {
+				scan.markLinkIssue(h.Height)
+			}
+			return false
+		}
+		if h.State == domains.LongestChain && parent.State != domains.LongestChain {
+			scan.addIssue(domains.IntegrityLongestChainLink, h.Height, h.Hash.String(),
+				fmt.Sprintf("previous header %s is %s", parent.Hash, parent.State))
+			scan.markLinkIssue(h.Height)
+		}
+		parentWork = parent.CumulatedWork
+	}
+
+	cumulatedWork := new(big.Int).Add(parentWork, chainwork)
+	changed := false
+	if h.Chainwork == nil || h.Chainwork.Cmp(chainwork) != 0 {
+		scan.addIssue(domains.IntegrityChainwork, h.Height, h.Hash.String(),
+			fmt.Sprintf("chainwork is %s, bits give %s", h.Chainwork, chainwork))
+		changed = true
+	}
+	if h.CumulatedWork == nil || h.CumulatedWork.Cmp(cumulatedWork) != 0 {
+		scan.addIssue(domains.IntegrityCumulatedWork, h.Height, h.Hash.String(),
+			fmt.Sprintf("cumulated work is %s, previous header with chainwork give %s", h.CumulatedWork, cumulatedWork))
+		changed = true
+	}
+
+	h.Chainwork = chainwork
+	h.CumulatedWork = cumulatedWork
+	if changed {
+		scan.update(*h)
+	}
+	return true
+}
+
+func (scan *integrityScan) updateBest(h *domains.BlockHeader) {
+	if scan.best == nil {
+		scan.best = h
+		return
+	}
+	switch h.CumulatedWork.Cmp(scan.best.CumulatedWork) {
+	case 1:
+		scan.best = h
+	case 0:
+		// the longest chain is kept between branches with the same work
+		if h.State == domains.LongestChain && scan.best.State != domains.LongestChain {
+			scan.best = h
+		}
+	}
+}
+
+// checkStaleBranches reports tips of stale branches with more work than the longest chain.
+func (scan *integrityScan) checkStaleBranches() error {
+	if scan.longestChainTip == nil {
+		return nil
+	}
+	tips, err := scan.service.repo.Headers.GetAllTips()
+	if err != nil {
+		return err
+	}
+	for _, tip := range tips {
+		work, ok := scan.staleWork[tip.Hash]
+		if tip.State != domains.Stale || !ok {
+			continue
+		}
+		if work.Cmp(scan.longestChainTip.CumulatedWork) > 0 {
+			scan.addIssue(domains.IntegrityStaleBranchWork, tip.Height, tip.Hash.String(),
+				fmt.Sprintf("stale branch has work %s, the longest chain has %s", work, scan.longestChainTip.CumulatedWork))
+			scan.markLinkIssue(tip.Height)
+		}
+	}
+	return nil
+}
+
+// checkOrphans reports orphans whose previous header is stored, and attaches them to it when repairing.
+func (scan *integrityScan) checkOrphans() error {
+	attached := make(map[chainhash.Hash]*domains.BlockHeader)
+	remaining := scan.orphans
+
+	for len(remaining) > 0 {
+		var left []*domains.BlockHeader
+		for _, orphan := range remaining {
+			parent, ok := attached[orphan.PreviousBlock]
+			if !ok {
+				stored, err := scan.service.repo.Headers.GetHeaderByHash(orphan.PreviousBlock.String())
+				if err != nil && !errors.Is(err, bhserrors.ErrHeaderNotFound) {
+					return err
+				}
+				if stored == nil || stored.State == domains.Orphan || stored.State == domains.Rejected {
+					left = append(left, orphan)
+					continue
+				}
+				parent = stored
+			}
+
+			scan.addIssue(domains.IntegrityOrphanWithParent, orphan.Height, orphan.Hash.String(),
+				fmt.Sprintf("previous header %s is stored at height %d", parent.Hash, parent.Height))
+			if !scan.repair {
+				continue
+			}
+
+			h := *orphan
+			h.Height = parent.Height + 1
+			h.State = domains.Stale
+			h.Chainwork = domains.CalculateWork(h.Bits).BigInt()
+			h.CumulatedWork = new(big.Int).Add(parent.CumulatedWork, h.Chainwork)
+			attached[h.Hash] = &h
+			scan.update(h)
+			scan.updateBest(&h)
+		}
+
+		// without repair or newly attached orphans, the rest can't be attached
+		if !scan.repair || len(left) == len(remaining) {
+			break
+		}
+		remaining = left
+	}
+	return scan.flush()
+}
+
+// repairStates makes the branch with the most work the longest chain. Headers are walked back from its tip
+// to the highest header of the longest chain below which the longest chain isn't broken, reading batches of heights.
+func (scan *integrityScan) repairStates() error {
+	if scan.best == nil {
+		return nil
+	}
+	if scan.best.State == domains.LongestChain && scan.lowestLinkIssue < 0 {
+		return nil
+	}
+
+	headers := scan.service.repo.Headers
+	longestChain := make(map[chainhash.Hash]struct{})
+	forkHeight := int32(-1)
+	var below map[chainhash.Hash]*domains.BlockHeader
+	for h := scan.best; ; {
+		if h.State == domains.LongestChain && (scan.lowestLinkIssue < 0 || h.Height < scan.lowestLinkIssue) {
+			forkHeight = h.Height
+			break
+		}
+		longestChain[h.Hash] = struct{}{}
+		if h.State != domains.LongestChain {
+			promoted := *h
+			promoted.State = domains.LongestChain
+			scan.update(promoted)
+		}
+		if h.Height == 0 {
+			break
+		}
+
+		previous, ok := below[h.PreviousBlock]
+		if !ok {
+			var err error
+			if below, err = scan.headersBelow(h.Height); err != nil {
+				return err
+			}
+			if previous, ok = below[h.PreviousBlock]; !ok {
+				return fmt.Errorf("cannot follow the branch with the most work below %s: %w", h.Hash, bhserrors.ErrHeaderNotFound)
+			}
+		}
+		h = previous
+	}
+
+	demoted, err := headers.GetLongestChainHeadersFromHeight(forkHeight + 1)
+	if err != nil {
+		return err
+	}
+	for _, h := range demoted {
+		if _, ok := longestChain[h.Hash]; !ok {
+			stale := *h
+			stale.State = domains.Stale
+			scan.update(stale)
+		}
+	}
+
+	scan.service.log.Info().Msgf("Longest chain set to the branch with tip %s at height %d from height %d",
+		scan.best.Hash, scan.best.Height, forkHeight+1)
+	return scan.flush()
+}
+
+// headersBelow reads headers of the batch of heights below the height, by their hashes.
+func (scan *integrityScan) headersBelow(height int32) (map[chainhash.Hash]*domains.BlockHeader, error) {
+	from := max(int(height)-scan.service.batchSize, 0)
+	headers, err := scan.service.repo.Headers.GetHeaderByHeightRange(from, int(height)-1)
+	if err != nil && !errors.Is(err, bhserrors.ErrHeadersForGivenRangeNotFound) {
+		return nil, err
+	}
+	byHash := make(map[chainhash.Hash]*domains.BlockHeader, len(headers))
+	for _, h := range headers {
+		byHash[h.Hash] = h
+	}
+	return byHash, nil
+}
+
+func (scan *integrityScan) addIssue(kind domains.IntegrityIssueKind, height int32, hash, details string) {
+	scan.report.IssuesCount++
+	if len(scan.report.Issues) < maxIntegrityIssues {
+		scan.report.Issues = append(scan.report.Issues, domains.IntegrityIssue{
+			Kind:    kind,
+			Height:  height,
+			Hash:    hash,
+			Details: details,
+		})
+	}
+}
+
+func (scan *integrityScan) markLinkIssue(height int32) {
+	if scan.lowestLinkIssue < 0 || height < scan.lowestLinkIssue {
+		scan.lowestLinkIssue = height
+	}
+}
+
+// update queues the repaired header to be written, it is ignored when only checking.
+func (scan *integrityScan) update(h domains.BlockHeader) {
+	if !scan.repair {
+		return
+	}
+	scan.pending = append(scan.pending, h)
+	scan.repaired[h.Hash] = struct{}{}
+}
+
+func (scan *integrityScan) flush() error {
+	if len(scan.pending) == 0 {
+		return nil
+	}
+	if err := scan.service.repo.Headers.UpdateChain(scan.pending); err != nil {
+		return err
+	}
+	scan.pending = scan.pending[:0]
+	return nil
+}
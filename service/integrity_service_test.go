@@ -0,0 +1,223 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+func TestIntegrityOfConsistentChain(t *testing.T) {
+	// given
+	integrity, _ := createIntegrityService(integrityTestChain())
+
+	// when
+	report, err := integrity.Check()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, report.Consistent(), true)
+	assert.Equal(t, report.TipHeight, 4)
+	assert.Equal(t, report.HeadersChecked, 7)
+}
+
+func TestIntegrityOfCorruptedCumulatedWork(t *testing.T) {
+	// given
+	db := integrityTestChain()
+	headerWithHash(db, fixtures.HashHeight2).CumulatedWork = big.NewInt(1)
+	integrity, repo := createIntegrityService(db)
+
+	// when
+	report, err := integrity.Check()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, report.IssuesCount, 1)
+	assert.Equal(t, report.Issues[0].Kind, domains.IntegrityCumulatedWork)
+	assert.Equal(t, report.Issues[0].Height, 2)
+
+	// when
+	report, err = integrity.Repair()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, report.Repaired, 1)
+	repaired, _ := repo.GetHeaderByHash(fixtures.HashHeight2.String())
+	assert.Equal(t, repaired.CumulatedWork.Cmp(headerWithHash(integrityTestChain(), fixtures.HashHeight2).CumulatedWork), 0)
+	assertConsistentAfterRepair(t, integrity)
+}
+
+func TestIntegrityOfTwoLongestChainHeadersOnHeight(t *testing.T) {
+	// given
+	db := integrityTestChain()
+	headerWithHash(db, fixtures.StaleHashHeight3).State = domains.LongestChain
+	integrity, repo := createIntegrityService(db)
+
+	// when
+	report, err := integrity.Check()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, hasIssue(report, domains.IntegrityLongestChainCount, 3), true)
+
+	// when
+	_, err = integrity.Repair()
+
+	// then
+	assert.NoError(t, err)
+	stale, _ := repo.GetHeaderByHash(fixtures.StaleHashHeight3.String())
+	assert.Equal(t, stale.State, domains.Stale)
+	assertConsistentAfterRepair(t, integrity)
+}
+
+func TestIntegrityOfHeightWithoutLongestChain(t *testing.T) {
+	// given
+	db := integrityTestChain()
+	headerWithHash(db, fixtures.HashHeight2).State = domains.Stale
+	integrity, repo := createIntegrityService(db)
+
+	// when
+	report, err := integrity.Check()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, hasIssue(report, domains.IntegrityLongestChainCount, 2), true)
+	assert.Equal(t, hasIssue(report, domains.IntegrityLongestChainLink, 3), true)
+
+	// when
+	_, err = integrity.Repair()
+
+	// then
+	assert.NoError(t, err)
+	header, _ := repo.GetHeaderByHash(fixtures.HashHeight2.String())
+	assert.Equal(t, header.State, domains.LongestChain)
+	assertConsistentAfterRepair(t, integrity)
+}
+
+func TestIntegrityOfStaleBranchWithMoreWork(t *testing.T) {
+	// heights of the branch are read in one batch and in batches of one height
+	for _, batchSize := range []int{integrityBatchSize, 1} {
+		t.Run(fmt.Sprintf("batch size %d", batchSize), func(t *testing.T) {
+			// given
+			db := integrityTestChain()
+			db = slices.DeleteFunc(db, func(h domains.BlockHeader) bool { return h.Hash == *fixtures.StaleHashHeight4 })
+			headerWithHash(db, fixtures.HashHeight3).State = domains.Stale
+			headerWithHash(db, fixtures.HashHeight4).State = domains.Stale
+			headerWithHash(db, fixtures.StaleHashHeight3).State = domains.LongestChain
+			integrity, repo := createIntegrityService(db)
+			integrity.batchSize = batchSize
+
+			// when
+			report, err := integrity.Check()
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, report.IssuesCount, 1)
+			assert.Equal(t, report.Issues[0].Kind, domains.IntegrityStaleBranchWork)
+			assert.Equal(t, report.Issues[0].Hash, fixtures.HashHeight4.String())
+
+			// when
+			_, err = integrity.Repair()
+
+			// then
+			assert.NoError(t, err)
+			for hash, state := range map[*chainhash.Hash]domains.HeaderState{
+				fixtures.HashHeight3:      domains.LongestChain,
+				fixtures.HashHeight4:      domains.LongestChain,
+				fixtures.StaleHashHeight3: domains.Stale,
+			} {
+				header, _ := repo.GetHeaderByHash(hash.String())
+				assert.Equal(t, header.State, state)
+			}
+			assertConsistentAfterRepair(t, integrity)
+		})
+	}
+}
+
+func TestIntegrityOfOrphanWithParent(t *testing.T) {
+	// given
+	db := integrityTestChain()
+	source := *fixtures.OrphanHeaderSource
+	source.PrevBlock = *fixtures.HashHeight4
+	db = append(db, *fixtures.BlockHeaderOf(1, fixtures.OrphanHash, &source, domains.Orphan))
+	integrity, repo := createIntegrityService(db)
+
+	// when
+	report, err := integrity.Check()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, report.IssuesCount, 1)
+	assert.Equal(t, report.Issues[0].Kind, domains.IntegrityOrphanWithParent)
+
+	// when
+	_, err = integrity.Repair()
+
+	// then
+	assert.NoError(t, err)
+	attached, _ := repo.GetHeaderByHash(fixtures.OrphanHash.String())
+	assert.Equal(t, attached.Height, 5)
+	assert.Equal(t, attached.State, domains.LongestChain)
+	assertConsistentAfterRepair(t, integrity)
+}
+
+func assertConsistentAfterRepair(t *testing.T, integrity *IntegrityService) {
+	t.Helper()
+	report, err := integrity.Check()
+	assert.NoError(t, err)
+	if !report.Consistent() {
+		t.Fatalf("Expected consistent headers after repair, got issues %v", report.Issues)
+	}
+}
+
+// integrityTestChain returns the longest chain up to height 4 with stale branch at heights 3 and 4, with their work.
+func integrityTestChain() []domains.BlockHeader {
+	db, _ := fixtures.StartingChain()
+	staleSource3 := *fixtures.StaleHeaderSourceHeight3
+	staleSource3.PrevBlock = *fixtures.HashHeight2
+
+	add := func(hash *chainhash.Hash, source *domains.BlockHeaderSource, state domains.HeaderState) {
+		h := domains.CreateHeader((*domains.BlockHash)(hash), source, headerWithHash(db, &source.PrevBlock))
+		h.State = state
+		db = append(db, h)
+	}
+	add(fixtures.HashHeight1, fixtures.HeaderSourceHeight1, domains.LongestChain)
+	add(fixtures.HashHeight2, fixtures.HeaderSourceHeight2, domains.LongestChain)
+	add(fixtures.HashHeight3, fixtures.HeaderSourceHeight3, domains.LongestChain)
+	add(fixtures.HashHeight4, fixtures.HeaderSourceHeight4, domains.LongestChain)
+	add(fixtures.StaleHashHeight3, &staleSource3, domains.Stale)
+	add(fixtures.StaleHashHeight4, fixtures.StaleHeaderSourceHeight4, domains.Stale)
+	return db
+}
+
+func headerWithHash(db []domains.BlockHeader, hash *chainhash.Hash) *domains.BlockHeader {
+	for i := range db {
+		if db[i].Hash == *hash {
+			return &db[i]
+		}
+	}
+	return nil
+}
+
+func hasIssue(report *domains.IntegrityReport, kind domains.IntegrityIssueKind, height int32) bool {
+	for _, issue := range report.Issues {
+		if issue.Kind == kind && issue.Height == height {
+			return true
+		}
+	}
+	return false
+}
+
+func createIntegrityService(db []domains.BlockHeader) (*IntegrityService, repository.Headers) {
+	headers := testrepository.NewHeadersTestRepository(&db)
+	logger := zerolog.Nop()
+	return NewIntegrityService(&repository.Repositories{Headers: headers}, &logger), headers
+}
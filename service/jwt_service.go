@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// jwksMinReloadInterval limits loading the key set when a JWT is signed by an unknown key.
+	jwksMinReloadInterval = time.Minute
+	// jwksReloadWait is the maximum time a request with unknown key waits until the key set can be loaded again.
+	jwksReloadWait   = time.Second
+	jwksFetchTimeout = 10 * time.Second
+	// jwtTokenIDPrefix distinguishes ids of JWT subjects from ids of tokens created by the service.
+	jwtTokenIDPrefix = "jwt:"
+)
+
+// jwtAlgorithms are the accepted signature algorithms, "none" and HMAC algorithms are never accepted.
+var jwtAlgorithms = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// jwtClaims are registered claims of the JWT and all its claims, from which scopes are read.
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	raw map[string]any
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *jwtClaims) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.RegisteredClaims); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.raw)
+}
+
+// GetExpirationTime hides the expiration time from the validator, so expired JWTs are returned
+// and rejected by callers as expired rather than invalid.
+func (c *jwtClaims) GetExpirationTime() (*jwt.NumericDate, error) {
+	return nil, nil
+}
+
+// JWTService verifies JWTs issued by an identity provider and maps them to tokens.
+type JWTService struct {
+	cfg          *config.JWTConfig
+	scopeMapping map[string][]domains.Scope
+	log          *zerolog.Logger
+	now          func() time.Time
+	keys         keyfunc.Keyfunc
+}
+
+// NewJWTService creates and returns JWTService instance, nil config disables JWTs.
+// The key set is loaded in the background for the lifetime of the process.
+func NewJWTService(cfg *config.JWTConfig, log *zerolog.Logger) *JWTService {
+	jwtLogger := log.With().Str("service", "jwt").Logger()
+	s := &JWTService{
+		cfg: cfg,
+		log: &jwtLogger,
+		now: time.Now,
+	}
+	if cfg == nil {
+		return s
+	}
+	s.scopeMapping = s.parseScopeMapping(cfg.ScopeMapping)
+	if cfg.Enabled {
+		keys, err := s.newKeyfunc()
+		if err != nil {
+			s.log.Error().Msgf("Cannot load jwks: %v", err)
+		}
+		s.keys = keys
+	}
+	return s
+}
+
+// Enabled checks if JWTs are accepted.
+func (s *JWTService) Enabled() bool {
+	return s != nil && s.cfg != nil && s.cfg.Enabled
+}
+
+// IsJWT checks if the value has the form of a JWT, values of tokens created by the service never do.
+func IsJWT(value string) bool {
+	return strings.Count(value, ".") == 2
+}
+
+// Verify checks signature, issuer and audience of the JWT and returns the token with scopes mapped from its claims.
+// Expired JWTs are returned as well, so callers should check expiration.
+func (s *JWTService) Verify(raw string) (*domains.Token, error) {
+	if s.keys == nil {
+		return nil, errors.New("jwks is not available")
+	}
+
+	claims := &jwtClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, s.keys.Keyfunc,
+		jwt.WithValidMethods(jwtAlgorithms),
+		jwt.WithIssuer(s.cfg.Issuer),
+		jwt.WithAudience(s.cfg.Audience),
+		jwt.WithLeeway(s.cfg.Leeway),
+		jwt.WithTimeFunc(s.now),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s.toToken(raw, claims)
+}
+
+func (s *JWTService) toToken(raw string, claims *jwtClaims) (*domains.Token, error) {
+	if claims.Subject == "" {
+		return nil, errors.New("jwt has no subject")
+	}
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("jwt has no expiration time")
+	}
+
+	expiresAt := claims.ExpiresAt.Add(s.cfg.Leeway)
+	token := &domains.Token{
+		Token:     raw,
+		ID:        jwtTokenIDPrefix + claims.Subject,
+		Name:      claims.Subject,
+		Owner:     claims.Issuer,
+		ExpiresAt: &expiresAt,
+		Scopes:    s.scopes(claims.raw[s.cfg.ScopesClaim]),
+		External:  true,
+	}
+	if claims.IssuedAt != nil {
+		token.CreatedAt = claims.IssuedAt.Time
+	}
+	return token, nil
+}
+
+// scopes maps the scopes claim to scopes of the service. Claimed scopes which are neither mapped
+// nor scopes of the service are dropped.
+func (s *JWTService) scopes(claim any) []domains.Scope {
+	var values []string
+	switch v := claim.(type) {
+	case string:
+		values = strings.Fields(v)
+	case []any:
+		for _, item := range v {
+			if value, ok := item.(string); ok {
+				values = append(values, value)
+			}
+		}
+	}
+
+	scopes := make([]domains.Scope, 0, len(values))
+	for _, value := range values {
+		mapped, ok := s.scopeMapping[strings.ToLower(value)]
+		if !ok {
+			if !slices.Contains(domains.AllScopes, domains.Scope(value)) {
+				s.log.Debug().Msgf("Dropping unknown jwt scope %s", value)
+				continue
+			}
+			mapped = []domains.Scope{domains.Scope(value)}
+		}
+		for _, scope := range mapped {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
+
+// parseScopeMapping parses mapping of scopes, every scope can be mapped to a comma separated list of scopes.
+// Keys are compared case-insensitively, as the configuration lowercases them.
+func (s *JWTService) parseScopeMapping(mapping map[string]string) map[string][]domains.Scope {
+	parsed := make(map[string][]domains.Scope, len(mapping))
+	for from, to := range mapping {
+		scopes, err := domains.ParseScopes(strings.Split(to, ","))
+		if err != nil {
+			s.log.Warn().Msgf("Ignoring mapping of jwt scope %s: %v", from, err)
+			continue
+		}
+		parsed[strings.ToLower(from)] = scopes
+	}
+	return parsed
+}
+
+// newKeyfunc creates source of keys verifying JWTs. The key set is loaded again every refresh interval
+// and when a JWT is signed by an unknown key, as the keys could have been rotated, but not more often than jwksMinReloadInterval.
+// Key set files are loaded the same way as URLs.
+func (s *JWTService) newKeyfunc() (keyfunc.Keyfunc, error) {
+	jwksURL := s.cfg.JWKSURL
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if s.cfg.JWKSFile != "" {
+		path, err := filepath.Abs(s.cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+		jwksURL = (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+	}
+
+	return keyfunc.NewDefaultOverrideCtx(context.Background(), []string{jwksURL}, keyfunc.Override{
+		Client:            &http.Client{Transport: transport, Timeout: jwksFetchTimeout},
+		HTTPTimeout:       jwksFetchTimeout,
+		RefreshInterval:   s.cfg.JWKSRefreshInterval,
+		RefreshUnknownKID: rate.NewLimiter(rate.Every(jwksMinReloadInterval), 1),
+		RateLimitWaitMax:  jwksReloadWait,
+		RefreshErrorHandlerFunc: func(u string) func(context.Context, error) {
+			return func(_ context.Context, err error) {
+				s.log.Error().Msgf("Cannot load jwks from %s: %v", u, err)
+			}
+		},
+	})
+}
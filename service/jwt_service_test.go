@@ -0,0 +1,148 @@
+package service
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testjwt"
+	"github.com/rs/zerolog"
+)
+
+func TestVerifyJWT(t *testing.T) {
+	for _, alg := range []string{"RS256", "ES256", "EdDSA"} {
+		t.Run(alg, func(t *testing.T) {
+			// setup
+			issuer := testjwt.NewIssuer(t, "key-1", alg)
+			jwt := createJWTService(testjwt.WriteJWKS(t, issuer), nil)
+
+			// when
+			token, err := jwt.Verify(issuer.Sign(t, testjwt.Claims("billing", "headers:read merkleroots:verify")))
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, token.ID, "jwt:billing")
+			assert.Equal(t, token.External, true)
+			assert.Equal(t, token.HasScope(domains.ScopeHeadersRead), true)
+			assert.Equal(t, token.HasScope(domains.ScopeMerkleRootsVerify), true)
+			assert.Equal(t, token.HasScope(domains.ScopeTokensAdmin), false)
+			assert.Equal(t, token.IsExpired(time.Now()), false)
+		})
+	}
+}
+
+func TestVerifyJWTMapsScopes(t *testing.T) {
+	// setup
+	issuer := testjwt.NewIssuer(t, "key-1", "ES256")
+	jwt := createJWTService(testjwt.WriteJWKS(t, issuer), map[string]string{
+		"bhs.read":  "headers:read",
+		"bhs.admin": "peers:admin,tokens:admin",
+	})
+	claims := testjwt.Claims("billing", "")
+	claims["scope"] = []string{"BHS.read", "bhs.admin", "unknown", "HEADERS:READ", "webhooks:write"}
+
+	// when
+	token, err := jwt.Verify(issuer.Sign(t, claims))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, domains.JoinScopes(token.Scopes), "headers:read,peers:admin,tokens:admin,webhooks:write")
+}
+
+func TestVerifyJWTRejectsInvalid(t *testing.T) {
+	issuer := testjwt.NewIssuer(t, "key-1", "RS256")
+	other := testjwt.NewIssuer(t, "key-2", "RS256")
+	jwksFile := testjwt.WriteJWKS(t, issuer)
+
+	valid := issuer.Sign(t, testjwt.Claims("billing", "headers:read"))
+	parts := strings.Split(valid, ".")
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+
+	withClaim := func(name string, value any) string {
+		claims := testjwt.Claims("billing", "headers:read")
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return issuer.Sign(t, claims)
+	}
+
+	tests := map[string]string{
+		"unknown key":        other.Sign(t, testjwt.Claims("billing", "headers:read")),
+		"tampered claims":    parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`)) + "." + parts[2],
+		"no signature":       unsigned,
+		"wrong issuer":       withClaim("iss", "https://other.example.com"),
+		"wrong audience":     withClaim("aud", []string{"other-service"}),
+		"without expiration": withClaim("exp", nil),
+		"without subject":    withClaim("sub", nil),
+		"not valid yet":      withClaim("nbf", time.Now().Add(time.Hour).Unix()),
+	}
+
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			// setup
+			jwt := createJWTService(jwksFile, nil)
+
+			// when
+			_, err := jwt.Verify(value)
+
+			// then
+			if err == nil {
+				t.Fatalf("Expected jwt to be rejected")
+			}
+		})
+	}
+}
+
+func TestVerifyExpiredJWT(t *testing.T) {
+	// setup
+	issuer := testjwt.NewIssuer(t, "key-1", "RS256")
+	jwt := createJWTService(testjwt.WriteJWKS(t, issuer), nil)
+	claims := testjwt.Claims("billing", "headers:read")
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+
+	// when
+	token, err := jwt.Verify(issuer.Sign(t, claims))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, token.IsExpired(time.Now()), true)
+}
+
+func TestJWTKeysAreReloadedForUnknownKey(t *testing.T) {
+	// setup
+	issuer := testjwt.NewIssuer(t, "key-1", "ES256")
+	rotated := testjwt.NewIssuer(t, "key-2", "ES256")
+	jwksFile := testjwt.WriteJWKS(t, issuer)
+	jwt := createJWTService(jwksFile, nil)
+	_, err := jwt.Verify(issuer.Sign(t, testjwt.Claims("billing", "")))
+	assert.NoError(t, err)
+
+	// given
+	rotatedKeys, err := os.ReadFile(testjwt.WriteJWKS(t, issuer, rotated))
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(jwksFile, rotatedKeys, 0o600))
+
+	// when
+	_, err = jwt.Verify(rotated.Sign(t, testjwt.Claims("billing", "")))
+
+	// then
+	assert.NoError(t, err)
+}
+
+func createJWTService(jwksFile string, scopeMapping map[string]string) *JWTService {
+	logger := zerolog.Nop()
+	cfg := config.GetDefaultAppConfig().HTTP.JWT
+	cfg.Enabled = true
+	cfg.JWKSFile = jwksFile
+	cfg.Issuer = testjwt.IssuerURL
+	cfg.Audience = testjwt.Audience
+	cfg.ScopeMapping = scopeMapping
+	return NewJWTService(cfg, &logger)
+}
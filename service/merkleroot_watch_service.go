@@ -0,0 +1,261 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/notification"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+// MerkleRootWatchService keeps track of watched merkle roots and notifies clients
+// when they get confirmed, invalidated or reach the configured number of confirmations.
+type MerkleRootWatchService struct {
+	repo      *repository.Repositories
+	merkleCfg *config.MerkleRootConfig
+	depth     int32
+	notifier  Notification
+	log       *zerolog.Logger
+	mu        sync.Mutex
+}
+
+// reorgSafetyDepth is the number of confirmations after which watches are retired when the confirmation depth
+// isn't set, until then reorgs invalidating their merkle roots are reported.
+const reorgSafetyDepth = 6
+
+type merkleRootKey struct {
+	merkleRoot  string
+	blockHeight int32
+}
+
+// NewMerkleRootWatchService creates and returns MerkleRootWatchService instance.
+func NewMerkleRootWatchService(
+	repo *repository.Repositories,
+	merkleCfg *config.MerkleRootConfig,
+	notificationCfg *config.NotificationConfig,
+	notifier Notification,
+	log *zerolog.Logger,
+) *MerkleRootWatchService {
+	watchLogger := log.With().Str("service", "merkleroot-watch").Logger()
+	s := &MerkleRootWatchService{
+		repo:      repo,
+		merkleCfg: merkleCfg,
+		notifier:  notifier,
+		log:       &watchLogger,
+	}
+	if notificationCfg != nil {
+		s.depth = int32(notificationCfg.ConfirmationDepth)
+	}
+	return s
+}
+
+// Watch registers merkle roots of the owner to notify about and returns them with their current state.
+func (s *MerkleRootWatchService) Watch(owner string, items []domains.MerkleRootConfirmationRequestItem) ([]*domains.MerkleRootWatch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.repo.MerkleRootWatches.GetMerkleRootWatches(owner)
+	if err != nil {
+		return nil, err
+	}
+	watched := make(map[merkleRootKey]*domains.MerkleRootWatch, len(existing))
+	for _, w := range existing {
+		watched[keyOf(w)] = w
+	}
+
+	result := make([]*domains.MerkleRootWatch, 0, len(items))
+	added := make([]*domains.MerkleRootWatch, 0, len(items))
+	for _, item := range items {
+		key := merkleRootKey{merkleRoot: item.MerkleRoot, blockHeight: item.BlockHeight}
+		if w, ok := watched[key]; ok {
+			result = append(result, w)
+			continue
+		}
+
+		w := domains.NewMerkleRootWatch(owner, item)
+		if err := s.repo.MerkleRootWatches.AddMerkleRootWatch(w); err != nil {
+			return nil, err
+		}
+		watched[key] = w
+		result = append(result, w)
+		added = append(added, w)
+	}
+
+	s.evaluate(added)
+	return result, nil
+}
+
+// Unwatch removes merkle root watched by the owner.
+func (s *MerkleRootWatchService) Unwatch(owner, merkleRoot string, blockHeight int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.repo.MerkleRootWatches.DeleteMerkleRootWatch(owner, merkleRoot, blockHeight)
+}
+
+// GetWatches returns merkle roots watched by the owner.
+func (s *MerkleRootWatchService) GetWatches(owner string) ([]*domains.MerkleRootWatch, error) {
+	return s.repo.MerkleRootWatches.GetMerkleRootWatches(owner)
+}
+
+// Accepts checks if event is a header added to the longest chain or the end of synchronization of headers,
+// the only events re-evaluating watched merkle roots.
+func (s *MerkleRootWatchService) Accepts(event notification.Event) bool {
+	switch e := event.(type) {
+	case *domains.HeaderEvent:
+		return e.Operation == domains.EventHeaderAdded && e.Header != nil && e.Header.State == domains.LongestChain
+	case *domains.SyncEvent:
+		return e.Operation == domains.EventSyncCaughtUp
+	default:
+		return false
+	}
+}
+
+// Notify re-evaluates watched merkle roots when a header is added to the longest chain
+// or headers caught up with the network.
+func (s *MerkleRootWatchService) Notify(event notification.Event) {
+	if !s.Accepts(event) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watches, err := s.watchesAffectedBy(event)
+	if err != nil {
+		s.log.Error().Msgf("Cannot load watched merkle roots. %v", err)
+		return
+	}
+	s.evaluate(watches)
+}
+
+// watchesAffectedBy returns watches which state can change after the event. A header at height H confirms
+// watches at H, brings watches at H - depth + 1 to the confirmation depth and settles watches at H - depth,
+// so only those heights are loaded. The end of synchronization re-evaluates all watches.
+func (s *MerkleRootWatchService) watchesAffectedBy(event notification.Event) ([]*domains.MerkleRootWatch, error) {
+	e, ok := event.(*domains.HeaderEvent)
+	if !ok {
+		return s.repo.MerkleRootWatches.GetAllMerkleRootWatches()
+	}
+	from := max(e.Header.Height-s.retireDepth(), 0)
+	return s.repo.MerkleRootWatches.GetMerkleRootWatchesByHeight(from, e.Header.Height)
+}
+
+func (s *MerkleRootWatchService) evaluate(watches []*domains.MerkleRootWatch) {
+	if len(watches) == 0 {
+		return
+	}
+
+	request := make([]domains.MerkleRootConfirmationRequestItem, 0, len(watches))
+	requested := make(map[merkleRootKey]bool, len(watches))
+	for _, w := range watches {
+		if !requested[keyOf(w)] {
+			requested[keyOf(w)] = true
+			request = append(request, domains.MerkleRootConfirmationRequestItem{MerkleRoot: w.MerkleRoot, BlockHeight: w.BlockHeight})
+		}
+	}
+
+	confirmations, err := s.repo.Headers.GetMerkleRootsConfirmations(request, s.merkleCfg.MaxBlockHeightExcess)
+	if err != nil {
+		s.log.Error().Msgf("Cannot verify watched merkle roots. %v", err)
+		return
+	}
+	tip, err := s.repo.Headers.GetTip()
+	if err != nil {
+		s.log.Error().Msgf("Cannot get tip to verify watched merkle roots. %v", err)
+		return
+	}
+
+	byKey := make(map[merkleRootKey]*domains.MerkleRootConfirmation, len(confirmations))
+	for _, c := range confirmations {
+		byKey[merkleRootKey{merkleRoot: c.MerkleRoot, blockHeight: c.BlockHeight}] = c
+	}
+
+	for _, w := range watches {
+		c, ok := byKey[keyOf(w)]
+		if !ok {
+			continue
+		}
+		changed := s.update(w, c, tip.Height)
+		if s.settled(w, tip.Height) {
+			if err := s.repo.MerkleRootWatches.DeleteMerkleRootWatch(w.Owner, w.MerkleRoot, w.BlockHeight); err != nil {
+				s.log.Error().Msgf("Error has happened during retiring merkle root watch: %v", err)
+			}
+		} else if changed {
+			if err := s.repo.MerkleRootWatches.UpdateMerkleRootWatch(w); err != nil {
+				s.log.Error().Msgf("Error has happened during updating merkle root watch state: %v", err)
+			}
+		}
+	}
+}
+
+// settled reports if the watch will not notify about anything else, it stayed confirmed or invalid
+// for the confirmation depth, so it can be retired.
+func (s *MerkleRootWatchService) settled(w *domains.MerkleRootWatch, tipHeight int32) bool {
+	switch w.Confirmation {
+	case domains.Confirmed:
+		if s.depth > 0 {
+			return w.ConfirmationsReached
+		}
+		return tipHeight-w.BlockHeight+1 >= reorgSafetyDepth
+	case domains.Invalid:
+		return tipHeight >= w.BlockHeight+s.retireDepth()
+	default:
+		return false
+	}
+}
+
+// retireDepth returns the number of blocks after which watches are settled, the confirmation depth
+// or reorgSafetyDepth when it isn't set.
+func (s *MerkleRootWatchService) retireDepth() int32 {
+	if s.depth > 0 {
+		return s.depth
+	}
+	return reorgSafetyDepth
+}
+
+// update applies confirmation to the watch, notifies about the changes and reports if the watch changed.
+func (s *MerkleRootWatchService) update(w *domains.MerkleRootWatch, c *domains.MerkleRootConfirmation, tipHeight int32) bool {
+	switch c.Confirmation {
+	case domains.Confirmed:
+		changed := false
+		confirmations := tipHeight - w.BlockHeight + 1
+		if w.Confirmation != domains.Confirmed {
+			w.Confirmation = domains.Confirmed
+			s.notify(domains.EventMerkleRootConfirmed, w, c, confirmations)
+			changed = true
+		}
+		if s.depth > 0 && !w.ConfirmationsReached && confirmations >= s.depth {
+			w.ConfirmationsReached = true
+			s.notify(domains.EventMerkleRootConfirmationsReached, w, c, confirmations)
+			changed = true
+		}
+		return changed
+	case domains.Invalid:
+		if w.Confirmation == domains.Invalid {
+			return false
+		}
+		w.Confirmation = domains.Invalid
+		w.ConfirmationsReached = false
+		s.notify(domains.EventMerkleRootInvalid, w, c, 0)
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *MerkleRootWatchService) notify(operation domains.MerkleRootEventType, w *domains.MerkleRootWatch, c *domains.MerkleRootConfirmation, confirmations int32) {
+	s.notifier.Notify(&domains.MerkleRootEvent{
+		Operation:     operation,
+		MerkleRoot:    c.MerkleRoot,
+		BlockHeight:   c.BlockHeight,
+		Hash:          c.Hash,
+		Confirmations: confirmations,
+		Watcher:       w.Owner,
+	})
+}
+
+func keyOf(w *domains.MerkleRootWatch) merkleRootKey {
+	return merkleRootKey{merkleRoot: w.MerkleRoot, blockHeight: w.BlockHeight}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/rs/zerolog"
+)
+
+func TestWatchNotifiesAboutConfirmedAndInvalidMerkleRoots(t *testing.T) {
+	// setup
+	notification := newRecordingNotification()
+	watches, repo := createMerkleRootWatchService(notification, 3)
+	header, _ := repo.Headers.GetHeaderByHeight(2)
+
+	// when
+	result, err := watches.Watch("token", []domains.MerkleRootConfirmationRequestItem{
+		{MerkleRoot: header.MerkleRoot.String(), BlockHeight: 2},
+		{MerkleRoot: "invalid_merkle_root", BlockHeight: 1},
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(result), 2)
+	assert.Equal(t, result[0].Confirmation, domains.Confirmed)
+	assert.Equal(t, result[0].ConfirmationsReached, true)
+	assert.Equal(t, result[1].Confirmation, domains.Invalid)
+
+	events := merkleRootEvents(notification)
+	assert.Equal(t, len(events), 3)
+	assert.Equal(t, events[0].Operation, domains.EventMerkleRootConfirmed)
+	assert.Equal(t, events[0].Hash, header.Hash.String())
+	assert.Equal(t, events[1].Operation, domains.EventMerkleRootConfirmationsReached)
+	assert.Equal(t, events[2].Operation, domains.EventMerkleRootInvalid)
+}
+
+func TestWatchNotifiesWhenMerkleRootReachesConfirmations(t *testing.T) {
+	// setup
+	notification := newRecordingNotification()
+	watches, repo := createMerkleRootWatchService(notification, 10)
+	tip, _ := repo.Headers.GetTip()
+	_, _ = watches.Watch("token", []domains.MerkleRootConfirmationRequestItem{
+		{MerkleRoot: tip.MerkleRoot.String(), BlockHeight: tip.Height},
+	})
+	notification.Clear()
+	watches.depth = 1
+
+	// when
+	watches.Notify(domains.HeaderAdded(tip))
+	watches.Notify(domains.HeaderAdded(tip))
+
+	// then
+	events := merkleRootEvents(notification)
+	assert.Equal(t, len(events), 1)
+	assert.Equal(t, events[0].Operation, domains.EventMerkleRootConfirmationsReached)
+	assert.Equal(t, events[0].Confirmations, int32(1))
+}
+
+func TestUnwatchNotExistingMerkleRoot(t *testing.T) {
+	// setup
+	watches, _ := createMerkleRootWatchService(newRecordingNotification(), 3)
+
+	// when
+	err := watches.Unwatch("token", "not_watched", 1)
+
+	// then
+	assert.Equal(t, err != nil, true)
+}
+
+func TestUnwatchMerkleRootOfOtherToken(t *testing.T) {
+	// setup
+	watches, _ := createMerkleRootWatchService(newRecordingNotification(), 3)
+	_, _ = watches.Watch("token", []domains.MerkleRootConfirmationRequestItem{{MerkleRoot: "future_merkle_root", BlockHeight: 1000}})
+
+	// when
+	err := watches.Unwatch("other_token", "future_merkle_root", 1000)
+
+	// then
+	assert.Equal(t, err != nil, true)
+	other, _ := watches.GetWatches("other_token")
+	assert.Equal(t, len(other), 0)
+	owned, _ := watches.GetWatches("token")
+	assert.Equal(t, len(owned), 1)
+}
+
+func TestWatchRetiresSettledMerkleRoots(t *testing.T) {
+	// setup
+	watches, repo := createMerkleRootWatchService(newRecordingNotification(), 3)
+	header, _ := repo.Headers.GetHeaderByHeight(2)
+	tip, _ := repo.Headers.GetTip()
+
+	// when
+	_, err := watches.Watch("token", []domains.MerkleRootConfirmationRequestItem{
+		{MerkleRoot: header.MerkleRoot.String(), BlockHeight: 2},
+		{MerkleRoot: "invalid_merkle_root", BlockHeight: 1},
+		{MerkleRoot: tip.MerkleRoot.String(), BlockHeight: tip.Height},
+	})
+
+	// then
+	assert.NoError(t, err)
+	remaining, _ := watches.GetWatches("token")
+	assert.Equal(t, len(remaining), 1)
+	assert.Equal(t, remaining[0].BlockHeight, tip.Height)
+	assert.Equal(t, remaining[0].Confirmation, domains.Confirmed)
+}
+
+func TestWatchNotifiesAboutReorgOfConfirmedMerkleRootWithoutConfirmationDepth(t *testing.T) {
+	// setup
+	notification := newRecordingNotification()
+	watches, repo := createMerkleRootWatchService(notification, 0)
+	tip, _ := repo.Headers.GetTip()
+	_, _ = watches.Watch("token", []domains.MerkleRootConfirmationRequestItem{
+		{MerkleRoot: tip.MerkleRoot.String(), BlockHeight: tip.Height},
+	})
+	notification.Clear()
+
+	// when
+	_ = repo.Headers.UpdateState([]chainhash.Hash{tip.Hash}, domains.Stale)
+	watches.Notify(domains.HeaderAdded(tip))
+
+	// then
+	events := merkleRootEvents(notification)
+	assert.Equal(t, len(events), 1)
+	assert.Equal(t, events[0].Operation, domains.EventMerkleRootInvalid)
+	remaining, _ := watches.GetWatches("token")
+	assert.Equal(t, len(remaining), 1)
+	assert.Equal(t, remaining[0].Confirmation, domains.Invalid)
+}
+
+func TestNotifyEvaluatesOnlyWatchesNearAddedHeader(t *testing.T) {
+	// setup
+	notification := newRecordingNotification()
+	watches, repo := createMerkleRootWatchService(notification, 10)
+	header, _ := repo.Headers.GetHeaderByHeight(2)
+	_, _ = watches.Watch("token", []domains.MerkleRootConfirmationRequestItem{
+		{MerkleRoot: header.MerkleRoot.String(), BlockHeight: 2},
+	})
+	notification.Clear()
+	watches.depth = 1
+	far := *header
+	far.Height = 2 + 5
+
+	// when
+	watches.Notify(domains.HeaderAdded(&far))
+
+	// then
+	assert.Equal(t, len(merkleRootEvents(notification)), 0)
+
+	// when
+	watches.Notify(domains.HeaderAdded(header))
+
+	// then
+	events := merkleRootEvents(notification)
+	assert.Equal(t, len(events), 1)
+	assert.Equal(t, events[0].Operation, domains.EventMerkleRootConfirmationsReached)
+}
+
+func createMerkleRootWatchService(notification *recordingNotification, depth int) (*MerkleRootWatchService, *testrepository.TestRepositories) {
+	log := zerolog.Nop()
+	db, _ := fixtures.LongestChain()
+	var array []domains.BlockHeader = db
+	repo := testrepository.NewCleanTestRepositories()
+	repo.Headers = testrepository.NewHeadersTestRepository(&array)
+	watches := NewMerkleRootWatchService(
+		repo.ToDomainRepo(),
+		&config.MerkleRootConfig{MaxBlockHeightExcess: 6},
+		&config.NotificationConfig{ConfirmationDepth: depth},
+		notification,
+		&log,
+	)
+	return watches, &repo
+}
+
+func merkleRootEvents(r *recordingNotification) []*domains.MerkleRootEvent {
+	events := make([]*domains.MerkleRootEvent, 0)
+	for _, e := range r.Events {
+		if me, ok := e.(*domains.MerkleRootEvent); ok {
+			events = append(events, me)
+		}
+	}
+	return events
+}
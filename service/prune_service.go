@@ -0,0 +1,201 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/metrics"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+// Triggers of prunes, distinguishing them in metrics.
+const (
+	pruneTriggerPeriodic = "periodic"
+	pruneTriggerAdmin    = "admin"
+)
+
+// PruneService removes headers outside the longest chain according to the retention policy.
+type PruneService struct {
+	repo  *repository.Repositories
+	cfg   *config.PruneConfig
+	audit *AuditService
+	now   func() time.Time
+	log   *zerolog.Logger
+
+	// mu prevents periodic and requested prunes from running at once.
+	mu sync.Mutex
+	// chainLock is shared with the chain service, so a stale branch isn't pruned while a reorganization promotes it.
+	chainLock sync.Locker
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewPruneService creates and returns PruneService instance. The chain lock has to be the one of the chain service.
+func NewPruneService(repo *repository.Repositories, cfg *config.PruneConfig, audit *AuditService, chainLock sync.Locker, log *zerolog.Logger) *PruneService {
+	if cfg == nil {
+		cfg = &config.PruneConfig{}
+	}
+	pruneLogger := log.With().Str("service", "prune").Logger()
+	return &PruneService{
+		repo:      repo,
+		cfg:       cfg,
+		audit:     audit,
+		chainLock: chainLock,
+		now:       time.Now,
+		log:       &pruneLogger,
+	}
+}
+
+// Start prunes headers every interval when periodic prune is enabled, until the service is stopped.
+func (s *PruneService) Start() {
+	if !s.cfg.Enabled {
+		return
+	}
+	s.log.Info().Msgf("Pruning headers every %s", s.cfg.Interval)
+
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if _, err := s.prune(pruneTriggerPeriodic); err != nil {
+					s.log.Error().Msgf("Cannot prune headers: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops periodic prune and waits until the running prune finishes.
+func (s *PruneService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+	s.stop = nil
+}
+
+// Prune removes headers according to the retention policy on request of the actor.
+func (s *PruneService) Prune(actor domains.AuditActor) (*domains.PruneReport, error) {
+	report, err := s.prune(pruneTriggerAdmin)
+	s.audit.Record(actor, domains.AuditHeaderPrune, "headers", err)
+	return report, err
+}
+
+func (s *PruneService) prune(trigger string) (*domains.PruneReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	start := time.Now()
+
+	tip, err := s.repo.Headers.GetTip()
+	if err != nil {
+		return nil, err
+	}
+	report := &domains.PruneReport{TipHeight: tip.Height}
+
+	if s.cfg.StaleDepth > 0 {
+		if report.Stale, err = s.pruneStaleBranches(tip.Height - s.cfg.StaleDepth); err != nil {
+			return nil, err
+		}
+	}
+	if s.cfg.OrphanMaxAge > 0 {
+		if report.Orphan, err = s.pruneOlderThan(domains.Orphan, s.cfg.OrphanMaxAge); err != nil {
+			return nil, err
+		}
+	}
+	if s.cfg.RejectedMaxAge > 0 {
+		if report.Rejected, err = s.pruneOlderThan(domains.Rejected, s.cfg.RejectedMaxAge); err != nil {
+			return nil, err
+		}
+	}
+
+	metrics.ObservePrune(trigger, time.Since(start))
+	s.log.Info().Msgf("Pruned %d stale, %d orphan and %d rejected headers", report.Stale, report.Orphan, report.Rejected)
+	return report, nil
+}
+
+// pruneStaleBranches removes stale branches forked from the longest chain below the height. Headers aren't added
+// meanwhile, so a branch becoming the longest chain isn't removed.
+func (s *PruneService) pruneStaleBranches(below int32) (int, error) {
+	s.chainLock.Lock()
+	defer s.chainLock.Unlock()
+
+	tips, err := s.repo.Headers.GetAllTips()
+	if err != nil {
+		return 0, err
+	}
+
+	hashes := make([]chainhash.Hash, 0)
+	selected := make(map[chainhash.Hash]struct{})
+	for _, tip := range tips {
+		if tip.State != domains.Stale {
+			continue
+		}
+		branch, forkHeight, err := s.staleBranch(tip)
+		if err != nil {
+			return 0, err
+		}
+		if forkHeight >= below {
+			continue
+		}
+		// branches forked from the same branch share its headers
+		for _, hash := range branch {
+			if _, ok := selected[hash]; !ok {
+				selected[hash] = struct{}{}
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+	if len(hashes) == 0 {
+		return 0, nil
+	}
+
+	pruned, err := s.repo.Headers.DeleteHeaders(hashes)
+	if err != nil {
+		return 0, err
+	}
+	metrics.AddPrunedHeaders(string(domains.Stale), pruned)
+	return pruned, nil
+}
+
+// staleBranch returns hashes of stale headers from the tip down to the fork and the height of the fork,
+// which is the height of the header of the longest chain the branch is built on.
+func (s *PruneService) staleBranch(tip *domains.BlockHeader) ([]chainhash.Hash, int32, error) {
+	branch := make([]chainhash.Hash, 0)
+	h := tip
+	for h.State == domains.Stale {
+		branch = append(branch, h.Hash)
+		previous, err := s.repo.Headers.GetHeaderByHash(h.PreviousBlock.String())
+		if errors.Is(err, bhserrors.ErrHeaderNotFound) {
+			return branch, h.Height - 1, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		h = previous
+	}
+	return branch, h.Height, nil
+}
+
+// pruneOlderThan removes headers in the state stored before the age, timestamps of headers are chosen by peers.
+func (s *PruneService) pruneOlderThan(state domains.HeaderState, age time.Duration) (int, error) {
+	pruned, err := s.repo.Headers.DeleteHeadersOlderThan(state, s.now().Add(-age))
+	if err != nil {
+		return 0, err
+	}
+	metrics.AddPrunedHeaders(string(state), pruned)
+	return pruned, nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+func TestPruneOfStaleBranches(t *testing.T) {
+	// stale branch at heights 3 and 4 is forked from the longest chain at height 2, 2 blocks below the tip
+	tests := map[string]struct {
+		staleDepth int32
+		pruned     int
+	}{
+		"forked deeper than stale depth": {
+			staleDepth: 1,
+			pruned:     2,
+		},
+		"forked at stale depth": {
+			staleDepth: 2,
+			pruned:     0,
+		},
+		"stale branches kept": {
+			staleDepth: 0,
+			pruned:     0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			prune, repo := createPruneService(integrityTestChain(), &config.PruneConfig{StaleDepth: tc.staleDepth})
+
+			// when
+			report, err := prune.prune(pruneTriggerAdmin)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, report.TipHeight, 4)
+			assert.Equal(t, report.Stale, tc.pruned)
+			count, _ := repo.GetHeadersCount()
+			assert.Equal(t, count, 7-tc.pruned)
+			_, err = repo.GetHeaderByHash(fixtures.HashHeight4.String())
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPruneOfStaleBranchesSharingHeaders(t *testing.T) {
+	// given
+	db := integrityTestChain()
+	source := *fixtures.StaleHeaderSourceHeight4
+	source.Nonce++
+	sibling := domains.CreateHeader((*domains.BlockHash)(fixtures.HashOf("1d53d3e3c1d51b8a1cb2b0ef2f49e5cdfe3ca98d5d5b9b1a4ab61e2d8a1f9c01")), &source, headerWithHash(db, fixtures.StaleHashHeight3))
+	sibling.State = domains.Stale
+	db = append(db, sibling)
+	prune, repo := createPruneService(db, &config.PruneConfig{StaleDepth: 1})
+
+	// when
+	report, err := prune.prune(pruneTriggerAdmin)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, report.Stale, 3)
+	for _, hash := range []*chainhash.Hash{fixtures.StaleHashHeight3, fixtures.StaleHashHeight4, &sibling.Hash} {
+		_, err = repo.GetHeaderByHash(hash.String())
+		if err == nil {
+			t.Fatalf("Expected stale header %s to be pruned", hash)
+		}
+	}
+}
+
+func TestPruneOfOldOrphans(t *testing.T) {
+	// given
+	now := time.Now()
+	db := integrityTestChain()
+	oldOrphan := fixtures.BlockHeaderOf(1, fixtures.OrphanHash, fixtures.OrphanHeaderSource, domains.Orphan)
+	oldOrphan.Timestamp = now
+	recentOrphan := fixtures.BlockHeaderOf(1, fixtures.HashOf("2ab3c0e1d6a5a9f3b7c8d2e4f60718293a4b5c6d7e8f90a1b2c3d4e5f6071829"), fixtures.OrphanHeaderSource, domains.Orphan)
+	// orphans are aged by the time they were stored, not by timestamps chosen by peers
+	recentOrphan.Timestamp = now.Add(-48 * time.Hour)
+	db = append(db, *oldOrphan, *recentOrphan)
+	prune, repo := createPruneService(db, &config.PruneConfig{OrphanMaxAge: 24 * time.Hour})
+	prune.now = func() time.Time { return now }
+	repo.SetStoredAt(oldOrphan.Hash, now.Add(-25*time.Hour))
+	repo.SetStoredAt(recentOrphan.Hash, now.Add(-time.Hour))
+
+	// when
+	report, err := prune.prune(pruneTriggerAdmin)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, report.Orphan, 1)
+	assert.Equal(t, report.Stale, 0)
+	_, err = repo.GetHeaderByHash(recentOrphan.Hash.String())
+	assert.NoError(t, err)
+	_, err = repo.GetHeaderByHash(oldOrphan.Hash.String())
+	if err == nil {
+		t.Fatalf("Expected old orphan to be pruned")
+	}
+}
+
+func TestPeriodicPrune(t *testing.T) {
+	// given
+	db := integrityTestChain()
+	prune, repo := createPruneService(db, &config.PruneConfig{Enabled: true, Interval: 10 * time.Millisecond, StaleDepth: 1})
+
+	// when
+	prune.Start()
+	deadline := time.Now().Add(time.Second)
+	count := 0
+	for count != 5 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		prune.mu.Lock()
+		count, _ = repo.GetHeadersCount()
+		prune.mu.Unlock()
+	}
+	prune.Stop()
+
+	// then
+	assert.Equal(t, count, 5)
+}
+
+func createPruneService(db []domains.BlockHeader, cfg *config.PruneConfig) (*PruneService, *testrepository.HeaderTestRepository) {
+	headers := testrepository.NewHeadersTestRepository(&db)
+	logger := zerolog.Nop()
+	return NewPruneService(&repository.Repositories{Headers: headers}, cfg, nil, &sync.Mutex{}, &logger), headers
+}
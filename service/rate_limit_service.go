@@ -0,0 +1,180 @@
+package service
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// quotaFlushInterval limits how often usage of a quota is saved, so not every request writes to db.
+	quotaFlushInterval = time.Minute
+	// bucketsCleanupInterval is the number of requests after which idle buckets are removed.
+	bucketsCleanupInterval = 1000
+	quotaDayLayout         = "2006-01-02"
+)
+
+// RateLimitService limits requests of clients with token buckets per group of routes and daily quotas per token.
+type RateLimitService struct {
+	repo    *repository.Repositories
+	cfg     *config.RateLimitConfig
+	log     *zerolog.Logger
+	now     func() time.Time
+	mu      sync.Mutex
+	buckets map[bucketKey]*tokenBucket
+	quotas  map[string]*quotaUsage
+	calls   int
+}
+
+type bucketKey struct {
+	client string
+	group  string
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type quotaUsage struct {
+	day       string
+	used      int64
+	flushedAt time.Time
+}
+
+// NewRateLimitService creates and returns RateLimitService instance, nil config disables limits.
+func NewRateLimitService(repo *repository.Repositories, cfg *config.RateLimitConfig, log *zerolog.Logger) *RateLimitService {
+	rateLimitLogger := log.With().Str("service", "rate-limit").Logger()
+	return &RateLimitService{
+		repo:    repo,
+		cfg:     cfg,
+		log:     &rateLimitLogger,
+		now:     time.Now,
+		buckets: make(map[bucketKey]*tokenBucket),
+		quotas:  make(map[string]*quotaUsage),
+	}
+}
+
+// RateLimitClient returns the key under which requests of the client are limited.
+func RateLimitClient(token *domains.Token, ip string) string {
+	switch {
+	case token == nil:
+		return "ip:" + ip
+	case token.IsAdmin:
+		return "token:admin"
+	default:
+		return "token:" + token.ID
+	}
+}
+
+// Enabled checks if requests are limited.
+func (s *RateLimitService) Enabled() bool {
+	return s != nil && s.cfg != nil && s.cfg.Enabled
+}
+
+// Allow takes cost tokens from the bucket of the client in the group.
+// When there are not enough tokens, it returns false and the time after which the request can be repeated.
+// A request costing more than the burst is allowed only when the bucket is full and it's charged the full cost,
+// so the bucket goes into debt and following requests wait until it's paid off.
+func (s *RateLimitService) Allow(client, group string, cost int) (bool, time.Duration) {
+	if !s.Enabled() {
+		return true, 0
+	}
+	limit := s.cfg.Limit(group)
+	charged := float64(max(cost, 1))
+	// a request costing more than the burst would never be allowed otherwise
+	needed := math.Min(charged, float64(limit.Burst))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.cleanupBuckets(now)
+
+	key := bucketKey{client: client, group: group}
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	if b.tokens < needed {
+		wait := time.Duration((needed - b.tokens) / limit.Rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens -= charged
+	return true, 0
+}
+
+// UseQuota counts a request of the token in its daily quota.
+// When the quota is exhausted, it returns false and the time until the quota is renewed.
+func (s *RateLimitService) UseQuota(token *domains.Token) (bool, time.Duration) {
+	if !s.Enabled() || token == nil || token.IsAdmin || token.ID == "" {
+		return true, 0
+	}
+	quota := token.DailyQuota
+	if quota == 0 {
+		quota = s.cfg.DailyQuota
+	}
+	if quota <= 0 {
+		return true, 0
+	}
+
+	s.mu.Lock()
+	now := s.now().UTC()
+	day := now.Format(quotaDayLayout)
+	usage, ok := s.quotas[token.ID]
+	if !ok {
+		// usage saved by a previous run of the service
+		usage = &quotaUsage{day: token.QuotaDay, used: token.QuotaUsed, flushedAt: now}
+		s.quotas[token.ID] = usage
+	}
+	if usage.day != day {
+		usage.day, usage.used = day, 0
+	}
+
+	if usage.used >= quota {
+		s.mu.Unlock()
+		nextDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+		return false, nextDay.Sub(now)
+	}
+	usage.used++
+
+	// usage of external tokens is kept only in memory, as they aren't stored
+	flush := !token.External && (now.Sub(usage.flushedAt) >= quotaFlushInterval || usage.used == quota)
+	if flush {
+		usage.flushedAt = now
+	}
+	day, used := usage.day, usage.used
+	s.mu.Unlock()
+
+	// saved without holding the lock, so requests of other clients don't wait for the database
+	if flush {
+		if err := s.repo.Tokens.UpdateTokenQuotaUsage(token.ID, day, used); err != nil {
+			s.log.Warn().Msgf("Cannot save quota usage of token %s: %v", token.Prefix, err)
+		}
+	}
+	return true, 0
+}
+
+// cleanupBuckets removes buckets which refilled completely, as they are the same as new ones.
+func (s *RateLimitService) cleanupBuckets(now time.Time) {
+	s.calls++
+	if s.calls < bucketsCleanupInterval {
+		return
+	}
+	s.calls = 0
+	for key, b := range s.buckets {
+		limit := s.cfg.Limit(key.group)
+		if b.tokens+now.Sub(b.last).Seconds()*limit.Rate >= float64(limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/rs/zerolog"
+)
+
+func TestRateLimitRefillsBucket(t *testing.T) {
+	// setup
+	limits, now := createRateLimitService(0)
+
+	// when
+	allowed := 0
+	for range 5 {
+		if ok, _ := limits.Allow("client", config.RateLimitGroupHeaders, 1); ok {
+			allowed++
+		}
+	}
+	ok, retryAfter := limits.Allow("client", config.RateLimitGroupHeaders, 1)
+
+	// then
+	assert.Equal(t, allowed, 4)
+	assert.Equal(t, ok, false)
+	assert.Equal(t, retryAfter, 500*time.Millisecond)
+
+	// when
+	*now = now.Add(500 * time.Millisecond)
+	ok, _ = limits.Allow("client", config.RateLimitGroupHeaders, 1)
+
+	// then
+	assert.Equal(t, ok, true)
+}
+
+func TestRateLimitSeparatesClientsAndGroups(t *testing.T) {
+	// setup
+	limits, _ := createRateLimitService(0)
+	ok, _ := limits.Allow("client", config.RateLimitGroupHeaders, 4)
+	assert.Equal(t, ok, true)
+
+	// when
+	otherClient, _ := limits.Allow("other", config.RateLimitGroupHeaders, 1)
+	otherGroup, _ := limits.Allow("client", config.RateLimitGroupMerkleRoots, 1)
+	sameGroup, _ := limits.Allow("client", config.RateLimitGroupHeaders, 1)
+
+	// then
+	assert.Equal(t, otherClient, true)
+	assert.Equal(t, otherGroup, true)
+	assert.Equal(t, sameGroup, false)
+}
+
+func TestRateLimitChargesFullCostOfLargeRequest(t *testing.T) {
+	// setup
+	limits, now := createRateLimitService(0)
+
+	// when
+	large, _ := limits.Allow("client", config.RateLimitGroupHeaders, 10)
+	next, retryAfter := limits.Allow("client", config.RateLimitGroupHeaders, 1)
+
+	// then the request over the burst of 4 left the bucket 6 tokens in debt
+	assert.Equal(t, large, true)
+	assert.Equal(t, next, false)
+	assert.Equal(t, retryAfter, 3500*time.Millisecond)
+
+	// when
+	*now = now.Add(3500 * time.Millisecond)
+	next, _ = limits.Allow("client", config.RateLimitGroupHeaders, 1)
+
+	// then
+	assert.Equal(t, next, true)
+}
+
+func TestDailyQuota(t *testing.T) {
+	// setup
+	limits, now := createRateLimitService(2)
+	token := domains.CreateToken("quota_token_value", domains.TokenOptions{})
+	assert.NoError(t, limits.repo.Tokens.AddTokenToDatabase(token))
+
+	// when
+	first, _ := limits.UseQuota(token)
+	second, _ := limits.UseQuota(token)
+	third, retryAfter := limits.UseQuota(token)
+
+	// then
+	assert.Equal(t, first, true)
+	assert.Equal(t, second, true)
+	assert.Equal(t, third, false)
+	assert.Equal(t, retryAfter, 12*time.Hour)
+
+	// and the exhausted quota is saved
+	stored, err := limits.repo.Tokens.GetTokenByHash(token.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, stored.QuotaDay, "2024-05-01")
+	assert.Equal(t, stored.QuotaUsed, int64(2))
+
+	// when
+	*now = now.Add(12 * time.Hour)
+	nextDay, _ := limits.UseQuota(token)
+
+	// then
+	assert.Equal(t, nextDay, true)
+}
+
+func TestDailyQuotaOfToken(t *testing.T) {
+	// setup
+	limits, _ := createRateLimitService(0)
+	token := domains.CreateToken("quota_token_value", domains.TokenOptions{DailyQuota: 1})
+	// usage saved before restart of the service
+	token.QuotaDay, token.QuotaUsed = "2024-05-01", 1
+
+	// when
+	ok, _ := limits.UseQuota(token)
+
+	// then
+	assert.Equal(t, ok, false)
+
+	// when
+	admin, _ := limits.UseQuota(domains.CreateAdminToken("admin"))
+
+	// then
+	assert.Equal(t, admin, true)
+}
+
+func createRateLimitService(dailyQuota int64) (*RateLimitService, *time.Time) {
+	repo := testrepository.NewCleanTestRepositories()
+	cfg := &config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimit{Rate: 2, Burst: 4},
+		Groups: map[string]*config.RateLimit{
+			config.RateLimitGroupMerkleRoots: {Rate: 1, Burst: 1},
+		},
+		VerifyBatchSize: 10,
+		DailyQuota:      dailyQuota,
+	}
+	log := zerolog.Nop()
+	limits := NewRateLimitService(repo.ToDomainRepo(), cfg, &log)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	limits.now = func() time.Time { return now }
+	return limits, &now
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+// RecoveryService recreates header events missed by clients from the headers stored in the database.
+type RecoveryService struct {
+	repo  *repository.Repositories
+	limit int
+	log   *zerolog.Logger
+}
+
+// NewRecoveryService creates and returns RecoveryService instance.
+func NewRecoveryService(repo *repository.Repositories, cfg *config.WebsocketConfig, log *zerolog.Logger) *RecoveryService {
+	recoveryLogger := log.With().Str("service", "recovery").Logger()
+	s := &RecoveryService{
+		repo: repo,
+		log:  &recoveryLogger,
+	}
+	if cfg != nil {
+		s.limit = cfg.RecoveryMax
+	}
+	return s
+}
+
+// MissedEvents returns events missed by a client since given position, up to the configured limit.
+// When the header known by client is no longer part of the longest chain, the first returned event is a reorg.
+// When the events can't be recreated, no events are returned and the client is asked to resync.
+func (s *RecoveryService) MissedEvents(position domains.ChainPosition) (*domains.MissedEvents, error) {
+	limit := s.limit
+
+	tip, err := s.repo.Headers.GetTip()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*domains.HeaderEvent, 0)
+	from := position.Height + 1
+
+	if position.Hash != "" {
+		stale, err := s.staleBranchOf(position.Hash, limit)
+		if err != nil {
+			s.log.Debug().Msgf("Cannot recover events since %s: %v", position.Hash, err)
+			return &domains.MissedEvents{Events: events, Resync: true}, nil
+		}
+		if len(stale) > 0 {
+			forkHeight := stale[len(stale)-1].Height
+			events = append(events, domains.ChainReorganized(tip, forkHeight, stale.hashStrings()))
+			from = forkHeight
+		}
+	}
+
+	to := tip.Height
+	if limit > 0 && to-from+1 > int32(limit-len(events)) {
+		to = from + int32(limit-len(events)) - 1
+	}
+
+	if from <= to {
+		headers, err := s.repo.Headers.GetHeadersByHeightRange(int(from), int(to))
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(headers, func(i, j int) bool { return headers[i].Height < headers[j].Height })
+		for _, h := range headers {
+			if h.IsLongestChain() {
+				events = append(events, domains.HeaderAdded(h))
+			}
+		}
+	}
+
+	return &domains.MissedEvents{
+		Events:   events,
+		Complete: to >= tip.Height,
+	}, nil
+}
+
+// staleBranchOf returns headers from the one with given hash down to the fork with the longest chain,
+// or nothing when the header is part of the longest chain. It fails when the header is unknown,
+// its branch can't be followed to the longest chain or it is longer than the limit.
+func (s *RecoveryService) staleBranchOf(hash string, limit int) (chain, error) {
+	branch := make(chain, 0)
+	for limit <= 0 || len(branch) < limit {
+		h, err := s.repo.Headers.GetHeaderByHash(hash)
+		if err != nil {
+			return nil, fmt.Errorf("cannot follow branch of header %s: %w", hash, err)
+		}
+		if h == nil {
+			return nil, fmt.Errorf("header %s not found", hash)
+		}
+		if h.IsLongestChain() {
+			return branch, nil
+		}
+		if h.State != domains.Stale {
+			return nil, fmt.Errorf("header %s is %s", hash, h.State)
+		}
+		branch = append(branch, h)
+		hash = h.PreviousBlock.String()
+	}
+	return nil, fmt.Errorf("stale branch is longer than %d headers", limit)
+}
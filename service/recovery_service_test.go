@@ -0,0 +1,110 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/rs/zerolog"
+)
+
+func TestMissedEventsSinceLongestChainHeader(t *testing.T) {
+	// given
+	recovery := createRecoveryService()
+	position := domains.ChainPosition{Height: 1, Hash: fixtures.HashHeight1.String()}
+
+	// when
+	missed, err := recovery.MissedEvents(position)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, missed.Complete, true)
+	assert.Equal(t, len(missed.Events), 3)
+	for i, e := range missed.Events {
+		assert.Equal(t, e.Operation, domains.EventHeaderAdded)
+		assert.Equal(t, e.Header.Height, int32(i+2))
+		assert.Equal(t, e.Header.State, domains.LongestChain)
+	}
+}
+
+func TestMissedEventsLimit(t *testing.T) {
+	// given
+	recovery := createRecoveryService()
+	recovery.limit = 2
+	position := domains.ChainPosition{Height: 1}
+
+	// when
+	missed, err := recovery.MissedEvents(position)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, missed.Complete, false)
+	assert.Equal(t, len(missed.Events), 2)
+	assert.Equal(t, missed.Events[1].Header.Height, int32(3))
+}
+
+func TestMissedEventsSinceStaleHeaderStartWithReorg(t *testing.T) {
+	// given
+	recovery := createRecoveryService()
+	position := domains.ChainPosition{Height: 4, Hash: fixtures.StaleHashHeight4.String()}
+
+	// when
+	missed, err := recovery.MissedEvents(position)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, missed.Complete, true)
+	assert.Equal(t, len(missed.Events), 5)
+
+	reorg := missed.Events[0]
+	assert.Equal(t, reorg.Operation, domains.EventChainReorganized)
+	assert.Equal(t, reorg.Header.Hash, fixtures.HashHeight4.String())
+	assert.Equal(t, reorg.Reorg.ForkHeight, int32(1))
+	assert.Equal(t, len(reorg.Reorg.StaleHeaders), 4)
+	assert.Equal(t, reorg.Reorg.StaleHeaders[0], fixtures.StaleHashHeight4.String())
+
+	for i, e := range missed.Events[1:] {
+		assert.Equal(t, e.Operation, domains.EventHeaderAdded)
+		assert.Equal(t, e.Header.Height, int32(i+1))
+		assert.Equal(t, e.Header.State, domains.LongestChain)
+	}
+}
+
+func TestMissedEventsSinceUnknownHeaderRequireResync(t *testing.T) {
+	// given
+	recovery := createRecoveryService()
+	position := domains.ChainPosition{Height: 1, Hash: "0000000000000000000000000000000000000000000000000000000000000001"}
+
+	// when
+	missed, err := recovery.MissedEvents(position)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, missed.Resync, true)
+	assert.Equal(t, missed.Complete, false)
+	assert.Equal(t, len(missed.Events), 0)
+}
+
+func TestMissedEventsSinceStaleBranchLongerThanLimitRequireResync(t *testing.T) {
+	// given
+	recovery := createRecoveryService()
+	recovery.limit = 2
+	position := domains.ChainPosition{Height: 4, Hash: fixtures.StaleHashHeight4.String()}
+
+	// when
+	missed, err := recovery.MissedEvents(position)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, missed.Resync, true)
+	assert.Equal(t, len(missed.Events), 0)
+}
+
+func createRecoveryService() *RecoveryService {
+	log := zerolog.Nop()
+	r, _ := givenLongestChainInRepository()
+	givenStaleChainInRepository(&r)
+	return NewRecoveryService(&r, &config.WebsocketConfig{RecoveryMax: 10}, &log)
+}
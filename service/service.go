@@ -3,6 +3,7 @@ package service
 import (
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 	"github.com/bitcoin-sv/block-headers-service/internal/wire"
 	"github.com/bitcoin-sv/block-headers-service/notification"
@@ -10,6 +11,7 @@ import (
 	"github.com/bitcoin-sv/block-headers-service/transports/http/client"
 	peerpkg "github.com/bitcoin-sv/block-headers-service/transports/p2p/peer"
 	"github.com/rs/zerolog"
+	"sync"
 )
 
 // Network is an interface which represents methods required for Network service.
@@ -43,28 +45,61 @@ type Merkleroots interface {
 	GetMerkleRootsConfirmations(request []domains.MerkleRootConfirmationRequestItem) ([]*domains.MerkleRootConfirmation, error)
 }
 
+// MerkleRootWatches is an interface which represents methods required for MerkleRootWatches service.
+type MerkleRootWatches interface {
+	Watch(owner string, items []domains.MerkleRootConfirmationRequestItem) ([]*domains.MerkleRootWatch, error)
+	Unwatch(owner, merkleRoot string, blockHeight int32) error
+	GetWatches(owner string) ([]*domains.MerkleRootWatch, error)
+}
+
 // Chains is an interface which represents methods exposed by Chains Service.
 type Chains interface {
 	Add(domains.BlockHeaderSource) (*domains.BlockHeader, error)
 }
 
+// Confirmations is an interface which represents methods required for Confirmations service.
+type Confirmations interface {
+	Depth() int32
+	Watch(owner string, hashes []string) error
+	Unwatch(owner, hash string) error
+	Watched(owner string) []string
+}
+
+// Recovery is an interface which represents methods required for Recovery service.
+type Recovery interface {
+	MissedEvents(position domains.ChainPosition) (*domains.MissedEvents, error)
+}
+
 // Tokens is an interface which represents methods required for Tokens service.
 type Tokens interface {
-	GenerateToken() (*domains.Token, error)
+	GenerateToken(actor domains.AuditActor, opts domains.TokenOptions) (*domains.Token, error)
 	GetToken(token string) (*domains.Token, error)
-	DeleteToken(token string) error
+	GetTokens() ([]*domains.Token, error)
+	RotateToken(actor domains.AuditActor, id string) (*domains.Token, error)
+	DeleteToken(actor domains.AuditActor, token string) error
+	DeleteTokenByID(actor domains.AuditActor, id string) error
 }
 
 // Services represents all services in app and provide access to them.
 type Services struct {
-	Network     Network
-	Headers     Headers
-	Merkleroots Merkleroots
-	Chains      Chains
-	Tokens      Tokens
-	Notifier    *notification.Notifier
-	Webhooks    *notification.WebhooksService
-	Logger      *zerolog.Logger
+	Network           Network
+	Headers           Headers
+	Merkleroots       Merkleroots
+	Chains            Chains
+	Confirmations     Confirmations
+	Recovery          Recovery
+	Tokens            Tokens
+	RateLimits        *RateLimitService
+	ClientCerts       *ClientCertService
+	Audit             *AuditService
+	Integrity         *IntegrityService
+	Prune             *PruneService
+	Notifier          *notification.Notifier
+	Webhooks          *notification.WebhooksService
+	EventStream       *notification.EventStream
+	EventEncoder      *notification.EventEncoder
+	MerkleRootWatches *MerkleRootWatchService
+	Logger            *zerolog.Logger
 }
 
 // Dept is a struct used to create Services.
@@ -74,43 +109,118 @@ type Dept struct {
 	AdminToken   string
 	Logger       *zerolog.Logger
 	Config       *config.AppConfig
+	// Checkpoints and TimeSource tell if headers are synchronized with the network,
+	// config.Checkpoints and config.TimeSource are used when they are nil, empty Checkpoints disable checkpoints.
+	Checkpoints []chaincfg.Checkpoint
+	TimeSource  config.MedianTimeSource
 }
 
 // NewServices creates and returns Services instance.
 func NewServices(d Dept) *Services {
-	notifier := newNotifier()
+	headers := NewHeaderService(d.Repositories, d.Config.P2P, d.Logger)
+	headers.checkpoints, headers.timeSource = syncSources(d)
+	notifier := newNotifier(d, headers)
+	confirmations := NewConfirmationsService(d.Config.Notification, d.Repositories.ConfirmationWatches, d.Logger)
+	encoder := newEventEncoder(d)
+	audit := NewAuditService(d.Repositories, d.Logger)
+	chainLock := &sync.Mutex{}
 
 	return &Services{
-		Network:     NewNetworkService(d.Peers),
-		Headers:     NewHeaderService(d.Repositories, d.Config.P2P, d.Logger),
-		Merkleroots: NewMerklerootsService(d.Repositories, d.Config.MerkleRoot, d.Logger),
-		Notifier:    notifier,
-		Chains:      newChainService(d, notifier),
-		Tokens:      NewTokenService(d.Repositories, d.AdminToken),
-		Webhooks:    newWebhooks(d),
-		Logger:      d.Logger,
+		Network:           NewNetworkService(d.Peers),
+		Headers:           headers,
+		Merkleroots:       NewMerklerootsService(d.Repositories, d.Config.MerkleRoot, d.Logger),
+		MerkleRootWatches: NewMerkleRootWatchService(d.Repositories, d.Config.MerkleRoot, d.Config.Notification, notifier, d.Logger),
+		Notifier:          notifier,
+		Chains:            newChainService(d, notifier, confirmations, chainLock),
+		Confirmations:     confirmations,
+		Recovery:          NewRecoveryService(d.Repositories, d.Config.Websocket, d.Logger),
+		Tokens:            NewTokenService(d.Repositories, d.AdminToken, NewJWTService(jwtConfig(d), d.Logger), audit, confirmations, d.Logger),
+		RateLimits:        NewRateLimitService(d.Repositories, rateLimitConfig(d), d.Logger),
+		ClientCerts:       NewClientCertService(d.Repositories, tlsConfig(d), d.Logger),
+		Audit:             audit,
+		Integrity:         NewIntegrityService(d.Repositories, d.Logger),
+		Prune:             NewPruneService(d.Repositories, d.Config.Prune, audit, chainLock, d.Logger),
+		Webhooks:          newWebhooks(d, encoder, audit),
+		EventStream:       notification.NewEventStream(d.Logger),
+		EventEncoder:      encoder,
+		Logger:            d.Logger,
+	}
+}
+
+func rateLimitConfig(d Dept) *config.RateLimitConfig {
+	if d.Config.HTTP == nil {
+		return nil
+	}
+	return d.Config.HTTP.RateLimit
+}
+
+func jwtConfig(d Dept) *config.JWTConfig {
+	if d.Config.HTTP == nil {
+		return nil
 	}
+	return d.Config.HTTP.JWT
 }
 
-func newChainService(d Dept, notifier *notification.Notifier) Chains {
-	return NewChainsService(
+func tlsConfig(d Dept) *config.TLSConfig {
+	if d.Config.HTTP == nil {
+		return nil
+	}
+	return d.Config.HTTP.TLS
+}
+
+func newChainService(d Dept, notifier *notification.Notifier, confirmations *ConfirmationsService, lock sync.Locker) Chains {
+	checkpoints, timeSource := syncSources(d)
+	return newChainsService(
 		d.Repositories,
 		d.Config.P2P.GetNetParams(),
 		d.Logger,
 		DefaultBlockHasher(),
 		notifier,
+		confirmations,
+		lock,
+		checkpoints,
+		timeSource,
 	)
 }
 
-func newWebhooks(d Dept) *notification.WebhooksService {
+func syncSources(d Dept) ([]chaincfg.Checkpoint, config.MedianTimeSource) {
+	checkpoints, timeSource := d.Checkpoints, d.TimeSource
+	if checkpoints == nil {
+		checkpoints = config.Checkpoints
+	}
+	if timeSource == nil {
+		timeSource = config.TimeSource
+	}
+	return checkpoints, timeSource
+}
+
+func newWebhooks(d Dept, encoder *notification.EventEncoder, audit *AuditService) *notification.WebhooksService {
+	cfg := d.Config.Webhook
+	if cfg == nil {
+		cfg = &config.WebhookConfig{}
+	}
+	policy := notification.NewWebhookPolicy(cfg.Policy)
+
 	return notification.NewWebhooksService(
 		d.Repositories.Webhooks,
-		client.NewWebhookTargetClient(),
+		client.NewWebhookTargetClient(policy, cfg.ConnectTimeout, cfg.ResponseTimeout),
+		policy,
+		encoder,
+		audit,
 		d.Logger,
-		d.Config.Webhook,
+		cfg,
 	)
 }
 
-func newNotifier() *notification.Notifier {
-	return notification.NewNotifier()
+// newEventEncoder creates encoder of events identifying this instance by its name.
+func newEventEncoder(d Dept) *notification.EventEncoder {
+	source := ""
+	if d.Config.Logging != nil {
+		source = d.Config.Logging.InstanceName
+	}
+	return notification.NewEventEncoder(source)
+}
+
+func newNotifier(d Dept, headers Headers) *notification.Notifier {
+	return notification.NewNotifier(d.Config.Notification, headers, d.Logger)
 }
@@ -1,49 +1,130 @@
 package service
 
 import (
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/repository"
 	"github.com/dchest/uniuri"
+	"github.com/rs/zerolog"
 )
 
+// lastUsedResolution limits how often the last usage of a token is saved, so not every request writes to db.
+const lastUsedResolution = time.Minute
+
+const tokenLength = 32
+
 // TokenService represents Token service and provide access to repositories.
 type TokenService struct {
 	repo       *repository.Repositories
 	adminToken string
+	jwt        *JWTService
+	audit      *AuditService
+	// confirmations has headers watched by tokens, which are transferred to rotated tokens.
+	confirmations *ConfirmationsService
+	log           *zerolog.Logger
 }
 
-// NewTokenService creates and returns TokenService instance.
-func NewTokenService(repo *repository.Repositories, adminToken string) *TokenService {
+// NewTokenService creates and returns TokenService instance, JWTs are accepted when jwt service is enabled.
+func NewTokenService(repo *repository.Repositories, adminToken string, jwt *JWTService, audit *AuditService, confirmations *ConfirmationsService, log *zerolog.Logger) *TokenService {
+	tokenLogger := log.With().Str("service", "tokens").Logger()
 	return &TokenService{
-		repo:       repo,
-		adminToken: adminToken,
+		repo:          repo,
+		adminToken:    adminToken,
+		jwt:           jwt,
+		audit:         audit,
+		confirmations: confirmations,
+		log:           &tokenLogger,
 	}
 }
 
 // GenerateToken generates and save new token.
-func (s *TokenService) GenerateToken() (*domains.Token, error) {
-	tValue := uniuri.NewLen(32)
-	token := domains.CreateToken(tValue)
+func (s *TokenService) GenerateToken(actor domains.AuditActor, opts domains.TokenOptions) (*domains.Token, error) {
+	if opts.ExpiresAt != nil && !opts.ExpiresAt.After(time.Now()) {
+		s.audit.Record(actor, domains.AuditTokenCreate, opts.Name, bhserrors.ErrInvalidTokenExpiry)
+		return nil, bhserrors.ErrInvalidTokenExpiry
+	}
+	token := domains.CreateToken(uniuri.NewLen(tokenLength), opts)
 	err := s.repo.Tokens.AddTokenToDatabase(token)
+	s.audit.Record(actor, domains.AuditTokenCreate, token.ID, err)
 	if err != nil {
 		return nil, err
 	}
 	return token, nil
 }
 
-// GetToken returns token by given value.
+// GetToken returns token by given value and records its usage, or verifies the value if it's a JWT.
+// Expired tokens are returned as well, so callers should check expiration.
 func (s *TokenService) GetToken(token string) (*domains.Token, error) {
 	if token == s.adminToken {
 		return domains.CreateAdminToken(token), nil
 	}
-	t, err := s.repo.Tokens.GetTokenByValue(token)
+	if s.jwt.Enabled() && IsJWT(token) {
+		t, err := s.jwt.Verify(token)
+		if err != nil {
+			s.log.Debug().Msgf("Rejected jwt: %v", err)
+			return nil, bhserrors.ErrInvalidAccessToken.Wrap(err)
+		}
+		return t, nil
+	}
+	t, err := s.repo.Tokens.GetTokenByHash(domains.HashToken(token))
 	if err != nil {
 		return nil, err
 	}
+	t.Token = token
+
+	now := time.Now()
+	if !t.IsExpired(now) && (t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) >= lastUsedResolution) {
+		if err := s.repo.Tokens.UpdateTokenLastUsed(t.ID, now); err != nil {
+			s.log.Warn().Msgf("Cannot save last usage of token %s: %v", t.Prefix, err)
+		} else {
+			t.LastUsedAt = &now
+		}
+	}
 	return t, nil
 }
 
-// DeleteToken deletes token from db.
-func (s *TokenService) DeleteToken(token string) error {
-	return s.repo.Tokens.DeleteToken(token)
+// GetTokens returns all tokens without their values.
+func (s *TokenService) GetTokens() ([]*domains.Token, error) {
+	return s.repo.Tokens.GetTokens()
+}
+
+// RotateToken replaces token with given id with a new token with the same name, owner, scopes and expiration time.
+// Expired tokens cannot be rotated.
+func (s *TokenService) RotateToken(actor domains.AuditActor, id string) (*domains.Token, error) {
+	t, err := s.repo.Tokens.GetTokenByHash(id)
+	if err != nil {
+		s.audit.Record(actor, domains.AuditTokenRotate, id, err)
+		return nil, bhserrors.ErrTokenNotFound.Wrap(err)
+	}
+	if t.IsExpired(time.Now()) {
+		s.audit.Record(actor, domains.AuditTokenRotate, id, bhserrors.ErrRotateExpiredToken)
+		return nil, bhserrors.ErrRotateExpiredToken
+	}
+	rotated := t.Rotate(uniuri.NewLen(tokenLength))
+	err = s.repo.Tokens.RotateToken(id, rotated)
+	s.audit.Record(actor, domains.AuditTokenRotate, id, err)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.MerkleRootWatches.TransferMerkleRootWatches(id, rotated.ID); err != nil {
+		s.log.Error().Msgf("Cannot transfer merkle root watches of rotated token %s. %v", id, err)
+	}
+	if err := s.confirmations.Transfer(id, rotated.ID); err != nil {
+		s.log.Error().Msgf("Cannot transfer headers watched by rotated token %s. %v", id, err)
+	}
+	return rotated, nil
+}
+
+// DeleteToken deletes token with given value from db.
+func (s *TokenService) DeleteToken(actor domains.AuditActor, token string) error {
+	return s.DeleteTokenByID(actor, domains.HashToken(token))
+}
+
+// DeleteTokenByID deletes token with given id from db.
+func (s *TokenService) DeleteTokenByID(actor domains.AuditActor, id string) error {
+	err := s.repo.Tokens.DeleteToken(id)
+	s.audit.Record(actor, domains.AuditTokenDelete, id, err)
+	return err
 }
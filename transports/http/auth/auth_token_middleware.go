@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/config"
@@ -12,32 +14,32 @@ import (
 
 const (
 	authorizationHeader = "Authorization"
+	acceptHeader        = "Accept"
+	eventStreamMIMEType = "text/event-stream"
+	tokenQueryParam     = "token"
 )
 
-// TokenMiddleware middleware that is retrieving token from Authorization header.
+// TokenMiddleware middleware that is retrieving token from Authorization header,
+// or from the mapping of the client certificate when the request has no Authorization header.
 type TokenMiddleware struct {
-	tokens service.Tokens
-	cfg    *config.HTTPConfig
+	tokens      service.Tokens
+	clientCerts *service.ClientCertService
+	cfg         *config.HTTPConfig
 }
 
 // NewMiddleware create Token middleware that is retrieving token from Authorization header.
 func NewMiddleware(s *service.Services, cfg *config.HTTPConfig) *TokenMiddleware {
 	return &TokenMiddleware{
-		tokens: s.Tokens,
-		cfg:    cfg,
+		tokens:      s.Tokens,
+		clientCerts: s.ClientCerts,
+		cfg:         cfg,
 	}
 }
 
 // ApplyToAPI is a middleware which checks if the request has a valid token.
 func (h *TokenMiddleware) ApplyToAPI(c *gin.Context) {
 	if h.cfg.UseAuth {
-		rawToken, err := h.parseAuthHeader(c)
-		if err != nil {
-			bhserrors.AbortWithErrorResponse(c, err, nil)
-			return
-		}
-
-		token, err := h.getToken(rawToken)
+		token, err := h.authenticate(c)
 		if err != nil {
 			bhserrors.AbortWithErrorResponse(c, err, nil)
 			return
@@ -47,9 +49,39 @@ func (h *TokenMiddleware) ApplyToAPI(c *gin.Context) {
 	}
 }
 
+func (h *TokenMiddleware) authenticate(c *gin.Context) (*domains.Token, error) {
+	rawToken, err := h.parseAuthHeader(c)
+	if errors.Is(err, bhserrors.ErrMissingAuthHeader) {
+		return h.getClientCertToken(c)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return h.getToken(rawToken)
+}
+
+// getClientCertToken returns token of the verified client certificate, a mapped certificate counts as authentication.
+func (h *TokenMiddleware) getClientCertToken(c *gin.Context) (*domains.Token, error) {
+	t, err := h.clientCerts.Authenticate(c.Request.TLS)
+	if err != nil {
+		return nil, bhserrors.ErrInvalidAccessToken
+	}
+	if t == nil {
+		return nil, bhserrors.ErrMissingAuthHeader
+	}
+	if t.IsExpired(time.Now()) {
+		return nil, bhserrors.ErrTokenExpired
+	}
+	return t, nil
+}
+
 func (h *TokenMiddleware) parseAuthHeader(c *gin.Context) (string, error) {
 	header := c.GetHeader(authorizationHeader)
 	if header == "" {
+		// browsers' EventSource cannot set headers, so event streams accept token also as a query param
+		if token := c.Query(tokenQueryParam); token != "" && c.GetHeader(acceptHeader) == eventStreamMIMEType {
+			return token, nil
+		}
 		return "", bhserrors.ErrMissingAuthHeader
 	}
 
@@ -66,5 +98,8 @@ func (h *TokenMiddleware) getToken(token string) (*domains.Token, error) {
 	if err != nil {
 		return nil, bhserrors.ErrInvalidAccessToken
 	}
+	if t.IsExpired(time.Now()) {
+		return nil, bhserrors.ErrTokenExpired
+	}
 	return t, nil
 }
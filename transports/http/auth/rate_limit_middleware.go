@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/metrics"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	retryAfterHeader = "Retry-After"
+	rateLimitKey     = "rateLimit"
+)
+
+// RateLimitMiddleware middleware that is limiting requests of every token, or every IP address when auth is disabled.
+// It has to be applied after TokenMiddleware, the limits are charged by RateLimitGroup of the route group.
+type RateLimitMiddleware struct {
+	limits *service.RateLimitService
+}
+
+// IPRateLimitMiddleware middleware that is limiting requests of every IP address before they are authorized,
+// so clients sending invalid tokens are limited as well.
+type IPRateLimitMiddleware struct {
+	limits *service.RateLimitService
+}
+
+// rateLimit is the limit applied to the request, kept to charge requests which cost more than one.
+type rateLimit struct {
+	limits *service.RateLimitService
+	token  *domains.Token
+	client string
+	group  string
+}
+
+// NewRateLimitMiddleware create middleware that is limiting requests.
+func NewRateLimitMiddleware(s *service.Services) *RateLimitMiddleware {
+	return &RateLimitMiddleware{limits: s.RateLimits}
+}
+
+// NewIPRateLimitMiddleware create middleware that is limiting requests of IP addresses before authorization.
+func NewIPRateLimitMiddleware(s *service.Services) *IPRateLimitMiddleware {
+	return &IPRateLimitMiddleware{limits: s.RateLimits}
+}
+
+// ApplyToAPI is a middleware which checks if the IP address of the client didn't exceed its rate limit.
+func (m *IPRateLimitMiddleware) ApplyToAPI(c *gin.Context) {
+	if !m.limits.Enabled() {
+		return
+	}
+	limit := &rateLimit{limits: m.limits, client: service.RateLimitClient(nil, c.ClientIP()), group: config.RateLimitGroupIP}
+	limit.charge(c, 1)
+}
+
+// ApplyToAPI is a middleware which identifies the client whose requests are limited by RateLimitGroup.
+func (m *RateLimitMiddleware) ApplyToAPI(c *gin.Context) {
+	if !m.limits.Enabled() {
+		return
+	}
+	token := requestToken(c)
+	c.Set(rateLimitKey, &rateLimit{limits: m.limits, token: token, client: service.RateLimitClient(token, c.ClientIP())})
+}
+
+// RateLimitGroup returns a middleware checking if the client didn't exceed rate limit of the group and daily quota of its token.
+// It has to be applied to every group of API routes, requests are not limited without it.
+func RateLimitGroup(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, ok := requestRateLimit(c)
+		if !ok {
+			return
+		}
+		limit.group = group
+		if !limit.charge(c, 1) {
+			return
+		}
+		if ok, retryAfter := limit.limits.UseQuota(limit.token); !ok {
+			throttle(c, limit.group, "quota", retryAfter, bhserrors.ErrQuotaExceeded)
+		}
+	}
+}
+
+// ChargeRequest charges the request for additional cost, ex. for the size of requested batch.
+// It aborts the request and returns false when the client exceeded the rate limit.
+func ChargeRequest(c *gin.Context, cost int) bool {
+	limit, ok := requestRateLimit(c)
+	if !ok || cost <= 0 || limit.group == "" {
+		return true
+	}
+	return limit.charge(c, cost)
+}
+
+func requestRateLimit(c *gin.Context) (*rateLimit, bool) {
+	if value, exists := c.Get(rateLimitKey); exists {
+		limit, ok := value.(*rateLimit)
+		return limit, ok
+	}
+	return nil, false
+}
+
+func (l *rateLimit) charge(c *gin.Context, cost int) bool {
+	if ok, retryAfter := l.limits.Allow(l.client, l.group, cost); !ok {
+		throttle(c, l.group, "rate", retryAfter, bhserrors.ErrRateLimited)
+		return false
+	}
+	return true
+}
+
+func throttle(c *gin.Context, group, reason string, retryAfter time.Duration, err error) {
+	metrics.AddThrottledRequest("http", group, reason)
+	seconds := max(1, int(math.Ceil(retryAfter.Seconds())))
+	c.Header(retryAfterHeader, strconv.Itoa(seconds))
+	bhserrors.AbortWithErrorResponse(c, err, nil)
+}
+
+func requestToken(c *gin.Context) *domains.Token {
+	if value, exists := c.Get("token"); exists {
+		if t, ok := value.(*domains.Token); ok {
+			return t
+		}
+	}
+	return nil
+}
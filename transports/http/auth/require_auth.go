@@ -6,33 +6,40 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// RequireAdmin adds wrapper to endpoint handler
-// that will check if the endpoint was called with admin token.
+// RequireScope returns middleware that will check if the endpoint was called with token granted the scope.
 // This verification will be skipped if authentication isn't enabled.
-func RequireAdmin(handler gin.HandlerFunc, requireAdmin bool) gin.HandlerFunc {
-	if requireAdmin {
-		return func(c *gin.Context) {
-			if err := validateToken(c); err == nil {
-				handler(c)
-			} else {
-				bhserrors.AbortWithErrorResponse(c, err, nil)
-			}
+func RequireScope(scope domains.Scope, useAuth bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !useAuth {
+			return
+		}
+		if err := CheckScope(c, scope); err != nil {
+			bhserrors.AbortWithErrorResponse(c, err, nil)
 		}
 	}
-	return handler
 }
 
-func validateToken(c *gin.Context) error {
+// CheckScope checks if the token of the request was granted the scope.
+func CheckScope(c *gin.Context, scope domains.Scope) error {
 	token, exist := c.Get("token")
 	if !exist {
-		return bhserrors.ErrAdminTokenNotFound
+		return bhserrors.ErrUnauthorized
 	}
 	t, ok := token.(*domains.Token)
 	if !ok {
 		return bhserrors.ErrGeneric
 	}
-	if !t.IsAdmin {
-		return bhserrors.ErrUnauthorized
+	if !t.HasScope(scope) {
+		return bhserrors.ErrMissingScope
+	}
+	return nil // the token has the scope
+}
+
+// Actor returns who makes the request, as recorded in the audit log.
+func Actor(c *gin.Context) domains.AuditActor {
+	actor := domains.AuditActor{IP: c.ClientIP()}
+	if t := requestToken(c); t != nil {
+		actor.TokenID = t.ActorID()
 	}
-	return nil // the token is valid
+	return actor
 }
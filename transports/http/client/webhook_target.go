@@ -4,23 +4,53 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/notification"
 )
 
-type webhookTargetClientFunc func(headers map[string]string, method string, url string, body any) (*http.Response, error)
+// maxRedirects is the number of redirects followed by a webhook request.
+const maxRedirects = 10
 
-func (f webhookTargetClientFunc) Call(headers map[string]string, method string, url string, body any) (*http.Response, error) {
-	return f(headers, method, url, body)
+type webhookTargetClient struct {
+	client *http.Client
 }
 
-// NewWebhookTargetClient returns a new WebhookTargetClient.
-func NewWebhookTargetClient() notification.WebhookTargetClient {
-	return webhookTargetClientFunc(callRequest)
+// NewWebhookTargetClient returns a new WebhookTargetClient connecting only to addresses allowed by the policy.
+// Connecting to a webhook is limited by connectTimeout and waiting for its response by responseTimeout, zero means no limit.
+func NewWebhookTargetClient(policy *notification.WebhookPolicy, connectTimeout, responseTimeout time.Duration) notification.WebhookTargetClient {
+	dialer := &net.Dialer{Timeout: connectTimeout, Control: policy.Control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// proxy would connect to the webhook instead of us, so the policy couldn't check the address
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = connectTimeout
+	transport.ResponseHeaderTimeout = responseTimeout
+
+	timeout := time.Duration(0)
+	if connectTimeout > 0 && responseTimeout > 0 {
+		// limits also reading of the response body, which is not covered by the transport timeouts
+		timeout = 2*connectTimeout + responseTimeout
+	}
+
+	return &webhookTargetClient{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return errors.New("stopped after too many redirects")
+				}
+				return policy.CheckTarget(req.URL)
+			},
+		},
+	}
 }
 
-func callRequest(headers map[string]string, method string, url string, body any) (*http.Response, error) {
+func (c *webhookTargetClient) Call(headers map[string]string, method string, url string, body any) (*http.Response, error) {
 	bBytes, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
@@ -36,8 +66,7 @@ func callRequest(headers map[string]string, method string, url string, body any)
 		req.Header.Add(header, value)
 	}
 
-	client := &http.Client{}
-	res, err := client.Do(req)
+	res, err := c.client.Do(req)
 
 	if err != nil {
 		return nil, err
@@ -1,16 +1,24 @@
 package access_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/testapp"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testjwt"
 )
 
 const EmptyToken = ""
@@ -137,6 +145,489 @@ func TestDeleteTokenEndpoint(t *testing.T) {
 	}
 }
 
+// Tests the POST /access endpoint with requested scopes.
+func TestCreateScopedToken(t *testing.T) {
+	// setup
+	cfg := config.GetDefaultAppConfig()
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(createScopedToken(cfg.HTTP.AuthToken, `{"scopes":["merkleroots:verify"]}`))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	token := tokenFromResponse(t, res)
+	assert.Equal(t, len(token.Scopes), 1)
+	assert.Equal(t, token.Scopes[0], domains.ScopeMerkleRootsVerify)
+
+	// when
+	res = bhs.API().Call(getTokenInfo(token.Token))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	assert.Equal(t, tokenFromResponse(t, res).Scopes[0], domains.ScopeMerkleRootsVerify)
+}
+
+// Tests the POST /access endpoint without requested scopes grants default scopes.
+func TestCreateTokenWithDefaultScopes(t *testing.T) {
+	// setup
+	cfg := config.GetDefaultAppConfig()
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(createToken(cfg.HTTP.AuthToken))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	token := tokenFromResponse(t, res)
+	assert.Equal(t, len(token.Scopes), len(domains.DefaultScopes))
+	for _, scope := range token.Scopes {
+		if scope == domains.ScopeTokensAdmin {
+			t.Fatalf("Expected token without %s scope", domains.ScopeTokensAdmin)
+		}
+	}
+}
+
+// Tests the POST /access endpoint with unknown scope.
+func TestCreateTokenWithUnknownScope(t *testing.T) {
+	// setup
+	cfg := config.GetDefaultAppConfig()
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(createScopedToken(cfg.HTTP.AuthToken, `{"scopes":["headers:write"]}`))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusBadRequest)
+}
+
+// Tests that endpoints reject tokens without the required scope.
+func TestEndpointsRequireScope(t *testing.T) {
+	tests := map[string]struct {
+		scopes        string
+		method        string
+		url           string
+		expectAllowed bool
+	}{
+		"merkle root verification with merkleroots:verify scope": {
+			scopes:        `["merkleroots:verify"]`,
+			method:        http.MethodGet,
+			url:           "/api/v1/chain/merkleroot",
+			expectAllowed: true,
+		},
+		"webhook registration with merkleroots:verify scope": {
+			scopes:        `["merkleroots:verify"]`,
+			method:        http.MethodPost,
+			url:           "/api/v1/webhook",
+			expectAllowed: false,
+		},
+		"headers with merkleroots:verify scope": {
+			scopes:        `["merkleroots:verify"]`,
+			method:        http.MethodGet,
+			url:           "/api/v1/chain/tip",
+			expectAllowed: false,
+		},
+		"peers with headers:read scope": {
+			scopes:        `["headers:read"]`,
+			method:        http.MethodGet,
+			url:           "/api/v1/network/peer/count",
+			expectAllowed: false,
+		},
+		"token creation with default scopes": {
+			scopes:        `[]`,
+			method:        http.MethodPost,
+			url:           "/api/v1/access",
+			expectAllowed: false,
+		},
+		"token creation with tokens:admin scope": {
+			scopes:        `["tokens:admin"]`,
+			method:        http.MethodPost,
+			url:           "/api/v1/access",
+			expectAllowed: true,
+		},
+	}
+
+	for name, params := range tests {
+		t.Run(name, func(t *testing.T) {
+			// setup
+			cfg := config.GetDefaultAppConfig()
+			bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+			defer cleanup()
+
+			// given
+			res := bhs.API().Call(createScopedToken(cfg.HTTP.AuthToken, `{"scopes":`+params.scopes+`}`))
+			assert.Equal(t, res.Code, http.StatusOK)
+			token := tokenFromResponse(t, res)
+
+			// when
+			res = bhs.API().Call(authorizedRequest(token.Token, params.method, params.url))
+
+			// then
+			if params.expectAllowed && res.Code == http.StatusForbidden {
+				t.Fatalf("Expected request to be allowed but got status %d\n", res.Code)
+			}
+			if !params.expectAllowed && res.Code != http.StatusForbidden {
+				t.Fatalf("Expected to get status %d but instead got %d\n", http.StatusForbidden, res.Code)
+			}
+		})
+	}
+}
+
+// Tests that expired token is rejected.
+func TestExpiredToken(t *testing.T) {
+	// setup
+	expiresAt := time.Now().Add(-time.Minute)
+	expired := domains.CreateToken("expired_token_value", domains.TokenOptions{ExpiresAt: &expiresAt})
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithTokens(expired))
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(getTokenInfo("expired_token_value"))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusUnauthorized)
+	assert.Equal(t, strings.Contains(res.Body.String(), "ErrTokenExpired"), true)
+}
+
+// Tests the POST /access endpoint with name, owner and expiration time.
+func TestCreateTokenWithLifecycle(t *testing.T) {
+	// setup
+	cfg := config.GetDefaultAppConfig()
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	// when
+	res := bhs.API().Call(createScopedToken(cfg.HTTP.AuthToken, `{"name":"verifier","owner":"wallet team","expiresAt":"`+expiresAt+`"}`))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	token := tokenFromResponse(t, res)
+	assert.Equal(t, token.Name, "verifier")
+	assert.Equal(t, token.Owner, "wallet team")
+	assert.Equal(t, token.ExpiresAt.UTC().Format(time.RFC3339), expiresAt)
+
+	// when
+	res = bhs.API().Call(getTokenInfo(token.Token))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	if tokenFromResponse(t, res).LastUsedAt == nil {
+		t.Fatalf("Expected token to have last usage time")
+	}
+}
+
+// Tests the POST /access endpoint with expiration time in the past.
+func TestCreateExpiredToken(t *testing.T) {
+	// setup
+	cfg := config.GetDefaultAppConfig()
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+	expiresAt := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	// when
+	res := bhs.API().Call(createScopedToken(cfg.HTTP.AuthToken, `{"expiresAt":"`+expiresAt+`"}`))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusBadRequest)
+}
+
+// Tests the GET /access/tokens endpoint shows only prefixes of tokens.
+func TestListTokens(t *testing.T) {
+	// setup
+	cfg := config.GetDefaultAppConfig()
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	// given
+	res := bhs.API().Call(createScopedToken(cfg.HTTP.AuthToken, `{"name":"verifier"}`))
+	assert.Equal(t, res.Code, http.StatusOK)
+	created := tokenFromResponse(t, res)
+
+	// when
+	res = bhs.API().Call(authorizedRequest(cfg.HTTP.AuthToken, http.MethodGet, "/api/v1/access/tokens"))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	assert.Equal(t, strings.Contains(res.Body.String(), created.Token), false)
+
+	var tokens []domains.Token
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &tokens))
+	assert.Equal(t, len(tokens), 1)
+	assert.Equal(t, tokens[0].ID, created.ID)
+	assert.Equal(t, tokens[0].Prefix, created.Token[:domains.TokenPrefixLength])
+	assert.Equal(t, tokens[0].Name, "verifier")
+}
+
+// Tests the POST /access/tokens/{id}/rotate endpoint replaces the token.
+func TestRotateToken(t *testing.T) {
+	// setup
+	cfg := config.GetDefaultAppConfig()
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	// given
+	res := bhs.API().Call(createScopedToken(cfg.HTTP.AuthToken, `{"name":"verifier","scopes":["merkleroots:verify"]}`))
+	assert.Equal(t, res.Code, http.StatusOK)
+	created := tokenFromResponse(t, res)
+
+	// when
+	res = bhs.API().Call(authorizedRequest(cfg.HTTP.AuthToken, http.MethodPost, "/api/v1/access/tokens/"+created.ID+"/rotate"))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	rotated := tokenFromResponse(t, res)
+	assert.Equal(t, rotated.Name, "verifier")
+	assert.Equal(t, len(rotated.Scopes), 1)
+	assert.Equal(t, rotated.Scopes[0], domains.ScopeMerkleRootsVerify)
+
+	// and
+	assert.Equal(t, bhs.API().Call(getTokenInfo(created.Token)).Code, http.StatusUnauthorized)
+	assert.Equal(t, bhs.API().Call(getTokenInfo(rotated.Token)).Code, http.StatusOK)
+}
+
+// Tests the POST /access/tokens/{id}/rotate endpoint keeps the expiration time of the token.
+func TestRotateTokenKeepsExpiration(t *testing.T) {
+	// setup
+	cfg := config.GetDefaultAppConfig()
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	// given
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	res := bhs.API().Call(createScopedToken(cfg.HTTP.AuthToken, `{"expiresAt":"`+expiresAt+`"}`))
+	assert.Equal(t, res.Code, http.StatusOK)
+	created := tokenFromResponse(t, res)
+
+	// when
+	res = bhs.API().Call(authorizedRequest(cfg.HTTP.AuthToken, http.MethodPost, "/api/v1/access/tokens/"+created.ID+"/rotate"))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	rotated := tokenFromResponse(t, res)
+	assert.Equal(t, rotated.ExpiresAt.UTC().Format(time.RFC3339), expiresAt)
+}
+
+// Tests the POST /access/tokens/{id}/rotate endpoint refuses to rotate expired token.
+func TestRotateExpiredToken(t *testing.T) {
+	// setup
+	cfg := config.GetDefaultAppConfig()
+	expiresAt := time.Now().Add(-time.Minute)
+	expired := domains.CreateToken("expired_token_value", domains.TokenOptions{ExpiresAt: &expiresAt})
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithTokens(expired))
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(authorizedRequest(cfg.HTTP.AuthToken, http.MethodPost, "/api/v1/access/tokens/"+expired.ID+"/rotate"))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusBadRequest)
+	assert.Equal(t, strings.Contains(res.Body.String(), "ErrRotateExpiredToken"), true)
+	assert.Equal(t, bhs.API().Call(getTokenInfo("expired_token_value")).Code, http.StatusUnauthorized)
+}
+
+// Tests the DELETE /access/tokens/{id} endpoint revokes the token.
+func TestDeleteTokenByID(t *testing.T) {
+	// setup
+	cfg := config.GetDefaultAppConfig()
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	// given
+	res := bhs.API().Call(createToken(cfg.HTTP.AuthToken))
+	assert.Equal(t, res.Code, http.StatusOK)
+	created := tokenFromResponse(t, res)
+
+	// when
+	res = bhs.API().Call(authorizedRequest(cfg.HTTP.AuthToken, http.MethodDelete, "/api/v1/access/tokens/"+created.ID))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	assert.Equal(t, bhs.API().Call(getTokenInfo(created.Token)).Code, http.StatusUnauthorized)
+}
+
+// Tests that requests over the rate limit are rejected with Retry-After header.
+func TestRateLimitedRequests(t *testing.T) {
+	// setup
+	cfg := config.GetDefaultAppConfig()
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.ConfigOpt(func(c *config.AppConfig) {
+		c.HTTP.RateLimit.Enabled = true
+		c.HTTP.RateLimit.Groups[config.RateLimitGroupAccess] = &config.RateLimit{Rate: 0.1, Burst: 2}
+	}))
+	defer cleanup()
+
+	// given
+	for range 2 {
+		assert.Equal(t, bhs.API().Call(getTokenInfo(cfg.HTTP.AuthToken)).Code, http.StatusOK)
+	}
+
+	// when
+	res := bhs.API().Call(getTokenInfo(cfg.HTTP.AuthToken))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusTooManyRequests)
+	assert.Equal(t, res.Header().Get("Retry-After"), "10")
+}
+
+// Tests that requests of an IP address are limited before authorization.
+func TestRateLimitedUnauthorizedRequests(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.ConfigOpt(func(c *config.AppConfig) {
+		c.HTTP.RateLimit.Enabled = true
+		c.HTTP.RateLimit.Groups[config.RateLimitGroupIP] = &config.RateLimit{Rate: 0.1, Burst: 2}
+	}))
+	defer cleanup()
+
+	// given
+	for range 2 {
+		assert.Equal(t, bhs.API().Call(getTokenInfo("invalid_token_value")).Code, http.StatusUnauthorized)
+	}
+
+	// when
+	res := bhs.API().Call(getTokenInfo("invalid_token_value"))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusTooManyRequests)
+	assert.Equal(t, res.Header().Get("Retry-After"), "10")
+}
+
+// Tests that requests of token over its daily quota are rejected.
+func TestTokenDailyQuota(t *testing.T) {
+	// setup
+	cfg := config.GetDefaultAppConfig()
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.ConfigOpt(func(c *config.AppConfig) {
+		c.HTTP.RateLimit.Enabled = true
+	}))
+	defer cleanup()
+
+	// given
+	res := bhs.API().Call(createScopedToken(cfg.HTTP.AuthToken, `{"dailyQuota":2}`))
+	assert.Equal(t, res.Code, http.StatusOK)
+	created := tokenFromResponse(t, res)
+	assert.Equal(t, created.DailyQuota, int64(2))
+
+	for range 2 {
+		assert.Equal(t, bhs.API().Call(getTokenInfo(created.Token)).Code, http.StatusOK)
+	}
+
+	// when
+	res = bhs.API().Call(getTokenInfo(created.Token))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusTooManyRequests)
+	assert.Equal(t, strings.Contains(res.Body.String(), "ErrQuotaExceeded"), true)
+	if res.Header().Get("Retry-After") == "" {
+		t.Fatalf("Expected Retry-After header")
+	}
+}
+
+// Tests the POST /access endpoint with negative daily quota.
+func TestCreateTokenWithNegativeQuota(t *testing.T) {
+	// setup
+	cfg := config.GetDefaultAppConfig()
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(createScopedToken(cfg.HTTP.AuthToken, `{"dailyQuota":-1}`))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusBadRequest)
+}
+
+// Tests the API accepts JWTs signed by the configured identity provider.
+func TestJWTAuthorization(t *testing.T) {
+	// setup
+	issuer := testjwt.NewIssuer(t, "key-1", "RS256")
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithJWT(testjwt.WriteJWKS(t, issuer)))
+	defer cleanup()
+	jwt := issuer.Sign(t, testjwt.Claims("billing", "merkleroots:verify"))
+
+	// when
+	res := bhs.API().Call(getTokenInfo(jwt))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	token := tokenFromResponse(t, res)
+	assert.Equal(t, token.Name, "billing")
+	assert.Equal(t, token.External, true)
+
+	// when
+	res = bhs.API().Call(authorizedRequest(jwt, http.MethodGet, "/api/v1/chain/tip/longest"))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusForbidden)
+}
+
+// Tests the API rejects JWTs signed by unknown keys and expired JWTs.
+func TestInvalidJWTAuthorization(t *testing.T) {
+	// setup
+	issuer := testjwt.NewIssuer(t, "key-1", "ES256")
+	other := testjwt.NewIssuer(t, "key-1", "ES256")
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithJWT(testjwt.WriteJWKS(t, issuer)))
+	defer cleanup()
+	expired := testjwt.Claims("billing", "headers:read")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	// when
+	res := bhs.API().Call(getTokenInfo(other.Sign(t, testjwt.Claims("billing", "headers:read"))))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusUnauthorized)
+
+	// when
+	res = bhs.API().Call(getTokenInfo(issuer.Sign(t, expired)))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusUnauthorized)
+	assert.Equal(t, strings.Contains(res.Body.String(), "ErrTokenExpired"), true)
+}
+
+// Tests the API authenticates requests with mapped client certificates.
+func TestClientCertAuthorization(t *testing.T) {
+	// setup
+	stored := domains.CreateToken("stored_token_value", domains.TokenOptions{Scopes: []domains.Scope{domains.ScopeHeadersRead}})
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t,
+		testapp.WithTokens(stored),
+		testapp.WithClientCerts(
+			config.ClientCertMapping{Subject: "CN=billing,O=Example", Scopes: []string{"merkleroots:verify"}},
+			config.ClientCertMapping{Subject: "CN=wallet,O=Example", TokenID: stored.ID},
+		),
+	)
+	defer cleanup()
+
+	tests := map[string]struct {
+		subject pkix.Name
+		status  int
+		scopes  string
+	}{
+		"mapped to scopes": {subject: pkix.Name{CommonName: "billing", Organization: []string{"Example"}}, status: http.StatusOK, scopes: "merkleroots:verify"},
+		"mapped to token":  {subject: pkix.Name{CommonName: "wallet", Organization: []string{"Example"}}, status: http.StatusOK, scopes: "headers:read"},
+		"not mapped":       {subject: pkix.Name{CommonName: "unknown"}, status: http.StatusUnauthorized},
+	}
+
+	for name, params := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			req, err := getTokenInfo(EmptyToken)
+			assert.NoError(t, err)
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: params.subject}}}}
+
+			// when
+			res := bhs.API().Call(req)
+
+			// then
+			assert.Equal(t, res.Code, params.status)
+			if params.status == http.StatusOK {
+				assert.Equal(t, domains.JoinScopes(tokenFromResponse(t, res).Scopes), params.scopes)
+			}
+		})
+	}
+}
+
 func tokenFromResponse(t *testing.T, res *httptest.ResponseRecorder) domains.Token {
 	var body domains.Token
 	err := json.Unmarshal(res.Body.Bytes(), &body)
@@ -162,6 +653,23 @@ func createToken(headerToken string) (req *http.Request, err error) {
 	return
 }
 
+func createScopedToken(headerToken string, body string) (req *http.Request, err error) {
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/access", bytes.NewReader([]byte(body)))
+	if headerToken != "" && err == nil {
+		req.Header.Add("Authorization", "Bearer "+headerToken)
+		req.Header.Add("Content-Type", "application/json")
+	}
+	return
+}
+
+func authorizedRequest(headerToken, method, url string) (req *http.Request, err error) {
+	req, err = http.NewRequestWithContext(context.Background(), method, url, io.NopCloser(bytes.NewReader(nil)))
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+headerToken)
+	}
+	return
+}
+
 func deleteToken(headerToken string, tokenToDelete string) (req *http.Request, err error) {
 	req, err = http.NewRequestWithContext(context.Background(), http.MethodDelete, "/api/v1/access/"+tokenToDelete, nil)
 	if headerToken != "" && err == nil {
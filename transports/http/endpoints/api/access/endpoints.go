@@ -1,10 +1,13 @@
 package access
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/service"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
 	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
@@ -24,11 +27,18 @@ func NewHandler(s *service.Services) router.APIEndpoints {
 
 // RegisterAPIEndpoints registers routes that are part of service API.
 func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
-	tokens := router.Group("/access")
+	tokens := router.Group("/access", auth.RateLimitGroup(config.RateLimitGroupAccess))
 	{
 		tokens.GET("", h.getToken)
-		tokens.POST("", auth.RequireAdmin(h.createToken, cfg.UseAuth))
-		tokens.DELETE("/:token", auth.RequireAdmin(h.revokeToken, cfg.UseAuth))
+		tokens.POST("", auth.RequireScope(domains.ScopeTokensAdmin, cfg.UseAuth), h.createToken)
+		tokens.DELETE("/:token", auth.RequireScope(domains.ScopeTokensAdmin, cfg.UseAuth), h.revokeToken)
+	}
+
+	admin := router.Group("/access/tokens", auth.RateLimitGroup(config.RateLimitGroupAccess), auth.RequireScope(domains.ScopeTokensAdmin, cfg.UseAuth))
+	{
+		admin.GET("", h.listTokens)
+		admin.POST("/:id/rotate", h.rotateToken)
+		admin.DELETE("/:id", h.revokeTokenByID)
 	}
 }
 
@@ -54,14 +64,42 @@ func (h *handler) getToken(c *gin.Context) {
 // createToken godoc.
 //
 //		@Summary Creates new token
+//		@Description Creates new token with requested scopes, all scopes except tokens:admin are granted when none is requested.
 //		@Tags access
-//		@Accept */*
+//		@Accept json
 //		@Produce json
 //		@Success 200 {object} domains.Token
 //		@Router /access [post]
+//		@Param data body access.CreateTokenRequest false "Name, owner, expiration time, daily quota and scopes of the token"
 //	 @Security Bearer
 func (h *handler) createToken(c *gin.Context) {
-	bh, err := h.service.GenerateToken()
+	var body CreateTokenRequest
+	// body is optional, so tokens can still be created without any
+	if c.Request.Body != nil && c.Request.Body != http.NoBody {
+		if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+			bhserrors.ErrorResponse(c, bhserrors.ErrBindBody.Wrap(err), h.log)
+			return
+		}
+	}
+
+	if body.DailyQuota < 0 {
+		bhserrors.ErrorResponse(c, bhserrors.ErrBindBody.Wrap(errors.New("daily quota cannot be negative")), h.log)
+		return
+	}
+
+	scopes, err := domains.ParseScopes(body.Scopes)
+	if err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrInvalidScope.Wrap(err), h.log)
+		return
+	}
+
+	bh, err := h.service.GenerateToken(auth.Actor(c), domains.TokenOptions{
+		Name:       body.Name,
+		Owner:      body.Owner,
+		ExpiresAt:  body.ExpiresAt,
+		DailyQuota: body.DailyQuota,
+		Scopes:     scopes,
+	})
 
 	if err == nil {
 		c.JSON(http.StatusOK, bh)
@@ -82,7 +120,68 @@ func (h *handler) createToken(c *gin.Context) {
 //	 @Security Bearer
 func (h *handler) revokeToken(c *gin.Context) {
 	token := c.Param("token")
-	err := h.service.DeleteToken(token)
+	err := h.service.DeleteToken(auth.Actor(c), token)
+
+	if err == nil {
+		c.JSON(http.StatusOK, "Token revoked")
+	} else {
+		bhserrors.ErrorResponse(c, err, h.log)
+	}
+}
+
+// listTokens godoc.
+//
+//		@Summary Lists tokens
+//		@Description Lists all created tokens showing only prefixes of their values, the id of the token is used to rotate or revoke it.
+//		@Tags access
+//		@Accept */*
+//		@Produce json
+//		@Success 200 {array} domains.Token
+//		@Router /access/tokens [get]
+//	 @Security Bearer
+func (h *handler) listTokens(c *gin.Context) {
+	tokens, err := h.service.GetTokens()
+
+	if err == nil {
+		c.JSON(http.StatusOK, tokens)
+	} else {
+		bhserrors.ErrorResponse(c, err, h.log)
+	}
+}
+
+// rotateToken godoc.
+//
+//		@Summary Rotates token
+//		@Description Replaces token with a new one with the same name, owner, scopes and lifetime. The old token can't be used anymore.
+//		@Tags access
+//		@Accept */*
+//		@Produce json
+//		@Success 200 {object} domains.Token
+//		@Router /access/tokens/{id}/rotate [post]
+//		@Param id path string true "Id of the token to rotate"
+//	 @Security Bearer
+func (h *handler) rotateToken(c *gin.Context) {
+	token, err := h.service.RotateToken(auth.Actor(c), c.Param("id"))
+
+	if err == nil {
+		c.JSON(http.StatusOK, token)
+	} else {
+		bhserrors.ErrorResponse(c, err, h.log)
+	}
+}
+
+// revokeTokenByID godoc.
+//
+//		@Summary Revokes token by its id
+//		@Tags access
+//		@Accept */*
+//		@Success 200
+//		@Produce json
+//		@Router /access/tokens/{id} [delete]
+//		@Param id path string true "Id of the token to delete"
+//	 @Security Bearer
+func (h *handler) revokeTokenByID(c *gin.Context) {
+	err := h.service.DeleteTokenByID(auth.Actor(c), c.Param("id"))
 
 	if err == nil {
 		c.JSON(http.StatusOK, "Token revoked")
@@ -0,0 +1,17 @@
+package access
+
+import "time"
+
+// CreateTokenRequest defines an optional request body for token creation.
+type CreateTokenRequest struct {
+	// Name describing the token.
+	Name string `json:"name"`
+	// Owner of the token.
+	Owner string `json:"owner"`
+	// ExpiresAt is the time after which the token is rejected, the token never expires when empty.
+	ExpiresAt *time.Time `json:"expiresAt"`
+	// DailyQuota is the number of requests the token can make per day, the configured quota applies when empty.
+	DailyQuota int64 `json:"dailyQuota"`
+	// Scopes granted to the token, all scopes except tokens:admin are granted when empty.
+	Scopes []string `json:"scopes"`
+}
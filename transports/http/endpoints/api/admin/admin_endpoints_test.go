@@ -0,0 +1,36 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testapp"
+)
+
+func TestPrune(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChainFork())
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(request("/api/v1/admin/prune"))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	var report domains.PruneReport
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+	assert.Equal(t, report.Total(), 0)
+}
+
+func request(url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, nil)
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+config.GetDefaultAppConfig().HTTP.AuthToken)
+	}
+	return req, err
+}
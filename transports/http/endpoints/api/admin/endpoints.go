@@ -0,0 +1,52 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
+	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type handler struct {
+	prune *service.PruneService
+	log   *zerolog.Logger
+}
+
+// NewHandler creates new endpoint handler.
+func NewHandler(s *service.Services) router.APIEndpoints {
+	return &handler{prune: s.Prune, log: s.Logger}
+}
+
+// RegisterAPIEndpoints registers routes that are part of service API.
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	admin := router.Group("/admin", auth.RateLimitGroup(config.RateLimitGroupAccess), auth.RequireScope(domains.ScopeTokensAdmin, cfg.UseAuth))
+	{
+		admin.POST("/prune", h.pruneHeaders)
+	}
+}
+
+// pruneHeaders godoc.
+//
+//		@Summary Prunes headers outside the longest chain
+//		@Description Removes stale branches forked deeper below the tip than prune.stale_depth, and orphan and rejected headers older than prune.orphan_max_age and prune.rejected_max_age, returning the number of removed headers.
+//		@Tags admin
+//		@Accept */*
+//		@Produce json
+//		@Success 200 {object} domains.PruneReport
+//		@Router /admin/prune [post]
+//	 @Security Bearer
+func (h *handler) pruneHeaders(c *gin.Context) {
+	report, err := h.prune.Prune(auth.Actor(c))
+
+	if err == nil {
+		c.JSON(http.StatusOK, report)
+	} else {
+		bhserrors.ErrorResponse(c, err, h.log)
+	}
+}
@@ -0,0 +1,111 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testapp"
+)
+
+var adminToken = config.GetDefaultAppConfig().HTTP.AuthToken
+
+func TestAuditLogOfTokenActions(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	// given
+	res := bhs.API().Call(request(http.MethodPost, "/api/v1/access", `{"name":"billing"}`))
+	assert.Equal(t, res.Code, http.StatusOK)
+	var token domains.Token
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&token))
+	res = bhs.API().Call(request(http.MethodPost, "/api/v1/access/tokens/unknown/rotate", ""))
+	assert.Equal(t, res.Code, http.StatusNotFound)
+
+	// when
+	res = bhs.API().Call(request(http.MethodGet, "/api/v1/audit", ""))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	records := recordsFromResponse(t, res)
+	assert.Equal(t, len(records), 2)
+
+	assert.Equal(t, records[0].Action, domains.AuditTokenRotate)
+	assert.Equal(t, records[0].Actor, "admin")
+	assert.Equal(t, records[0].Target, "unknown")
+	assert.Equal(t, records[0].Result, domains.AuditResultFailure)
+
+	assert.Equal(t, records[1].Action, domains.AuditTokenCreate)
+	assert.Equal(t, records[1].Actor, "admin")
+	assert.Equal(t, records[1].Target, token.ID)
+	assert.Equal(t, records[1].Result, domains.AuditResultSuccess)
+	assert.Equal(t, records[1].Error, "")
+}
+
+func TestAuditLogFilters(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	// given
+	for range 3 {
+		res := bhs.API().Call(request(http.MethodPost, "/api/v1/access", ""))
+		assert.Equal(t, res.Code, http.StatusOK)
+	}
+	bhs.API().Call(request(http.MethodDelete, "/api/v1/access/tokens/unknown", ""))
+
+	// when
+	res := bhs.API().Call(request(http.MethodGet, "/api/v1/audit?action=token.create&limit=2", ""))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	page := recordsFromResponse(t, res)
+	assert.Equal(t, len(page), 2)
+
+	// when
+	res = bhs.API().Call(request(http.MethodGet, "/api/v1/audit?action=token.create&before="+page[1].ID, ""))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	next := recordsFromResponse(t, res)
+	assert.Equal(t, len(next), 1)
+	assert.Equal(t, next[0].Action, domains.AuditTokenCreate)
+}
+
+func TestAuditLogWithInvalidLimit(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	for _, limit := range []string{"abc", "-1", "1001"} {
+		// when
+		res := bhs.API().Call(request(http.MethodGet, "/api/v1/audit?limit="+limit, ""))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusBadRequest)
+	}
+}
+
+func request(method, url, body string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(context.Background(), method, url, strings.NewReader(body))
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+adminToken)
+		if body != "" {
+			req.Header.Add("Content-Type", "application/json")
+		}
+	}
+	return req, err
+}
+
+func recordsFromResponse(t *testing.T, res *httptest.ResponseRecorder) []domains.AuditRecord {
+	var records []domains.AuditRecord
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&records))
+	return records
+}
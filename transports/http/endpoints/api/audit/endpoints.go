@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
+	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type handler struct {
+	service *service.AuditService
+	log     *zerolog.Logger
+}
+
+// NewHandler creates new endpoint handler.
+func NewHandler(s *service.Services) router.APIEndpoints {
+	return &handler{service: s.Audit, log: s.Logger}
+}
+
+// RegisterAPIEndpoints registers routes that are part of service API.
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	audit := router.Group("/audit", auth.RateLimitGroup(config.RateLimitGroupAccess), auth.RequireScope(domains.ScopeTokensAdmin, cfg.UseAuth))
+	{
+		audit.GET("", h.getRecords)
+	}
+}
+
+// getRecords godoc.
+//
+//		@Summary Gets records of the audit log
+//		@Description Returns records of administrative actions, newest first. Pass the id of the last returned record as before to get the next page.
+//		@Tags audit
+//		@Accept */*
+//		@Produce json
+//		@Success 200 {array} domains.AuditRecord
+//		@Router /audit [get]
+//		@Param action query string false "Action of records, e.g. token.create"
+//		@Param actor query string false "Id of the token which performed the actions, admin for the admin token"
+//		@Param before query string false "Id of the record before which records are returned"
+//		@Param limit query int false "Maximum number of records, 100 by default and at most 1000"
+//	 @Security Bearer
+func (h *handler) getRecords(c *gin.Context) {
+	filter := domains.AuditFilter{
+		Action: domains.AuditAction(c.Query("action")),
+		Actor:  c.Query("actor"),
+		Before: c.Query("before"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			bhserrors.ErrorResponse(c, bhserrors.ErrInvalidAuditFilter.Wrap(err), h.log)
+			return
+		}
+		filter.Limit = l
+	}
+
+	records, err := h.service.GetRecords(filter)
+
+	if err == nil {
+		c.JSON(http.StatusOK, records)
+	} else {
+		bhserrors.ErrorResponse(c, err, h.log)
+	}
+}
@@ -0,0 +1,121 @@
+package confirmations
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
+	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type handler struct {
+	service service.Confirmations
+	audit   *service.AuditService
+	log     *zerolog.Logger
+}
+
+// NewHandler creates new endpoint handler.
+func NewHandler(s *service.Services) router.APIEndpoints {
+	return &handler{service: s.Confirmations, audit: s.Audit, log: s.Logger}
+}
+
+// RegisterAPIEndpoints registers routes that are part of service API.
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	confirmations := router.Group("/chain/header/confirmations", auth.RateLimitGroup(config.RateLimitGroupHeaders))
+	{
+		confirmations.GET("", auth.RequireScope(domains.ScopeHeadersRead, cfg.UseAuth), h.getWatched)
+		confirmations.POST("", auth.RequireScope(domains.ScopeWebhooksWrite, cfg.UseAuth), h.watch)
+		confirmations.DELETE("/:hash", auth.RequireScope(domains.ScopeWebhooksWrite, cfg.UseAuth), h.unwatch)
+	}
+}
+
+// getWatched godoc.
+//
+//	@Summary Gets headers watched by the token for reaching the confirmation depth
+//	@Tags confirmations
+//	@Accept */*
+//	@Produce json
+//	@Success 200 {object} confirmations.WatchedHeadersResponse
+//	@Router /chain/header/confirmations [get]
+//	@Security Bearer
+func (h *handler) getWatched(c *gin.Context) {
+	c.JSON(http.StatusOK, h.watchedResponse(c))
+}
+
+// watch godoc.
+//
+//	@Summary Watches headers to be notified when they reach or drop below the confirmation depth
+//	@Tags confirmations
+//	@Accept json
+//	@Produce json
+//	@Success 200 {object} confirmations.WatchedHeadersResponse
+//	@Router /chain/header/confirmations [post]
+//	@Param hashes body []string true "Hashes of headers to watch"
+//	@Security Bearer
+func (h *handler) watch(c *gin.Context) {
+	var body []string
+	if err := c.BindJSON(&body); err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrBindBody.Wrap(err), h.log)
+		return
+	}
+
+	if len(body) == 0 {
+		bhserrors.ErrorResponse(c, bhserrors.ErrWatchHeadersBadBody, h.log)
+		return
+	}
+
+	for _, hash := range body {
+		if _, err := chainhash.NewHashFromStr(hash); err != nil {
+			bhserrors.ErrorResponse(c, bhserrors.ErrInvalidHeaderHash.Wrap(err), h.log)
+			return
+		}
+	}
+
+	err := h.service.Watch(auth.Actor(c).TokenID, body)
+	h.audit.Record(auth.Actor(c), domains.AuditHeaderWatch, strings.Join(body, ","), err)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, h.watchedResponse(c))
+}
+
+// unwatch godoc.
+//
+//	@Summary Stops watching header by the token for reaching the confirmation depth
+//	@Tags confirmations
+//	@Accept */*
+//	@Produce json
+//	@Success 200 {object} confirmations.WatchedHeadersResponse
+//	@Router /chain/header/confirmations/{hash} [delete]
+//	@Param hash path string true "Hash of header to stop watching"
+//	@Security Bearer
+func (h *handler) unwatch(c *gin.Context) {
+	hash := c.Param("hash")
+	if _, err := chainhash.NewHashFromStr(hash); err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrInvalidHeaderHash.Wrap(err), h.log)
+		return
+	}
+
+	err := h.service.Unwatch(auth.Actor(c).TokenID, hash)
+	h.audit.Record(auth.Actor(c), domains.AuditHeaderUnwatch, hash, err)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, h.watchedResponse(c))
+}
+
+func (h *handler) watchedResponse(c *gin.Context) WatchedHeadersResponse {
+	return WatchedHeadersResponse{
+		ConfirmationDepth: h.service.Depth(),
+		Hashes:            h.service.Watched(auth.Actor(c).TokenID),
+	}
+}
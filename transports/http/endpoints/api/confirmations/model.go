@@ -0,0 +1,7 @@
+package confirmations
+
+// WatchedHeadersResponse defines a response with headers watched for reaching the confirmation depth.
+type WatchedHeadersResponse struct {
+	ConfirmationDepth int32    `json:"confirmationDepth"`
+	Hashes            []string `json:"hashes"`
+}
@@ -0,0 +1,197 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/notification"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
+	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+const (
+	lastEventIDHeader = "Last-Event-ID"
+	keepAliveInterval = 30 * time.Second
+	// resyncEvent is sent when not all missed events were recovered.
+	resyncEvent = "RESYNC"
+)
+
+type handler struct {
+	stream   *notification.EventStream
+	recovery service.Recovery
+	encoder  *notification.EventEncoder
+	log      *zerolog.Logger
+	useAuth  bool
+}
+
+// NewHandler creates new endpoint handler.
+func NewHandler(s *service.Services) router.APIEndpoints {
+	return &handler{stream: s.EventStream, recovery: s.Recovery, encoder: s.EventEncoder, log: s.Logger}
+}
+
+// RegisterAPIEndpoints registers routes that are part of service API.
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	h.useAuth = cfg.UseAuth
+	router.GET("/events/stream", auth.RateLimitGroup(config.RateLimitGroupEvents), h.streamEvents)
+}
+
+// streamEvents godoc.
+//
+//	@Summary Streams events as Server-Sent Events
+//	@Description Streams events of the channel, resuming after the event with id passed in Last-Event-ID header.
+//	@Tags events
+//	@Produce text/event-stream
+//	@Success 200
+//	@Router /events/stream [get]
+//	@Param channel query string false "Channel to stream: headers (default), headers:all, tip, reorg or merkleroots:<tokenId> (merkleroots without authorization)"
+//	@Param minHeight query int false "Skips headers below given height"
+//	@Param states query string false "Comma separated list of header states to stream"
+//	@Param format query string false "Format of events: json (default) or cloudevents"
+//	@Param Last-Event-ID header string false "Id of the last received event"
+//	@Security Bearer
+func (h *handler) streamEvents(c *gin.Context) {
+	sub, err := h.subscription(c)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+
+	// live events are buffered while missed ones are recovered, so nothing published in between is lost
+	subscriber := h.stream.Subscribe(sub)
+	defer h.stream.Unsubscribe(subscriber)
+
+	missed := &domains.MissedEvents{Complete: true}
+	if lastEventID := c.GetHeader(lastEventIDHeader); lastEventID != "" {
+		if missed, err = h.missedEvents(sub, lastEventID); err != nil {
+			bhserrors.ErrorResponse(c, err, h.log)
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	recovered := make(map[string]struct{}, len(missed.Events))
+	for _, e := range missed.Events {
+		if id := notification.EventID(e); id != "" {
+			recovered[id] = struct{}{}
+		}
+		h.writeEvent(c.Writer, e, sub.Format)
+	}
+	if !missed.Complete {
+		h.writeResync(c.Writer, missed)
+	}
+	c.Writer.Flush()
+	// live events can't follow recovered ones when some events in between were not recovered, so the stream ends
+	// and the client reconnects from the last recovered event
+	if !missed.Complete && !missed.Resync {
+		return
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			_, _ = io.WriteString(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+		case e, ok := <-subscriber.Events():
+			if !ok {
+				return
+			}
+			// live events could be already sent as recovered ones
+			if _, duplicated := recovered[notification.EventID(e)]; duplicated {
+				continue
+			}
+			h.writeEvent(c.Writer, e, sub.Format)
+			c.Writer.Flush()
+		}
+	}
+}
+
+func (h *handler) subscription(c *gin.Context) (*notification.WebsocketSubscription, error) {
+	channel := c.DefaultQuery("channel", notification.HeadersChannel)
+	query := c.Request.URL.Query()
+	query.Del("channel")
+	query.Del("token")
+	if filters := query.Encode(); filters != "" {
+		channel += "?" + filters
+	}
+
+	sub, err := notification.ParseWebsocketChannel(channel)
+	if err != nil {
+		return nil, bhserrors.ErrInvalidEventsChannel.Wrap(err)
+	}
+
+	// required scope depends on the channel, so it can't be declared on the route
+	if h.useAuth {
+		if err := auth.CheckScope(c, sub.Scope()); err != nil {
+			return nil, err
+		}
+	}
+
+	// events of watched merkle roots are streamed only to the token watching them
+	if sub.MerkleRoots && sub.Watcher != auth.Actor(c).TokenID {
+		return nil, bhserrors.ErrMerklerootsChannelForbidden
+	}
+	return sub, nil
+}
+
+func (h *handler) missedEvents(sub *notification.WebsocketSubscription, lastEventID string) (*domains.MissedEvents, error) {
+	position, err := notification.ParseEventID(lastEventID)
+	if err != nil {
+		return nil, bhserrors.ErrInvalidLastEventID.Wrap(err)
+	}
+
+	missed, err := h.recovery.MissedEvents(*position)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*domains.HeaderEvent, 0, len(missed.Events))
+	for _, e := range missed.Events {
+		if sub.Accepts(e) {
+			events = append(events, e)
+		}
+	}
+	missed.Events = events
+	return missed, nil
+}
+
+// writeResync tells the client that not all missed events were sent: with resync set the chain state has to be
+// fetched again, otherwise the client should reconnect to receive the rest of them.
+func (h *handler) writeResync(w io.Writer, missed *domains.MissedEvents) {
+	data, _ := json.Marshal(struct {
+		Complete bool `json:"complete"`
+		Resync   bool `json:"resync"`
+	}{Complete: missed.Complete, Resync: missed.Resync})
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", resyncEvent, data)
+}
+
+func (h *handler) writeEvent(w io.Writer, event notification.Event, format notification.EventFormat) {
+	data, err := h.encoder.Encode(event, format)
+	if err != nil {
+		h.log.Error().Msgf("Error when creating json from event %v: %v", event, err)
+		return
+	}
+
+	if id := notification.EventID(event); id != "" {
+		_, _ = fmt.Fprintf(w, "id: %s\n", id)
+	}
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", notification.EventName(event), data)
+}
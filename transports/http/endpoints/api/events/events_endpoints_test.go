@@ -0,0 +1,134 @@
+package events_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testapp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamResumesAfterLastEventID(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(stream(t, "channel=tip", "2:"+fixtures.HashHeight2.String()))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	assert.Equal(t, res.Header().Get("Content-Type"), "text/event-stream")
+
+	body := res.Body.String()
+	assert.Equal(t, strings.Count(body, "event: ADD\n"), 2)
+	assert.Equal(t, strings.Contains(body, "id: 3:"+fixtures.HashHeight3.String()+"\n"), true)
+	assert.Equal(t, strings.Contains(body, "id: 4:"+fixtures.HashHeight4.String()+"\n"), true)
+}
+
+func TestStreamAsksToResyncAfterUnknownLastEventID(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(stream(t, "channel=tip", "2:"+strings.Repeat("0", 63)+"1"))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	body := res.Body.String()
+	assert.Equal(t, strings.Contains(body, "event: RESYNC\n"), true)
+	assert.Equal(t, strings.Count(body, "event: ADD\n"), 0)
+}
+
+func TestStreamEndsWithResyncWhenRecoveryIsIncomplete(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled(), testapp.ConfigOpt(func(cfg *config.AppConfig) {
+		cfg.Websocket.RecoveryMax = 1
+	}))
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(stream(t, "channel=tip", "2:"+fixtures.HashHeight2.String()))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	body := res.Body.String()
+	assert.Equal(t, strings.Count(body, "event: ADD\n"), 1)
+	assert.Equal(t, strings.Contains(body, "event: RESYNC\ndata: {\"complete\":false,\"resync\":false}\n"), true)
+}
+
+func TestStreamRejectsInvalidChannel(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithAPIAuthorizationDisabled())
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(stream(t, "channel=tip&maxHeight=1", ""))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusBadRequest)
+	require.JSONEq(t, `{"code":"ErrInvalidEventsChannel","message":"invalid events channel"}`, res.Body.String())
+}
+
+func TestStreamRejectsInvalidLastEventID(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithAPIAuthorizationDisabled())
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(stream(t, "", "not-an-id"))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusBadRequest)
+	require.JSONEq(t, `{"code":"ErrInvalidLastEventID","message":"invalid Last-Event-ID"}`, res.Body.String())
+}
+
+func TestStreamAcceptsTokenFromQuery(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(stream(t, "token=mQZQ6WmxURxWz5ch", ""))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+}
+
+func TestStreamMerkleRootsOnlyOfTheToken(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	// when
+	own := bhs.API().Call(stream(t, "token=mQZQ6WmxURxWz5ch&channel=merkleroots:admin", ""))
+	other := bhs.API().Call(stream(t, "token=mQZQ6WmxURxWz5ch&channel=merkleroots:"+strings.Repeat("ab", 32), ""))
+
+	// then
+	assert.Equal(t, own.Code, http.StatusOK)
+	assert.Equal(t, other.Code, http.StatusForbidden)
+	require.JSONEq(t, `{"code":"ErrMerklerootsChannelForbidden","message":"merkleroots channel of another token"}`, other.Body.String())
+}
+
+// stream creates request to the events stream which is cancelled shortly, so the stream ends.
+func stream(t *testing.T, query string, lastEventID string) (*http.Request, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/events/stream?"+query, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	return req, nil
+}
@@ -6,7 +6,9 @@ import (
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
 	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -23,8 +25,8 @@ func NewHandler(s *service.Services) router.APIEndpoints {
 }
 
 // RegisterAPIEndpoints registers routes that are part of service API.
-func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, _ *config.HTTPConfig) {
-	headers := router.Group("/chain/header")
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	headers := router.Group("/chain/header", auth.RateLimitGroup(config.RateLimitGroupHeaders), auth.RequireScope(domains.ScopeHeadersRead, cfg.UseAuth))
 	{
 		headers.GET("/:hash", h.getHeaderByHash)
 		headers.GET("/byHeight", h.getHeaderByHeight)
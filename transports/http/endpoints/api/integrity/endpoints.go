@@ -0,0 +1,52 @@
+package integrity
+
+import (
+	"net/http"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
+	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type handler struct {
+	service *service.IntegrityService
+	log     *zerolog.Logger
+}
+
+// NewHandler creates new endpoint handler.
+func NewHandler(s *service.Services) router.APIEndpoints {
+	return &handler{service: s.Integrity, log: s.Logger}
+}
+
+// RegisterAPIEndpoints registers routes that are part of service API.
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	integrity := router.Group("/integrity", auth.RateLimitGroup(config.RateLimitGroupAccess), auth.RequireScope(domains.ScopeTokensAdmin, cfg.UseAuth))
+	{
+		integrity.GET("", h.check)
+	}
+}
+
+// check godoc.
+//
+//		@Summary Checks integrity of stored headers
+//		@Description Scans all stored headers and reports heights without exactly one header of the longest chain, headers without previous header, wrong chainwork or cumulated work, stale branches with more work than the longest chain and orphans whose previous header is stored. Headers are repaired only by the check command with --repair flag.
+//		@Tags integrity
+//		@Accept */*
+//		@Produce json
+//		@Success 200 {object} domains.IntegrityReport
+//		@Router /integrity [get]
+//	 @Security Bearer
+func (h *handler) check(c *gin.Context) {
+	report, err := h.service.Check()
+
+	if err == nil {
+		c.JSON(http.StatusOK, report)
+	} else {
+		bhserrors.ErrorResponse(c, err, h.log)
+	}
+}
@@ -0,0 +1,38 @@
+package integrity_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testapp"
+)
+
+func TestIntegrityCheck(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(request("/api/v1/integrity"))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	var report domains.IntegrityReport
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+	assert.Equal(t, report.Consistent(), true)
+	assert.Equal(t, report.HeadersChecked, 1)
+	assert.Equal(t, report.Repaired, 0)
+}
+
+func request(url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+config.GetDefaultAppConfig().HTTP.AuthToken)
+	}
+	return req, err
+}
@@ -1,13 +1,16 @@
 package merkleroots
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
 	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -19,21 +22,30 @@ const (
 )
 
 type handler struct {
-	service service.Merkleroots
-	log     *zerolog.Logger
+	service         service.Merkleroots
+	watches         service.MerkleRootWatches
+	audit           *service.AuditService
+	log             *zerolog.Logger
+	verifyBatchSize int
 }
 
 // NewHandler creates new endpoint handler.
 func NewHandler(s *service.Services) router.APIEndpoints {
-	return &handler{service: s.Merkleroots, log: s.Logger}
+	return &handler{service: s.Merkleroots, watches: s.MerkleRootWatches, audit: s.Audit, log: s.Logger}
 }
 
 // RegisterAPIEndpoints registers routes that are part of service API.
-func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, _ *config.HTTPConfig) {
-	merkle := router.Group("/chain/merkleroot")
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	if cfg.RateLimit != nil {
+		h.verifyBatchSize = cfg.RateLimit.VerifyBatchSize
+	}
+	merkle := router.Group("/chain/merkleroot", auth.RateLimitGroup(config.RateLimitGroupMerkleRoots), auth.RequireScope(domains.ScopeMerkleRootsVerify, cfg.UseAuth))
 	{
 		merkle.POST("/verify", h.verify)
 		merkle.GET("", h.merkleroots)
+		merkle.GET("/watch", h.getWatches)
+		merkle.POST("/watch", h.watch)
+		merkle.DELETE("/watch", h.unwatch)
 	}
 }
 
@@ -89,6 +101,11 @@ func (h *handler) verify(c *gin.Context) {
 		return
 	}
 
+	// the first batch of merkle roots is covered by the request itself
+	if h.verifyBatchSize > 0 && !auth.ChargeRequest(c, (len(body)-1)/h.verifyBatchSize) {
+		return
+	}
+
 	mrcs, err := h.service.GetMerkleRootsConfirmations(body)
 
 	if err == nil {
@@ -97,3 +114,101 @@ func (h *handler) verify(c *gin.Context) {
 		bhserrors.ErrorResponse(c, err, h.log)
 	}
 }
+
+// GetWatches godoc.
+//
+//	@Summary Gets merkle roots watched by the token
+//	@Tags merkleroots
+//	@Accept */*
+//	@Produce json
+//	@Success 200 {array} domains.MerkleRootWatch
+//	@Router /chain/merkleroot/watch [get]
+//	@Security Bearer
+func (h *handler) getWatches(c *gin.Context) {
+	watches, err := h.watches.GetWatches(auth.Actor(c).TokenID)
+
+	if err == nil {
+		c.JSON(http.StatusOK, watches)
+	} else {
+		bhserrors.ErrorResponse(c, err, h.log)
+	}
+}
+
+// Watch godoc.
+//
+//	@Summary Watches merkle roots to be notified when they get confirmed, invalidated or reach the configured number of confirmations
+//	@Tags merkleroots
+//	@Accept json
+//	@Produce json
+//	@Success 200 {array} domains.MerkleRootWatch
+//	@Router /chain/merkleroot/watch [post]
+//	@Param request body []merkleroots.WatchRequestItem true "Merkle roots with block heights or BUMPs"
+//	@Security Bearer
+func (h *handler) watch(c *gin.Context) {
+	var body []WatchRequestItem
+	if err := c.BindJSON(&body); err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrBindBody.Wrap(err), h.log)
+		return
+	}
+
+	if len(body) == 0 {
+		bhserrors.ErrorResponse(c, bhserrors.ErrWatchMerklerootsBadBody, h.log)
+		return
+	}
+
+	items := make([]domains.MerkleRootConfirmationRequestItem, 0, len(body))
+	for _, rq := range body {
+		item, err := rq.toRequestItem()
+		if err != nil {
+			bhserrors.ErrorResponse(c, err, h.log)
+			return
+		}
+		items = append(items, item)
+	}
+
+	watches, err := h.watches.Watch(auth.Actor(c).TokenID, items)
+	h.audit.Record(auth.Actor(c), domains.AuditMerkleRootWatch, watchTarget(items), err)
+
+	if err == nil {
+		c.JSON(http.StatusOK, watches)
+	} else {
+		bhserrors.ErrorResponse(c, err, h.log)
+	}
+}
+
+// Unwatch godoc.
+//
+//	@Summary Stops watching merkle root
+//	@Tags merkleroots
+//	@Accept */*
+//	@Produce json
+//	@Success 200
+//	@Router /chain/merkleroot/watch [delete]
+//	@Param merkleRoot query string true "Watched merkle root"
+//	@Param blockHeight query int true "Block height of watched merkle root"
+//	@Security Bearer
+func (h *handler) unwatch(c *gin.Context) {
+	merkleRoot := c.Query("merkleRoot")
+	blockHeight, err := strconv.ParseInt(c.Query("blockHeight"), 10, 32)
+	if merkleRoot == "" || err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrWatchMerklerootsBadBody, h.log)
+		return
+	}
+
+	err = h.watches.Unwatch(auth.Actor(c).TokenID, merkleRoot, int32(blockHeight))
+	h.audit.Record(auth.Actor(c), domains.AuditMerkleRootUnwatch, fmt.Sprintf("%s@%d", merkleRoot, blockHeight), err)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// watchTarget describes watched merkle roots in the audit log.
+func watchTarget(items []domains.MerkleRootConfirmationRequestItem) string {
+	targets := make([]string, 0, len(items))
+	for _, item := range items {
+		targets = append(targets, fmt.Sprintf("%s@%d", item.MerkleRoot, item.BlockHeight))
+	}
+	return strings.Join(targets, ",")
+}
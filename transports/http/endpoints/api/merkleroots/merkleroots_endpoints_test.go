@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/bitcoin-sv/block-headers-service/domains"
@@ -450,3 +451,68 @@ func getMerkleRoots(batchSize, lastEvaluatedKey string) (req *http.Request, err
 		nil,
 	)
 }
+
+func TestReturnWatchedMerkleRootsWithState(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+	defer cleanup()
+	request := []merkleroots.WatchRequestItem{
+		{MerkleRoot: chaincfg.GenesisMerkleRoot.String(), BlockHeight: 0},
+		{MerkleRoot: strings.Repeat("ab", 32), BlockHeight: 1},
+	}
+
+	// when
+	res := bhs.API().Call(watch(request))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+
+	var watches []domains.MerkleRootWatch
+	json.NewDecoder(res.Body).Decode(&watches)
+
+	assert.Equal(t, len(watches), 2)
+	assert.Equal(t, watches[0].MerkleRoot, chaincfg.GenesisMerkleRoot.String())
+	assert.Equal(t, watches[0].Confirmation, domains.Confirmed)
+	assert.Equal(t, watches[1].Confirmation, domains.Invalid)
+}
+
+func TestReturnBadRequestErrorFromWatchWhenGivenInvalidBUMP(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+	defer cleanup()
+	request := []merkleroots.WatchRequestItem{{BUMP: "not_a_bump"}}
+
+	// when
+	res := bhs.API().Call(watch(request))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusBadRequest)
+	require.JSONEq(t, "{\"code\":\"ErrInvalidBUMP\",\"message\":\"invalid BUMP\"}", res.Body.String())
+}
+
+func TestReturnBadRequestErrorFromWatchWhenGivenMerkleRootNotHex(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+	defer cleanup()
+	request := []merkleroots.WatchRequestItem{{MerkleRoot: "invalid_merkle_root", BlockHeight: 1}}
+
+	// when
+	res := bhs.API().Call(watch(request))
+
+	// then
+	assert.Equal(t, res.Code, http.StatusBadRequest)
+	require.JSONEq(t, "{\"code\":\"ErrInvalidMerkleRoot\",\"message\":\"merkleroot must be a hex encoded hash\"}", res.Body.String())
+}
+
+func watch(request []merkleroots.WatchRequestItem) (req *http.Request, err error) {
+	query, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(
+		context.Background(),
+		http.MethodPost,
+		"/api/v1/chain/merkleroot/watch",
+		bytes.NewReader(query),
+	)
+}
@@ -1,7 +1,11 @@
 package merkleroots
 
 import (
+	"encoding/hex"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 )
 
 // MerkleRootConfirmation is a confirmation
@@ -67,3 +71,29 @@ func convertState(s domains.MerkleRootConfirmationState) int {
 		return 2
 	}
 }
+
+// WatchRequestItem is a request item for watching merkle root. Either merkle root
+// with block height or BUMP (BRC-74) encoded as hex string must be provided.
+type WatchRequestItem struct {
+	MerkleRoot  string `json:"merkleRoot,omitempty"`
+	BlockHeight int32  `json:"blockHeight,omitempty"`
+	BUMP        string `json:"bump,omitempty"`
+}
+
+// toRequestItem converts WatchRequestItem to domain's MerkleRootConfirmationRequestItem.
+func (w WatchRequestItem) toRequestItem() (domains.MerkleRootConfirmationRequestItem, error) {
+	if w.BUMP != "" {
+		item, err := domains.MerkleRootFromBUMP(w.BUMP)
+		if err != nil {
+			return domains.MerkleRootConfirmationRequestItem{}, bhserrors.ErrInvalidBUMP.Wrap(err)
+		}
+		return *item, nil
+	}
+	if w.MerkleRoot == "" {
+		return domains.MerkleRootConfirmationRequestItem{}, bhserrors.ErrWatchMerklerootsBadBody
+	}
+	if _, err := hex.DecodeString(w.MerkleRoot); err != nil || len(w.MerkleRoot) != chainhash.MaxHashStringSize {
+		return domains.MerkleRootConfirmationRequestItem{}, bhserrors.ErrInvalidMerkleRoot
+	}
+	return domains.MerkleRootConfirmationRequestItem{MerkleRoot: w.MerkleRoot, BlockHeight: w.BlockHeight}, nil
+}
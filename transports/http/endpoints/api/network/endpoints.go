@@ -4,7 +4,9 @@ import (
 	"net/http"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
 	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
 	"github.com/gin-gonic/gin"
 )
@@ -19,8 +21,8 @@ func NewHandler(s *service.Services) router.APIEndpoints {
 }
 
 // RegisterAPIEndpoints registers routes that are part of service API.
-func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, _ *config.HTTPConfig) {
-	network := router.Group("/network")
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	network := router.Group("/network", auth.RateLimitGroup(config.RateLimitGroupNetwork), auth.RequireScope(domains.ScopePeersAdmin, cfg.UseAuth))
 	{
 		network.GET("/peer", h.getPeers)
 		network.GET("/peer/count", h.getPeersCount)
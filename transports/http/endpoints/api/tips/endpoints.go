@@ -5,7 +5,9 @@ import (
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
 	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -22,8 +24,8 @@ func NewHandler(s *service.Services) router.APIEndpoints {
 }
 
 // RegisterAPIEndpoints registers routes that are part of service API.
-func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, _ *config.HTTPConfig) {
-	tip := router.Group("/chain")
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	tip := router.Group("/chain", auth.RateLimitGroup(config.RateLimitGroupHeaders), auth.RequireScope(domains.ScopeHeadersRead, cfg.UseAuth))
 	{
 		tip.GET("/tip", h.getTips)
 		tip.GET("/tip/longest", h.getTipLongestChain)
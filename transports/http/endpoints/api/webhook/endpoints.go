@@ -5,8 +5,10 @@ import (
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/notification"
 	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
 	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -14,8 +16,8 @@ import (
 
 // Webhooks is an interface which represents methods required for Webhooks service.
 type Webhooks interface {
-	CreateWebhook(authType, header, token, url string) (*notification.Webhook, error)
-	DeleteWebhook(value string) error
+	CreateWebhook(actor domains.AuditActor, authType, header, token, url string, format notification.EventFormat) (*notification.Webhook, error)
+	DeleteWebhook(actor domains.AuditActor, value string) error
 	GetWebhookByURL(url string) (*notification.Webhook, error)
 }
 
@@ -30,8 +32,8 @@ func NewHandler(s *service.Services) router.APIEndpoints {
 }
 
 // RegisterAPIEndpoints registers routes that are part of service API.
-func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, _ *config.HTTPConfig) {
-	webhooks := router.Group("/webhook")
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	webhooks := router.Group("/webhook", auth.RateLimitGroup(config.RateLimitGroupWebhooks), auth.RequireScope(domains.ScopeWebhooksWrite, cfg.UseAuth))
 	{
 		webhooks.POST("", h.registerWebhook)
 		webhooks.GET("", h.getWebhook)
@@ -63,7 +65,13 @@ func (h *handler) registerWebhook(c *gin.Context) {
 		return
 	}
 
-	webhook, err := h.service.CreateWebhook(reqBody.RequiredAuth.Type, reqBody.RequiredAuth.Header, reqBody.RequiredAuth.Token, reqBody.URL)
+	format, err := notification.ParseEventFormat(reqBody.Format)
+	if err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrInvalidWebhookFormat.Wrap(err), h.log)
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(auth.Actor(c), reqBody.RequiredAuth.Type, reqBody.RequiredAuth.Header, reqBody.RequiredAuth.Token, reqBody.URL, format)
 	if err == nil {
 		c.JSON(http.StatusOK, webhook)
 	} else {
@@ -114,7 +122,7 @@ func (h *handler) revokeWebhook(c *gin.Context) {
 		bhserrors.ErrorResponse(c, bhserrors.ErrURLParamRequired, h.log)
 		return
 	}
-	err := h.service.DeleteWebhook(url)
+	err := h.service.DeleteWebhook(auth.Actor(c), url)
 
 	if err == nil {
 		c.JSON(http.StatusOK, "Webhook revoked")
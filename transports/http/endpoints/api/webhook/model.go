@@ -4,6 +4,8 @@ package webhook
 type Request struct {
 	URL          string       `json:"url"`
 	RequiredAuth RequiredAuth `json:"requiredAuth"`
+	// Format of events sent to the webhook: json (default), cloudevents or cloudevents-binary.
+	Format string `json:"format"`
 }
 
 // RequiredAuth defines an auth information for webhook registration.
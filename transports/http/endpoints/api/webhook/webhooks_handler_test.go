@@ -64,6 +64,24 @@ func TestMultipleIdenticalWebhooks(t *testing.T) {
 	require.JSONEq(t, expectedBodyResponse, res2.Body.String())
 }
 
+// TestCreateWebhookWithUnknownFormat tests the webhook registration with unsupported format of events.
+func TestCreateWebhookWithUnknownFormat(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithAPIAuthorizationDisabled())
+	defer cleanup()
+	request := preparedWebhook
+	request.Format = "xml"
+
+	// when
+	res := bhs.API().Call(createWebhookRequest(&request))
+
+	// then
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("Expected to get status %d but instead got %d\n", http.StatusBadRequest, res.Code)
+	}
+	require.Contains(t, res.Body.String(), "ErrInvalidWebhookFormat")
+}
+
 // TestRevokeWebhookEndpoint tests the webhook revocation.
 func TestRevokeWebhookEndpoint(t *testing.T) {
 	// setup
@@ -93,7 +111,11 @@ func TestRevokeWebhookEndpoint(t *testing.T) {
 }
 
 func createWebhook() (req *http.Request, err error) {
-	webhookBytes, err := json.Marshal(&preparedWebhook)
+	return createWebhookRequest(&preparedWebhook)
+}
+
+func createWebhookRequest(request *webhook.Request) (req *http.Request, err error) {
+	webhookBytes, err := json.Marshal(request)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't marshal webhook: %w", err)
 	}
@@ -7,7 +7,12 @@ import (
 	"github.com/bitcoin-sv/block-headers-service/service"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/access"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/admin"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/audit"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/confirmations"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/events"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/headers"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/integrity"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/merkleroots"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/network"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/profile"
@@ -29,17 +34,22 @@ func SetupRoutes(s *service.Services, cfg *config.HTTPConfig) httpserver.GinEngi
 		swagger.NewHandler(s, "/api/v1"),
 		access.NewHandler(s),
 		headers.NewHandler(s),
+		confirmations.NewHandler(s),
 		network.NewHandler(s),
 		tips.NewHandler(s),
 		webhook.NewHandler(s),
 		merkleroots.NewHandler(s),
+		events.NewHandler(s),
+		audit.NewHandler(s),
+		integrity.NewHandler(s),
+		admin.NewHandler(s),
 	}
 
 	if cfg.ProfilingEndpointsEnabled {
 		routes = append(routes, profile.NewHandler(s))
 	}
 
-	apiMiddlewares := toHandlers(auth.NewMiddleware(s, cfg))
+	apiMiddlewares := toHandlers(auth.NewIPRateLimitMiddleware(s), auth.NewMiddleware(s, cfg), auth.NewRateLimitMiddleware(s))
 
 	return func(engine *gin.Engine) {
 		rootRouter := engine.Group("")
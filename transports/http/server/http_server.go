@@ -19,6 +19,7 @@ type GinEngineOpt func(*gin.Engine)
 type HTTPServer struct {
 	httpServer *http.Server
 	handler    *gin.Engine
+	tls        *tlsConfigLoader
 	log        *zerolog.Logger
 }
 
@@ -36,7 +37,7 @@ func NewHTTPServer(cfg *config.HTTPConfig, log *zerolog.Logger) *HTTPServer {
 
 	serverLogger := log.With().Str("subservice", "server").Logger()
 
-	return &HTTPServer{
+	s := &HTTPServer{
 		httpServer: &http.Server{
 			Addr:         ":" + fmt.Sprint(cfg.Port),
 			Handler:      handler,
@@ -46,6 +47,11 @@ func NewHTTPServer(cfg *config.HTTPConfig, log *zerolog.Logger) *HTTPServer {
 		handler: handler,
 		log:     &serverLogger,
 	}
+	if cfg.TLS != nil && cfg.TLS.Enabled {
+		s.tls = newTLSConfigLoader(cfg.TLS)
+		s.httpServer.TLSConfig = s.tls.serverConfig()
+	}
+	return s
 }
 
 // ApplyConfiguration it's entrypoint to configure a gin engine used by a server.
@@ -55,9 +61,29 @@ func (s *HTTPServer) ApplyConfiguration(opts ...GinEngineOpt) {
 	}
 }
 
-// Start is used to start http server.
+// Start is used to start http server, over TLS when it's enabled.
 func (s *HTTPServer) Start() error {
-	return s.httpServer.ListenAndServe()
+	if s.tls == nil {
+		return s.httpServer.ListenAndServe()
+	}
+	if err := s.tls.load(); err != nil {
+		return err
+	}
+	s.log.Info().Msg("HTTP Server serves over TLS")
+	return s.httpServer.ListenAndServeTLS("", "")
+}
+
+// ReloadCertificates loads certificates of the server and CAs of clients again, used by new connections.
+// It does nothing when TLS isn't enabled.
+func (s *HTTPServer) ReloadCertificates() error {
+	if s.tls == nil {
+		return nil
+	}
+	if err := s.tls.load(); err != nil {
+		return err
+	}
+	s.log.Info().Msg("TLS certificates reloaded")
+	return nil
 }
 
 // ShutdownWithContext is used to stop http server using provided context.
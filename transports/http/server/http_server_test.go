@@ -0,0 +1,184 @@
+package httpserver_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	httpserver "github.com/bitcoin-sv/block-headers-service/transports/http/server"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+func TestServerWithMutualTLS(t *testing.T) {
+	// setup
+	ca := newCertificate(t, "Test CA", nil)
+	serverCert := newCertificate(t, "localhost", ca)
+	clientCert := newCertificate(t, "billing", ca)
+	cfg, url := startTLSServer(t, serverCert, ca, true)
+	defer cfg.cleanup()
+
+	// when
+	_, err := newClient(ca, nil).Get(url)
+
+	// then
+	if err == nil {
+		t.Fatalf("Expected connection without client certificate to be rejected")
+	}
+
+	// when
+	res, err := newClient(ca, clientCert).Get(url)
+
+	// then
+	assert.NoError(t, err)
+	defer res.Body.Close() //nolint: all
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.Equal(t, res.Header.Get("X-Client-Subject"), "CN=billing")
+}
+
+func TestServerReloadsCertificates(t *testing.T) {
+	// setup
+	ca := newCertificate(t, "Test CA", nil)
+	cfg, url := startTLSServer(t, newCertificate(t, "localhost", ca), ca, false)
+	defer cfg.cleanup()
+
+	// given
+	renewed := newCertificate(t, "localhost", ca)
+	renewed.write(t, cfg.tls.CertFile, cfg.tls.KeyFile)
+
+	// when
+	err := cfg.server.ReloadCertificates()
+
+	// then
+	assert.NoError(t, err)
+	res, err := newClient(ca, nil).Get(url)
+	assert.NoError(t, err)
+	defer res.Body.Close() //nolint: all
+	assert.Equal(t, res.TLS.PeerCertificates[0].SerialNumber.Cmp(renewed.cert.SerialNumber), 0)
+}
+
+type testServer struct {
+	server  *httpserver.HTTPServer
+	tls     *config.TLSConfig
+	cleanup func()
+}
+
+func startTLSServer(t *testing.T, serverCert, ca *certificate, requireClientCert bool) (*testServer, string) {
+	dir := t.TempDir()
+	cfg := config.GetDefaultAppConfig().HTTP
+	cfg.Port = freePort(t)
+	cfg.TLS.Enabled = true
+	cfg.TLS.CertFile = filepath.Join(dir, "server.pem")
+	cfg.TLS.KeyFile = filepath.Join(dir, "server.key")
+	cfg.TLS.ClientCAFile = filepath.Join(dir, "ca.pem")
+	cfg.TLS.RequireClientCert = requireClientCert
+	serverCert.write(t, cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	ca.write(t, cfg.TLS.ClientCAFile, filepath.Join(dir, "ca.key"))
+
+	logger := zerolog.Nop()
+	gin.SetMode(gin.TestMode)
+	server := httpserver.NewHTTPServer(cfg, &logger)
+	server.ApplyConfiguration(func(engine *gin.Engine) {
+		engine.GET("/status", func(c *gin.Context) {
+			if c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 {
+				c.Header("X-Client-Subject", c.Request.TLS.VerifiedChains[0][0].Subject.String())
+			}
+			c.Status(http.StatusOK)
+		})
+	})
+	go func() {
+		_ = server.Start()
+	}()
+
+	url := fmt.Sprintf("https://localhost:%d/status", cfg.Port)
+	waitForServer(t, cfg.Port)
+	return &testServer{server: server, tls: cfg.TLS, cleanup: func() { _ = server.Shutdown() }}, url
+}
+
+type certificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newCertificate creates certificate signed by the CA, or self-signed CA certificate when ca is nil.
+func newCertificate(t *testing.T, commonName string, ca *certificate) *certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{commonName},
+	}
+	parent, signer := template, key
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		parent, signer = ca.cert, ca.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return &certificate{cert: cert, key: key, der: der}
+}
+
+func (c *certificate) write(t *testing.T, certFile, keyFile string) {
+	keyDer, err := x509.MarshalECPrivateKey(c.key)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+}
+
+func newClient(ca *certificate, clientCert *certificate) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	tlsCfg := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	if clientCert != nil {
+		tlsCfg.Certificates = []tls.Certificate{{Certificate: [][]byte{clientCert.der}, PrivateKey: clientCert.key}}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}, Timeout: 5 * time.Second}
+}
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	defer l.Close() //nolint: all
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func waitForServer(t *testing.T, port int) {
+	for range 50 {
+		conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+		if err == nil {
+			_ = conn.Close()
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("server didn't start")
+}
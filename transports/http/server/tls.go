@@ -0,0 +1,90 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+)
+
+var tlsVersions = map[string]uint16{
+	config.TLSVersion12: tls.VersionTLS12,
+	config.TLSVersion13: tls.VersionTLS13,
+}
+
+// tlsConfigLoader loads certificates of the server and CAs of clients, and serves them to new connections.
+// Loading them again replaces them without restarting the server.
+type tlsConfigLoader struct {
+	cfg     *config.TLSConfig
+	mu      sync.RWMutex
+	current *tls.Config
+}
+
+func newTLSConfigLoader(cfg *config.TLSConfig) *tlsConfigLoader {
+	return &tlsConfigLoader{cfg: cfg}
+}
+
+// serverConfig returns TLS config of the server, which takes the loaded config for every connection.
+func (l *tlsConfigLoader) serverConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tlsVersions[l.cfg.MinVersion],
+		GetConfigForClient: l.configForClient,
+	}
+}
+
+// load loads certificates from files, keeping the previous ones when it fails.
+func (l *tlsConfigLoader) load() error {
+	cert, err := tls.LoadX509KeyPair(l.cfg.CertFile, l.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("cannot load server certificate: %w", err)
+	}
+
+	// ALPN is not negotiated, so connections use HTTP/1.1, which websocket connections require
+	tlsCfg := &tls.Config{
+		MinVersion:   tlsVersions[l.cfg.MinVersion],
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.NoClientCert,
+	}
+
+	if l.cfg.ClientCAFile != "" {
+		pool, err := loadCertPool(l.cfg.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+		if l.cfg.RequireClientCert {
+			tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	l.mu.Lock()
+	l.current = tlsCfg
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *tlsConfigLoader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.current == nil {
+		return nil, errors.New("certificates are not loaded")
+	}
+	return l.current, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("cannot load client CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("client CA bundle has no certificates")
+	}
+	return pool, nil
+}
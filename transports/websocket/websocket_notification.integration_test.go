@@ -12,6 +12,12 @@ import (
 )
 
 const (
+	expectedCaughtUpEvent = `{
+		"operation": "SYNC_CAUGHT_UP",
+		"height": 1,
+		"hash": "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048",
+		"creationTimestamp": "2009-01-09T02:54:25Z"
+	  }`
 	expectedEvent = `{
 		"operation": "ADD",
 		"header": {
@@ -45,10 +51,14 @@ func TestShouldNotifyWebsocketAboutNewHeader(t *testing.T) {
 	err = p.When().NewHeaderReceived(*fixtures.HeaderSourceHeight1)
 	assert.NoError(t, err)
 
-	// then
+	// then the test service starting at the genesis block catches up with the network
+	json := jsonassert.New(t)
 	msg, err := wait.ForString(onMsg, time.Second)
 	assert.NoError(t, err)
+	json.Assertf(msg, expectedCaughtUpEvent)
 
-	json := jsonassert.New(t)
+	// and
+	msg, err = wait.ForString(onMsg, time.Second)
+	assert.NoError(t, err)
 	json.Assertf(msg, expectedEvent)
 }
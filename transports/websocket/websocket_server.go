@@ -2,8 +2,16 @@ package websocket
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/metrics"
+	"github.com/bitcoin-sv/block-headers-service/notification"
 	"github.com/bitcoin-sv/block-headers-service/service"
 	"github.com/centrifugal/centrifuge"
 	"github.com/gin-gonic/gin"
@@ -21,17 +29,36 @@ type Server interface {
 	Shutdown() error
 	SetupEntrypoint(*gin.Engine)
 	Publisher() Publisher
+	Subscriptions() *notification.WebsocketSubscriptions
 }
 
+// tokenContextKey is a key of the token of connected client in the client context.
+type tokenContextKey struct{}
+
+// clientIPContextKey is a key of the IP address of connecting client in the request context.
+type clientIPContextKey struct{}
+
+// clientTLSContextKey is a key of the TLS connection state of connecting client in the request context.
+type clientTLSContextKey struct{}
+
+// rateLimitClientContextKey is a key under which requests of connected client are limited.
+type rateLimitClientContextKey struct{}
+
 type server struct {
 	node           *centrifuge.Node
 	isAuthRequired bool
 	tokens         service.Tokens
+	limits         *service.RateLimitService
+	clientCerts    *service.ClientCertService
+	recovery       service.Recovery
+	encoder        *notification.EventEncoder
+	httpStream     bool
+	subscriptions  *notification.WebsocketSubscriptions
 	log            *zerolog.Logger
 }
 
 // NewServer creates new websocket server.
-func NewServer(log *zerolog.Logger, services *service.Services, isAuthenticationOn bool) (Server, error) {
+func NewServer(log *zerolog.Logger, services *service.Services, isAuthenticationOn bool, cfg *config.WebsocketConfig) (Server, error) {
 	websocketLogger := log.With().Str("subservice", "websocket-server").Logger()
 	node, err := newNode(&websocketLogger)
 	if err != nil {
@@ -41,6 +68,12 @@ func NewServer(log *zerolog.Logger, services *service.Services, isAuthentication
 		node:           node,
 		isAuthRequired: isAuthenticationOn,
 		tokens:         services.Tokens,
+		limits:         services.RateLimits,
+		clientCerts:    services.ClientCerts,
+		recovery:       services.Recovery,
+		encoder:        services.EventEncoder,
+		httpStream:     cfg.HTTPStreamEnabled,
+		subscriptions:  notification.NewWebsocketSubscriptions(),
 		log:            &websocketLogger,
 	}
 	return s, nil
@@ -71,7 +104,37 @@ func (s *server) ShutdownWithContext(ctx context.Context) error {
 
 // SetupEntrypoint setup gin to init websocket connection.
 func (s *server) SetupEntrypoint(engine *gin.Engine) {
-	engine.GET("/connection/websocket", gin.WrapH(centrifuge.NewWebsocketHandler(s.node, centrifuge.WebsocketConfig{})))
+	engine.GET("/connection/websocket", withClient, gin.WrapH(centrifuge.NewWebsocketHandler(s.node, centrifuge.WebsocketConfig{})))
+	if s.httpStream {
+		engine.POST("/connection/http_stream", withClient, gin.WrapH(centrifuge.NewHTTPStreamHandler(s.node, centrifuge.HTTPStreamConfig{})))
+		engine.POST("/emulation", withClient, gin.WrapH(centrifuge.NewEmulationHandler(s.node, centrifuge.EmulationConfig{})))
+	}
+}
+
+// withClient passes IP address and TLS connection state of the client to the context of connection,
+// so clients can be limited without tokens and authenticated with client certificates.
+func withClient(c *gin.Context) {
+	ctx := context.WithValue(c.Request.Context(), clientIPContextKey{}, c.ClientIP())
+	if c.Request.TLS != nil {
+		ctx = context.WithValue(ctx, clientTLSContextKey{}, c.Request.TLS)
+	}
+	c.Request = c.Request.WithContext(ctx)
+}
+
+// authenticate returns token of the connecting client, clients without a token can be authenticated with client certificates.
+func (s *server) authenticate(ctx context.Context, rawToken string) (*domains.Token, error) {
+	if rawToken != "" {
+		return s.tokens.GetToken(rawToken)
+	}
+	state, _ := ctx.Value(clientTLSContextKey{}).(*tls.ConnectionState)
+	token, err := s.clientCerts.Authenticate(state)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, errors.New("missing token")
+	}
+	return token, nil
 }
 
 // Publisher returns websocket Publisher component.
@@ -79,6 +142,11 @@ func (s *server) Publisher() Publisher {
 	return s.node
 }
 
+// Subscriptions returns filtered channels subscribed by clients.
+func (s *server) Subscriptions() *notification.WebsocketSubscriptions {
+	return s.subscriptions
+}
+
 func newNode(log *zerolog.Logger) (*centrifuge.Node, error) {
 	lh := newLogHandler(log)
 	return centrifuge.New(centrifuge.Config{
@@ -89,18 +157,37 @@ func newNode(log *zerolog.Logger) (*centrifuge.Node, error) {
 }
 
 func (s *server) setupNode() {
-	s.node.OnConnecting(func(_ context.Context, event centrifuge.ConnectEvent) (centrifuge.ConnectReply, error) {
+	s.node.OnConnecting(func(ctx context.Context, event centrifuge.ConnectEvent) (centrifuge.ConnectReply, error) {
 		s.log.Info().Msg("client connecting")
 
+		var token *domains.Token
 		if s.isAuthRequired {
 			s.log.Debug().Msgf("client connecting with token: %s", event.Token)
-			_, err := s.tokens.GetToken(event.Token)
+			var err error
+			token, err = s.authenticate(ctx, event.Token)
 			if err != nil {
 				return centrifuge.ConnectReply{}, centrifuge.DisconnectInvalidToken
 			}
+			if token.IsExpired(time.Now()) {
+				return centrifuge.ConnectReply{}, centrifuge.DisconnectExpired
+			}
+			// scopes of the token are checked on every subscription
+			ctx = context.WithValue(ctx, tokenContextKey{}, token)
 		}
 
+		ip, _ := ctx.Value(clientIPContextKey{}).(string)
+		client := service.RateLimitClient(token, ip)
+		if !s.allow(client) {
+			return centrifuge.ConnectReply{}, centrifuge.ErrorLimitExceeded
+		}
+		if ok, _ := s.limits.UseQuota(token); !ok {
+			metrics.AddThrottledRequest("websocket", config.RateLimitGroupWebsocket, "quota")
+			return centrifuge.ConnectReply{}, centrifuge.ErrorLimitExceeded
+		}
+		ctx = context.WithValue(ctx, rateLimitClientContextKey{}, client)
+
 		return centrifuge.ConnectReply{
+			Context: ctx,
 			Credentials: &centrifuge.Credentials{
 				UserID: "",
 			},
@@ -113,12 +200,46 @@ func (s *server) setupNode() {
 
 		client.OnSubscribe(func(e centrifuge.SubscribeEvent, cb centrifuge.SubscribeCallback) {
 			s.log.Info().Msgf("user %s subscribes on %s", client.UserID(), e.Channel)
-			cb(centrifuge.SubscribeReply{
+			if limited, _ := client.Context().Value(rateLimitClientContextKey{}).(string); !s.allow(limited) {
+				cb(centrifuge.SubscribeReply{}, centrifuge.ErrorLimitExceeded)
+				return
+			}
+			sub, err := s.authorizeSubscription(client.Context(), e.Channel)
+			if err != nil {
+				s.log.Info().Msgf("user %s cannot subscribe on %s: %v", client.UserID(), e.Channel, err)
+				cb(centrifuge.SubscribeReply{}, err)
+				return
+			}
+			reply := centrifuge.SubscribeReply{
 				Options: centrifuge.SubscribeOptions{
 					EnablePositioning: true,
 					EnableRecovery:    true,
 				},
-			}, nil)
+			}
+			// events are published to a filtered channel only while it has subscribers, so it is registered
+			// before missed events are read
+			s.subscriptions.Subscribe(sub)
+			if len(e.Data) > 0 {
+				// the subscription goes live only after missed events are read from the database, so events published
+				// in the meantime are recovered from the history of the channel since the position taken before
+				since, err := s.streamPosition(e.Channel)
+				if err != nil {
+					s.log.Error().Msgf("cannot read position of %s: %v", e.Channel, err)
+					s.subscriptions.Unsubscribe(sub.Channel)
+					cb(centrifuge.SubscribeReply{}, centrifuge.ErrorInternal)
+					return
+				}
+				missed, err := s.missedEvents(sub, e.Data, since)
+				if err != nil {
+					s.log.Info().Msgf("user %s cannot recover missed events of %s: %v", client.UserID(), e.Channel, err)
+					s.subscriptions.Unsubscribe(sub.Channel)
+					cb(centrifuge.SubscribeReply{}, centrifuge.ErrorBadRequest)
+					return
+				}
+				reply.Options.Data = missed
+				reply.Options.RecoverSince = &since
+			}
+			cb(reply, nil)
 		})
 
 		client.OnHistory(func(e centrifuge.HistoryEvent, cb centrifuge.HistoryCallback) {
@@ -131,6 +252,7 @@ func (s *server) setupNode() {
 
 		client.OnUnsubscribe(func(e centrifuge.UnsubscribeEvent) {
 			s.log.Info().Msgf("user %s unsubscribed from %s", client.UserID(), e.Channel)
+			s.subscriptions.Unsubscribe(e.Channel)
 		})
 
 		client.OnDisconnect(func(e centrifuge.DisconnectEvent) {
@@ -138,3 +260,107 @@ func (s *server) setupNode() {
 		})
 	})
 }
+
+// allow checks if the client didn't exceed rate limit of websocket connections and subscriptions.
+func (s *server) allow(client string) bool {
+	if ok, _ := s.limits.Allow(client, config.RateLimitGroupWebsocket, 1); !ok {
+		metrics.AddThrottledRequest("websocket", config.RateLimitGroupWebsocket, "rate")
+		return false
+	}
+	return true
+}
+
+// authorizeSubscription checks if the channel exists and client is allowed to subscribe on it.
+func (s *server) authorizeSubscription(ctx context.Context, channel string) (*notification.WebsocketSubscription, error) {
+	sub, err := notification.ParseWebsocketChannel(channel)
+	if err != nil {
+		return nil, centrifuge.ErrorUnknownChannel
+	}
+
+	// merkle roots watched without a token are published to the channel of an empty watcher
+	watcher := ""
+	if s.isAuthRequired {
+		token, ok := ctx.Value(tokenContextKey{}).(*domains.Token)
+		if !ok || !token.HasScope(sub.Scope()) {
+			return nil, centrifuge.ErrorPermissionDenied
+		}
+		watcher = token.ActorID()
+	}
+
+	if sub.MerkleRoots && sub.Watcher != watcher {
+		return nil, centrifuge.ErrorPermissionDenied
+	}
+	return sub, nil
+}
+
+// streamPosition returns the position of the last publication in the history of the channel.
+func (s *server) streamPosition(channel string) (centrifuge.StreamPosition, error) {
+	history, err := s.node.History(channel, centrifuge.WithLimit(0))
+	if err != nil {
+		return centrifuge.StreamPosition{}, err
+	}
+	return history.StreamPosition, nil
+}
+
+// publishedSince returns ids of events published to the channel after the position, which are recovered
+// from the history of the channel.
+func (s *server) publishedSince(channel string, since centrifuge.StreamPosition) (map[string]struct{}, error) {
+	history, err := s.node.History(channel, centrifuge.WithSince(&since), centrifuge.WithLimit(centrifuge.NoLimit))
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]struct{}, len(history.Publications))
+	for _, pub := range history.Publications {
+		if id := pub.Tags[notification.EventIDTag]; id != "" {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids, nil
+}
+
+// missedEvents returns events of the channel missed since the position sent by the client in subscription data,
+// without events published after the position of the channel, which are recovered from its history.
+func (s *server) missedEvents(sub *notification.WebsocketSubscription, data []byte, since centrifuge.StreamPosition) ([]byte, error) {
+	if sub.MerkleRoots {
+		return nil, errors.New("recovery is not supported by merkle root channels")
+	}
+
+	var position domains.ChainPosition
+	if err := json.Unmarshal(data, &position); err != nil {
+		return nil, fmt.Errorf("invalid position: %w", err)
+	}
+
+	missed, err := s.recovery.MissedEvents(position)
+	if err != nil {
+		return nil, err
+	}
+
+	published, err := s.publishedSince(sub.Channel, since)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*domains.HeaderEvent, 0, len(missed.Events))
+	for _, e := range missed.Events {
+		if _, duplicated := published[notification.EventID(e)]; duplicated {
+			continue
+		}
+		if sub.Accepts(e) {
+			events = append(events, e)
+		}
+	}
+	missed.Events = events
+	if sub.Format != notification.FormatCloudEvents {
+		return json.Marshal(missed)
+	}
+
+	envelopes := make([]*notification.CloudEvent, 0, len(events))
+	for _, e := range events {
+		envelopes = append(envelopes, s.encoder.CloudEvent(e))
+	}
+	return json.Marshal(struct {
+		Events   []*notification.CloudEvent `json:"events"`
+		Complete bool                       `json:"complete"`
+		Resync   bool                       `json:"resync,omitempty"`
+	}{Events: envelopes, Complete: missed.Complete, Resync: missed.Resync})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/testapp"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testjwt"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/wait"
 	"github.com/centrifugal/centrifuge-go"
 )
@@ -22,13 +23,13 @@ func TestWebsocketCommunicationWithoutAuthentication(t *testing.T) {
 	publisher := p.Websocket().Publisher()
 
 	// when
-	onMsg, err := client.Subscribe("test")
+	onMsg, err := client.Subscribe("headers:all")
 
 	// then
 	assert.NoError(t, err)
 
 	// when
-	publisher.Publish("test", `{ "something": "value" }`)
+	publisher.Publish("headers:all", `{ "something": "value" }`)
 
 	// then
 	msg, err := wait.ForString(onMsg, time.Second)
@@ -48,7 +49,7 @@ func TestWebsocketCommunicationWithAuthentication(t *testing.T) {
 	defer client.Close()
 
 	// when
-	_, err := client.Subscribe("test")
+	_, err := client.Subscribe("headers:all")
 
 	// then
 	assert.NoError(t, err)
@@ -66,8 +67,27 @@ func TestWebsocketCommunicationWithInvalidAuthentication(t *testing.T) {
 	defer client.Close()
 
 	// when
-	_, err := client.Subscribe("test")
+	_, err := client.Subscribe("headers:all")
 
 	// then
 	assert.IsError(t, err, "invalid token")
 }
+
+func TestWebsocketCommunicationWithJWT(t *testing.T) {
+	// setup
+	issuer := testjwt.NewIssuer(t, "key-1", "RS256")
+	p, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithJWT(testjwt.WriteJWKS(t, issuer)))
+	defer cleanup()
+
+	// given
+	client := p.Websocket().ClientWithConfig(centrifuge.Config{
+		Token: issuer.Sign(t, testjwt.Claims("billing", "headers:read")),
+	})
+	defer client.Close()
+
+	// when
+	_, err := client.Subscribe("headers:all")
+
+	// then
+	assert.NoError(t, err)
+}